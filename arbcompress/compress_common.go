@@ -3,6 +3,8 @@
 
 package arbcompress
 
+import "errors"
+
 type Dictionary uint32
 
 const (
@@ -10,6 +12,12 @@ const (
 	StylusProgramDictionary
 )
 
+var ErrOutputWontFit = errors.New("output won't fit in maxsize")
+var ErrDictionaryTooLarge = errors.New("compression dictionary too large")
+
+// MaxDictionarySize is the largest custom dictionary accepted when compressing or decompressing.
+const MaxDictionarySize = 1024 * 1024
+
 const LEVEL_WELL = 11
 const WINDOW_SIZE = 22 // BROTLI_DEFAULT_WINDOW
 
@@ -0,0 +1,67 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbcompress
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+const dictionarySegmentSize = 32
+const dictionarySegmentStride = 8
+
+// TrainDictionary builds a raw brotli dictionary of at most maxSize bytes out of the
+// segments which repeat across the most samples.
+// Segments are ordered from least to most common, as brotli finds matches at the end of
+// the dictionary with the shortest distances.
+func TrainDictionary(samples [][]byte, maxSize int) []byte {
+	maxSize = min(maxSize, MaxDictionarySize)
+	counts := make(map[string]int)
+	for _, sample := range samples {
+		seen := make(map[string]struct{})
+		for pos := 0; pos+dictionarySegmentSize <= len(sample); pos += dictionarySegmentStride {
+			segment := string(sample[pos : pos+dictionarySegmentSize])
+			if _, ok := seen[segment]; ok {
+				continue
+			}
+			seen[segment] = struct{}{}
+			counts[segment]++
+		}
+	}
+
+	var candidates []string
+	for segment, count := range counts {
+		// a segment seen in a single sample won't help compressing other batches
+		if count > 1 {
+			candidates = append(candidates, segment)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	var selected [][]byte
+	var dictionary []byte
+	for _, segment := range candidates {
+		if len(dictionary)+len(segment) > maxSize {
+			break
+		}
+		// overlapping segments of a common sequence are likely already included
+		if bytes.Contains(dictionary, []byte(segment)) {
+			continue
+		}
+		selected = append(selected, []byte(segment))
+		dictionary = append(dictionary, segment...)
+	}
+
+	dictionary = dictionary[:0:0]
+	for i := len(selected) - 1; i >= 0; i-- {
+		dictionary = append(dictionary, selected[i]...)
+	}
+	return dictionary
+}
@@ -0,0 +1,92 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbcompress
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func testDictionaryCompressDecompress(t *testing.T, data []byte, dictionary []byte) []byte {
+	compressed, err := CompressWithCustomDictionary(data, LEVEL_WELL, dictionary)
+	Require(t, err)
+	res, err := DecompressWithCustomDictionary(compressed, len(data), dictionary)
+	Require(t, err)
+	if !bytes.Equal(res, data) {
+		t.Fatal("results differ ", res, " vs. ", data)
+	}
+	return compressed
+}
+
+func TestTrainedDictionary(t *testing.T) {
+	source := testhelpers.NewPseudoRandomDataSource(t, 1)
+	template := source.GetData(512)
+	var samples [][]byte
+	for i := 0; i < 64; i++ {
+		sample := append([]byte(fmt.Sprintf("transfer %d to ", i)), template...)
+		samples = append(samples, append(sample, source.GetData(16)...))
+	}
+	dictionary := TrainDictionary(samples, 4096)
+	if len(dictionary) == 0 || len(dictionary) > 4096 {
+		t.Fatal("unexpected dictionary size", len(dictionary))
+	}
+
+	sample := append([]byte("transfer 1000 to "), template...)
+	withoutDictionary, err := CompressWell(sample)
+	Require(t, err)
+	withDictionary := testDictionaryCompressDecompress(t, sample, dictionary)
+	if len(withDictionary) >= len(withoutDictionary) {
+		t.Fatal("trained dictionary didn't improve compression", len(withDictionary), "vs.", len(withoutDictionary))
+	}
+	testDictionaryCompressDecompress(t, []byte{}, dictionary)
+
+	if res, err := Decompress(withDictionary, len(sample)); err == nil && bytes.Equal(res, sample) {
+		t.Fatal("decompressed without the dictionary it was compressed with")
+	}
+	if _, err := CompressWithCustomDictionary(sample, LEVEL_WELL, make([]byte, MaxDictionarySize+1)); err == nil {
+		t.Fatal("expected an error compressing with a dictionary over the maximum size")
+	}
+}
+
+func TestDictionaryWriter(t *testing.T) {
+	source := testhelpers.NewPseudoRandomDataSource(t, 1)
+	template := source.GetData(256)
+	dictionary := append(source.GetData(64), template...)
+
+	var data []byte
+	var output bytes.Buffer
+	writer, err := NewDictionaryWriter(&output, LEVEL_WELL, dictionary)
+	Require(t, err)
+	lastSize := 0
+	for i := 0; i < 16; i++ {
+		chunk := append([]byte(fmt.Sprintf("chunk %d ", i)), template...)
+		if i%4 == 0 {
+			// large enough to fill the output buffer given to a single compression call
+			chunk = append(chunk, source.GetData(4096)...)
+		}
+		data = append(data, chunk...)
+		_, err := writer.Write(chunk)
+		Require(t, err)
+		Require(t, writer.Flush())
+		// flushes only append the newly written data to the output
+		if output.Len() <= lastSize {
+			t.Fatal("flush didn't extend the output")
+		}
+		lastSize = output.Len()
+	}
+	Require(t, writer.Close())
+	Require(t, writer.Close())
+
+	res, err := DecompressWithCustomDictionary(output.Bytes(), len(data), dictionary)
+	Require(t, err)
+	if !bytes.Equal(res, data) {
+		t.Fatal("results differ")
+	}
+	if _, err := writer.Write(data); err == nil {
+		t.Fatal("expected an error writing to a closed writer")
+	}
+}
@@ -16,6 +16,8 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"io"
+	"runtime"
 )
 
 type u8 = C.uint8_t
@@ -48,8 +50,6 @@ func Compress(input []byte, level uint32, dictionary Dictionary) ([]byte, error)
 	return output, nil
 }
 
-var ErrOutputWontFit = errors.New("output won't fit in maxsize")
-
 func Decompress(input []byte, maxSize int) ([]byte, error) {
 	return DecompressWithDictionary(input, maxSize, EmptyDictionary)
 }
@@ -73,6 +73,128 @@ func DecompressWithDictionary(input []byte, maxSize int, dictionary Dictionary)
 	return output, nil
 }
 
+// CompressWithCustomDictionary compresses input with a raw brotli dictionary, which has to be
+// given to DecompressWithCustomDictionary as well.
+func CompressWithCustomDictionary(input []byte, level uint32, dictionary []byte) ([]byte, error) {
+	if len(dictionary) > MaxDictionarySize {
+		return nil, ErrDictionaryTooLarge
+	}
+	maxSize := compressedBufferSizeFor(len(input))
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+	dictbuf := sliceToBuffer(dictionary)
+
+	status := C.brotli_compress_with_dictionary(inbuf, outbuf, dictbuf, u32(level))
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed compression: %d", status)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
+func DecompressWithCustomDictionary(input []byte, maxSize int, dictionary []byte) ([]byte, error) {
+	if len(dictionary) > MaxDictionarySize {
+		return nil, ErrDictionaryTooLarge
+	}
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+	dictbuf := sliceToBuffer(dictionary)
+
+	status := C.brotli_decompress_with_dictionary(inbuf, outbuf, dictbuf)
+	if status == C.BrotliStatus_NeedsMoreOutput {
+		return nil, ErrOutputWontFit
+	}
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed decompression: %d", status)
+	}
+	if *outbuf.len > usize(maxSize) {
+		return nil, fmt.Errorf("failed decompression: result too large: %d", *outbuf.len)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
+// DictionaryWriter is a streaming brotli compressor using a custom raw dictionary.
+// Written data is compressed on Flush, with the output appended to the underlying writer.
+type DictionaryWriter struct {
+	output  io.Writer
+	encoder *C.DictionaryEncoder
+	pending []byte
+}
+
+// NewDictionaryWriter returns a streaming brotli compressor using a custom raw dictionary, which has
+// to be given to DecompressWithCustomDictionary as well.
+func NewDictionaryWriter(w io.Writer, level uint32, dictionary []byte) (*DictionaryWriter, error) {
+	if len(dictionary) > MaxDictionarySize {
+		return nil, ErrDictionaryTooLarge
+	}
+	encoder := C.brotli_encoder_create_with_dictionary(sliceToBuffer(dictionary), u32(level))
+	if encoder == nil {
+		return nil, errors.New("failed to create brotli encoder")
+	}
+	writer := &DictionaryWriter{output: w, encoder: encoder}
+	// writers replaced before being closed still have to free their encoder
+	runtime.SetFinalizer(writer, (*DictionaryWriter).free)
+	return writer, nil
+}
+
+func (w *DictionaryWriter) Write(p []byte) (int, error) {
+	if w.encoder == nil {
+		return 0, errors.New("write to closed brotli writer")
+	}
+	w.pending = append(w.pending, p...)
+	return len(p), nil
+}
+
+// Flush compresses the data written since the last flush.
+func (w *DictionaryWriter) Flush() error {
+	return w.compress(false)
+}
+
+// Close compresses the remaining data, finishes the stream and frees the encoder.
+func (w *DictionaryWriter) Close() error {
+	if w.encoder == nil {
+		return nil
+	}
+	err := w.compress(true)
+	w.free()
+	return err
+}
+
+func (w *DictionaryWriter) compress(finish bool) error {
+	if w.encoder == nil {
+		return errors.New("flush of closed brotli writer")
+	}
+	input := w.pending
+	w.pending = nil
+	for {
+		output := make([]byte, compressedBufferSizeFor(len(input)))
+		inbuf := sliceToBuffer(input)
+		outbuf := sliceToBuffer(output)
+		status := C.brotli_encoder_compress(w.encoder, inbuf, outbuf, C.bool(finish))
+		if status != C.BrotliStatus_Success && status != C.BrotliStatus_NeedsMoreOutput {
+			return fmt.Errorf("failed compression: %d", status)
+		}
+		if _, err := w.output.Write(output[:*outbuf.len]); err != nil {
+			return err
+		}
+		// #nosec G115
+		input = input[len(input)-int(*inbuf.len):]
+		if status == C.BrotliStatus_Success {
+			return nil
+		}
+	}
+}
+
+func (w *DictionaryWriter) free() {
+	if w.encoder != nil {
+		C.brotli_encoder_destroy(w.encoder)
+		w.encoder = nil
+	}
+}
+
 func sliceToBuffer(slice []byte) brotliBuffer {
 	count := usize(len(slice))
 	if count == 0 {
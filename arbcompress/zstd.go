@@ -0,0 +1,62 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbcompress
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstd is implemented in pure go, so unlike brotli the same code is used natively and in the replay binary.
+
+// NewZstdWriter returns a streaming zstd compressor writing to w.
+func NewZstdWriter(w io.Writer, level int) (*zstd.Encoder, error) {
+	return zstd.NewWriter(w,
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithEncoderCRC(false),
+	)
+}
+
+func CompressZstd(input []byte, level int) ([]byte, error) {
+	var output bytes.Buffer
+	writer, err := NewZstdWriter(&output, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(input); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// DecompressZstd decompresses input, returning ErrOutputWontFit if the result would be larger than maxSize.
+func DecompressZstd(input []byte, maxSize int) ([]byte, error) {
+	// #nosec G115
+	maxWindow := uint64(max(maxSize, zstd.MinWindowSize))
+	reader, err := zstd.NewReader(bytes.NewReader(input),
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderLowmem(true),
+		zstd.WithDecoderMaxWindow(maxWindow),
+		zstd.WithDecoderMaxMemory(maxWindow),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	output, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(output) > maxSize {
+		return nil, ErrOutputWontFit
+	}
+	return output, nil
+}
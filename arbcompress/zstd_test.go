@@ -0,0 +1,44 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbcompress
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func testZstdCompressDecompress(t *testing.T, data []byte) {
+	compressed, err := CompressZstd(data, 3)
+	Require(t, err)
+	res, err := DecompressZstd(compressed, len(data))
+	Require(t, err)
+	if !bytes.Equal(res, data) {
+		t.Fatal("results differ ", res, " vs. ", data)
+	}
+	if len(data) > 0 {
+		if _, err := DecompressZstd(compressed, len(data)-1); !errors.Is(err, ErrOutputWontFit) {
+			t.Fatal("expected ErrOutputWontFit, got", err)
+		}
+	}
+}
+
+func TestZstdCompress(t *testing.T) {
+	asciiData := []byte("This is a long and repetitive string. Yadda yadda yadda yadda yadda. The quick brown fox jumped over the lazy dog.")
+	for i := 0; i < 8; i++ {
+		asciiData = append(asciiData, asciiData...)
+	}
+	testZstdCompressDecompress(t, asciiData)
+
+	source := testhelpers.NewPseudoRandomDataSource(t, 0)
+	testZstdCompressDecompress(t, source.GetData(2500))
+	testZstdCompressDecompress(t, []byte{})
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
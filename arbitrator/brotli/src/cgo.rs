@@ -1,8 +1,9 @@
 // Copyright 2021-2024, Offchain Labs, Inc.
 // For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
 
-use crate::{BrotliStatus, Dictionary, DEFAULT_WINDOW_SIZE};
-use core::{mem::MaybeUninit, slice};
+use crate::{BrotliStatus, Dictionary, DictionaryEncoder, DEFAULT_WINDOW_SIZE};
+use alloc::boxed::Box;
+use core::{mem::MaybeUninit, ptr, slice};
 
 /// Mechanism for passing data between Go and Rust where Rust can specify the initialized length.
 #[derive(Clone, Copy)]
@@ -64,3 +65,83 @@ pub extern "C" fn brotli_decompress(
     }
     BrotliStatus::Success
 }
+
+/// Brotli compresses the given Go data into a buffer of limited capacity using a custom dictionary.
+#[no_mangle]
+pub extern "C" fn brotli_compress_with_dictionary(
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    dictionary: BrotliBuffer,
+    level: u32,
+) -> BrotliStatus {
+    let window = DEFAULT_WINDOW_SIZE;
+    let buffer = output.as_uninit();
+    let dict = dictionary.as_slice();
+    match crate::compress_fixed_with_dictionary(input.as_slice(), buffer, level, window, dict) {
+        Ok(slice) => unsafe { *output.len = slice.len() },
+        Err(status) => return status,
+    }
+    BrotliStatus::Success
+}
+
+/// Brotli decompresses the given Go data into a buffer of limited capacity using a custom dictionary.
+#[no_mangle]
+pub extern "C" fn brotli_decompress_with_dictionary(
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    dictionary: BrotliBuffer,
+) -> BrotliStatus {
+    let dict = dictionary.as_slice();
+    match crate::decompress_fixed_with_dictionary(input.as_slice(), output.as_uninit(), dict) {
+        Ok(slice) => unsafe { *output.len = slice.len() },
+        Err(status) => return status,
+    }
+    BrotliStatus::Success
+}
+
+/// Creates a brotli encoder using a custom dictionary, which compresses Go data as it's given.
+/// Returns null on failure. The encoder must be freed with `brotli_encoder_destroy`.
+#[no_mangle]
+pub extern "C" fn brotli_encoder_create_with_dictionary(
+    dictionary: BrotliBuffer,
+    level: u32,
+) -> *mut DictionaryEncoder {
+    let window = DEFAULT_WINDOW_SIZE;
+    match DictionaryEncoder::new(level, window, dictionary.as_slice()) {
+        Ok(encoder) => Box::into_raw(Box::new(encoder)),
+        Err(_) => ptr::null_mut(),
+    }
+}
+
+/// Compresses the given Go data into a buffer of limited capacity, then flushes or finishes the stream.
+/// Rust sets the input length to the number of bytes left, and returns `NeedsMoreOutput` if the call
+/// should be repeated with them and another buffer.
+///
+/// # Safety
+///
+/// The encoder must have been created by `brotli_encoder_create_with_dictionary` and not yet destroyed.
+#[no_mangle]
+pub unsafe extern "C" fn brotli_encoder_compress(
+    encoder: *mut DictionaryEncoder,
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    finish: bool,
+) -> BrotliStatus {
+    let mut data = input.as_slice();
+    let (written, status) = (*encoder).compress(&mut data, output.as_uninit(), finish);
+    *input.len = data.len();
+    *output.len = written;
+    status
+}
+
+/// Frees an encoder created by `brotli_encoder_create_with_dictionary`.
+///
+/// # Safety
+///
+/// The encoder must not be used afterward.
+#[no_mangle]
+pub unsafe extern "C" fn brotli_encoder_destroy(encoder: *mut DictionaryEncoder) {
+    if !encoder.is_null() {
+        drop(Box::from_raw(encoder));
+    }
+}
@@ -51,6 +51,26 @@ lazy_static! {
         });
 }
 
+/// Prepares a custom LZ77 dictionary for compression. The caller must destroy the result.
+pub(crate) unsafe fn prepare_raw(
+    data: &[u8],
+    level: u32,
+) -> Result<*const EncoderPreparedDictionary, BrotliStatus> {
+    let dict = BrotliEncoderPrepareDictionary(
+        BrotliSharedDictionaryType::Raw,
+        data.len() as c_int,
+        data.as_ptr(),
+        level as c_int,
+        None,
+        None,
+        ptr::null_mut(),
+    );
+    if dict.is_null() {
+        return Err(BrotliStatus::Failure);
+    }
+    Ok(dict as _)
+}
+
 /// Brotli dictionary selection.
 #[derive(Clone, Copy, Debug, PartialEq, IntoPrimitive, TryFromPrimitive)]
 #[repr(u32)]
@@ -53,6 +53,8 @@ extern "C" {
         dictionary: *const EncoderPreparedDictionary,
     ) -> BrotliBool;
 
+    fn BrotliEncoderDestroyPreparedDictionary(dictionary: *mut EncoderPreparedDictionary);
+
     fn BrotliEncoderCompressStream(
         state: *mut EncoderState,
         op: BrotliEncoderOperation,
@@ -65,6 +67,8 @@ extern "C" {
 
     fn BrotliEncoderIsFinished(state: *mut EncoderState) -> BrotliBool;
 
+    fn BrotliEncoderHasMoreOutput(state: *mut EncoderState) -> BrotliBool;
+
     fn BrotliEncoderDestroyInstance(state: *mut EncoderState);
 }
 
@@ -141,6 +145,33 @@ pub fn compress_fixed<'a>(
     level: u32,
     window_size: u32,
     dictionary: Dictionary,
+) -> Result<&'a [u8], BrotliStatus> {
+    let dict = dictionary.ptr(level)?;
+    unsafe { compress_fixed_impl(input, output, level, window_size, dict) }
+}
+
+/// Brotli compresses a slice into a buffer of limited capacity using a custom raw LZ77 dictionary.
+pub fn compress_fixed_with_dictionary<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    level: u32,
+    window_size: u32,
+    dictionary: &[u8],
+) -> Result<&'a [u8], BrotliStatus> {
+    unsafe {
+        let dict = dicts::prepare_raw(dictionary, level)?;
+        let result = compress_fixed_impl(input, output, level, window_size, Some(dict));
+        BrotliEncoderDestroyPreparedDictionary(dict as _);
+        result
+    }
+}
+
+unsafe fn compress_fixed_impl<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    level: u32,
+    window_size: u32,
+    dictionary: Option<*const EncoderPreparedDictionary>,
 ) -> Result<&'a [u8], BrotliStatus> {
     unsafe {
         let state = BrotliEncoderCreateInstance(None, None, ptr::null_mut());
@@ -166,10 +197,8 @@ pub fn compress_fixed<'a>(
         ));
 
         // attach a custom dictionary if requested
-        match dictionary.ptr(level) {
-            Ok(Some(dict)) => check!(BrotliEncoderAttachPreparedDictionary(state, dict)),
-            Err(status) => check!(status),
-            _ => {}
+        if let Some(dict) = dictionary {
+            check!(BrotliEncoderAttachPreparedDictionary(state, dict));
         }
 
         let mut in_len = input.len();
@@ -197,6 +226,91 @@ pub fn compress_fixed<'a>(
     }
 }
 
+/// A brotli encoder using a custom raw LZ77 dictionary, which compresses its input as it's given.
+pub struct DictionaryEncoder {
+    state: *mut EncoderState,
+    dictionary: *const EncoderPreparedDictionary,
+}
+
+impl DictionaryEncoder {
+    pub fn new(level: u32, window_size: u32, dictionary: &[u8]) -> Result<Self, BrotliStatus> {
+        unsafe {
+            let dictionary = dicts::prepare_raw(dictionary, level)?;
+            let state = BrotliEncoderCreateInstance(None, None, ptr::null_mut());
+            let encoder = Self { state, dictionary };
+            if state.is_null()
+                || BrotliEncoderSetParameter(state, BrotliEncoderParameter::Quality, level).is_err()
+                || BrotliEncoderSetParameter(state, BrotliEncoderParameter::WindowSize, window_size)
+                    .is_err()
+                || BrotliEncoderAttachPreparedDictionary(state, dictionary).is_err()
+            {
+                return Err(BrotliStatus::Failure);
+            }
+            Ok(encoder)
+        }
+    }
+
+    /// Compresses the input into a buffer of limited capacity, then flushes or finishes the stream.
+    /// Advances the input past the bytes consumed and returns the number of bytes written, with
+    /// [`BrotliStatus::NeedsMoreOutput`] if the buffer filled up first and the call should be repeated.
+    pub fn compress(
+        &mut self,
+        input: &mut &[u8],
+        output: &mut [MaybeUninit<u8>],
+        finish: bool,
+    ) -> (usize, BrotliStatus) {
+        let op = if finish {
+            BrotliEncoderOperation::Finish
+        } else {
+            BrotliEncoderOperation::Flush
+        };
+        unsafe {
+            let mut in_len = input.len();
+            let mut in_ptr = input.as_ptr();
+            let mut out_left = output.len();
+            let mut out_ptr = output.as_mut_ptr() as *mut u8;
+            let mut out_len = 0;
+
+            let status = BrotliEncoderCompressStream(
+                self.state,
+                op,
+                &mut in_len as _,
+                &mut in_ptr as _,
+                &mut out_left as _,
+                &mut out_ptr as _,
+                &mut out_len as _,
+            );
+            let data: &[u8] = *input;
+            *input = &data[data.len() - in_len..];
+            let written = output.len() - out_left;
+            if status.is_err() {
+                return (written, BrotliStatus::Failure);
+            }
+            let done = if finish {
+                BrotliEncoderIsFinished(self.state).is_ok()
+            } else {
+                BrotliEncoderHasMoreOutput(self.state).is_err()
+            };
+            if in_len > 0 || !done {
+                return (written, BrotliStatus::NeedsMoreOutput);
+            }
+            (written, BrotliStatus::Success)
+        }
+    }
+}
+
+impl Drop for DictionaryEncoder {
+    fn drop(&mut self) {
+        unsafe {
+            // the encoder references the dictionary, so it's destroyed first
+            if !self.state.is_null() {
+                BrotliEncoderDestroyInstance(self.state);
+            }
+            BrotliEncoderDestroyPreparedDictionary(self.dictionary as _);
+        }
+    }
+}
+
 /// Brotli compresses a slice into a buffer of limited capacity.
 pub fn decompress(input: &[u8], dictionary: Dictionary) -> Result<Vec<u8>, BrotliStatus> {
     unsafe {
@@ -262,6 +376,23 @@ pub fn decompress_fixed<'a>(
     input: &'a [u8],
     output: &'a mut [MaybeUninit<u8>],
     dictionary: Dictionary,
+) -> Result<&'a [u8], BrotliStatus> {
+    unsafe { decompress_fixed_impl(input, output, dictionary.slice()) }
+}
+
+/// Brotli decompresses a slice compressed with a custom raw LZ77 dictionary into a buffer of limited capacity.
+pub fn decompress_fixed_with_dictionary<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    dictionary: &[u8],
+) -> Result<&'a [u8], BrotliStatus> {
+    unsafe { decompress_fixed_impl(input, output, Some(dictionary)) }
+}
+
+unsafe fn decompress_fixed_impl<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    dictionary: Option<&[u8]>,
 ) -> Result<&'a [u8], BrotliStatus> {
     unsafe {
         let state = BrotliDecoderCreateInstance(None, None, ptr::null_mut());
@@ -275,7 +406,7 @@ pub fn decompress_fixed<'a>(
             };
         }
 
-        if let Some(dict) = dictionary.slice() {
+        if let Some(dict) = dictionary {
             let attatched = BrotliDecoderAttachDictionary(
                 state,
                 BrotliSharedDictionaryType::Raw,
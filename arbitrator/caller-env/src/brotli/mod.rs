@@ -70,3 +70,35 @@ pub fn brotli_decompress<M: MemAccess, E: ExecEnv>(
         Err(status) => status,
     }
 }
+
+/// Brotli decompresses a go slice compressed with a custom dictionary held in guest memory.
+///
+/// # Safety
+///
+/// The output buffer must be sufficiently large.
+/// The pointers must not be null.
+pub fn brotli_decompress_with_dictionary<M: MemAccess, E: ExecEnv>(
+    mem: &mut M,
+    _env: &mut E,
+    in_buf_ptr: GuestPtr,
+    in_buf_len: u32,
+    out_buf_ptr: GuestPtr,
+    out_len_ptr: GuestPtr,
+    dict_buf_ptr: GuestPtr,
+    dict_buf_len: u32,
+) -> BrotliStatus {
+    let input = mem.read_slice(in_buf_ptr, in_buf_len as usize);
+    let dictionary = mem.read_slice(dict_buf_ptr, dict_buf_len as usize);
+    let mut output = Vec::with_capacity(mem.read_u32(out_len_ptr) as usize);
+
+    let result =
+        brotli::decompress_fixed_with_dictionary(&input, output.spare_capacity_mut(), &dictionary);
+    match result {
+        Ok(slice) => {
+            mem.write_slice(out_buf_ptr, slice);
+            mem.write_u32(out_len_ptr, slice.len() as u32);
+            BrotliStatus::Success
+        }
+        Err(status) => status,
+    }
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -38,6 +39,7 @@ import (
 	"github.com/offchainlabs/nitro/arbnode/dataposter"
 	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
 	"github.com/offchainlabs/nitro/arbnode/redislock"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
@@ -90,6 +92,9 @@ type batchPosterPosition struct {
 	MessageCount        arbutil.MessageIndex
 	DelayedMessageCount uint64
 	NextSeqNum          uint64
+	// DABackend is the DA backend which carried the batch ending at this position.
+	// It's empty for positions read from the parent chain or written by older versions.
+	DABackend string `rlp:"optional"`
 }
 
 type BatchPoster struct {
@@ -108,6 +113,9 @@ type BatchPoster struct {
 	building           *buildingBatch
 	dapWriter          daprovider.Writer
 	dapReaders         []daprovider.Reader
+	dictReader         arbstate.DictionaryReader
+	compressionDict    []byte
+	daBackends         *daBackendSelector
 	dataPoster         *dataposter.DataPoster
 	redisLock          *redislock.Simple
 	messagesPerBatch   *arbmath.MovingAverage[uint64]
@@ -123,6 +131,8 @@ type BatchPoster struct {
 	postedFirstBatch     bool        // indicates if batch poster has posted the first batch
 
 	accessList func(SequencerInboxAccs, AfterDelayedMessagesRead uint64) types.AccessList
+
+	dryRun dryRunState // only used when DryRun is enabled
 }
 
 type l1BlockBound int
@@ -157,6 +167,8 @@ type BatchPosterConfig struct {
 	// Batch posting error delay.
 	ErrorDelay                     time.Duration               `koanf:"error-delay" reload:"hot"`
 	CompressionLevel               int                         `koanf:"compression-level" reload:"hot"`
+	CompressionAlgorithm           string                      `koanf:"compression-algorithm" reload:"hot"`
+	CompressionDictionary          string                      `koanf:"compression-dictionary"`
 	DASRetentionPeriod             time.Duration               `koanf:"das-retention-period" reload:"hot"`
 	GasRefunderAddress             string                      `koanf:"gas-refunder-address" reload:"hot"`
 	DataPoster                     dataposter.DataPosterConfig `koanf:"data-poster" reload:"hot"`
@@ -175,6 +187,8 @@ type BatchPosterConfig struct {
 	CheckBatchCorrectness          bool                        `koanf:"check-batch-correctness"`
 	MaxEmptyBatchDelay             time.Duration               `koanf:"max-empty-batch-delay"`
 	DelayBufferThresholdMargin     uint64                      `koanf:"delay-buffer-threshold-margin"`
+	DAFallback                     DAFallbackConfig            `koanf:"da-fallback" reload:"hot"`
+	DryRun                         BatchPosterDryRunConfig     `koanf:"dry-run" reload:"hot"`
 
 	gasRefunder  common.Address
 	l1BlockBound l1BlockBound
@@ -201,6 +215,12 @@ func (c *BatchPosterConfig) Validate() error {
 	} else {
 		return fmt.Errorf("invalid L1 block bound tag \"%v\" (see --help for options)", c.L1BlockBound)
 	}
+	if err := validateCompressionConfig(c.CompressionAlgorithm, c.CompressionDictionary); err != nil {
+		return err
+	}
+	if err := c.DAFallback.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -220,6 +240,8 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Duration(prefix+".poll-interval", DefaultBatchPosterConfig.PollInterval, "how long to wait after no batches are ready to be posted before checking again")
 	f.Duration(prefix+".error-delay", DefaultBatchPosterConfig.ErrorDelay, "how long to delay after error posting batch")
 	f.Int(prefix+".compression-level", DefaultBatchPosterConfig.CompressionLevel, "batch compression level")
+	f.String(prefix+".compression-algorithm", DefaultBatchPosterConfig.CompressionAlgorithm, fmt.Sprintf("batch compression algorithm (%v)", knownCompressionAlgorithms))
+	f.String(prefix+".compression-dictionary", DefaultBatchPosterConfig.CompressionDictionary, "file containing the trained dictionary used by the brotli-dictionary batch compression algorithm, published by the first batch using it")
 	f.Duration(prefix+".das-retention-period", DefaultBatchPosterConfig.DASRetentionPeriod, "In AnyTrust mode, the period which DASes are requested to retain the stored batches.")
 	f.String(prefix+".gas-refunder-address", DefaultBatchPosterConfig.GasRefunderAddress, "The gas refunder contract address (optional)")
 	f.Uint64(prefix+".extra-batch-gas", DefaultBatchPosterConfig.ExtraBatchGas, "use this much more gas than estimation says is necessary to post batches")
@@ -238,6 +260,8 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	dataposter.DataPosterConfigAddOptions(prefix+".data-poster", f, dataposter.DefaultDataPosterConfig)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultBatchPosterConfig.ParentChainWallet.Pathname)
 	DangerousBatchPosterConfigAddOptions(prefix+".dangerous", f)
+	DAFallbackConfigAddOptions(prefix+".da-fallback", f)
+	BatchPosterDryRunConfigAddOptions(prefix+".dry-run", f)
 }
 
 var DefaultBatchPosterConfig = BatchPosterConfig{
@@ -252,6 +276,8 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	MaxDelay:                       time.Hour,
 	WaitForMaxDelay:                false,
 	CompressionLevel:               brotli.BestCompression,
+	CompressionAlgorithm:           CompressionBrotli,
+	CompressionDictionary:          "",
 	DASRetentionPeriod:             daprovider.DefaultDASRetentionPeriod,
 	GasRefunderAddress:             "",
 	ExtraBatchGas:                  50_000,
@@ -268,6 +294,8 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	CheckBatchCorrectness:          true,
 	MaxEmptyBatchDelay:             3 * 24 * time.Hour,
 	DelayBufferThresholdMargin:     25, // 5 minutes considering 12-second blocks
+	DAFallback:                     DefaultDAFallbackConfig,
+	DryRun:                         DefaultBatchPosterDryRunConfig,
 }
 
 var DefaultBatchPosterL1WalletConfig = genericconf.WalletConfig{
@@ -287,6 +315,7 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	MaxDelay:                       0,
 	WaitForMaxDelay:                false,
 	CompressionLevel:               2,
+	CompressionAlgorithm:           CompressionBrotli,
 	DASRetentionPeriod:             daprovider.DefaultDASRetentionPeriod,
 	GasRefunderAddress:             "",
 	ExtraBatchGas:                  10_000,
@@ -300,6 +329,8 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	GasEstimateBaseFeeMultipleBips: arbmath.OneInUBips * 3 / 2,
 	CheckBatchCorrectness:          true,
 	DelayBufferThresholdMargin:     0,
+	DAFallback:                     DefaultDAFallbackConfig,
+	DryRun:                         DefaultBatchPosterDryRunConfig,
 }
 
 type BatchPosterOpts struct {
@@ -315,6 +346,8 @@ type BatchPosterOpts struct {
 	DAPWriter     daprovider.Writer
 	ParentChainID *big.Int
 	DAPReaders    []daprovider.Reader
+	// DAPWriters are the DA writers keyed by DA backend name, used when DA fallback is enabled
+	DAPWriters map[string]daprovider.Writer
 }
 
 func NewBatchPoster(ctx context.Context, opts *BatchPosterOpts) (*BatchPoster, error) {
@@ -343,6 +376,14 @@ func NewBatchPoster(ctx context.Context, opts *BatchPosterOpts) (*BatchPoster, e
 	if err != nil {
 		return nil, err
 	}
+	compressionDictionary, err := readCompressionDictionary(opts.Config().CompressionDictionary)
+	if err != nil {
+		return nil, err
+	}
+	var dictReader arbstate.DictionaryReader
+	if len(compressionDictionary) > 0 {
+		dictReader = arbstate.NewStaticDictionaryReader(compressionDictionary)
+	}
 	b := &BatchPoster{
 		l1Reader:           opts.L1Reader,
 		inbox:              opts.Inbox,
@@ -356,8 +397,11 @@ func NewBatchPoster(ctx context.Context, opts *BatchPosterOpts) (*BatchPoster, e
 		gasRefunderAddr:    opts.Config().gasRefunder,
 		bridgeAddr:         opts.DeployInfo.Bridge,
 		dapWriter:          opts.DAPWriter,
+		daBackends:         newDABackendSelector(opts.DAPWriters),
 		redisLock:          redisLock,
 		dapReaders:         opts.DAPReaders,
+		dictReader:         dictReader,
+		compressionDict:    compressionDictionary,
 	}
 	b.messagesPerBatch, err = arbmath.NewMovingAverage[uint64](20)
 	if err != nil {
@@ -418,6 +462,7 @@ type simulatedMuxBackend struct {
 	allMsgs               map[arbutil.MessageIndex]*arbostypes.MessageWithMetadata
 	delayedInboxStart     uint64
 	delayedInbox          []*arbostypes.MessageWithMetadata
+	batchCompression      bool
 }
 
 func (b *simulatedMuxBackend) PeekSequencerInbox() ([]byte, common.Hash, error) {
@@ -429,6 +474,10 @@ func (b *simulatedMuxBackend) AdvanceSequencerInbox()              {}
 func (b *simulatedMuxBackend) GetPositionWithinMessage() uint64    { return b.positionWithinMessage }
 func (b *simulatedMuxBackend) SetPositionWithinMessage(pos uint64) { b.positionWithinMessage = pos }
 
+func (b *simulatedMuxBackend) BatchCompressionEnabled() (bool, error) {
+	return b.batchCompression, nil
+}
+
 func (b *simulatedMuxBackend) ReadDelayedInbox(seqNum uint64) (*arbostypes.L1IncomingMessage, error) {
 	pos := arbmath.SaturatingUSub(seqNum, b.delayedInboxStart)
 	if pos < uint64(len(b.delayedInbox)) {
@@ -710,7 +759,8 @@ var errBatchAlreadyClosed = errors.New("batch segments already closed")
 
 type batchSegments struct {
 	compressedBuffer      *bytes.Buffer
-	compressedWriter      *brotli.Writer
+	compressedWriter      batchCompressor
+	compression           *batchCompression
 	rawSegments           [][]byte
 	timestamp             uint64
 	blockNum              uint64
@@ -730,13 +780,14 @@ type buildingBatch struct {
 	msgCount           arbutil.MessageIndex
 	haveUsefulMessage  bool
 	use4844            bool
+	daBackend          string
 	muxBackend         *simulatedMuxBackend
 	firstDelayedMsg    *arbostypes.MessageWithMetadata
 	firstNonDelayedMsg *arbostypes.MessageWithMetadata
 	firstUsefulMsg     *arbostypes.MessageWithMetadata
 }
 
-func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog uint64, use4844 bool) *batchSegments {
+func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog uint64, use4844 bool, daBackend string, compression *batchCompression) (*batchSegments, error) {
 	maxSize := config.MaxSize
 	if use4844 {
 		maxSize = config.Max4844BatchSize
@@ -746,6 +797,26 @@ func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog ui
 		}
 		maxSize -= 40
 	}
+	if daBackend != "" {
+		// DA writers aren't bound by the parent chain's size limits, so their limit may be larger
+		limit := config.DAFallback.backend(daBackend).MaxSize
+		if limit > 0 && (isDAWriterBackend(daBackend) || limit < maxSize) {
+			maxSize = limit
+		}
+	}
+	if !use4844 && !isDAWriterBackend(daBackend) && compression.zeroheavyEncodedForCalldata() {
+		// zeroheavy encoding grows compressed data by about a fifth
+		maxSize = maxSize * 4 / 5
+	}
+	if headerSize := len(compression.header()); headerSize > 1 {
+		// an inline dictionary taking most of the batch isn't worth publishing yet
+		if headerSize > maxSize/2 {
+			log.Warn("batch compression dictionary doesn't fit in the batch, compressing with brotli", "dictionarySize", len(compression.dictionary), "maxSize", maxSize)
+			compression = &batchCompression{algorithm: CompressionBrotli}
+		} else {
+			maxSize -= headerSize
+		}
+	}
 	compressedBuffer := bytes.NewBuffer(make([]byte, 0, maxSize*2))
 	compressionLevel := config.CompressionLevel
 	recompressionLevel := config.CompressionLevel
@@ -767,19 +838,28 @@ func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog ui
 		)
 		recompressionLevel = compressionLevel
 	}
+	compressedWriter, err := compression.newWriter(compressedBuffer, compressionLevel)
+	if err != nil {
+		return nil, err
+	}
 	return &batchSegments{
 		compressedBuffer:   compressedBuffer,
-		compressedWriter:   brotli.NewWriterLevel(compressedBuffer, compressionLevel),
+		compressedWriter:   compressedWriter,
+		compression:        compression,
 		sizeLimit:          maxSize,
 		recompressionLevel: recompressionLevel,
 		rawSegments:        make([][]byte, 0, 128),
 		delayedMsg:         firstDelayed,
-	}
+	}, nil
 }
 
 func (s *batchSegments) recompressAll() error {
 	s.compressedBuffer = bytes.NewBuffer(make([]byte, 0, s.sizeLimit*2))
-	s.compressedWriter = brotli.NewWriterLevel(s.compressedBuffer, s.recompressionLevel)
+	compressedWriter, err := s.compression.newWriter(s.compressedBuffer, s.recompressionLevel)
+	if err != nil {
+		return err
+	}
+	s.compressedWriter = compressedWriter
 	s.newUncompressedSize = 0
 	s.totalUncompressedSize = 0
 	for _, segment := range s.rawSegments {
@@ -957,8 +1037,9 @@ func (s *batchSegments) CloseAndGetBytes() ([]byte, error) {
 		return nil, err
 	}
 	compressedBytes := s.compressedBuffer.Bytes()
-	fullMsg := make([]byte, 1, len(compressedBytes)+1)
-	fullMsg[0] = daprovider.BrotliMessageHeaderByte
+	header := s.compression.header()
+	fullMsg := make([]byte, 0, len(header)+len(compressedBytes))
+	fullMsg = append(fullMsg, header...)
 	fullMsg = append(fullMsg, compressedBytes...)
 	return fullMsg, nil
 }
@@ -1125,11 +1206,101 @@ const ethPosBlockTime = 12 * time.Second
 
 var errAttemptLockFailed = errors.New("failed to acquire lock; either another batch poster posted a batch or this node fell behind")
 
+// batchCompressionState returns whether ArbOS accepts the batch compression formats other than
+// plain brotli at the start of the next batch, and whether the configured compression dictionary
+// was published by a batch already read from the inbox.
+func (b *BatchPoster) batchCompressionState(ctx context.Context, config *BatchPosterConfig, batchPosition batchPosterPosition) (bool, bool, error) {
+	if config.CompressionAlgorithm == CompressionBrotli || batchPosition.MessageCount == 0 {
+		return false, false, nil
+	}
+	arbOSVersion, err := b.arbOSVersionGetter.ArbOSVersionForMessageNumber(batchPosition.MessageCount - 1)
+	if err != nil {
+		return false, false, err
+	}
+	if arbOSVersion < arbosState.ArbosVersion_BatchCompression {
+		return false, false, nil
+	}
+	if len(b.compressionDict) == 0 {
+		return true, false, nil
+	}
+	_, err = b.inbox.GetDictionary(ctx, crypto.Keccak256Hash(b.compressionDict))
+	if errors.Is(err, arbstate.ErrUnknownDictionary) {
+		return true, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, true, nil
+}
+
+// blobsPreferred returns true if the parent chain supports EIP-4844 blobs, the chain's ArbOS
+// version can read them, and posting blobs is currently cheaper than calldata (unless the
+// blob price is configured to be ignored).
+func (b *BatchPoster) blobsPreferred(config *BatchPosterConfig, latestHeader *types.Header, batchPosition batchPosterPosition) (bool, error) {
+	if latestHeader.ExcessBlobGas == nil || latestHeader.BlobGasUsed == nil {
+		return false, nil
+	}
+	arbOSVersion, err := b.arbOSVersionGetter.ArbOSVersionForMessageNumber(arbutil.MessageIndex(arbmath.SaturatingUSub(uint64(batchPosition.MessageCount), 1)))
+	if err != nil {
+		return false, err
+	}
+	if arbOSVersion < params.ArbosVersion_20 {
+		return false, nil
+	}
+	if config.IgnoreBlobPrice {
+		return true, nil
+	}
+	backlog := b.backlog.Load()
+	// Logic to prevent switching from non-4844 batches to 4844 batches too often,
+	// so that blocks can be filled efficiently. The geth txpool rejects txs for
+	// accounts that already have the other type of txs in the pool with
+	// "address already reserved". This logic makes sure that, if there is a backlog,
+	// that enough non-4844 batches have been posted to fill a block before switching.
+	if backlog == 0 ||
+		b.non4844BatchCount == 0 ||
+		b.non4844BatchCount > 16 {
+		return arbmath.BigLessThan(blobFeePerByte(latestHeader), calldataFeePerByte(latestHeader)), nil
+	}
+	return false, nil
+}
+
+func blobFeePerByte(latestHeader *types.Header) *big.Int {
+	fee := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*latestHeader.ExcessBlobGas, *latestHeader.BlobGasUsed))
+	fee.Mul(fee, blobTxBlobGasPerBlob)
+	fee.Div(fee, usableBytesInBlob)
+	return fee
+}
+
+func calldataFeePerByte(latestHeader *types.Header) *big.Int {
+	return arbmath.BigMulByUint(latestHeader.BaseFee, 16)
+}
+
+// daBackendUsable returns whether the DA backend may carry the next batch: blobs have to be enabled
+// and preferred over calldata, and the backends posting to the parent chain have to be under their fee
+// thresholds.
+func (b *BatchPoster) daBackendUsable(config *BatchPosterConfig, latestHeader *types.Header, batchPosition batchPosterPosition, name string) (bool, error) {
+	maxFee := config.DAFallback.backend(name).MaxFeePerByte
+	switch name {
+	case DABackend4844:
+		if !config.Post4844Blobs {
+			return false, nil
+		}
+		preferred, err := b.blobsPreferred(config, latestHeader, batchPosition)
+		if err != nil || !preferred {
+			return false, err
+		}
+		return maxFee == 0 || blobFeePerByte(latestHeader).Cmp(new(big.Int).SetUint64(maxFee)) <= 0, nil
+	case DABackendCalldata:
+		return maxFee == 0 || calldataFeePerByte(latestHeader).Cmp(new(big.Int).SetUint64(maxFee)) <= 0, nil
+	}
+	return true, nil
+}
+
 func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error) {
 	if b.batchReverted.Load() {
 		return false, fmt.Errorf("batch was reverted, not posting any more batches")
 	}
-	nonce, batchPositionBytes, err := b.dataPoster.GetNextNonceAndMeta(ctx)
+	nonce, batchPositionBytes, err := b.getNextNonceAndMeta(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -1142,7 +1313,7 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if err != nil {
 		return false, err
 	}
-	if dbBatchCount > batchPosition.NextSeqNum {
+	if dbBatchCount > batchPosition.NextSeqNum && !b.config().DryRun.Enable {
 		return false, fmt.Errorf("attempting to post batch %v, but the local inbox tracker database already has %v batches", batchPosition.NextSeqNum, dbBatchCount)
 	}
 	if b.building == nil || b.building.startMsgCount != batchPosition.MessageCount {
@@ -1151,46 +1322,47 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 			return false, err
 		}
 		var use4844 bool
+		var daBackend string
 		config := b.config()
-		if config.Post4844Blobs && b.dapWriter == nil && latestHeader.ExcessBlobGas != nil && latestHeader.BlobGasUsed != nil {
-			arbOSVersion, err := b.arbOSVersionGetter.ArbOSVersionForMessageNumber(arbutil.MessageIndex(arbmath.SaturatingUSub(uint64(batchPosition.MessageCount), 1)))
+		if config.DAFallback.Enable {
+			daBackend, err = b.daBackends.selectBackend(ctx, &config.DAFallback, func(name string) (bool, error) {
+				return b.daBackendUsable(config, latestHeader, batchPosition, name)
+			})
 			if err != nil {
 				return false, err
 			}
-			if arbOSVersion >= params.ArbosVersion_20 {
-				if config.IgnoreBlobPrice {
-					use4844 = true
-				} else {
-					backlog := b.backlog.Load()
-					// Logic to prevent switching from non-4844 batches to 4844 batches too often,
-					// so that blocks can be filled efficiently. The geth txpool rejects txs for
-					// accounts that already have the other type of txs in the pool with
-					// "address already reserved". This logic makes sure that, if there is a backlog,
-					// that enough non-4844 batches have been posted to fill a block before switching.
-					if backlog == 0 ||
-						b.non4844BatchCount == 0 ||
-						b.non4844BatchCount > 16 {
-						blobFeePerByte := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*latestHeader.ExcessBlobGas, *latestHeader.BlobGasUsed))
-						blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
-						blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
-
-						calldataFeePerByte := arbmath.BigMulByUint(latestHeader.BaseFee, 16)
-						use4844 = arbmath.BigLessThan(blobFeePerByte, calldataFeePerByte)
-					}
-				}
+			use4844 = daBackend == DABackend4844
+		} else if config.Post4844Blobs && b.dapWriter == nil {
+			use4844, err = b.blobsPreferred(config, latestHeader, batchPosition)
+			if err != nil {
+				return false, err
 			}
 		}
 
+		batchCompressionEnabled, dictionaryPublished, err := b.batchCompressionState(ctx, config, batchPosition)
+		if err != nil {
+			return false, err
+		}
+		compression, err := newBatchCompression(config.CompressionAlgorithm, b.compressionDict, batchCompressionEnabled, dictionaryPublished)
+		if err != nil {
+			return false, err
+		}
+		segments, err := newBatchSegments(batchPosition.DelayedMessageCount, config, b.GetBacklogEstimate(), use4844, daBackend, compression)
+		if err != nil {
+			return false, err
+		}
 		b.building = &buildingBatch{
-			segments:      newBatchSegments(batchPosition.DelayedMessageCount, b.config(), b.GetBacklogEstimate(), use4844),
+			segments:      segments,
 			msgCount:      batchPosition.MessageCount,
 			startMsgCount: batchPosition.MessageCount,
 			use4844:       use4844,
+			daBackend:     daBackend,
 		}
 		if b.config().CheckBatchCorrectness {
 			b.building.muxBackend = &simulatedMuxBackend{
-				batchSeqNum: batchPosition.NextSeqNum,
-				allMsgs:     make(map[arbutil.MessageIndex]*arbostypes.MessageWithMetadata),
+				batchSeqNum:      batchPosition.NextSeqNum,
+				allMsgs:          make(map[arbutil.MessageIndex]*arbostypes.MessageWithMetadata),
+				batchCompression: batchCompressionEnabled,
 			}
 		}
 	}
@@ -1416,12 +1588,23 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		return false, nil
 	}
 
-	if b.dapWriter != nil {
+	dapWriter := b.dapWriter
+	disableDapFallbackStoreDataOnChain := config.DisableDapFallbackStoreDataOnChain
+	if config.DAFallback.Enable {
+		// Falling back to other DA backends, including calldata, is handled by the backend order
+		dapWriter = b.daBackends.writer(b.building.daBackend)
+		disableDapFallbackStoreDataOnChain = true
+	}
+	if config.DryRun.Enable {
+		// Never store data with a DA backend in dry-run mode
+		dapWriter = nil
+	}
+	if dapWriter != nil {
 		if !b.redisLock.AttemptLock(ctx) {
 			return false, errAttemptLockFailed
 		}
 
-		gotNonce, gotMeta, err := b.dataPoster.GetNextNonceAndMeta(ctx)
+		gotNonce, gotMeta, err := b.getNextNonceAndMeta(ctx)
 		if err != nil {
 			batchPosterDAFailureCounter.Inc(1)
 			return false, err
@@ -1431,9 +1614,14 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 			return false, fmt.Errorf("%w: nonce changed from %d to %d while creating batch", storage.ErrStorageRace, nonce, gotNonce)
 		}
 		// #nosec G115
-		sequencerMsg, err = b.dapWriter.Store(ctx, sequencerMsg, uint64(time.Now().Add(config.DASRetentionPeriod).Unix()), config.DisableDapFallbackStoreDataOnChain)
+		sequencerMsg, err = dapWriter.Store(ctx, sequencerMsg, uint64(time.Now().Add(config.DASRetentionPeriod).Unix()), disableDapFallbackStoreDataOnChain)
 		if err != nil {
 			batchPosterDAFailureCounter.Inc(1)
+			if config.DAFallback.Enable {
+				log.Warn("DA backend failed to store batch, rebuilding batch for the next backend", "daBackend", b.building.daBackend, "err", err)
+				b.daBackends.markFailure(b.building.daBackend, config.DAFallback.FailureBackoff)
+				b.building = nil
+			}
 			return false, err
 		}
 
@@ -1479,6 +1667,13 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		}
 	}
 
+	if !b.building.use4844 {
+		sequencerMsg, err = encodeForCalldata(sequencerMsg)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	data, kzgBlobs, err := b.encodeAddBatch(new(big.Int).SetUint64(batchPosition.NextSeqNum), prevMessageCount, b.building.msgCount, sequencerMsg, b.building.segments.delayedMsg, b.building.use4844, delayProof)
 	if err != nil {
 		return false, err
@@ -1496,13 +1691,19 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	// posts a new delayed message that we didn't see while gas estimating.
 	gasLimit, err := b.estimateGas(ctx, sequencerMsg, lastPotentialMsg.DelayedMessagesRead, data, kzgBlobs, nonce, accessList, delayProof)
 	if err != nil {
-		return false, err
+		if !config.DryRun.Enable {
+			return false, err
+		}
+		log.Debug("BatchPoster: falling back to intrinsic gas for dry-run batch", "err", err)
+		gasLimit = dryRunGasEstimate(data, config.ExtraBatchGas)
 	}
-	newMeta, err := rlp.EncodeToBytes(batchPosterPosition{
+	newPosition := batchPosterPosition{
 		MessageCount:        b.building.msgCount,
 		DelayedMessageCount: b.building.segments.delayedMsg,
 		NextSeqNum:          batchPosition.NextSeqNum + 1,
-	})
+		DABackend:           b.building.daBackend,
+	}
+	newMeta, err := rlp.EncodeToBytes(newPosition)
 	if err != nil {
 		return false, err
 	}
@@ -1521,7 +1722,7 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		b.building.muxBackend.seqMsg = seqMsg
 		b.building.muxBackend.delayedInboxStart = batchPosition.DelayedMessageCount
 		b.building.muxBackend.SetPositionWithinMessage(0)
-		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, daprovider.KeysetValidate)
+		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, b.dictReader, daprovider.KeysetValidate)
 		log.Debug("Begin checking the correctness of batch against inbox multiplexer", "startMsgSeqNum", batchPosition.MessageCount, "endMsgSeqNum", b.building.msgCount-1)
 		for i := batchPosition.MessageCount; i < b.building.msgCount; i++ {
 			msg, err := simMux.Pop(ctx)
@@ -1540,6 +1741,14 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		log.Debug("Successfully checked that the batch produces correct messages when ran through inbox multiplexer", "sequenceNumber", batchPosition.NextSeqNum)
 	}
 
+	if config.DryRun.Enable {
+		if err := b.reportDryRunBatch(ctx, config, nonce, firstUsefulMsgTime, batchPosition, newPosition, sequencerMsg, gasLimit, len(kzgBlobs)); err != nil {
+			return false, err
+		}
+		b.updateBacklogAfterBatch(config, batchPosition, msgCount)
+		return true, nil
+	}
+
 	tx, err := b.dataPoster.PostTransaction(ctx,
 		firstUsefulMsgTime,
 		nonce,
@@ -1564,8 +1773,29 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		"currentDelayed", b.building.segments.delayedMsg,
 		"totalSegments", len(b.building.segments.rawSegments),
 		"numBlobs", len(kzgBlobs),
+		"daBackend", b.building.daBackend,
 	)
+	if b.building.daBackend != "" {
+		b.daBackends.markSuccess(b.building.daBackend)
+	}
+
+	b.updateBacklogAfterBatch(config, batchPosition, msgCount)
+
+	// If we aren't queueing up transactions, wait for the receipt before moving on to the next batch.
+	if config.DataPoster.UseNoOpStorage {
+		receipt, err := b.l1Reader.WaitForTxApproval(ctx, tx)
+		if err != nil {
+			return false, fmt.Errorf("error waiting for tx receipt: %w", err)
+		}
+		log.Info("Got successful receipt from batch poster transaction", "txHash", tx.Hash(), "blockNumber", receipt.BlockNumber, "blockHash", receipt.BlockHash)
+	}
 
+	return true, nil
+}
+
+// updateBacklogAfterBatch updates the batch backlog estimate after the batch being built was
+// posted, and clears the batch being built.
+func (b *BatchPoster) updateBacklogAfterBatch(config *BatchPosterConfig, batchPosition batchPosterPosition, msgCount arbutil.MessageIndex) {
 	recentlyHitL1Bounds := time.Since(b.lastHitL1Bounds) < config.PollInterval*3
 	postedMessages := b.building.msgCount - batchPosition.MessageCount
 	b.messagesPerBatch.Update(uint64(postedMessages))
@@ -1615,17 +1845,6 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	}
 	b.backlog.Store(backlog)
 	b.building = nil
-
-	// If we aren't queueing up transactions, wait for the receipt before moving on to the next batch.
-	if config.DataPoster.UseNoOpStorage {
-		receipt, err := b.l1Reader.WaitForTxApproval(ctx, tx)
-		if err != nil {
-			return false, fmt.Errorf("error waiting for tx receipt: %w", err)
-		}
-		log.Info("Got successful receipt from batch poster transaction", "txHash", tx.Hash(), "blockNumber", receipt.BlockNumber, "blockHash", receipt.BlockHash)
-	}
-
-	return true, nil
 }
 
 func (b *BatchPoster) GetBacklogEstimate() uint64 {
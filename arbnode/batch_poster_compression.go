@@ -0,0 +1,193 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/zeroheavy"
+)
+
+const (
+	CompressionBrotli           = "brotli"
+	CompressionZstd             = "zstd"
+	CompressionBrotliDictionary = "brotli-dictionary"
+)
+
+var knownCompressionAlgorithms = []string{CompressionBrotli, CompressionZstd, CompressionBrotliDictionary}
+
+func validateCompressionConfig(algorithm string, dictionaryFile string) error {
+	if !slices.Contains(knownCompressionAlgorithms, algorithm) {
+		return fmt.Errorf("unknown batch compression algorithm \"%v\" (known algorithms: %v)", algorithm, knownCompressionAlgorithms)
+	}
+	if algorithm == CompressionBrotliDictionary && dictionaryFile == "" {
+		return errors.New("batch compression algorithm brotli-dictionary requires compression-dictionary to be set")
+	}
+	return nil
+}
+
+// batchCompressor is implemented by the streaming writers of every supported compression algorithm.
+type batchCompressor interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// batchCompression is the compression algorithm and dictionary used for a single batch.
+type batchCompression struct {
+	algorithm      string
+	dictionary     []byte
+	dictionaryHash common.Hash
+	// whether an earlier batch published the dictionary, so it can be referenced by hash
+	dictionaryPublished bool
+}
+
+// newBatchCompression returns the compression of the next batch. Batches are compressed with brotli
+// until ArbOS enables the other formats, and the dictionary is published inline until a batch has
+// published it.
+func newBatchCompression(algorithm string, dictionary []byte, enabled bool, dictionaryPublished bool) (*batchCompression, error) {
+	if !enabled {
+		algorithm = CompressionBrotli
+	}
+	compression := &batchCompression{algorithm: algorithm}
+	if algorithm == CompressionBrotliDictionary {
+		if len(dictionary) == 0 {
+			return nil, errors.New("batch compression algorithm brotli-dictionary is configured without a dictionary")
+		}
+		compression.dictionary = dictionary
+		compression.dictionaryHash = crypto.Keccak256Hash(dictionary)
+		compression.dictionaryPublished = dictionaryPublished
+	}
+	return compression, nil
+}
+
+func readCompressionDictionary(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	dictionary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading batch compression dictionary: %w", err)
+	}
+	if len(dictionary) > arbcompress.MaxDictionarySize {
+		return nil, fmt.Errorf("batch compression dictionary is %v bytes, more than the maximum of %v", len(dictionary), arbcompress.MaxDictionarySize)
+	}
+	return dictionary, nil
+}
+
+func (c *batchCompression) newWriter(w *bytes.Buffer, level int) (batchCompressor, error) {
+	switch c.algorithm {
+	case CompressionZstd:
+		return arbcompress.NewZstdWriter(w, level)
+	case CompressionBrotliDictionary:
+		// #nosec G115
+		return arbcompress.NewDictionaryWriter(w, uint32(level), c.dictionary)
+	default:
+		return brotli.NewWriterLevel(w, level), nil
+	}
+}
+
+// header returns the bytes preceding the compressed data in a batch.
+func (c *batchCompression) header() []byte {
+	switch c.algorithm {
+	case CompressionZstd:
+		return []byte{daprovider.ZstdMessageHeaderByte}
+	case CompressionBrotliDictionary:
+		if !c.dictionaryPublished {
+			return arbstate.EncodeInlineDictionary(c.dictionary)
+		}
+		return append([]byte{daprovider.BrotliWithDictionaryMessageHeaderByte}, c.dictionaryHash.Bytes()...)
+	default:
+		return []byte{daprovider.BrotliMessageHeaderByte}
+	}
+}
+
+// zeroheavyEncodedForCalldata returns whether encodeForCalldata encodes batches compressed this way.
+func (c *batchCompression) zeroheavyEncodedForCalldata() bool {
+	return c.algorithm != CompressionBrotli
+}
+
+// ReadDictionarySamples returns the uncompressed batch data of the messages in [from, to) in
+// samples of about sampleSize bytes, to train compression dictionaries on historical batches.
+func ReadDictionarySamples(db ethdb.KeyValueReader, from, to arbutil.MessageIndex, sampleSize int) ([][]byte, error) {
+	countBytes, err := db.Get(messageCountKey)
+	if err != nil {
+		return nil, err
+	}
+	var count uint64
+	if err := rlp.DecodeBytes(countBytes, &count); err != nil {
+		return nil, err
+	}
+	to = min(to, arbutil.MessageIndex(count))
+
+	var samples [][]byte
+	var sample []byte
+	var delayedRead uint64
+	for pos := from; pos < to; pos++ {
+		data, err := db.Get(dbKey(messagePrefix, uint64(pos)))
+		if err != nil {
+			return nil, err
+		}
+		var msg arbostypes.MessageWithMetadata
+		if err := rlp.DecodeBytes(data, &msg); err != nil {
+			return nil, err
+		}
+		var segment []byte
+		if pos > from && msg.DelayedMessagesRead > delayedRead {
+			segment = []byte{arbstate.BatchSegmentKindDelayedMessages}
+		} else {
+			segment = append([]byte{arbstate.BatchSegmentKindL2Message}, msg.Message.L2msg...)
+		}
+		delayedRead = msg.DelayedMessagesRead
+		encoded, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, encoded...)
+		if len(sample) >= sampleSize {
+			samples = append(samples, sample)
+			sample = nil
+		}
+	}
+	if len(sample) > 0 {
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// encodeForCalldata zeroheavy-encodes batches compressed with anything but plain brotli, as the
+// sequencer inbox doesn't accept their header bytes in calldata but does accept the zeroheavy header byte.
+func encodeForCalldata(sequencerMsg []byte) ([]byte, error) {
+	if len(sequencerMsg) == 0 {
+		return sequencerMsg, nil
+	}
+	header := sequencerMsg[0]
+	if !daprovider.IsZstdMessageHeaderByte(header) &&
+		!daprovider.IsBrotliWithDictionaryMessageHeaderByte(header) &&
+		!daprovider.IsBrotliWithInlineDictionaryMessageHeaderByte(header) {
+		return sequencerMsg, nil
+	}
+	encoded, err := io.ReadAll(zeroheavy.NewZeroheavyEncoder(bytes.NewReader(sequencerMsg)))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{daprovider.ZeroheavyMessageHeaderFlag}, encoded...), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+)
+
+func TestValidateCompressionConfig(t *testing.T) {
+	if err := validateCompressionConfig(CompressionBrotli, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateCompressionConfig(CompressionZstd, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateCompressionConfig(CompressionBrotliDictionary, ""); err == nil {
+		t.Fatal("expected error for brotli-dictionary without a dictionary")
+	}
+	if err := validateCompressionConfig("lz4", ""); err == nil {
+		t.Fatal("expected error for unknown compression algorithm")
+	}
+}
+
+func TestBatchSegmentsCompressionRoundtrip(t *testing.T) {
+	dictionary := []byte(strings.Repeat("repetitive l2 message ", 8))
+	msg := &arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind: arbostypes.L1MessageType_L2Message,
+			},
+			L2msg: []byte("repetitive l2 message 1"),
+		},
+	}
+	for _, algorithm := range knownCompressionAlgorithms {
+		for _, published := range []bool{false, true} {
+			config := TestBatchPosterConfig
+			config.CompressionAlgorithm = algorithm
+			compression, err := newBatchCompression(algorithm, dictionary, true, published)
+			Require(t, err)
+			segments, err := newBatchSegments(0, &config, 0, false, "", compression)
+			Require(t, err)
+			success, err := segments.AddMessage(msg)
+			Require(t, err)
+			if !success {
+				t.Fatal("failed to add message to batch", algorithm)
+			}
+			sequencerMsg, err := segments.CloseAndGetBytes()
+			Require(t, err)
+			if algorithm == CompressionBrotliDictionary && published != daprovider.IsBrotliWithDictionaryMessageHeaderByte(sequencerMsg[0]) {
+				t.Fatal("unpublished dictionaries should be inline, published ones referenced by hash", sequencerMsg[0])
+			}
+			sequencerMsg, err = encodeForCalldata(sequencerMsg)
+			Require(t, err)
+			if algorithm == CompressionBrotli && !daprovider.IsBrotliMessageHeaderByte(sequencerMsg[0]) {
+				t.Fatal("brotli batches shouldn't be zeroheavy-encoded")
+			}
+			if algorithm != CompressionBrotli && !daprovider.IsZeroheavyEncodedHeaderByte(sequencerMsg[0]) {
+				t.Fatal("batches posted as calldata should be zeroheavy-encoded", algorithm)
+			}
+
+			backend := &simulatedMuxBackend{seqMsg: append(make([]byte, 40), sequencerMsg...), batchCompression: true}
+			multiplexer := arbstate.NewInboxMultiplexer(backend, 0, nil, arbstate.NewStaticDictionaryReader(dictionary), daprovider.KeysetValidate)
+			parsed, err := multiplexer.Pop(context.Background())
+			Require(t, err)
+			if !bytes.Equal(parsed.Message.L2msg, msg.Message.L2msg) {
+				t.Fatal("unexpected message from", algorithm, "batch:", parsed.Message.L2msg)
+			}
+		}
+	}
+}
+
+func TestBatchCompressionFallsBackToBrotli(t *testing.T) {
+	dictionary := []byte(strings.Repeat("repetitive l2 message ", 8))
+	compression, err := newBatchCompression(CompressionBrotliDictionary, dictionary, false, false)
+	Require(t, err)
+	if compression.algorithm != CompressionBrotli {
+		t.Fatal("batches should be compressed with brotli before batch compression is enabled, got", compression.algorithm)
+	}
+
+	config := TestBatchPosterConfig
+	config.MaxSize = 1000
+	compression, err = newBatchCompression(CompressionBrotliDictionary, []byte(strings.Repeat("x", 1000)), true, false)
+	Require(t, err)
+	segments, err := newBatchSegments(0, &config, 0, false, "", compression)
+	Require(t, err)
+	if segments.compression.algorithm != CompressionBrotli {
+		t.Fatal("batches should be compressed with brotli when the inline dictionary doesn't fit, got", segments.compression.algorithm)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+)
+
+const (
+	DABackendAnyTrust   = "anytrust"
+	DABackendDAProvider = "da-provider"
+	DABackend4844       = "4844"
+	DABackendCalldata   = "calldata"
+)
+
+var knownDABackends = []string{DABackendAnyTrust, DABackendDAProvider, DABackend4844, DABackendCalldata}
+
+// isDAWriterBackend returns true if the batch data is stored through a daprovider.Writer
+// for this backend, rather than being posted directly to the parent chain.
+func isDAWriterBackend(name string) bool {
+	return name == DABackendAnyTrust || name == DABackendDAProvider
+}
+
+var errNoDABackendAvailable = errors.New("no DA backend is currently available to post batches")
+
+// DABackendConfig holds the limits of a single DA backend.
+type DABackendConfig struct {
+	MaxSize       int    `koanf:"max-size" reload:"hot"`
+	MaxFeePerByte uint64 `koanf:"max-fee-per-byte" reload:"hot"`
+}
+
+var DefaultDABackendConfig = DABackendConfig{
+	MaxSize:       0,
+	MaxFeePerByte: 0,
+}
+
+// DABackendConfigAddOptions adds the options of a DA backend. Fees are only known for the
+// backends posting to the parent chain, so max-fee-per-byte is only added for those.
+func DABackendConfigAddOptions(prefix string, f *flag.FlagSet, name string, postsToParentChain bool) {
+	f.Int(prefix+".max-size", DefaultDABackendConfig.MaxSize, fmt.Sprintf("maximum estimated compressed batch size for batches posted with the %v backend (0 uses the batch poster's size limit)", name))
+	if postsToParentChain {
+		f.Uint64(prefix+".max-fee-per-byte", DefaultDABackendConfig.MaxFeePerByte, fmt.Sprintf("skip the %v backend while its parent chain fee per byte of batch data is above this many wei (0 is unlimited)", name))
+	}
+}
+
+type DAFallbackConfig struct {
+	Enable             bool            `koanf:"enable"`
+	Order              []string        `koanf:"order" reload:"hot"`
+	FailureBackoff     time.Duration   `koanf:"failure-backoff" reload:"hot"`
+	HealthCheckTimeout time.Duration   `koanf:"health-check-timeout" reload:"hot"`
+	AnyTrust           DABackendConfig `koanf:"anytrust" reload:"hot"`
+	DAProvider         DABackendConfig `koanf:"da-provider" reload:"hot"`
+	Blobs              DABackendConfig `koanf:"4844" reload:"hot"`
+	Calldata           DABackendConfig `koanf:"calldata" reload:"hot"`
+}
+
+var DefaultDAFallbackConfig = DAFallbackConfig{
+	Enable:             false,
+	Order:              []string{DABackendAnyTrust, DABackend4844, DABackendCalldata},
+	FailureBackoff:     5 * time.Minute,
+	HealthCheckTimeout: 5 * time.Second,
+	AnyTrust:           DefaultDABackendConfig,
+	DAProvider:         DefaultDABackendConfig,
+	Blobs:              DefaultDABackendConfig,
+	Calldata:           DefaultDABackendConfig,
+}
+
+func DAFallbackConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultDAFallbackConfig.Enable, "post batches to the first available DA backend in da-fallback.order instead of only the configured DA writer")
+	f.StringSlice(prefix+".order", DefaultDAFallbackConfig.Order, fmt.Sprintf("ordered list of DA backends to try when posting a batch (%v)", knownDABackends))
+	f.Duration(prefix+".failure-backoff", DefaultDAFallbackConfig.FailureBackoff, "how long a DA backend is skipped after it failed to store a batch or a health check")
+	f.Duration(prefix+".health-check-timeout", DefaultDAFallbackConfig.HealthCheckTimeout, "timeout for checking the health of a DA writer backend before selecting it")
+	DABackendConfigAddOptions(prefix+".anytrust", f, DABackendAnyTrust, false)
+	DABackendConfigAddOptions(prefix+".da-provider", f, DABackendDAProvider, false)
+	DABackendConfigAddOptions(prefix+".4844", f, DABackend4844, true)
+	DABackendConfigAddOptions(prefix+".calldata", f, DABackendCalldata, true)
+}
+
+// backend returns the limits of the named DA backend.
+func (c *DAFallbackConfig) backend(name string) *DABackendConfig {
+	switch name {
+	case DABackendAnyTrust:
+		return &c.AnyTrust
+	case DABackendDAProvider:
+		return &c.DAProvider
+	case DABackend4844:
+		return &c.Blobs
+	case DABackendCalldata:
+		return &c.Calldata
+	}
+	return &DABackendConfig{}
+}
+
+func (c *DAFallbackConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if len(c.Order) == 0 {
+		return errors.New("da-fallback is enabled but da-fallback.order is empty")
+	}
+	for _, name := range knownDABackends {
+		if c.backend(name).MaxSize < 0 {
+			return fmt.Errorf("da-fallback.%v.max-size can't be negative", name)
+		}
+	}
+	for i, name := range c.Order {
+		if !slices.Contains(knownDABackends, name) {
+			return fmt.Errorf("unknown DA backend \"%v\" in da-fallback.order (known backends: %v)", name, knownDABackends)
+		}
+		if slices.Contains(c.Order[:i], name) {
+			return fmt.Errorf("DA backend \"%v\" listed more than once in da-fallback.order", name)
+		}
+	}
+	return nil
+}
+
+// daBackendSelector picks the DA backend used for each batch, skipping backends
+// that recently failed until their backoff expires.
+type daBackendSelector struct {
+	writers map[string]daprovider.Writer
+
+	mutex          sync.Mutex
+	unhealthyUntil map[string]time.Time
+}
+
+func newDABackendSelector(writers map[string]daprovider.Writer) *daBackendSelector {
+	return &daBackendSelector{
+		writers:        writers,
+		unhealthyUntil: make(map[string]time.Time),
+	}
+}
+
+// writer returns the DA writer for the backend, or nil if batches for this backend
+// are posted directly to the parent chain.
+func (s *daBackendSelector) writer(name string) daprovider.Writer {
+	if !isDAWriterBackend(name) {
+		return nil
+	}
+	return s.writers[name]
+}
+
+func (s *daBackendSelector) isHealthy(name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return !time.Now().Before(s.unhealthyUntil[name])
+}
+
+func (s *daBackendSelector) markFailure(name string, backoff time.Duration) {
+	metrics.GetOrRegisterCounter("arb/batchposter/da/"+name+"/failure", nil).Inc(1)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unhealthyUntil[name] = time.Now().Add(backoff)
+}
+
+func (s *daBackendSelector) markSuccess(name string) {
+	metrics.GetOrRegisterCounter("arb/batchposter/da/"+name+"/success", nil).Inc(1)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.unhealthyUntil, name)
+}
+
+// selectBackend returns the first backend in the configured order which is healthy and
+// for which usable returns true. usable is used to apply cost thresholds, e.g. blob prices.
+func (s *daBackendSelector) selectBackend(ctx context.Context, config *DAFallbackConfig, usable func(name string) (bool, error)) (string, error) {
+	for _, name := range config.Order {
+		if isDAWriterBackend(name) && s.writers[name] == nil {
+			log.Warn("DA backend listed in da-fallback.order isn't configured, skipping it", "daBackend", name)
+			continue
+		}
+		if !s.isHealthy(name) {
+			continue
+		}
+		if checker, ok := s.writer(name).(daprovider.HealthChecker); ok {
+			checkCtx, cancel := context.WithTimeout(ctx, config.HealthCheckTimeout)
+			err := checker.HealthCheck(checkCtx)
+			cancel()
+			if err != nil {
+				log.Warn("DA backend failed health check, skipping it", "daBackend", name, "err", err)
+				s.markFailure(name, config.FailureBackoff)
+				continue
+			}
+		}
+		ok, err := usable(name)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			metrics.GetOrRegisterGauge("arb/batchposter/da/"+name+"/selected", nil).Update(time.Now().Unix())
+			return name, nil
+		}
+	}
+	return "", errNoDABackendAvailable
+}
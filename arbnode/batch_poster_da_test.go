@@ -0,0 +1,188 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+)
+
+type testDAWriter struct {
+	healthErr error
+}
+
+func (w *testDAWriter) Store(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, error) {
+	return message, nil
+}
+
+func (w *testDAWriter) HealthCheck(ctx context.Context) error {
+	return w.healthErr
+}
+
+func alwaysUsable(string) (bool, error) {
+	return true, nil
+}
+
+func TestDAFallbackConfigValidate(t *testing.T) {
+	config := DefaultDAFallbackConfig
+	config.Enable = true
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.Order = []string{DABackendAnyTrust, "unknown"}
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected error for unknown DA backend")
+	}
+	config.Order = []string{DABackendCalldata, DABackendCalldata}
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected error for duplicate DA backend")
+	}
+	config.Order = nil
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected error for empty order")
+	}
+}
+
+func TestDABackendSelectorOrder(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultDAFallbackConfig
+	config.Enable = true
+	config.Order = []string{DABackendAnyTrust, DABackend4844, DABackendCalldata}
+
+	selector := newDABackendSelector(map[string]daprovider.Writer{DABackendAnyTrust: &testDAWriter{}})
+	name, err := selector.selectBackend(ctx, &config, alwaysUsable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackendAnyTrust {
+		t.Fatalf("expected %v, got %v", DABackendAnyTrust, name)
+	}
+
+	// A failed store should move posting on to the next backend until the backoff expires
+	selector.markFailure(DABackendAnyTrust, time.Hour)
+	name, err = selector.selectBackend(ctx, &config, alwaysUsable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackend4844 {
+		t.Fatalf("expected %v, got %v", DABackend4844, name)
+	}
+
+	// Blobs being too expensive should fall through to calldata
+	name, err = selector.selectBackend(ctx, &config, func(name string) (bool, error) {
+		return name != DABackend4844, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackendCalldata {
+		t.Fatalf("expected %v, got %v", DABackendCalldata, name)
+	}
+
+	selector.markSuccess(DABackendAnyTrust)
+	name, err = selector.selectBackend(ctx, &config, alwaysUsable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackendAnyTrust {
+		t.Fatalf("expected %v, got %v", DABackendAnyTrust, name)
+	}
+}
+
+func TestDABackendSelectorHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultDAFallbackConfig
+	config.Enable = true
+	config.Order = []string{DABackendDAProvider, DABackendAnyTrust}
+
+	unhealthy := &testDAWriter{healthErr: errors.New("unhealthy")}
+	selector := newDABackendSelector(map[string]daprovider.Writer{
+		DABackendDAProvider: unhealthy,
+		DABackendAnyTrust:   &testDAWriter{},
+	})
+	name, err := selector.selectBackend(ctx, &config, alwaysUsable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackendAnyTrust {
+		t.Fatalf("expected %v, got %v", DABackendAnyTrust, name)
+	}
+	if selector.isHealthy(DABackendDAProvider) {
+		t.Fatal("expected DA backend failing its health check to be marked unhealthy")
+	}
+
+	selector.markFailure(DABackendAnyTrust, time.Hour)
+	if _, err := selector.selectBackend(ctx, &config, alwaysUsable); !errors.Is(err, errNoDABackendAvailable) {
+		t.Fatalf("expected errNoDABackendAvailable, got %v", err)
+	}
+}
+
+func TestDABackendSelectorSkipsUnconfiguredWriters(t *testing.T) {
+	config := DefaultDAFallbackConfig
+	config.Enable = true
+	config.Order = []string{DABackendAnyTrust, DABackendCalldata}
+
+	selector := newDABackendSelector(nil)
+	name, err := selector.selectBackend(context.Background(), &config, alwaysUsable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != DABackendCalldata {
+		t.Fatalf("expected %v, got %v", DABackendCalldata, name)
+	}
+	if selector.writer(DABackendCalldata) != nil {
+		t.Fatal("calldata backend shouldn't have a DA writer")
+	}
+}
+
+func TestDABackendUsable(t *testing.T) {
+	b := &BatchPoster{}
+	config := TestBatchPosterConfig
+	config.Post4844Blobs = false
+	header := &types.Header{BaseFee: big.NewInt(10)}
+	batchPosition := batchPosterPosition{}
+
+	// Blobs are never used while posting them is disabled
+	usable, err := b.daBackendUsable(&config, header, batchPosition, DABackend4844)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usable {
+		t.Fatal("expected 4844 backend to be unusable with post-4844-blobs disabled")
+	}
+
+	// Calldata costs 16 times the base fee per byte
+	config.DAFallback.Calldata.MaxFeePerByte = 160
+	usable, err = b.daBackendUsable(&config, header, batchPosition, DABackendCalldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !usable {
+		t.Fatal("expected calldata backend to be usable at its fee threshold")
+	}
+	config.DAFallback.Calldata.MaxFeePerByte = 159
+	usable, err = b.daBackendUsable(&config, header, batchPosition, DABackendCalldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usable {
+		t.Fatal("expected calldata backend to be unusable over its fee threshold")
+	}
+
+	// DA writer backends have no parent chain fees
+	usable, err = b.daBackendUsable(&config, header, batchPosition, DABackendAnyTrust)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !usable {
+		t.Fatal("expected anytrust backend to be usable")
+	}
+}
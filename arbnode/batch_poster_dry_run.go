@@ -0,0 +1,176 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type BatchPosterDryRunConfig struct {
+	Enable     bool   `koanf:"enable"`
+	ReportFile string `koanf:"report-file" reload:"hot"`
+}
+
+var DefaultBatchPosterDryRunConfig = BatchPosterDryRunConfig{
+	Enable:     false,
+	ReportFile: "",
+}
+
+func BatchPosterDryRunConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultBatchPosterDryRunConfig.Enable, "build and price batches exactly as they would be posted, but never send them to the parent chain or DA backends")
+	f.String(prefix+".report-file", DefaultBatchPosterDryRunConfig.ReportFile, "if set, append a JSON report line for every dry-run batch to this file")
+}
+
+// DryRunBatchReport describes a batch that the batch poster built in dry-run mode.
+// Costs are in wei of the parent chain's native token.
+type DryRunBatchReport struct {
+	SequenceNumber     uint64               `json:"sequenceNumber"`
+	FromMessage        arbutil.MessageIndex `json:"fromMessage"`
+	ToMessage          arbutil.MessageIndex `json:"toMessage"`
+	DABackend          string               `json:"daBackend,omitempty"`
+	UncompressedSize   int                  `json:"uncompressedSize"`
+	CompressedSize     int                  `json:"compressedSize"`
+	CompressionRatio   float64              `json:"compressionRatio"`
+	NumBlobs           int                  `json:"numBlobs"`
+	GasLimit           uint64               `json:"gasLimit"`
+	ParentChainBaseFee *big.Int             `json:"parentChainBaseFee"`
+	ParentChainBlobFee *big.Int             `json:"parentChainBlobFee,omitempty"`
+	GasFeeCap          *big.Int             `json:"gasFeeCap"`
+	GasTipCap          *big.Int             `json:"gasTipCap"`
+	BlobGasFeeCap      *big.Int             `json:"blobGasFeeCap,omitempty"`
+	// EstimatedCost assumes the batch is included at the current parent chain fees
+	EstimatedCost *big.Int `json:"estimatedCost"`
+	// MaxCost is what the batch would cost if the fee caps were fully charged
+	MaxCost *big.Int `json:"maxCost"`
+}
+
+// dryRunState tracks the position of the batches built in dry-run mode, as the data poster's
+// position never advances when nothing is posted.
+type dryRunState struct {
+	position *batchPosterPosition
+}
+
+// getNextNonceAndMeta returns the data poster's next nonce even in dry-run mode, so that every dry-run
+// batch is priced as the next batch production would post, without a backlog of unconfirmed batches.
+func (b *BatchPoster) getNextNonceAndMeta(ctx context.Context) (uint64, []byte, error) {
+	nonce, meta, err := b.dataPoster.GetNextNonceAndMeta(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	if b.config().DryRun.Enable && b.dryRun.position != nil {
+		meta, err = rlp.EncodeToBytes(b.dryRun.position)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return nonce, meta, nil
+}
+
+// dryRunGasEstimate is used when the parent chain stand-in can't estimate the batch posting
+// transaction, e.g. because it doesn't have the sequencer inbox deployed.
+func dryRunGasEstimate(data []byte, extraGas uint64) uint64 {
+	gas := params.TxGas + extraGas
+	for _, b := range data {
+		if b == 0 {
+			gas += params.TxDataZeroGas
+		} else {
+			gas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
+}
+
+func (b *BatchPoster) reportDryRunBatch(ctx context.Context, config *BatchPosterConfig, nonce uint64, dataCreatedAt time.Time, batchPosition batchPosterPosition, newPosition batchPosterPosition, sequencerMsg []byte, gasLimit uint64, numBlobs int) error {
+	// #nosec G115
+	feeCap, tipCap, blobFeeCap, latestHeader, err := b.dataPoster.EstimateFeeAndTipCaps(ctx, dataCreatedAt, nonce, gasLimit, uint64(numBlobs))
+	if err != nil {
+		return fmt.Errorf("error pricing dry-run batch: %w", err)
+	}
+	compressedSize := len(sequencerMsg)
+	uncompressedSize := b.building.segments.totalUncompressedSize
+	report := DryRunBatchReport{
+		SequenceNumber:     batchPosition.NextSeqNum,
+		FromMessage:        batchPosition.MessageCount,
+		ToMessage:          newPosition.MessageCount,
+		DABackend:          newPosition.DABackend,
+		UncompressedSize:   uncompressedSize,
+		CompressedSize:     compressedSize,
+		NumBlobs:           numBlobs,
+		GasLimit:           gasLimit,
+		ParentChainBaseFee: latestHeader.BaseFee,
+		GasFeeCap:          feeCap,
+		GasTipCap:          tipCap,
+	}
+	if compressedSize > 0 {
+		report.CompressionRatio = float64(uncompressedSize) / float64(compressedSize)
+	}
+	gasPrice := arbmath.BigMin(feeCap, arbmath.BigAdd(latestHeader.BaseFee, tipCap))
+	report.EstimatedCost = arbmath.BigMulByUint(gasPrice, gasLimit)
+	report.MaxCost = arbmath.BigMulByUint(feeCap, gasLimit)
+	if numBlobs > 0 {
+		// #nosec G115
+		blobGas := uint64(numBlobs) * params.BlobTxBlobGasPerBlob
+		if latestHeader.ExcessBlobGas != nil && latestHeader.BlobGasUsed != nil {
+			report.ParentChainBlobFee = eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*latestHeader.ExcessBlobGas, *latestHeader.BlobGasUsed))
+			blobPrice := arbmath.BigMin(blobFeeCap, report.ParentChainBlobFee)
+			report.EstimatedCost.Add(report.EstimatedCost, arbmath.BigMulByUint(blobPrice, blobGas))
+		}
+		report.BlobGasFeeCap = blobFeeCap
+		report.MaxCost.Add(report.MaxCost, arbmath.BigMulByUint(blobFeeCap, blobGas))
+	}
+
+	log.Info(
+		"BatchPoster: dry-run batch built",
+		"sequenceNumber", report.SequenceNumber,
+		"from", report.FromMessage,
+		"to", report.ToMessage,
+		"daBackend", report.DABackend,
+		"uncompressedSize", report.UncompressedSize,
+		"compressedSize", report.CompressedSize,
+		"compressionRatio", report.CompressionRatio,
+		"numBlobs", report.NumBlobs,
+		"gasLimit", report.GasLimit,
+		"estimatedCost", report.EstimatedCost,
+		"maxCost", report.MaxCost,
+	)
+	if config.DryRun.ReportFile != "" {
+		if err := appendDryRunReport(config.DryRun.ReportFile, &report); err != nil {
+			return fmt.Errorf("error writing dry-run batch report: %w", err)
+		}
+	}
+
+	b.dryRun.position = &newPosition
+	return nil
+}
+
+func appendDryRunReport(path string, report *DryRunBatchReport) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
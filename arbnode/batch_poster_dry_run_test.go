@@ -0,0 +1,64 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestDryRunGasEstimate(t *testing.T) {
+	data := []byte{0, 1, 0, 2}
+	expected := params.TxGas + 1000 + 2*params.TxDataZeroGas + 2*params.TxDataNonZeroGasEIP2028
+	if got := dryRunGasEstimate(data, 1000); got != expected {
+		t.Fatalf("expected gas estimate %v, got %v", expected, got)
+	}
+}
+
+func TestAppendDryRunReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.jsonl")
+	for i := uint64(0); i < 3; i++ {
+		report := &DryRunBatchReport{
+			SequenceNumber: i,
+			EstimatedCost:  big.NewInt(int64(i) * 100),
+			MaxCost:        big.NewInt(int64(i) * 200),
+		}
+		if err := appendDryRunReport(path, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var count uint64
+	for scanner.Scan() {
+		var report DryRunBatchReport
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		if report.SequenceNumber != count {
+			t.Fatalf("expected report for batch %v, got %v", count, report.SequenceNumber)
+		}
+		if report.MaxCost.Uint64() != count*200 {
+			t.Fatalf("unexpected max cost %v in report for batch %v", report.MaxCost, count)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 reports, got %v", count)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+// ConfirmedTracker remembers the message count of the latest assertion the staker saw confirmed.
+type ConfirmedTracker struct {
+	count atomic.Uint64
+	known atomic.Bool
+}
+
+func (t *ConfirmedTracker) UpdateLatestConfirmed(count arbutil.MessageIndex, _ validator.GoGlobalState) {
+	t.count.Store(uint64(count))
+	t.known.Store(true)
+}
+
+func (t *ConfirmedTracker) ConfirmedMessageCount() (arbutil.MessageIndex, error) {
+	if t == nil {
+		return 0, errors.New("staker not set up")
+	}
+	if !t.known.Load() {
+		return 0, errors.New("latest confirmed assertion not known yet")
+	}
+	return arbutil.MessageIndex(t.count.Load()), nil
+}
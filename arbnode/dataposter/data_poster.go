@@ -713,6 +713,26 @@ func (p *DataPoster) feeAndTipCaps(ctx context.Context, nonce uint64, gasLimit u
 	return newBaseFeeCap, newTipCap, newBlobFeeCap, nil
 }
 
+// EstimateFeeAndTipCaps prices a transaction the same way PostTransaction would, without posting it.
+// It returns the fee, tip and blob fee caps along with the parent chain header they were computed against.
+func (p *DataPoster) EstimateFeeAndTipCaps(ctx context.Context, dataCreatedAt time.Time, nonce uint64, gasLimit uint64, numBlobs uint64) (*big.Int, *big.Int, *big.Int, *types.Header, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.updateBalance(ctx); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to update data poster balance: %w", err)
+	}
+	latestHeader, err := p.headerReader.LastHeader(ctx)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	feeCap, tipCap, blobFeeCap, err := p.feeAndTipCaps(ctx, nonce, gasLimit, numBlobs, nil, dataCreatedAt, 0, latestHeader)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return feeCap, tipCap, blobFeeCap, latestHeader, nil
+}
+
 func (p *DataPoster) PostSimpleTransaction(ctx context.Context, to common.Address, calldata []byte, gasLimit uint64, value *big.Int) (*types.Transaction, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -121,7 +121,11 @@ func (r *InboxReader) Start(ctxIn context.Context) error {
 	hadError := false
 	r.CallIteratively(func(ctx context.Context) time.Duration {
 		err := r.run(ctx, hadError)
-		if err != nil && !errors.Is(err, context.Canceled) && !strings.Contains(err.Error(), "header not found") {
+		if errors.Is(err, errBatchStartNotExecuted) {
+			// not an error: batches are added up to the one execution has to catch up to
+			log.Debug("waiting for execution to reach the start of a sequencer batch", "err", err)
+			hadError = false
+		} else if err != nil && !errors.Is(err, context.Canceled) && !strings.Contains(err.Error(), "header not found") {
 			log.Warn("error reading inbox", "err", err)
 			hadError = true
 		} else {
@@ -13,12 +13,14 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rlp"
 
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
@@ -27,6 +29,7 @@ import (
 	m "github.com/offchainlabs/nitro/broadcaster/message"
 	"github.com/offchainlabs/nitro/staker"
 	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/util/dbutil"
 )
 
 var (
@@ -122,6 +125,59 @@ func (t *InboxTracker) deleteBatchMetadataStartingAt(dbBatch ethdb.Batch, startI
 	return iter.Error()
 }
 
+// publishedDictionary is a compression dictionary published inline by a sequencer batch.
+type publishedDictionary struct {
+	BatchNumber uint64
+	Dictionary  []byte
+}
+
+func (t *InboxTracker) getPublishedDictionary(hash common.Hash) (*publishedDictionary, error) {
+	data, err := t.db.Get(append(common.CopyBytes(publishedDictionaryPrefix), hash.Bytes()...))
+	if err != nil {
+		if dbutil.IsErrNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var published publishedDictionary
+	if err := rlp.DecodeBytes(data, &published); err != nil {
+		return nil, err
+	}
+	return &published, nil
+}
+
+// GetDictionary returns a compression dictionary published by a sequencer batch the tracker has.
+func (t *InboxTracker) GetDictionary(ctx context.Context, hash common.Hash) ([]byte, error) {
+	published, err := t.getPublishedDictionary(hash)
+	if err != nil {
+		return nil, err
+	}
+	if published == nil {
+		return nil, fmt.Errorf("%w: %v", arbstate.ErrUnknownDictionary, hash)
+	}
+	return published.Dictionary, nil
+}
+
+// deletePublishedDictionariesStartingAt deletes the dictionaries published by the batches starting at startIndex.
+// Dictionaries are keyed by hash, but there are few of them, so all are checked.
+func (t *InboxTracker) deletePublishedDictionariesStartingAt(dbBatch ethdb.Batch, startIndex uint64) error {
+	iter := t.db.NewIterator(publishedDictionaryPrefix, nil)
+	defer iter.Release()
+	for iter.Next() {
+		var published publishedDictionary
+		if err := rlp.DecodeBytes(iter.Value(), &published); err != nil {
+			return err
+		}
+		if published.BatchNumber < startIndex {
+			continue
+		}
+		if err := dbBatch.Delete(iter.Key()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
 func (t *InboxTracker) GetDelayedAcc(seqNum uint64) (common.Hash, error) {
 	key := dbKey(rlpDelayedMessagePrefix, seqNum)
 	hasKey, err := t.db.Has(key)
@@ -274,10 +330,11 @@ func (t *InboxTracker) FindInboxBatchContainingMessage(pos arbutil.MessageIndex)
 	}
 }
 
-func (t *InboxTracker) PopulateFeedBacklog(broadcastServer *broadcaster.Broadcaster) error {
+// FeedBacklogStart returns the first message PopulateFeedBacklog broadcasts.
+func (t *InboxTracker) FeedBacklogStart() (arbutil.MessageIndex, error) {
 	batchCount, err := t.GetBatchCount()
 	if err != nil {
-		return fmt.Errorf("error getting batch count: %w", err)
+		return 0, fmt.Errorf("error getting batch count: %w", err)
 	}
 	var startMessage arbutil.MessageIndex
 	if batchCount >= 2 {
@@ -287,9 +344,17 @@ func (t *InboxTracker) PopulateFeedBacklog(broadcastServer *broadcaster.Broadcas
 		batchIndex := batchCount - 2
 		startMessage, err = t.GetBatchMessageCount(batchIndex)
 		if err != nil {
-			return fmt.Errorf("error getting batch %v message count: %w", batchIndex, err)
+			return 0, fmt.Errorf("error getting batch %v message count: %w", batchIndex, err)
 		}
 	}
+	return startMessage, nil
+}
+
+func (t *InboxTracker) PopulateFeedBacklog(broadcastServer *broadcaster.Broadcaster) error {
+	startMessage, err := t.FeedBacklogStart()
+	if err != nil {
+		return err
+	}
 	messageCount, err := t.txStreamer.GetMessageCount()
 	if err != nil {
 		return fmt.Errorf("error getting tx streamer message count: %w", err)
@@ -606,6 +671,9 @@ func (t *InboxTracker) setDelayedCountReorgAndWriteBatch(batch ethdb.Batch, firs
 	if err := t.deleteBatchMetadataStartingAt(batch, count); err != nil {
 		return err
 	}
+	if err := t.deletePublishedDictionariesStartingAt(batch, count); err != nil {
+		return err
+	}
 	var prevMesssageCount arbutil.MessageIndex
 	if count > 0 {
 		prevMesssageCount, err = t.GetBatchMessageCount(count - 1)
@@ -617,11 +685,23 @@ func (t *InboxTracker) setDelayedCountReorgAndWriteBatch(batch ethdb.Batch, firs
 	return t.txStreamer.ReorgToAndEndBatch(batch, prevMesssageCount)
 }
 
+// errBatchStartNotExecuted is returned when a sequencer batch can't be parsed until execution has
+// reached the batch's start, as its format depends on the ArbOS version at that point.
+var errBatchStartNotExecuted = errors.New("execution hasn't reached the start of the sequencer batch")
+
 type multiplexerBackend struct {
 	batchSeqNum           uint64
 	batches               []*SequencerInboxBatch
 	positionWithinMessage uint64
 
+	// the first batch parsed and its message count before it
+	startBatchSeqNum             uint64
+	startMessageCount            arbutil.MessageIndex
+	startBatchCompressionEnabled *bool
+	// dictionaries published by the batches parsed so far, written with dbBatch
+	publishedDictionaries map[common.Hash]publishedDictionary
+	dbBatch               ethdb.Batch
+
 	ctx    context.Context
 	client *ethclient.Client
 	inbox  *InboxTracker
@@ -661,6 +741,83 @@ func (b *multiplexerBackend) ReadDelayedInbox(seqNum uint64) (*arbostypes.L1Inco
 	return b.inbox.GetDelayedMessage(b.ctx, seqNum)
 }
 
+// BatchCompressionEnabled checks the ArbOS version after the message before the batch. Execution only
+// reflects the messages of batches already added, so a later batch can only be checked if the ArbOS
+// version was already enabled before the first batch, as ArbOS versions only go up. The version at a
+// message is determined by the messages before it, so this matches what the replay binary reads from
+// its state; execution just has to catch up first, which errBatchStartNotExecuted retries.
+func (b *multiplexerBackend) BatchCompressionEnabled() (bool, error) {
+	if b.startBatchCompressionEnabled == nil {
+		enabled, err := b.checkBatchCompressionEnabled()
+		if err != nil {
+			return false, err
+		}
+		b.startBatchCompressionEnabled = &enabled
+	}
+	if !*b.startBatchCompressionEnabled && b.batchSeqNum != b.startBatchSeqNum {
+		return false, errBatchStartNotExecuted
+	}
+	return *b.startBatchCompressionEnabled, nil
+}
+
+func (b *multiplexerBackend) checkBatchCompressionEnabled() (bool, error) {
+	if b.startMessageCount == 0 {
+		return false, nil
+	}
+	head, err := b.inbox.txStreamer.exec.HeadMessageNumber()
+	if err != nil {
+		return false, err
+	}
+	if head+1 < b.startMessageCount {
+		return false, fmt.Errorf("%w: batch %v starts at message %v, execution head is %v", errBatchStartNotExecuted, b.startBatchSeqNum, b.startMessageCount, head)
+	}
+	arbosVersion, err := b.inbox.txStreamer.exec.ArbOSVersionForMessageNumber(b.startMessageCount - 1)
+	if err != nil {
+		return false, err
+	}
+	return arbosVersion >= arbosState.ArbosVersion_BatchCompression, nil
+}
+
+// GetDictionary returns a dictionary published by a batch before the one being parsed.
+func (b *multiplexerBackend) GetDictionary(ctx context.Context, hash common.Hash) ([]byte, error) {
+	if published, ok := b.publishedDictionaries[hash]; ok && published.BatchNumber < b.batchSeqNum {
+		return published.Dictionary, nil
+	}
+	published, err := b.inbox.getPublishedDictionary(hash)
+	if err != nil {
+		return nil, err
+	}
+	// later batches are being replaced
+	if published == nil || published.BatchNumber >= b.startBatchSeqNum {
+		return nil, fmt.Errorf("%w: %v", arbstate.ErrUnknownDictionary, hash)
+	}
+	return published.Dictionary, nil
+}
+
+// PublishDictionary records a dictionary published by the batch being parsed, unless an earlier batch
+// already published it.
+func (b *multiplexerBackend) PublishDictionary(ctx context.Context, dictionary []byte) error {
+	hash := crypto.Keccak256Hash(dictionary)
+	if _, err := b.GetDictionary(ctx, hash); err == nil {
+		return nil
+	} else if !errors.Is(err, arbstate.ErrUnknownDictionary) {
+		return err
+	}
+	published := publishedDictionary{
+		BatchNumber: b.batchSeqNum,
+		Dictionary:  dictionary,
+	}
+	data, err := rlp.EncodeToBytes(published)
+	if err != nil {
+		return err
+	}
+	if err := b.dbBatch.Put(append(common.CopyBytes(publishedDictionaryPrefix), hash.Bytes()...), data); err != nil {
+		return err
+	}
+	b.publishedDictionaries[hash] = published
+	return nil
+}
+
 var delayedMessagesMismatch = errors.New("sequencer batch delayed messages missing or different")
 
 func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclient.Client, batches []*SequencerInboxBatch) error {
@@ -717,6 +874,10 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 	if err != nil {
 		return err
 	}
+	err = t.deletePublishedDictionariesStartingAt(dbBatch, startPos)
+	if err != nil {
+		return err
+	}
 
 	for _, batch := range batches {
 		if batch.SequenceNumber != pos {
@@ -754,19 +915,33 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		batchSeqNum: batches[0].SequenceNumber,
 		batches:     batches,
 
+		startBatchSeqNum:      batches[0].SequenceNumber,
+		startMessageCount:     prevbatchmeta.MessageCount,
+		publishedDictionaries: make(map[common.Hash]publishedDictionary),
+		dbBatch:               dbBatch,
+
 		inbox:  t,
 		ctx:    ctx,
 		client: client,
 	}
-	multiplexer := arbstate.NewInboxMultiplexer(backend, prevbatchmeta.DelayedMessageCount, t.dapReaders, daprovider.KeysetValidate)
+	multiplexer := arbstate.NewInboxMultiplexer(backend, prevbatchmeta.DelayedMessageCount, t.dapReaders, backend, daprovider.KeysetValidate)
 	batchMessageCounts := make(map[uint64]arbutil.MessageIndex)
 	currentpos := prevbatchmeta.MessageCount + 1
+	// set if only the batches before the one at pos could be added
+	var batchStartErr error
 	for {
 		if len(backend.batches) == 0 {
 			break
 		}
 		batchSeqNum := backend.batches[0].SequenceNumber
 		msg, err := multiplexer.Pop(ctx)
+		if errors.Is(err, errBatchStartNotExecuted) && batchSeqNum > startPos {
+			// add the earlier batches, so that execution can catch up to this one
+			batches = batches[:batchSeqNum-startPos]
+			pos = batchSeqNum
+			batchStartErr = err
+			break
+		}
 		if err != nil {
 			return err
 		}
@@ -876,7 +1051,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client *ethclien
 		}
 	}
 
-	return nil
+	return batchStartErr
 }
 
 func (t *InboxTracker) ReorgDelayedTo(count uint64) error {
@@ -927,6 +1102,10 @@ func (t *InboxTracker) ReorgBatchesTo(count uint64) error {
 	if err != nil {
 		return err
 	}
+	err = t.deletePublishedDictionariesStartingAt(dbBatch, count)
+	if err != nil {
+		return err
+	}
 	countData, err := rlp.EncodeToBytes(count)
 	if err != nil {
 		return err
@@ -30,6 +30,7 @@ import (
 	"github.com/offchainlabs/nitro/arbnode/resourcemanager"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbstate/daprovider/daclient"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcastclient"
 	"github.com/offchainlabs/nitro/broadcastclients"
@@ -37,6 +38,7 @@ import (
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
 	"github.com/offchainlabs/nitro/execution/gethexec"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
@@ -94,12 +96,14 @@ type Config struct {
 	Bold                 boldstaker.BoldConfig          `koanf:"bold"`
 	SeqCoordinator       SeqCoordinatorConfig           `koanf:"seq-coordinator"`
 	DataAvailability     das.DataAvailabilityConfig     `koanf:"data-availability"`
+	DAProvider           daclient.ClientConfig          `koanf:"da-provider" reload:"hot"`
 	SyncMonitor          SyncMonitorConfig              `koanf:"sync-monitor"`
 	Dangerous            DangerousConfig                `koanf:"dangerous"`
 	TransactionStreamer  TransactionStreamerConfig      `koanf:"transaction-streamer" reload:"hot"`
 	Maintenance          MaintenanceConfig              `koanf:"maintenance" reload:"hot"`
 	ResourceMgmt         resourcemanager.Config         `koanf:"resource-mgmt" reload:"hot"`
 	BlockMetadataFetcher BlockMetadataFetcherConfig     `koanf:"block-metadata-fetcher" reload:"hot"`
+	ExecutionClient      rpcclient.ClientConfig         `koanf:"execution-client" reload:"hot"`
 	// SnapSyncConfig is only used for testing purposes, these should not be configured in production.
 	SnapSyncTest SnapSyncConfig
 }
@@ -136,9 +140,22 @@ func (c *Config) Validate() error {
 	if err := c.Staker.Validate(); err != nil {
 		return err
 	}
+	if c.DAProvider.Enable {
+		if c.DataAvailability.Enable && c.BatchPoster.Enable && c.DAProvider.WithWriter && !c.BatchPoster.DAFallback.Enable {
+			return errors.New("cannot enable both data-availability and da-provider writers for the batch poster without batch-poster.da-fallback")
+		}
+		if err := c.DAProvider.RPC.Validate(); err != nil {
+			return err
+		}
+	}
 	if c.TransactionStreamer.TrackBlockMetadataFrom != 0 && !c.BlockMetadataFetcher.Enable {
 		log.Warn("track-block-metadata-from is set but blockMetadata fetcher is not enabled")
 	}
+	if c.ExecutionClient.URL != "" {
+		if err := c.ExecutionClient.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -165,11 +182,13 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet, feedInputEnable bool, feed
 	boldstaker.BoldConfigAddOptions(prefix+".bold", f)
 	SeqCoordinatorConfigAddOptions(prefix+".seq-coordinator", f)
 	das.DataAvailabilityConfigAddNodeOptions(prefix+".data-availability", f)
+	daclient.ClientConfigAddOptions(prefix+".da-provider", f)
 	SyncMonitorConfigAddOptions(prefix+".sync-monitor", f)
 	DangerousConfigAddOptions(prefix+".dangerous", f)
 	TransactionStreamerConfigAddOptions(prefix+".transaction-streamer", f)
 	MaintenanceConfigAddOptions(prefix+".maintenance", f)
 	BlockMetadataFetcherConfigAddOptions(prefix+".block-metadata-fetcher", f)
+	rpcclient.RPCClientAddOptions(prefix+".execution-client", f, &ConfigDefault.ExecutionClient)
 }
 
 var ConfigDefault = Config{
@@ -185,12 +204,14 @@ var ConfigDefault = Config{
 	Bold:                 boldstaker.DefaultBoldConfig,
 	SeqCoordinator:       DefaultSeqCoordinatorConfig,
 	DataAvailability:     das.DefaultDataAvailabilityConfig,
+	DAProvider:           daclient.DefaultClientConfig,
 	SyncMonitor:          DefaultSyncMonitorConfig,
 	Dangerous:            DefaultDangerousConfig,
 	TransactionStreamer:  DefaultTransactionStreamerConfig,
 	ResourceMgmt:         resourcemanager.DefaultConfig,
 	Maintenance:          DefaultMaintenanceConfig,
 	BlockMetadataFetcher: DefaultBlockMetadataFetcherConfig,
+	ExecutionClient:      execrpc.DefaultClientConfig,
 	SnapSyncTest:         DefaultSnapSyncConfig,
 }
 
@@ -279,6 +300,7 @@ type Node struct {
 	DelayedSequencer        *DelayedSequencer
 	BatchPoster             *BatchPoster
 	MessagePruner           *MessagePruner
+	ConfirmedTracker        *ConfirmedTracker
 	BlockValidator          *staker.BlockValidator
 	StatelessBlockValidator *staker.StatelessBlockValidator
 	Staker                  *multiprotocolstaker.MultiProtocolStaker
@@ -544,6 +566,7 @@ func createNodeImpl(
 			DelayedSequencer:        nil,
 			BatchPoster:             nil,
 			MessagePruner:           nil,
+			ConfirmedTracker:        nil,
 			BlockValidator:          nil,
 			StatelessBlockValidator: nil,
 			Staker:                  nil,
@@ -605,10 +628,22 @@ func createNodeImpl(
 	if txStreamer != nil && txStreamer.chainConfig.ArbitrumChainParams.DataAvailabilityCommittee && daReader == nil {
 		return nil, errors.New("data availability service required but unconfigured")
 	}
+	var daClient *daclient.Client
+	if config.DAProvider.Enable {
+		// the client reads the hot rpc options from the live config
+		daClient, err = daclient.NewClient(ctx, func() *rpcclient.ClientConfig { return &configFetcher.Get().DAProvider.RPC })
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var dapReaders []daprovider.Reader
 	if daReader != nil {
 		dapReaders = append(dapReaders, daprovider.NewReaderForDAS(daReader, dasKeysetFetcher))
 	}
+	if daClient != nil {
+		dapReaders = append(dapReaders, daClient)
+	}
 	if blobReader != nil {
 		dapReaders = append(dapReaders, daprovider.NewReaderForBlobReader(blobReader))
 	}
@@ -653,6 +688,7 @@ func createNodeImpl(
 			exec,
 			rawdb.NewTable(arbDb, storage.BlockValidatorPrefix),
 			dapReaders,
+			inboxTracker,
 			func() *staker.BlockValidatorConfig { return &configFetcher.Get().BlockValidator },
 			stack,
 		)
@@ -683,6 +719,7 @@ func createNodeImpl(
 
 	var stakerObj *multiprotocolstaker.MultiProtocolStaker
 	var messagePruner *MessagePruner
+	var confirmedTracker *ConfirmedTracker
 	var stakerAddr common.Address
 
 	if config.Staker.Enable {
@@ -729,7 +766,8 @@ func createNodeImpl(
 			}
 		}
 
-		var confirmedNotifiers []legacystaker.LatestConfirmedNotifier
+		confirmedTracker = &ConfirmedTracker{}
+		confirmedNotifiers := []legacystaker.LatestConfirmedNotifier{confirmedTracker}
 		if config.MessagePruner.Enable {
 			messagePruner = NewMessagePruner(txStreamer, inboxTracker, func() *MessagePrunerConfig { return &configFetcher.Get().MessagePruner })
 			confirmedNotifiers = append(confirmedNotifiers, messagePruner)
@@ -754,8 +792,14 @@ func createNodeImpl(
 			return nil, errors.New("batchposter, but no TxOpts")
 		}
 		var dapWriter daprovider.Writer
+		dapWriters := make(map[string]daprovider.Writer)
+		if daClient != nil && config.DAProvider.WithWriter {
+			dapWriter = daClient
+			dapWriters[DABackendDAProvider] = daClient
+		}
 		if daWriter != nil {
 			dapWriter = daprovider.NewWriterForDAS(daWriter)
+			dapWriters[DABackendAnyTrust] = dapWriter
 		}
 		batchPoster, err = NewBatchPoster(ctx, &BatchPosterOpts{
 			DataPosterDB:  rawdb.NewTable(arbDb, storage.BatchPosterPrefix),
@@ -770,6 +814,7 @@ func createNodeImpl(
 			DAPWriter:     dapWriter,
 			ParentChainID: parentChainID,
 			DAPReaders:    dapReaders,
+			DAPWriters:    dapWriters,
 		})
 		if err != nil {
 			return nil, err
@@ -800,6 +845,7 @@ func createNodeImpl(
 		DelayedSequencer:        delayedSequencer,
 		BatchPoster:             batchPoster,
 		MessagePruner:           messagePruner,
+		ConfirmedTracker:        confirmedTracker,
 		BlockValidator:          blockValidator,
 		StatelessBlockValidator: statelessBlockValidator,
 		Staker:                  stakerObj,
@@ -916,6 +962,16 @@ func CreateNode(
 			Public: false,
 		})
 	}
+	if configFetcher.Get().ExecutionClient.URL != "" {
+		// The execution node calls back into consensus
+		apis = append(apis, rpc.API{
+			Namespace:     execrpc.ConsensusNamespace,
+			Version:       "1.0",
+			Service:       execrpc.NewConsensusServerAPI(currentNode),
+			Public:        false,
+			Authenticated: true,
+		})
+	}
 	stack.RegisterAPIs(apis)
 
 	return currentNode, nil
@@ -957,7 +1013,17 @@ func (n *Node) Start(ctx context.Context) error {
 		}
 	}
 	if n.BroadcastServer != nil {
-		err = n.BroadcastServer.Initialize()
+		if n.InboxTracker != nil {
+			// PopulateFeedBacklog broadcasts this node's messages from the backlog start on,
+			// which must replace any persisted by a previous run, as they may have been reorged.
+			var backlogStart arbutil.MessageIndex
+			backlogStart, err = n.InboxTracker.FeedBacklogStart()
+			if err == nil {
+				err = n.BroadcastServer.InitializeTruncated(backlogStart)
+			}
+		} else {
+			err = n.BroadcastServer.Initialize()
+		}
 		if err != nil {
 			return fmt.Errorf("error initializing feed broadcast server: %w", err)
 		}
@@ -1122,6 +1188,9 @@ func (n *Node) StopAndWait() {
 }
 
 func (n *Node) FindInboxBatchContainingMessage(message arbutil.MessageIndex) (uint64, bool, error) {
+	if n.InboxTracker == nil {
+		return 0, false, errors.New("inbox tracker not set up")
+	}
 	return n.InboxTracker.FindInboxBatchContainingMessage(message)
 }
 
@@ -1165,6 +1234,10 @@ func (n *Node) ValidatedMessageCount() (arbutil.MessageIndex, error) {
 	return n.BlockValidator.GetValidated(), nil
 }
 
+func (n *Node) ConfirmedMessageCount() (arbutil.MessageIndex, error) {
+	return n.ConfirmedTracker.ConfirmedMessageCount()
+}
+
 func (n *Node) BlockMetadataAtCount(count arbutil.MessageIndex) (common.BlockMetadata, error) {
 	return n.TxStreamer.BlockMetadataAtCount(count)
 }
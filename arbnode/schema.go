@@ -14,6 +14,7 @@ var (
 	parentChainBlockNumberPrefix        []byte = []byte("p") // maps a delayed sequence number to a parent chain block number
 	sequencerBatchMetaPrefix            []byte = []byte("s") // maps a batch sequence number to BatchMetadata
 	delayedSequencedPrefix              []byte = []byte("a") // maps a delayed message count to the first sequencer batch sequence number with this delayed count
+	publishedDictionaryPrefix           []byte = []byte("c") // maps a compression dictionary hash to the dictionary and the sequencer batch publishing it
 
 	messageCountKey             []byte = []byte("_messageCount")                // contains the current message count
 	lastPrunedMessageKey        []byte = []byte("_lastPrunedMessageKey")        // contains the last pruned message key
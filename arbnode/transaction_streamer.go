@@ -124,12 +124,21 @@ func NewTransactionStreamer(
 	if err != nil {
 		return nil, err
 	}
-	if config().TrackBlockMetadataFrom != 0 {
-		trackBlockMetadataFrom, err := exec.BlockNumberToMessageIndex(config().TrackBlockMetadataFrom)
-		if err != nil {
-			return nil, err
+	if trackFrom := config().TrackBlockMetadataFrom; trackFrom != 0 {
+		if exec != nil {
+			trackBlockMetadataFrom, err := exec.BlockNumberToMessageIndex(trackFrom)
+			if err != nil {
+				return nil, err
+			}
+			streamer.trackBlockMetadataFrom = trackBlockMetadataFrom
+		} else {
+			// Read-only streamers, like the one of the feed replay server, run without an execution client
+			genesis := chainConfig.ArbitrumChainParams.GenesisBlockNum
+			if trackFrom < genesis {
+				return nil, fmt.Errorf("track-block-metadata-from %d < genesis %d", trackFrom, genesis)
+			}
+			streamer.trackBlockMetadataFrom = arbutil.MessageIndex(trackFrom - genesis)
 		}
-		streamer.trackBlockMetadataFrom = trackBlockMetadataFrom
 	}
 	return streamer, nil
 }
@@ -455,6 +464,9 @@ func (s *TransactionStreamer) GetMessage(seqNum arbutil.MessageIndex) (*arbostyp
 	}
 
 	err = message.Message.FillInBatchGasCost(func(batchNum uint64) ([]byte, error) {
+		if s.inboxReader == nil {
+			return nil, fmt.Errorf("no inbox reader to fetch batch %v", batchNum)
+		}
 		ctx, err := s.GetContextSafe()
 		if err != nil {
 			return nil, err
@@ -505,6 +517,19 @@ func (s *TransactionStreamer) getMessageWithMetadataAndBlockInfo(seqNum arbutil.
 	return &msgWithBlockInfo, nil
 }
 
+// GetMessagesWithBlockInfo returns the messages in [start, end) along with the block info that was broadcast with them.
+func (s *TransactionStreamer) GetMessagesWithBlockInfo(start, end arbutil.MessageIndex) ([]arbostypes.MessageWithMetadataAndBlockInfo, error) {
+	var messages []arbostypes.MessageWithMetadataAndBlockInfo
+	for seqNum := start; seqNum < end; seqNum++ {
+		msg, err := s.getMessageWithMetadataAndBlockInfo(seqNum)
+		if err != nil {
+			return nil, fmt.Errorf("error getting message %v: %w", seqNum, err)
+		}
+		messages = append(messages, *msg)
+	}
+	return messages, nil
+}
+
 // Note: if changed to acquire the mutex, some internal users may need to be updated to a non-locking version.
 func (s *TransactionStreamer) GetMessageCount() (arbutil.MessageIndex, error) {
 	posBytes, err := s.db.Get(messageCountKey)
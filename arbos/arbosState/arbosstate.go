@@ -58,6 +58,7 @@ type ArbosState struct {
 	genesisBlockNum        storage.StorageBackedUint64
 	infraFeeAccount        storage.StorageBackedAddress
 	brotliCompressionLevel storage.StorageBackedUint64 // brotli compression level used for pricing
+	batchCompressionBlock  storage.StorageBackedUint64 // first block of ArbosVersion_BatchCompression
 	backingStorage         *storage.Storage
 	Burner                 burn.Burner
 }
@@ -92,6 +93,7 @@ func OpenArbosState(stateDB vm.StateDB, burner burn.Burner) (*ArbosState, error)
 		backingStorage.OpenStorageBackedUint64(uint64(genesisBlockNumOffset)),
 		backingStorage.OpenStorageBackedAddress(uint64(infraFeeAccountOffset)),
 		backingStorage.OpenStorageBackedUint64(uint64(brotliCompressionLevelOffset)),
+		backingStorage.OpenStorageBackedUint64(uint64(batchCompressionBlockOffset)),
 		backingStorage,
 		burner,
 	}, nil
@@ -154,6 +156,7 @@ const (
 	genesisBlockNumOffset
 	infraFeeAccountOffset
 	brotliCompressionLevelOffset
+	batchCompressionBlockOffset
 )
 
 type SubspaceID []byte
@@ -172,6 +175,10 @@ var (
 
 var PrecompileMinArbOSVersions = make(map[common.Address]uint64)
 
+// ArbosVersion_BatchCompression is the first ArbOS version whose batches may be compressed with zstd
+// or with a custom brotli dictionary.
+const ArbosVersion_BatchCompression uint64 = 33
+
 func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage) (*ArbosState, error) {
 	sto := storage.NewGeth(stateDB, burner)
 	arbosVersion, err := sto.GetUint64ByUint64(uint64(versionOffset))
@@ -232,7 +239,7 @@ func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *p
 		return nil, err
 	}
 	if desiredArbosVersion > 1 {
-		err = aState.UpgradeArbosVersion(desiredArbosVersion, true, stateDB, chainConfig)
+		err = aState.UpgradeArbosVersion(desiredArbosVersion, true, chainConfig.ArbitrumChainParams.GenesisBlockNum, stateDB, chainConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -241,13 +248,13 @@ func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *p
 }
 
 func (state *ArbosState) UpgradeArbosVersionIfNecessary(
-	currentTimestamp uint64, stateDB vm.StateDB, chainConfig *params.ChainConfig,
+	currentTimestamp uint64, currentBlockNumber uint64, stateDB vm.StateDB, chainConfig *params.ChainConfig,
 ) error {
 	upgradeTo, err := state.upgradeVersion.Get()
 	state.Restrict(err)
 	flagday, _ := state.upgradeTimestamp.Get()
 	if state.arbosVersion < upgradeTo && currentTimestamp >= flagday {
-		return state.UpgradeArbosVersion(upgradeTo, false, stateDB, chainConfig)
+		return state.UpgradeArbosVersion(upgradeTo, false, currentBlockNumber, stateDB, chainConfig)
 	}
 	return nil
 }
@@ -255,7 +262,7 @@ func (state *ArbosState) UpgradeArbosVersionIfNecessary(
 var ErrFatalNodeOutOfDate = errors.New("please upgrade to the latest version of the node software")
 
 func (state *ArbosState) UpgradeArbosVersion(
-	upgradeTo uint64, firstTime bool, stateDB vm.StateDB, chainConfig *params.ChainConfig,
+	upgradeTo uint64, firstTime bool, currentBlockNumber uint64, stateDB vm.StateDB, chainConfig *params.ChainConfig,
 ) error {
 	for state.arbosVersion < upgradeTo {
 		ensure := func(err error) {
@@ -331,6 +338,19 @@ func (state *ArbosState) UpgradeArbosVersion(
 		case params.ArbosVersion_32:
 			// no change state needed
 
+		case ArbosVersion_BatchCompression:
+			// the new batch formats need go-ethereum's support and the module root of a replay binary
+			// which parses them, so nodes built without them treat the upgrade as unsupported
+			if params.MaxArbosVersionSupported < ArbosVersion_BatchCompression {
+				return fmt.Errorf(
+					"the chain is upgrading to ArbOS version %v, not yet supported by go-ethereum, %w",
+					nextArbosVersion,
+					ErrFatalNodeOutOfDate,
+				)
+			}
+			// the replay binary needs this to tell the ArbOS version at the start of a batch
+			ensure(state.batchCompressionBlock.Set(currentBlockNumber))
+
 		default:
 			return fmt.Errorf(
 				"the chain is upgrading to unsupported ArbOS version %v, %w",
@@ -411,6 +431,12 @@ func (state *ArbosState) SetBrotliCompressionLevel(val uint64) error {
 	return errors.New("invalid brotli compression level")
 }
 
+// BatchCompressionBlock returns the number of the first block of ArbosVersion_BatchCompression.
+// Only meaningful once that version is reached.
+func (state *ArbosState) BatchCompressionBlock() (uint64, error) {
+	return state.batchCompressionBlock.Get()
+}
+
 func (state *ArbosState) RetryableState() *retryables.RetryableState {
 	return state.retryableState
 }
@@ -0,0 +1,141 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package daclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+)
+
+// Client is a daprovider.Reader and daprovider.Writer backed by an external DA provider
+// reachable over JSON-RPC, typically served by the daprovider/server package.
+type Client struct {
+	*rpcclient.RpcClient
+
+	validHeaderBytes sync.Map // byte -> bool
+}
+
+type ClientConfig struct {
+	Enable     bool                   `koanf:"enable"`
+	WithWriter bool                   `koanf:"with-writer"`
+	RPC        rpcclient.ClientConfig `koanf:"rpc" reload:"hot"`
+}
+
+var DefaultClientConfig = ClientConfig{
+	Enable:     false,
+	WithWriter: false,
+	RPC: rpcclient.ClientConfig{
+		Retries:                   3,
+		RetryErrors:               "websocket: close.*|dial tcp .*|.*i/o timeout|.*connection reset by peer|.*connection refused",
+		ArgLogLimit:               2048,
+		WebsocketMessageSizeLimit: 256 * 1024 * 1024,
+	},
+}
+
+func ClientConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultClientConfig.Enable, "enable daprovider client")
+	f.Bool(prefix+".with-writer", DefaultClientConfig.WithWriter, "implies if the daprovider server supports writer interface")
+	rpcclient.RPCClientAddOptions(prefix+".rpc", f, &DefaultClientConfig.RPC)
+}
+
+func NewClient(ctx context.Context, config rpcclient.ClientConfigFetcher) (*Client, error) {
+	client := &Client{
+		RpcClient: rpcclient.NewRpcClient(config, nil),
+	}
+	if err := client.Start(ctx); err != nil {
+		return nil, fmt.Errorf("error starting daprovider client: %w", err)
+	}
+	return client, nil
+}
+
+// IsValidHeaderByte asks the DA provider whether it handles the given header byte.
+// Positive and negative answers are cached, as the set of header bytes a provider
+// understands doesn't change over its lifetime. Errors are returned and not cached, so
+// an unreachable provider stalls the batch instead of it being parsed as empty.
+func (c *Client) IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error) {
+	if cached, ok := c.validHeaderBytes.Load(headerByte); ok {
+		return cached.(bool), nil
+	}
+	var isValid bool
+	if err := c.CallContext(ctx, &isValid, "daprovider_isValidHeaderByte", headerByte); err != nil {
+		return false, fmt.Errorf("error returned from daprovider_isValidHeaderByte rpc method: %w", err)
+	}
+	c.validHeaderBytes.Store(headerByte, isValid)
+	return isValid, nil
+}
+
+// HealthCheck asks the DA provider whether its writer is able to store batches. Providers
+// predating the health check method are assumed healthy.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	err := c.CallContext(ctx, nil, "daprovider_healthCheck")
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error returned from daprovider_healthCheck rpc method: %w", err)
+	}
+	return nil
+}
+
+// RecoverPayloadFromBatchResult is the result struct that data availability providers should use to respond with underlying payload and updated preimages map to a RecoverPayloadFromBatch fetch request
+type RecoverPayloadFromBatchResult struct {
+	Payload   hexutil.Bytes                                          `json:"payload,omitempty"`
+	Preimages map[arbutil.PreimageType]map[common.Hash]hexutil.Bytes `json:"preimages,omitempty"`
+}
+
+// RecoverPayloadFromBatch fetches the underlying payload from the DA provider. Preimages needed
+// to validate the payload are recorded remotely and replayed into preimageRecorder.
+func (c *Client) RecoverPayloadFromBatch(
+	ctx context.Context,
+	batchNum uint64,
+	batchBlockHash common.Hash,
+	sequencerMsg []byte,
+	preimageRecorder daprovider.PreimageRecorder,
+	validateSeqMsg bool,
+) ([]byte, error) {
+	var recoverPayloadFromBatchResult RecoverPayloadFromBatchResult
+	if err := c.CallContext(ctx, &recoverPayloadFromBatchResult, "daprovider_recoverPayloadFromBatch", hexutil.Uint64(batchNum), batchBlockHash, hexutil.Bytes(sequencerMsg), preimageRecorder != nil, validateSeqMsg); err != nil {
+		return nil, fmt.Errorf("error returned from daprovider_recoverPayloadFromBatch rpc method, err: %w", err)
+	}
+	if preimageRecorder != nil {
+		for ty, preimages := range recoverPayloadFromBatchResult.Preimages {
+			for hash, preimage := range preimages {
+				preimageRecorder(hash, preimage, ty)
+			}
+		}
+	}
+	return recoverPayloadFromBatchResult.Payload, nil
+}
+
+// StoreResult is the result struct that data availability providers should use to respond with a commitment to a Store request for posting batch data to their DA service
+type StoreResult struct {
+	SerializedDACert hexutil.Bytes `json:"serialized-da-cert,omitempty"`
+}
+
+// Store posts the batch data to the DA provider and returns the sequencer message to be posted on the parent chain
+func (c *Client) Store(
+	ctx context.Context,
+	message []byte,
+	timeout uint64,
+	disableFallbackStoreDataOnChain bool,
+) ([]byte, error) {
+	var storeResult StoreResult
+	if err := c.CallContext(ctx, &storeResult, "daprovider_store", hexutil.Bytes(message), hexutil.Uint64(timeout), disableFallbackStoreDataOnChain); err != nil {
+		return nil, fmt.Errorf("error returned from daprovider_store rpc method, err: %w", err)
+	}
+	return storeResult.SerializedDACert, nil
+}
@@ -15,8 +15,9 @@ import (
 )
 
 type Reader interface {
-	// IsValidHeaderByte returns true if the given headerByte has bits corresponding to the DA provider
-	IsValidHeaderByte(headerByte byte) bool
+	// IsValidHeaderByte returns true if the given headerByte has bits corresponding to the DA provider.
+	// An error means the provider couldn't tell, and the batch must not be treated as unknown.
+	IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error)
 
 	// RecoverPayloadFromBatch fetches the underlying payload from the DA provider given the batch header information
 	RecoverPayloadFromBatch(
@@ -43,8 +44,8 @@ type readerForDAS struct {
 	keysetFetcher DASKeysetFetcher
 }
 
-func (d *readerForDAS) IsValidHeaderByte(headerByte byte) bool {
-	return IsDASMessageHeaderByte(headerByte)
+func (d *readerForDAS) IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error) {
+	return IsDASMessageHeaderByte(headerByte), nil
 }
 
 func (d *readerForDAS) RecoverPayloadFromBatch(
@@ -68,8 +69,8 @@ type readerForBlobReader struct {
 	blobReader BlobReader
 }
 
-func (b *readerForBlobReader) IsValidHeaderByte(headerByte byte) bool {
-	return IsBlobHashesHeaderByte(headerByte)
+func (b *readerForBlobReader) IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error) {
+	return IsBlobHashesHeaderByte(headerByte), nil
 }
 
 func (b *readerForBlobReader) RecoverPayloadFromBatch(
@@ -0,0 +1,182 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbstate/daprovider/daclient"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/util/signature"
+)
+
+// Server exposes a daprovider.Reader, and optionally a daprovider.Writer, over JSON-RPC
+// under the "daprovider" namespace so that it can be consumed by daclient.Client.
+type Server struct {
+	reader daprovider.Reader
+	writer daprovider.Writer
+}
+
+type ServerConfig struct {
+	Addr               string                              `koanf:"addr"`
+	Port               uint64                              `koanf:"port"`
+	JWTSecret          string                              `koanf:"jwtsecret"`
+	EnableDAWriter     bool                                `koanf:"enable-da-writer"`
+	ServerTimeouts     genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
+	RPCServerBodyLimit int                                 `koanf:"rpc-server-body-limit"`
+}
+
+var DefaultServerConfig = ServerConfig{
+	Addr:               "localhost",
+	Port:               9880,
+	JWTSecret:          "",
+	EnableDAWriter:     false,
+	ServerTimeouts:     genericconf.HTTPServerTimeoutConfigDefault,
+	RPCServerBodyLimit: genericconf.HTTPServerBodyLimitDefault,
+}
+
+func ServerConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".addr", DefaultServerConfig.Addr, "JSON rpc server listening interface")
+	f.Uint64(prefix+".port", DefaultServerConfig.Port, "JSON rpc server listening port")
+	f.String(prefix+".jwtsecret", DefaultServerConfig.JWTSecret, "path to file with jwtsecret for validation")
+	f.Bool(prefix+".enable-da-writer", DefaultServerConfig.EnableDAWriter, "implies if the das server supports daprovider's writer interface")
+	f.Int(prefix+".rpc-server-body-limit", DefaultServerConfig.RPCServerBodyLimit, "HTTP-RPC server maximum request body size in bytes; the default (0) uses geth's 5MB limit")
+	genericconf.HTTPServerTimeoutConfigAddOptions(prefix+".server-timeouts", f)
+}
+
+func NewServer(ctx context.Context, config *ServerConfig, reader daprovider.Reader, writer daprovider.Writer) (*http.Server, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Addr, config.Port))
+	if err != nil {
+		return nil, err
+	}
+	return NewServerOnListener(ctx, listener, config, reader, writer)
+}
+
+func NewServerOnListener(ctx context.Context, listener net.Listener, config *ServerConfig, reader daprovider.Reader, writer daprovider.Writer) (*http.Server, error) {
+	if reader == nil {
+		return nil, errors.New("no reader backend was configured for daprovider server")
+	}
+	if config.EnableDAWriter && writer == nil {
+		return nil, errors.New("writer is enabled for daprovider server but no writer backend was configured")
+	}
+	if !config.EnableDAWriter {
+		writer = nil
+	}
+
+	rpcServer := rpc.NewServer()
+	if config.RPCServerBodyLimit > 0 {
+		rpcServer.SetHTTPBodyLimit(config.RPCServerBodyLimit)
+	}
+	if err := rpcServer.RegisterName("daprovider", &Server{
+		reader: reader,
+		writer: writer,
+	}); err != nil {
+		return nil, err
+	}
+
+	var handler http.Handler = rpcServer
+	if config.JWTSecret != "" {
+		jwt, err := signature.LoadSigningKey(config.JWTSecret)
+		if err != nil {
+			return nil, fmt.Errorf("error loading jwtsecret for daprovider server: %w", err)
+		}
+		handler = node.NewHTTPHandlerStack(rpcServer, nil, nil, jwt[:])
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       config.ServerTimeouts.ReadTimeout,
+		ReadHeaderTimeout: config.ServerTimeouts.ReadHeaderTimeout,
+		WriteTimeout:      config.ServerTimeouts.WriteTimeout,
+		IdleTimeout:       config.ServerTimeouts.IdleTimeout,
+	}
+
+	go func() {
+		err := srv.Serve(listener)
+		if err != nil {
+			return
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	return srv, nil
+}
+
+func (s *Server) IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error) {
+	return s.reader.IsValidHeaderByte(ctx, headerByte)
+}
+
+func (s *Server) RecoverPayloadFromBatch(
+	ctx context.Context,
+	batchNum hexutil.Uint64,
+	batchBlockHash common.Hash,
+	sequencerMsg hexutil.Bytes,
+	recordPreimages bool,
+	validateSeqMsg bool,
+) (*daclient.RecoverPayloadFromBatchResult, error) {
+	var preimages map[arbutil.PreimageType]map[common.Hash][]byte
+	if recordPreimages {
+		preimages = make(map[arbutil.PreimageType]map[common.Hash][]byte)
+	}
+	payload, err := s.reader.RecoverPayloadFromBatch(ctx, uint64(batchNum), batchBlockHash, sequencerMsg, daprovider.RecordPreimagesTo(preimages), validateSeqMsg)
+	if err != nil {
+		return nil, err
+	}
+	result := &daclient.RecoverPayloadFromBatchResult{
+		Payload: payload,
+	}
+	if len(preimages) > 0 {
+		result.Preimages = make(map[arbutil.PreimageType]map[common.Hash]hexutil.Bytes, len(preimages))
+		for ty, typedPreimages := range preimages {
+			result.Preimages[ty] = make(map[common.Hash]hexutil.Bytes, len(typedPreimages))
+			for hash, preimage := range typedPreimages {
+				result.Preimages[ty][hash] = preimage
+			}
+		}
+	}
+	return result, nil
+}
+
+// HealthCheck returns the health of the writer. Writers that can't be health checked are
+// assumed healthy.
+func (s *Server) HealthCheck(ctx context.Context) error {
+	if s.writer == nil {
+		return errors.New("daprovider server was not configured with a writer")
+	}
+	if checker, ok := s.writer.(daprovider.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+func (s *Server) Store(
+	ctx context.Context,
+	message hexutil.Bytes,
+	timeout hexutil.Uint64,
+	disableFallbackStoreDataOnChain bool,
+) (*daclient.StoreResult, error) {
+	if s.writer == nil {
+		return nil, errors.New("daprovider server was not configured with a writer")
+	}
+	serializedDACert, err := s.writer.Store(ctx, message, uint64(timeout), disableFallbackStoreDataOnChain)
+	if err != nil {
+		return nil, err
+	}
+	return &daclient.StoreResult{SerializedDACert: serializedDACert}, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbstate/daprovider/daclient"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+const testHeaderByte byte = 0x01
+
+type memoryProvider struct {
+	data map[common.Hash][]byte
+}
+
+func (p *memoryProvider) IsValidHeaderByte(ctx context.Context, headerByte byte) (bool, error) {
+	return headerByte == testHeaderByte, nil
+}
+
+func (p *memoryProvider) RecoverPayloadFromBatch(
+	ctx context.Context,
+	batchNum uint64,
+	batchBlockHash common.Hash,
+	sequencerMsg []byte,
+	preimageRecorder daprovider.PreimageRecorder,
+	validateSeqMsg bool,
+) ([]byte, error) {
+	hash := common.BytesToHash(sequencerMsg[1:])
+	payload, ok := p.data[hash]
+	if !ok {
+		return nil, errors.New("unknown payload")
+	}
+	if preimageRecorder != nil {
+		preimageRecorder(hash, payload, arbutil.Keccak256PreimageType)
+	}
+	return payload, nil
+}
+
+func (p *memoryProvider) Store(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, error) {
+	hash := crypto.Keccak256Hash(message)
+	p.data[hash] = message
+	return append([]byte{testHeaderByte}, hash[:]...), nil
+}
+
+func TestProviderServerRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	testhelpers.RequireImpl(t, err)
+	provider := &memoryProvider{data: make(map[common.Hash][]byte)}
+	config := DefaultServerConfig
+	config.EnableDAWriter = true
+	_, err = NewServerOnListener(ctx, listener, &config, provider, provider)
+	testhelpers.RequireImpl(t, err)
+
+	clientConfig := daclient.DefaultClientConfig.RPC
+	clientConfig.URL = "http://" + listener.Addr().String()
+	testhelpers.RequireImpl(t, clientConfig.Validate())
+	client, err := daclient.NewClient(ctx, func() *rpcclient.ClientConfig { return &clientConfig })
+	testhelpers.RequireImpl(t, err)
+
+	isValid, err := client.IsValidHeaderByte(ctx, testHeaderByte)
+	testhelpers.RequireImpl(t, err)
+	if !isValid {
+		testhelpers.FailImpl(t, "expected header byte to be valid")
+	}
+	isValid, err = client.IsValidHeaderByte(ctx, daprovider.DASMessageHeaderFlag)
+	testhelpers.RequireImpl(t, err)
+	if isValid {
+		testhelpers.FailImpl(t, "expected DAS header byte to be invalid")
+	}
+
+	testhelpers.RequireImpl(t, client.HealthCheck(ctx))
+
+	message := []byte("external da provider payload")
+	sequencerMsg, err := client.Store(ctx, message, 0, true)
+	testhelpers.RequireImpl(t, err)
+
+	preimages := make(map[arbutil.PreimageType]map[common.Hash][]byte)
+	payload, err := client.RecoverPayloadFromBatch(ctx, 1, common.Hash{}, sequencerMsg, daprovider.RecordPreimagesTo(preimages), true)
+	testhelpers.RequireImpl(t, err)
+	if !bytes.Equal(payload, message) {
+		testhelpers.FailImpl(t, "recovered payload", payload, "doesn't match stored message", message)
+	}
+	if !bytes.Equal(preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash(message)], message) {
+		testhelpers.FailImpl(t, "preimage wasn't recorded through the client")
+	}
+}
+
+func TestProviderServerWithoutWriter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	testhelpers.RequireImpl(t, err)
+	provider := &memoryProvider{data: make(map[common.Hash][]byte)}
+	config := DefaultServerConfig
+	_, err = NewServerOnListener(ctx, listener, &config, provider, provider)
+	testhelpers.RequireImpl(t, err)
+
+	clientConfig := daclient.DefaultClientConfig.RPC
+	clientConfig.URL = "http://" + listener.Addr().String()
+	clientConfig.Retries = 0
+	client, err := daclient.NewClient(ctx, func() *rpcclient.ClientConfig { return &clientConfig })
+	testhelpers.RequireImpl(t, err)
+
+	if _, err := client.Store(ctx, []byte("data"), 0, true); err == nil {
+		testhelpers.FailImpl(t, "expected store to fail when writer is disabled")
+	}
+}
@@ -88,6 +88,19 @@ const BlobHashesHeaderFlag byte = L1AuthenticatedMessageHeaderFlag | 0x10 // 0x5
 // BrotliMessageHeaderByte indicates that the message is brotli-compressed.
 const BrotliMessageHeaderByte byte = 0
 
+// ZstdMessageHeaderByte indicates that the message is zstd-compressed.
+const ZstdMessageHeaderByte byte = 0x01
+
+// BrotliWithDictionaryMessageHeaderByte indicates that the message is brotli-compressed with a trained
+// dictionary published by an earlier batch. The header byte is followed by the keccak256 hash of the
+// dictionary, then the compressed data.
+const BrotliWithDictionaryMessageHeaderByte byte = 0x02
+
+// BrotliWithInlineDictionaryMessageHeaderByte indicates that the message is brotli-compressed with a
+// trained dictionary it publishes. The header byte is followed by the 4 byte big-endian length of the
+// dictionary, the dictionary, then the compressed data.
+const BrotliWithInlineDictionaryMessageHeaderByte byte = 0x03
+
 // KnownHeaderBits is all header bits with known meaning to this nitro version
 const KnownHeaderBits byte = DASMessageHeaderFlag | TreeDASMessageHeaderFlag | L1AuthenticatedMessageHeaderFlag | ZeroheavyMessageHeaderFlag | BlobHashesHeaderFlag | BrotliMessageHeaderByte
 
@@ -120,6 +133,18 @@ func IsBrotliMessageHeaderByte(b uint8) bool {
 	return b == BrotliMessageHeaderByte
 }
 
+func IsZstdMessageHeaderByte(b uint8) bool {
+	return b == ZstdMessageHeaderByte
+}
+
+func IsBrotliWithDictionaryMessageHeaderByte(b uint8) bool {
+	return b == BrotliWithDictionaryMessageHeaderByte
+}
+
+func IsBrotliWithInlineDictionaryMessageHeaderByte(b uint8) bool {
+	return b == BrotliWithInlineDictionaryMessageHeaderByte
+}
+
 // IsKnownHeaderByte returns true if the supplied header byte has only known bits
 func IsKnownHeaderByte(b uint8) bool {
 	return b&^KnownHeaderBits == 0
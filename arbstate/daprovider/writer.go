@@ -21,6 +21,12 @@ type Writer interface {
 	) ([]byte, error)
 }
 
+// HealthChecker is implemented by writers that can tell whether they're currently able to store
+// batches, so that batch posters can fall back to other DA backends before a store fails.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // DAProviderWriterForDAS is generally meant to be only used by nitro.
 // DA Providers should implement methods in the DAProviderWriter interface independently
 func NewWriterForDAS(dasWriter DASWriter) *writerForDAS {
@@ -31,6 +37,14 @@ type writerForDAS struct {
 	dasWriter DASWriter
 }
 
+// HealthCheck returns the health of the DAS writer, if it can be checked.
+func (d *writerForDAS) HealthCheck(ctx context.Context) error {
+	if checker, ok := d.dasWriter.(HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
 func (d *writerForDAS) Store(ctx context.Context, message []byte, timeout uint64, disableFallbackStoreDataOnChain bool) ([]byte, error) {
 	cert, err := d.dasWriter.Store(ctx, message, timeout)
 	if errors.Is(err, ErrBatchToDasFailed) {
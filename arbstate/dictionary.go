@@ -0,0 +1,102 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbstate
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/zeroheavy"
+)
+
+// DictionaryReader provides the compression dictionaries referenced by batches,
+// keyed by the keccak256 hash of their contents.
+type DictionaryReader interface {
+	GetDictionary(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// DictionaryWriter is implemented by DictionaryReaders which keep the dictionaries published by
+// batches, so that later batches can reference them by hash.
+type DictionaryWriter interface {
+	PublishDictionary(ctx context.Context, dictionary []byte) error
+}
+
+var ErrNoDictionaryReader = errors.New("batch compressed with a dictionary but no dictionary reader configured")
+var ErrUnknownDictionary = errors.New("unknown compression dictionary")
+
+type staticDictionaryReader struct {
+	dictionaries map[common.Hash][]byte
+}
+
+func NewStaticDictionaryReader(dictionaries ...[]byte) DictionaryReader {
+	reader := &staticDictionaryReader{dictionaries: make(map[common.Hash][]byte)}
+	for _, dictionary := range dictionaries {
+		reader.dictionaries[crypto.Keccak256Hash(dictionary)] = dictionary
+	}
+	return reader
+}
+
+func (r *staticDictionaryReader) GetDictionary(ctx context.Context, hash common.Hash) ([]byte, error) {
+	dictionary, ok := r.dictionaries[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownDictionary, hash)
+	}
+	return dictionary, nil
+}
+
+// inlineDictionaryLengthSize is the size of the dictionary length following the inline dictionary header byte.
+const inlineDictionaryLengthSize = 4
+
+// EncodeInlineDictionary returns the header of a batch publishing the dictionary it's compressed with.
+func EncodeInlineDictionary(dictionary []byte) []byte {
+	header := make([]byte, 1+inlineDictionaryLengthSize, 1+inlineDictionaryLengthSize+len(dictionary))
+	header[0] = daprovider.BrotliWithInlineDictionaryMessageHeaderByte
+	// #nosec G115
+	binary.BigEndian.PutUint32(header[1:], uint32(len(dictionary)))
+	return append(header, dictionary...)
+}
+
+// splitInlineDictionary splits a payload with the inline dictionary header byte into the dictionary
+// it publishes and the compressed data.
+func splitInlineDictionary(payload []byte) ([]byte, []byte, error) {
+	if len(payload) < 1+inlineDictionaryLengthSize {
+		return nil, nil, errors.New("sequencer message missing inline compression dictionary length")
+	}
+	length := uint64(binary.BigEndian.Uint32(payload[1:]))
+	payload = payload[1+inlineDictionaryLengthSize:]
+	if length > arbcompress.MaxDictionarySize {
+		return nil, nil, fmt.Errorf("inline compression dictionary of %v bytes is over the maximum of %v", length, arbcompress.MaxDictionarySize)
+	}
+	if uint64(len(payload)) < length {
+		return nil, nil, errors.New("sequencer message inline compression dictionary truncated")
+	}
+	return payload[:length], payload[length:], nil
+}
+
+// DictionaryHashFromPayload returns the hash of the compression dictionary referenced by a batch
+// payload that was already extracted from any data availability header, if it references one.
+// Dictionaries published inline aren't reported, as the payload already contains them.
+func DictionaryHashFromPayload(payload []byte) (common.Hash, bool) {
+	if len(payload) > 0 && daprovider.IsZeroheavyEncodedHeaderByte(payload[0]) {
+		// only the compression header and dictionary hash need to be decoded
+		prefix, err := io.ReadAll(io.LimitReader(zeroheavy.NewZeroheavyDecoder(bytes.NewReader(payload[1:])), 1+common.HashLength))
+		if err != nil {
+			return common.Hash{}, false
+		}
+		payload = prefix
+	}
+	if len(payload) < 1+common.HashLength || !daprovider.IsBrotliWithDictionaryMessageHeaderByte(payload[0]) {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(payload[1 : 1+common.HashLength]), true
+}
@@ -0,0 +1,157 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbstate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/zeroheavy"
+)
+
+// compressionBackend only implements what parseSequencerMessage uses
+type compressionBackend struct {
+	InboxBackend
+	enabled bool
+}
+
+func (b *compressionBackend) BatchCompressionEnabled() (bool, error) {
+	return b.enabled, nil
+}
+
+type publishedDictionaries struct {
+	staticDictionaryReader
+}
+
+func (r *publishedDictionaries) PublishDictionary(ctx context.Context, dictionary []byte) error {
+	r.dictionaries[crypto.Keccak256Hash(dictionary)] = dictionary
+	return nil
+}
+
+func compressedTestBatch(t *testing.T, segments [][]byte, header byte, dictionary []byte) []byte {
+	t.Helper()
+	var uncompressed []byte
+	for _, segment := range segments {
+		encoded, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uncompressed = append(uncompressed, encoded...)
+	}
+	batch := make([]byte, 40)
+	var compressed []byte
+	var err error
+	switch header {
+	case daprovider.ZstdMessageHeaderByte:
+		batch = append(batch, header)
+		compressed, err = arbcompress.CompressZstd(uncompressed, 3)
+	case daprovider.BrotliWithDictionaryMessageHeaderByte:
+		batch = append(batch, header)
+		batch = append(batch, crypto.Keccak256(dictionary)...)
+		compressed, err = arbcompress.CompressWithCustomDictionary(uncompressed, arbcompress.LEVEL_WELL, dictionary)
+	case daprovider.BrotliWithInlineDictionaryMessageHeaderByte:
+		batch = append(batch, EncodeInlineDictionary(dictionary)...)
+		compressed, err = arbcompress.CompressWithCustomDictionary(uncompressed, arbcompress.LEVEL_WELL, dictionary)
+	default:
+		t.Fatal("unexpected header byte", header)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(batch, compressed...)
+}
+
+func TestParseCompressedSequencerMessage(t *testing.T) {
+	ctx := context.Background()
+	enabled := &compressionBackend{enabled: true}
+	segments := [][]byte{
+		append([]byte{BatchSegmentKindL2Message}, []byte("first message")...),
+		append([]byte{BatchSegmentKindL2Message}, []byte("second message")...),
+	}
+	dictionary := []byte("first message second message third message")
+	checkSegments := func(parsed *sequencerMessage, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parsed.segments) != len(segments) || !bytes.Equal(parsed.segments[1], segments[1]) {
+			t.Fatal("unexpected segments", parsed.segments)
+		}
+	}
+
+	zstdBatch := compressedTestBatch(t, segments, daprovider.ZstdMessageHeaderByte, nil)
+	checkSegments(parseSequencerMessage(ctx, 0, common.Hash{}, zstdBatch, enabled, nil, nil, daprovider.KeysetValidate))
+
+	// Before the ArbOS upgrade the new formats are unknown, and so parse as empty batches
+	parsed, err := parseSequencerMessage(ctx, 0, common.Hash{}, zstdBatch, &compressionBackend{}, nil, nil, daprovider.KeysetValidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.segments) != 0 {
+		t.Fatal("batch compression isn't enabled but got segments", parsed.segments)
+	}
+
+	batch := compressedTestBatch(t, segments, daprovider.BrotliWithDictionaryMessageHeaderByte, dictionary)
+	checkSegments(parseSequencerMessage(ctx, 0, common.Hash{}, batch, enabled, nil, NewStaticDictionaryReader(dictionary), daprovider.KeysetValidate))
+
+	// Without the dictionary the batch contents are unknown, which must not be mistaken for an empty batch
+	_, err = parseSequencerMessage(ctx, 0, common.Hash{}, batch, enabled, nil, nil, daprovider.KeysetValidate)
+	if !errors.Is(err, ErrNoDictionaryReader) {
+		t.Fatal("expected ErrNoDictionaryReader, got", err)
+	}
+	published := &publishedDictionaries{staticDictionaryReader{dictionaries: make(map[common.Hash][]byte)}}
+	_, err = parseSequencerMessage(ctx, 0, common.Hash{}, batch, enabled, nil, published, daprovider.KeysetValidate)
+	if !errors.Is(err, ErrUnknownDictionary) {
+		t.Fatal("expected ErrUnknownDictionary, got", err)
+	}
+
+	// A batch publishing the dictionary makes it available to later batches
+	inlineBatch := compressedTestBatch(t, segments, daprovider.BrotliWithInlineDictionaryMessageHeaderByte, dictionary)
+	checkSegments(parseSequencerMessage(ctx, 0, common.Hash{}, inlineBatch, enabled, nil, published, daprovider.KeysetValidate))
+	checkSegments(parseSequencerMessage(ctx, 1, common.Hash{}, batch, enabled, nil, published, daprovider.KeysetValidate))
+
+	truncated := inlineBatch[:40+1+inlineDictionaryLengthSize+len(dictionary)/2]
+	parsed, err = parseSequencerMessage(ctx, 0, common.Hash{}, truncated, enabled, nil, published, daprovider.KeysetValidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.segments) != 0 {
+		t.Fatal("truncated inline dictionary batch has segments", parsed.segments)
+	}
+}
+
+func TestDictionaryHashFromPayload(t *testing.T) {
+	dictionary := []byte("dictionary")
+	payload := append([]byte{daprovider.BrotliWithDictionaryMessageHeaderByte}, crypto.Keccak256(dictionary)...)
+	payload = append(payload, 1, 2, 3)
+	hash, ok := DictionaryHashFromPayload(payload)
+	if !ok || hash != crypto.Keccak256Hash(dictionary) {
+		t.Fatal("failed to extract dictionary hash", hash, ok)
+	}
+
+	encoded, err := io.ReadAll(zeroheavy.NewZeroheavyEncoder(bytes.NewReader(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, ok = DictionaryHashFromPayload(append([]byte{daprovider.ZeroheavyMessageHeaderFlag}, encoded...))
+	if !ok || hash != crypto.Keccak256Hash(dictionary) {
+		t.Fatal("failed to extract dictionary hash from zeroheavy payload", hash, ok)
+	}
+
+	if _, ok := DictionaryHashFromPayload([]byte{daprovider.ZstdMessageHeaderByte, 1, 2, 3}); ok {
+		t.Fatal("zstd payload reported a dictionary hash")
+	}
+	if _, ok := DictionaryHashFromPayload(EncodeInlineDictionary(dictionary)); ok {
+		t.Fatal("payload publishing its dictionary reported a dictionary hash")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 
@@ -34,6 +35,10 @@ type InboxBackend interface {
 	SetPositionWithinMessage(pos uint64)
 
 	ReadDelayedInbox(seqNum uint64) (*arbostypes.L1IncomingMessage, error)
+
+	// BatchCompressionEnabled returns whether ArbOS was at arbosState.ArbosVersion_BatchCompression
+	// or later before the first message of the current sequencer batch.
+	BatchCompressionEnabled() (bool, error)
 }
 
 type sequencerMessage struct {
@@ -49,7 +54,9 @@ const MaxDecompressedLen int = 1024 * 1024 * 16 // 16 MiB
 const maxZeroheavyDecompressedLen = 101*MaxDecompressedLen/100 + 64
 const MaxSegmentsPerSequencerMessage = 100 * 1024
 
-func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders []daprovider.Reader, keysetValidationMode daprovider.KeysetValidationMode) (*sequencerMessage, error) {
+var errPublishingDictionary = errors.New("failed to publish compression dictionary")
+
+func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, backend InboxBackend, dapReaders []daprovider.Reader, dictReader DictionaryReader, keysetValidationMode daprovider.KeysetValidationMode) (*sequencerMessage, error) {
 	if len(data) < 40 {
 		return nil, errors.New("sequencer message missing L1 header")
 	}
@@ -77,9 +84,15 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 	// We try to extract payload from the first occuring valid DA reader in the dapReaders list
 	if len(payload) > 0 {
 		foundDA := false
-		var err error
 		for _, dapReader := range dapReaders {
-			if dapReader != nil && dapReader.IsValidHeaderByte(payload[0]) {
+			if dapReader == nil {
+				continue
+			}
+			isValid, err := dapReader.IsValidHeaderByte(ctx, payload[0])
+			if err != nil {
+				return nil, err
+			}
+			if isValid {
 				payload, err = dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, data, nil, keysetValidationMode != daprovider.KeysetDontValidate)
 				if err != nil {
 					// Matches the way keyset validation was done inside DAS readers i.e logging the error
@@ -124,10 +137,26 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 		payload = pl
 	}
 
-	// Stage 3: Decompress the brotli payload and fill the parsedMsg.segments list.
-	if len(payload) > 0 && daprovider.IsBrotliMessageHeaderByte(payload[0]) {
-		decompressed, err := arbcompress.Decompress(payload[1:], MaxDecompressedLen)
-		if err == nil {
+	// Stage 3: Decompress the payload and fill the parsedMsg.segments list.
+	compressed := len(payload) > 0 && isCompressedMessageHeaderByte(payload[0])
+	if compressed && !daprovider.IsBrotliMessageHeaderByte(payload[0]) {
+		// Before ArbosVersion_BatchCompression, only brotli without a dictionary is a known format
+		enabled, err := backend.BatchCompressionEnabled()
+		if err != nil {
+			return nil, err
+		}
+		compressed = enabled
+	}
+	if compressed {
+		decompressed, err := decompressPayload(ctx, payload, dictReader)
+		if err != nil {
+			// A missing dictionary means this node can't tell what the batch contains,
+			// so unlike a decompression failure it can't be treated as an empty batch.
+			if errors.Is(err, ErrNoDictionaryReader) || errors.Is(err, ErrUnknownDictionary) || errors.Is(err, errPublishingDictionary) {
+				return nil, err
+			}
+			log.Warn("sequencer msg decompression failed", "err", err)
+		} else {
 			reader := bytes.NewReader(decompressed)
 			stream := rlp.NewStream(reader, uint64(MaxDecompressedLen))
 			for {
@@ -145,8 +174,6 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 				}
 				parsedMsg.segments = append(parsedMsg.segments, segment)
 			}
-		} else {
-			log.Warn("sequencer msg decompression failed", "err", err)
 		}
 	} else {
 		length := len(payload)
@@ -161,10 +188,58 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 	return parsedMsg, nil
 }
 
+func isCompressedMessageHeaderByte(header byte) bool {
+	return daprovider.IsBrotliMessageHeaderByte(header) ||
+		daprovider.IsZstdMessageHeaderByte(header) ||
+		daprovider.IsBrotliWithDictionaryMessageHeaderByte(header) ||
+		daprovider.IsBrotliWithInlineDictionaryMessageHeaderByte(header)
+}
+
+// decompressPayload decompresses a payload starting with one of the compression header bytes.
+func decompressPayload(ctx context.Context, payload []byte, dictReader DictionaryReader) ([]byte, error) {
+	switch {
+	case daprovider.IsBrotliMessageHeaderByte(payload[0]):
+		return arbcompress.Decompress(payload[1:], MaxDecompressedLen)
+	case daprovider.IsZstdMessageHeaderByte(payload[0]):
+		return arbcompress.DecompressZstd(payload[1:], MaxDecompressedLen)
+	case daprovider.IsBrotliWithDictionaryMessageHeaderByte(payload[0]):
+		if len(payload) < 1+common.HashLength {
+			return nil, errors.New("sequencer message missing compression dictionary hash")
+		}
+		if dictReader == nil {
+			return nil, ErrNoDictionaryReader
+		}
+		hash := common.BytesToHash(payload[1 : 1+common.HashLength])
+		dictionary, err := dictReader.GetDictionary(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if crypto.Keccak256Hash(dictionary) != hash {
+			return nil, fmt.Errorf("%w: dictionary reader returned data not matching hash %v", ErrUnknownDictionary, hash)
+		}
+		return arbcompress.DecompressWithCustomDictionary(payload[1+common.HashLength:], MaxDecompressedLen, dictionary)
+	case daprovider.IsBrotliWithInlineDictionaryMessageHeaderByte(payload[0]):
+		dictionary, compressed, err := splitInlineDictionary(payload)
+		if err != nil {
+			return nil, err
+		}
+		// the dictionary is published whether or not the data decompresses
+		if writer, ok := dictReader.(DictionaryWriter); ok {
+			if err := writer.PublishDictionary(ctx, dictionary); err != nil {
+				return nil, fmt.Errorf("%w: %w", errPublishingDictionary, err)
+			}
+		}
+		return arbcompress.DecompressWithCustomDictionary(compressed, MaxDecompressedLen, dictionary)
+	default:
+		return nil, fmt.Errorf("unknown compression header byte 0x%02x", payload[0])
+	}
+}
+
 type inboxMultiplexer struct {
 	backend                   InboxBackend
 	delayedMessagesRead       uint64
 	dapReaders                []daprovider.Reader
+	dictReader                DictionaryReader
 	cachedSequencerMessage    *sequencerMessage
 	cachedSequencerMessageNum uint64
 	cachedSegmentNum          uint64
@@ -174,11 +249,12 @@ type inboxMultiplexer struct {
 	keysetValidationMode      daprovider.KeysetValidationMode
 }
 
-func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders []daprovider.Reader, keysetValidationMode daprovider.KeysetValidationMode) arbostypes.InboxMultiplexer {
+func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders []daprovider.Reader, dictReader DictionaryReader, keysetValidationMode daprovider.KeysetValidationMode) arbostypes.InboxMultiplexer {
 	return &inboxMultiplexer{
 		backend:              backend,
 		delayedMessagesRead:  delayedMessagesRead,
 		dapReaders:           dapReaders,
+		dictReader:           dictReader,
 		keysetValidationMode: keysetValidationMode,
 	}
 }
@@ -200,7 +276,7 @@ func (r *inboxMultiplexer) Pop(ctx context.Context) (*arbostypes.MessageWithMeta
 		}
 		r.cachedSequencerMessageNum = r.backend.GetSequencerInboxPosition()
 		var err error
-		r.cachedSequencerMessage, err = parseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.dapReaders, r.keysetValidationMode)
+		r.cachedSequencerMessage, err = parseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.backend, r.dapReaders, r.dictReader, r.keysetValidationMode)
 		if err != nil {
 			return nil, err
 		}
@@ -58,6 +58,10 @@ func (b *multiplexerBackend) ReadDelayedInbox(seqNum uint64) (*arbostypes.L1Inco
 	return msg, nil
 }
 
+func (b *multiplexerBackend) BatchCompressionEnabled() (bool, error) {
+	return true, nil
+}
+
 func FuzzInboxMultiplexer(f *testing.F) {
 	f.Fuzz(func(t *testing.T, seqMsg []byte, delayedMsg []byte) {
 		if len(seqMsg) < 40 {
@@ -69,7 +73,7 @@ func FuzzInboxMultiplexer(f *testing.F) {
 			delayedMessage:        delayedMsg,
 			positionWithinMessage: 0,
 		}
-		multiplexer := NewInboxMultiplexer(backend, 0, nil, daprovider.KeysetValidate)
+		multiplexer := NewInboxMultiplexer(backend, 0, nil, nil, daprovider.KeysetValidate)
 		_, err := multiplexer.Pop(context.TODO())
 		if err != nil {
 			panic(err)
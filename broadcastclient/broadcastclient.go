@@ -72,6 +72,7 @@ type Config struct {
 	SecondaryURL            []string                 `koanf:"secondary-url"`
 	Verify                  signature.VerifierConfig `koanf:"verify"`
 	EnableCompression       bool                     `koanf:"enable-compression" reload:"hot"`
+	AuthToken               string                   `koanf:"auth-token" reload:"hot"`
 }
 
 func (c *Config) Enable() bool {
@@ -90,6 +91,7 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.StringSlice(prefix+".secondary-url", DefaultConfig.SecondaryURL, "list of secondary URLs of sequencer feed source. Would be started in the order they appear in the list when primary feeds fails")
 	signature.FeedVerifierConfigAddOptions(prefix+".verify", f)
 	f.Bool(prefix+".enable-compression", DefaultConfig.EnableCompression, "enable per message deflate compression support")
+	f.String(prefix+".auth-token", DefaultConfig.AuthToken, "API key or JWT sent as a bearer token to authenticated sequencer feeds")
 }
 
 var DefaultConfig = Config{
@@ -102,6 +104,7 @@ var DefaultConfig = Config{
 	SecondaryURL:            []string{},
 	Timeout:                 20 * time.Second,
 	EnableCompression:       true,
+	AuthToken:               "",
 }
 
 var DefaultTestConfig = Config{
@@ -114,6 +117,7 @@ var DefaultTestConfig = Config{
 	SecondaryURL:            []string{},
 	Timeout:                 200 * time.Millisecond,
 	EnableCompression:       true,
+	AuthToken:               "",
 }
 
 type TransactionStreamerInterface interface {
@@ -220,10 +224,14 @@ func (bc *BroadcastClient) connect(ctx context.Context, nextSeqNum arbutil.Messa
 		return nil, nil
 	}
 
-	header := ws.HandshakeHeaderHTTP(http.Header{
+	httpHeader := http.Header{
 		wsbroadcastserver.HTTPHeaderFeedClientVersion:       []string{strconv.Itoa(wsbroadcastserver.FeedClientVersion)},
 		wsbroadcastserver.HTTPHeaderRequestedSequenceNumber: []string{strconv.FormatUint(uint64(nextSeqNum), 10)},
-	})
+	}
+	if token := bc.config().AuthToken; token != "" {
+		httpHeader[wsbroadcastserver.HTTPHeaderAuthorization] = []string{"Bearer " + token}
+	}
+	header := ws.HandshakeHeaderHTTP(httpHeader)
 
 	log.Info("connecting to arbitrum inbox message broadcaster", "url", bc.websocketUrl)
 	var foundChainId bool
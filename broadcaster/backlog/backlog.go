@@ -31,6 +31,9 @@ type Backlog interface {
 	Get(uint64, uint64) (*m.BroadcastMessage, error)
 	Count() uint64
 	Lookup(uint64) (BacklogSegment, error)
+	Initialize() error
+	InitializeTruncated(messageCount uint64) error
+	Close() error
 }
 
 // backlog stores backlogSegments and provides the ability to read/write
@@ -41,6 +44,8 @@ type backlog struct {
 	lookupByIndex atomic.Pointer[containers.SyncMap[uint64, *backlogSegment]]
 	config        ConfigFetcher
 	messageCount  atomic.Uint64
+	// store is only set if persistence is enabled, and is only used by the appending goroutine
+	store *diskStore
 }
 
 // NewBacklog creates a backlog.
@@ -52,6 +57,68 @@ func NewBacklog(c ConfigFetcher) Backlog {
 	return b
 }
 
+// Initialize reloads the messages persisted by a previous run if persistence is enabled.
+// It must be called before any messages are appended.
+func (b *backlog) Initialize() error {
+	return b.initialize(nil)
+}
+
+// InitializeTruncated is like Initialize, but first deletes the persisted messages from messageCount on,
+// as the caller will append its own version of them.
+func (b *backlog) InitializeTruncated(messageCount uint64) error {
+	return b.initialize(&messageCount)
+}
+
+func (b *backlog) initialize(truncateAt *uint64) error {
+	if !b.config().Persistence.Enable {
+		return nil
+	}
+	store, err := openDiskStore(func() *PersistenceConfig { return &b.config().Persistence })
+	if err != nil {
+		return fmt.Errorf("error opening backlog persistence: %w", err)
+	}
+	if truncateAt != nil {
+		if err := store.deleteFrom(*truncateAt); err != nil {
+			_ = store.close()
+			return fmt.Errorf("error truncating persisted backlog: %w", err)
+		}
+	}
+	messages, err := store.load()
+	if err != nil {
+		_ = store.close()
+		return fmt.Errorf("error loading persisted backlog: %w", err)
+	}
+	if len(messages) > 0 {
+		if err := b.Append(&m.BroadcastMessage{Version: m.V1, Messages: messages}); err != nil {
+			_ = store.close()
+			return err
+		}
+		log.Info("loaded persisted feed backlog", "messages", len(messages), "first", messages[0].SequenceNumber, "last", messages[len(messages)-1].SequenceNumber)
+	}
+	b.store = store
+	return nil
+}
+
+// Close closes the persisted backlog if persistence is enabled.
+func (b *backlog) Close() error {
+	if b.store == nil {
+		return nil
+	}
+	return b.store.close()
+}
+
+// persist runs a write through to the persisted backlog. Failing to persist messages
+// doesn't stop them from being broadcast, as the in-memory backlog is still intact.
+func (b *backlog) persist(action string, write func(*diskStore) error) {
+	if b.store == nil {
+		return
+	}
+	if err := write(b.store); err != nil {
+		persistenceErrorCounter.Inc(1)
+		log.Error("error writing through to persisted backlog", "action", action, "err", err)
+	}
+}
+
 // Head return the head backlogSegment within the backlog.
 func (b *backlog) Head() BacklogSegment {
 	return b.head.Load()
@@ -93,7 +160,9 @@ func (b *backlog) backlogSizeInBytes() (uint64, error) {
 func (b *backlog) Append(bm *m.BroadcastMessage) error {
 
 	if bm.ConfirmedSequenceNumberMessage != nil {
-		b.delete(uint64(bm.ConfirmedSequenceNumberMessage.SequenceNumber))
+		confirmed := uint64(bm.ConfirmedSequenceNumberMessage.SequenceNumber)
+		b.delete(confirmed)
+		b.persist("confirm", func(s *diskStore) error { return s.deleteUpTo(confirmed) })
 		size, err := b.backlogSizeInBytes()
 		if err != nil {
 			log.Warn("error calculating backlogSizeInBytes", "err", err)
@@ -104,6 +173,7 @@ func (b *backlog) Append(bm *m.BroadcastMessage) error {
 	}
 
 	lookupByIndex := b.lookupByIndex.Load()
+	var appended []*m.BroadcastFeedMessage
 	for _, msg := range bm.Messages {
 		segment := b.tail.Load()
 		if segment == nil {
@@ -138,6 +208,8 @@ func (b *backlog) Append(bm *m.BroadcastMessage) error {
 			b.messageCount.Store(0)
 			backlogSizeInBytesGauge.Update(0)
 			log.Warn(err.Error())
+			appended = nil
+			b.persist("drop", (*diskStore).reset)
 		} else if errors.Is(err, errSequenceNumberSeen) {
 			log.Info("ignoring message sequence number, already in backlog", "message sequence number", msg.SequenceNumber)
 			continue
@@ -145,11 +217,14 @@ func (b *backlog) Append(bm *m.BroadcastMessage) error {
 			return err
 		}
 		lookupByIndex.Store(uint64(msg.SequenceNumber), segment)
+		appended = append(appended, msg)
 		b.messageCount.Add(1)
 		// #nosec G115
 		backlogSizeInBytesGauge.Inc(int64(msg.Size()))
 	}
 
+	b.persist("append", func(s *diskStore) error { return s.write(appended) })
+
 	// #nosec G115
 	backlogSizeGauge.Update(int64(b.Count()))
 	return nil
@@ -294,6 +369,7 @@ func (b *backlog) reset() {
 	b.messageCount.Store(0)
 	backlogSizeInBytesGauge.Update(0)
 	backlogSizeGauge.Update(0)
+	b.persist("reset", (*diskStore).reset)
 }
 
 // BacklogSegment defines the interface for backlogSegment.
@@ -1,24 +1,62 @@
 package backlog
 
 import (
+	"errors"
+	"time"
+
 	flag "github.com/spf13/pflag"
 )
 
 type ConfigFetcher func() *Config
 
 type Config struct {
-	SegmentLimit int `koanf:"segment-limit" reload:"hot"`
+	SegmentLimit int               `koanf:"segment-limit" reload:"hot"`
+	Persistence  PersistenceConfig `koanf:"persistence" reload:"hot"`
+}
+
+func (c *Config) Validate() error {
+	return c.Persistence.Validate()
 }
 
 func AddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".segment-limit", DefaultConfig.SegmentLimit, "the maximum number of messages each segment within the backlog can contain")
+	PersistenceConfigAddOptions(prefix+".persistence", f)
+}
+
+type PersistenceConfig struct {
+	Enable    bool          `koanf:"enable"`
+	Directory string        `koanf:"directory"`
+	MaxSize   uint64        `koanf:"max-size" reload:"hot"`
+	MaxAge    time.Duration `koanf:"max-age" reload:"hot"`
+}
+
+func (c *PersistenceConfig) Validate() error {
+	if c.Enable && c.Directory == "" {
+		return errors.New("backlog persistence is enabled but no directory is set")
+	}
+	return nil
+}
+
+func PersistenceConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultPersistenceConfig.Enable, "write the backlog through to disk and reload it on startup, so clients can still catch up from the feed after a restart")
+	f.String(prefix+".directory", DefaultPersistenceConfig.Directory, "directory of the on-disk backlog database")
+	f.Uint64(prefix+".max-size", DefaultPersistenceConfig.MaxSize, "maximum size in bytes of the messages kept on disk, the oldest messages are removed first (0 = unlimited)")
+	f.Duration(prefix+".max-age", DefaultPersistenceConfig.MaxAge, "maximum age of the messages kept on disk (0 = unlimited)")
 }
 
 var (
+	DefaultPersistenceConfig = PersistenceConfig{
+		Enable:    false,
+		Directory: "",
+		MaxSize:   1024 * 1024 * 1024, // 1 GB
+		MaxAge:    24 * time.Hour,
+	}
 	DefaultConfig = Config{
 		SegmentLimit: 240,
+		Persistence:  DefaultPersistenceConfig,
 	}
 	DefaultTestConfig = Config{
 		SegmentLimit: 3,
+		Persistence:  DefaultPersistenceConfig,
 	}
 )
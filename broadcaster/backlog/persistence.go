@@ -0,0 +1,192 @@
+package backlog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+var (
+	persistedSizeInBytesGauge = metrics.NewRegisteredGauge("arb/feed/backlog/persisted/bytes", nil)
+	persistedSizeGauge        = metrics.NewRegisteredGauge("arb/feed/backlog/persisted/messages", nil)
+	persistenceErrorCounter   = metrics.NewRegisteredCounter("arb/feed/backlog/persisted/errors", nil)
+)
+
+var persistedMessagePrefix = []byte("m")
+
+// persistedMessage is a backlog message as stored on disk, along with when it was stored
+// so that the store can be bounded by age.
+type persistedMessage struct {
+	Message  *m.BroadcastFeedMessage `json:"message"`
+	StoredAt int64                   `json:"storedAt"`
+}
+
+// diskStore keeps a copy of the backlog messages on disk, keyed by sequence number.
+// It is only accessed by the goroutine appending to the backlog.
+type diskStore struct {
+	db     ethdb.Database
+	config func() *PersistenceConfig
+	size   uint64
+	count  uint64
+}
+
+func openDiskStore(config func() *PersistenceConfig) (*diskStore, error) {
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:      "pebble",
+		Directory: config().Directory,
+		Namespace: "feedbacklog/",
+		Cache:     16,
+		Handles:   16,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &diskStore{db: db, config: config}, nil
+}
+
+func persistedMessageKey(seqNum uint64) []byte {
+	key := make([]byte, 0, len(persistedMessagePrefix)+8)
+	key = append(key, persistedMessagePrefix...)
+	return binary.BigEndian.AppendUint64(key, seqNum)
+}
+
+func (s *diskStore) updateMetrics() {
+	// #nosec G115
+	persistedSizeInBytesGauge.Update(int64(s.size))
+	// #nosec G115
+	persistedSizeGauge.Update(int64(s.count))
+}
+
+// load returns the stored messages in sequence number order, after dropping the ones past the size and age limits.
+func (s *diskStore) load() ([]*m.BroadcastFeedMessage, error) {
+	s.size = 0
+	s.count = 0
+	iter := s.db.NewIterator(persistedMessagePrefix, nil)
+	for iter.Next() {
+		s.size += uint64(len(iter.Value()))
+		s.count++
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+
+	var messages []*m.BroadcastFeedMessage
+	iter = s.db.NewIterator(persistedMessagePrefix, nil)
+	defer iter.Release()
+	for iter.Next() {
+		var stored persistedMessage
+		if err := json.Unmarshal(iter.Value(), &stored); err != nil {
+			return nil, err
+		}
+		messages = append(messages, stored.Message)
+	}
+	s.updateMetrics()
+	return messages, iter.Error()
+}
+
+func (s *diskStore) write(messages []*m.BroadcastFeedMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	batch := s.db.NewBatch()
+	now := time.Now().Unix()
+	for _, msg := range messages {
+		value, err := json.Marshal(&persistedMessage{Message: msg, StoredAt: now})
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(persistedMessageKey(uint64(msg.SequenceNumber)), value); err != nil {
+			return err
+		}
+		s.size += uint64(len(value))
+		s.count++
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// deleteWhile removes messages from the start of the store as long as shouldDelete returns true.
+func (s *diskStore) deleteWhile(shouldDelete func(seqNum uint64, stored *persistedMessage) bool) error {
+	batch := s.db.NewBatch()
+	iter := s.db.NewIterator(persistedMessagePrefix, nil)
+	defer iter.Release()
+	for iter.Next() {
+		var stored persistedMessage
+		if err := json.Unmarshal(iter.Value(), &stored); err != nil {
+			return err
+		}
+		seqNum := binary.BigEndian.Uint64(iter.Key()[len(persistedMessagePrefix):])
+		if !shouldDelete(seqNum, &stored) {
+			break
+		}
+		if err := batch.Delete(iter.Key()); err != nil {
+			return err
+		}
+		s.size -= min(s.size, uint64(len(iter.Value())))
+		s.count -= min(s.count, 1)
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	defer s.updateMetrics()
+	return batch.Write()
+}
+
+// deleteUpTo removes the messages up to and including the given sequence number.
+func (s *diskStore) deleteUpTo(seqNum uint64) error {
+	return s.deleteWhile(func(stored uint64, _ *persistedMessage) bool {
+		return stored <= seqNum
+	})
+}
+
+// deleteFrom removes the messages from the given sequence number on. It must be called before load.
+func (s *diskStore) deleteFrom(seqNum uint64) error {
+	batch := s.db.NewBatch()
+	iter := s.db.NewIterator(persistedMessagePrefix, binary.BigEndian.AppendUint64(nil, seqNum))
+	defer iter.Release()
+	for iter.Next() {
+		if err := batch.Delete(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (s *diskStore) reset() error {
+	return s.deleteWhile(func(uint64, *persistedMessage) bool { return true })
+}
+
+// prune removes the oldest messages until the store is within its size and age limits.
+func (s *diskStore) prune() error {
+	config := s.config()
+	var minStoredAt int64
+	if config.MaxAge > 0 {
+		minStoredAt = time.Now().Add(-config.MaxAge).Unix()
+	}
+	overSize := config.MaxSize > 0 && s.size > config.MaxSize
+	if !overSize && minStoredAt == 0 {
+		return nil
+	}
+	return s.deleteWhile(func(_ uint64, stored *persistedMessage) bool {
+		return (config.MaxSize > 0 && s.size > config.MaxSize) || stored.StoredAt < minStoredAt
+	})
+}
+
+func (s *diskStore) close() error {
+	return s.db.Close()
+}
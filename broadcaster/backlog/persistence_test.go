@@ -0,0 +1,125 @@
+package backlog
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+func persistentTestBacklog(t *testing.T, config *Config) *backlog {
+	t.Helper()
+	b, ok := NewBacklog(func() *Config { return config }).(*backlog)
+	if !ok {
+		t.Fatal("unexpected backlog type")
+	}
+	if err := b.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func persistentTestConfig(t *testing.T) *Config {
+	config := DefaultTestConfig
+	config.Persistence.Enable = true
+	config.Persistence.Directory = t.TempDir()
+	return &config
+}
+
+func TestPersistedBacklogRestart(t *testing.T) {
+	config := persistentTestConfig(t)
+	b := persistentTestBacklog(t, config)
+	indexes := []arbutil.MessageIndex{40, 41, 42, 43, 44, 45, 46}
+	if err := b.Append(m.CreateDummyBroadcastMessage(indexes)); err != nil {
+		t.Fatal(err)
+	}
+	// Confirming messages must remove them from disk too
+	confirmed := &m.BroadcastMessage{ConfirmedSequenceNumberMessage: &m.ConfirmedSequenceNumberMessage{SequenceNumber: 41}}
+	if err := b.Append(confirmed); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := persistentTestBacklog(t, config)
+	defer restarted.Close()
+	validateBacklog(t, restarted, 5, 42, 46, indexes[2:])
+	bm, err := restarted.Get(42, 46)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateBroadcastMessage(t, bm, 5, 42, 46)
+
+	// New messages keep appending after the reloaded ones
+	if err := restarted.Append(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{47})); err != nil {
+		t.Fatal(err)
+	}
+	validateBacklog(t, restarted, 6, 42, 47, []arbutil.MessageIndex{42, 43, 44, 45, 46, 47})
+}
+
+func TestPersistedBacklogMaxSize(t *testing.T) {
+	config := persistentTestConfig(t)
+	b := persistentTestBacklog(t, config)
+	if err := b.Append(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{40})); err != nil {
+		t.Fatal(err)
+	}
+	messageSize := b.store.size
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Persistence.MaxSize = messageSize * 3
+	b = persistentTestBacklog(t, config)
+	if err := b.Append(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{41, 42, 43, 44})); err != nil {
+		t.Fatal(err)
+	}
+	if b.store.count != 3 {
+		t.Fatalf("expected 3 messages on disk, found %d", b.store.count)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the newest messages within the size limit are reloaded
+	restarted := persistentTestBacklog(t, config)
+	defer restarted.Close()
+	validateBacklog(t, restarted, 3, 42, 44, []arbutil.MessageIndex{42, 43, 44})
+}
+
+func TestPersistedBacklogTruncated(t *testing.T) {
+	config := persistentTestConfig(t)
+	b := persistentTestBacklog(t, config)
+	if err := b.Append(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{40, 41, 42, 43, 44})); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages from the truncation point on are dropped, so that replacements aren't ignored as already seen
+	restarted, ok := NewBacklog(func() *Config { return config }).(*backlog)
+	if !ok {
+		t.Fatal("unexpected backlog type")
+	}
+	if err := restarted.InitializeTruncated(43); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+	validateBacklog(t, restarted, 3, 40, 42, []arbutil.MessageIndex{40, 41, 42})
+	if restarted.store.count != 3 {
+		t.Fatalf("expected 3 messages on disk, found %d", restarted.store.count)
+	}
+	if err := restarted.Append(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{43})); err != nil {
+		t.Fatal(err)
+	}
+	validateBacklog(t, restarted, 4, 40, 43, []arbutil.MessageIndex{40, 41, 42, 43})
+}
+
+func TestPersistenceConfigValidate(t *testing.T) {
+	config := DefaultTestConfig
+	config.Persistence.Enable = true
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected error when persistence is enabled without a directory")
+	}
+}
@@ -154,6 +154,18 @@ func (b *Broadcaster) GetCachedMessageCount() int {
 }
 
 func (b *Broadcaster) Initialize() error {
+	if err := b.backlog.Initialize(); err != nil {
+		return err
+	}
+	return b.server.Initialize()
+}
+
+// InitializeTruncated is like Initialize, but drops the persisted backlog messages from messageCount
+// on, so that the caller's own messages replace them.
+func (b *Broadcaster) InitializeTruncated(messageCount arbutil.MessageIndex) error {
+	if err := b.backlog.InitializeTruncated(uint64(messageCount)); err != nil {
+		return err
+	}
 	return b.server.Initialize()
 }
 
@@ -167,6 +179,9 @@ func (b *Broadcaster) StartWithHeader(ctx context.Context, header ws.HandshakeHe
 
 func (b *Broadcaster) StopAndWait() {
 	b.server.StopAndWait()
+	if err := b.backlog.Close(); err != nil {
+		log.Error("error closing feed backlog", "err", err)
+	}
 }
 
 func (b *Broadcaster) Started() bool {
@@ -134,6 +134,9 @@ func (c *AutonomousAuctioneerConfig) Validate() error {
 	if err := c.AuctioneerServer.S3Storage.Validate(); err != nil {
 		return err
 	}
+	if err := c.AuctioneerServer.HistoryAPI.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -131,6 +131,20 @@ func mainImpl() int {
 			log.Error("Error creating new auctioneer", "error", err)
 			return 1
 		}
+		if historyAPI := auctioneer.HistoryAPI(); historyAPI != nil {
+			timeboost.EnsureAuctionHistoryExposedViaRPC(&stackConf)
+			stack, err := node.New(&stackConf)
+			if err != nil {
+				flag.Usage()
+				log.Crit("failed to initialize geth stack", "err", err)
+			}
+			stack.RegisterAPIs(historyAPI.APIs())
+			err = stack.Start()
+			if err != nil {
+				fatalErrChan <- fmt.Errorf("error starting stack: %w", err)
+			}
+			defer stack.Close()
+		}
 		auctioneer.Start(ctx)
 	} else if nodeConfig.BidValidator.Enable {
 		log.Info("Running Arbitrum express lane bid validator", "revision", vcsRevision, "vcs.time", vcsTime)
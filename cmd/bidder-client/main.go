@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"os/signal"
+	"syscall"
 
 	flag "github.com/spf13/pflag"
 
@@ -52,6 +54,13 @@ func mainImpl() error {
 		return errors.New("--deposit-gwei and --bid-gwei can't both be set, either make a deposit or a bid")
 	}
 
+	if bidderClientConfig.AutoBid.Enable {
+		if bidderClientConfig.DepositGwei > 0 || bidderClientConfig.BidGwei > 0 {
+			return errors.New("--auto-bid.enable can't be combined with --deposit-gwei or --bid-gwei")
+		}
+		return runAutoBidder(ctx, bidderClient, &bidderClientConfig.AutoBid)
+	}
+
 	if bidderClientConfig.DepositGwei > 0 {
 		err = bidderClient.Deposit(ctx, big.NewInt(int64(bidderClientConfig.DepositGwei)*1_000_000_000))
 		if err == nil {
@@ -72,6 +81,26 @@ func mainImpl() error {
 	return errors.New("select one of --deposit-gwei or --bid-gwei")
 }
 
+func runAutoBidder(ctx context.Context, bidderClient *timeboost.BidderClient, config *timeboost.AutoBidderConfig) error {
+	autoBidder, err := timeboost.NewAutoBidder(bidderClient, config)
+	if err != nil {
+		return err
+	}
+	bidderClient.Start(ctx)
+	defer bidderClient.StopAndWait()
+	if err := autoBidder.Start(ctx); err != nil {
+		return err
+	}
+	defer autoBidder.StopAndWait()
+	log.Info("Bidding in every auction", "strategy", config.Strategy.Name)
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	<-sigint
+	log.Info("shutting down because of sigint")
+	return nil
+}
+
 func parseBidderClientArgs(ctx context.Context, args []string) (*timeboost.BidderClientConfig, error) {
 	f := flag.NewFlagSet("", flag.ContinueOnError)
 
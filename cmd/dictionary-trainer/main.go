@@ -0,0 +1,160 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// dictionary-trainer builds a brotli compression dictionary out of the messages of historical
+// batches stored in a node's arbitrumdata database, for use with the batch poster's
+// brotli-dictionary compression algorithm.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+)
+
+type DictionaryTrainerConfig struct {
+	Data           string `koanf:"data"`
+	DBEngine       string `koanf:"db-engine"`
+	FromMessage    uint64 `koanf:"from-message"`
+	ToMessage      uint64 `koanf:"to-message"`
+	SampleSize     int    `koanf:"sample-size"`
+	DictionarySize int    `koanf:"dictionary-size"`
+	Output         string `koanf:"output"`
+	LogLevel       string `koanf:"log-level"`
+	LogType        string `koanf:"log-type"`
+}
+
+var DefaultDictionaryTrainerConfig = DictionaryTrainerConfig{
+	DBEngine:       "pebble",
+	FromMessage:    0,
+	ToMessage:      ^uint64(0),
+	SampleSize:     100_000,
+	DictionarySize: 32 * 1024,
+	Output:         "batch-dictionary.bin",
+	LogLevel:       "INFO",
+	LogType:        "plaintext",
+}
+
+func DictionaryTrainerConfigAddOptions(f *flag.FlagSet) {
+	f.String("data", DefaultDictionaryTrainerConfig.Data, "arbitrumdata database directory of the node to read historical messages from")
+	f.String("db-engine", DefaultDictionaryTrainerConfig.DBEngine, "backing database implementation of the node ('leveldb' or 'pebble')")
+	f.Uint64("from-message", DefaultDictionaryTrainerConfig.FromMessage, "first message to train the dictionary on")
+	f.Uint64("to-message", DefaultDictionaryTrainerConfig.ToMessage, "message to stop training before (defaults to the latest message)")
+	f.Int("sample-size", DefaultDictionaryTrainerConfig.SampleSize, "uncompressed size of each training sample, should be close to the uncompressed size of a batch")
+	f.Int("dictionary-size", DefaultDictionaryTrainerConfig.DictionarySize, "maximum size of the trained dictionary, which is published in the first batch using it")
+	f.String("output", DefaultDictionaryTrainerConfig.Output, "file to write the trained dictionary to")
+	f.String("log-level", DefaultDictionaryTrainerConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultDictionaryTrainerConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *DictionaryTrainerConfig) Validate() error {
+	if c.Data == "" {
+		return errors.New("--data must be set")
+	}
+	if c.FromMessage >= c.ToMessage {
+		return errors.New("--from-message must be lower than --to-message")
+	}
+	if c.SampleSize <= 0 || c.DictionarySize <= 0 {
+		return errors.New("--sample-size and --dictionary-size must be positive")
+	}
+	if c.DictionarySize > arbcompress.MaxDictionarySize {
+		return fmt.Errorf("--dictionary-size can't be larger than %v", arbcompress.MaxDictionarySize)
+	}
+	return nil
+}
+
+func parseDictionaryTrainer(args []string) (*DictionaryTrainerConfig, error) {
+	f := flag.NewFlagSet("dictionary-trainer", flag.ContinueOnError)
+	DictionaryTrainerConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config DictionaryTrainerConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --data <node data dir>/<chain name>/arbitrumdata --output dictionary.bin\n\n", name)
+}
+
+func main() {
+	config, err := parseDictionaryTrainer(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		os.Exit(1)
+	}
+	if err := train(config); err != nil {
+		log.Error("Error training dictionary", "err", err)
+		os.Exit(1)
+	}
+}
+
+func train(config *DictionaryTrainerConfig) error {
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:      config.DBEngine,
+		Directory: config.Data,
+		Namespace: "arbitrumdata/",
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	samples, err := arbnode.ReadDictionarySamples(db, arbutil.MessageIndex(config.FromMessage), arbutil.MessageIndex(config.ToMessage), config.SampleSize)
+	if err != nil {
+		return err
+	}
+	if len(samples) < 2 {
+		return errors.New("not enough messages to train a dictionary")
+	}
+	dictionary := arbcompress.TrainDictionary(samples, config.DictionarySize)
+	if len(dictionary) == 0 {
+		return errors.New("the messages have no content repeating across samples")
+	}
+
+	// Report how the dictionary does on the last sample, as it's the closest to current traffic
+	last := samples[len(samples)-1]
+	withoutDictionary, err := arbcompress.CompressWell(last)
+	if err != nil {
+		return err
+	}
+	withDictionary, err := arbcompress.CompressWithCustomDictionary(last, arbcompress.LEVEL_WELL, dictionary)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(config.Output, dictionary, 0o600); err != nil {
+		return err
+	}
+	log.Info(
+		"Trained compression dictionary",
+		"output", config.Output,
+		"hash", crypto.Keccak256Hash(dictionary),
+		"size", len(dictionary),
+		"samples", len(samples),
+		"lastSampleSize", len(last),
+		"compressedWithoutDictionary", len(withoutDictionary),
+		"compressedWithDictionary", len(withDictionary),
+	)
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestFeedReplayConfig(t *testing.T) {
+	args := strings.Split("--data /tmp/arbitrumdata --chain.id 42161 --from-message 10 --to-message 20 --output.port 9652", " ")
+	config, err := parseFeedReplay(args)
+	testhelpers.RequireImpl(t, err)
+	if config.FromMessage != 10 || config.ToMessage != 20 || config.Output.Port != "9652" {
+		t.Fatal("unexpected config", config)
+	}
+
+	args = strings.Split("--data /tmp/arbitrumdata --chain.id 42161 --from-message 20 --to-message 10", " ")
+	if _, err := parseFeedReplay(args); err == nil {
+		t.Fatal("expected error for empty message range")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// feed-replay serves a historical range of messages from a node's arbitrumdata database over
+// the sequencer feed protocol, as a deterministic feed source for testing and for backfilling
+// consumers that want feed-format data, without running a sequencer.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+type FeedReplayConfig struct {
+	Data                   string                              `koanf:"data"`
+	DBEngine               string                              `koanf:"db-engine"`
+	Chain                  conf.L2Config                       `koanf:"chain"`
+	FromMessage            uint64                              `koanf:"from-message"`
+	ToMessage              uint64                              `koanf:"to-message"`
+	TrackBlockMetadataFrom uint64                              `koanf:"track-block-metadata-from"`
+	MessagesPerSecond      uint64                              `koanf:"messages-per-second"`
+	ConfirmLag             uint64                              `koanf:"confirm-lag"`
+	Output                 wsbroadcastserver.BroadcasterConfig `koanf:"output"`
+	LogLevel               string                              `koanf:"log-level"`
+	LogType                string                              `koanf:"log-type"`
+}
+
+var DefaultFeedReplayConfig = FeedReplayConfig{
+	DBEngine:               "pebble",
+	Chain:                  conf.L2ConfigDefault,
+	FromMessage:            0,
+	ToMessage:              0,
+	TrackBlockMetadataFrom: 0,
+	MessagesPerSecond:      0,
+	ConfirmLag:             0,
+	Output:                 wsbroadcastserver.DefaultBroadcasterConfig,
+	LogLevel:               "INFO",
+	LogType:                "plaintext",
+}
+
+// replayBatchSize is the number of messages read from the database at once
+const replayBatchSize = 100
+
+func FeedReplayConfigAddOptions(f *flag.FlagSet) {
+	f.String("data", DefaultFeedReplayConfig.Data, "arbitrumdata database directory of the node to replay messages from")
+	f.String("db-engine", DefaultFeedReplayConfig.DBEngine, "backing database implementation of the node ('leveldb' or 'pebble')")
+	conf.L2ConfigAddOptions("chain", f)
+	f.Uint64("from-message", DefaultFeedReplayConfig.FromMessage, "first message to replay")
+	f.Uint64("to-message", DefaultFeedReplayConfig.ToMessage, "message to stop replaying before (0 = the latest message in the database)")
+	f.Uint64("track-block-metadata-from", DefaultFeedReplayConfig.TrackBlockMetadataFrom, "block number the node started tracking block metadata from (defaults to the chain info's value)")
+	f.Uint64("messages-per-second", DefaultFeedReplayConfig.MessagesPerSecond, "rate to replay messages at (0 = as fast as possible)")
+	f.Uint64("confirm-lag", DefaultFeedReplayConfig.ConfirmLag, "confirm messages this far behind the latest replayed message so they're dropped from the backlog (0 = keep the whole range in the backlog)")
+	wsbroadcastserver.BroadcasterConfigAddOptions("output", f)
+	f.String("log-level", DefaultFeedReplayConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultFeedReplayConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *FeedReplayConfig) Validate() error {
+	if c.Data == "" {
+		return errors.New("--data must be set")
+	}
+	if c.Chain.ID == 0 && c.Chain.Name == "" {
+		return errors.New("--chain.id or --chain.name must be set")
+	}
+	if c.ToMessage != 0 && c.FromMessage >= c.ToMessage {
+		return errors.New("--from-message must be lower than --to-message")
+	}
+	return c.Output.Validate()
+}
+
+func parseFeedReplay(args []string) (*FeedReplayConfig, error) {
+	f := flag.NewFlagSet("feed-replay", flag.ContinueOnError)
+	FeedReplayConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config FeedReplayConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --data <node data dir>/<chain name>/arbitrumdata --chain.id <L2 chain id> --from-message 1000 --to-message 2000\n\n", name)
+}
+
+func main() {
+	config, err := parseFeedReplay(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		os.Exit(1)
+	}
+	if err := run(config); err != nil {
+		log.Error("Error replaying feed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(config *FeedReplayConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainInfo, err := chaininfo.ProcessChainInfo(config.Chain.ID, config.Chain.Name, config.Chain.InfoFiles, config.Chain.InfoJson)
+	if err != nil {
+		return err
+	}
+	if chainInfo.ChainConfig == nil {
+		return errors.New("chain info has no chain config")
+	}
+	trackBlockMetadataFrom := config.TrackBlockMetadataFrom
+	if trackBlockMetadataFrom == 0 {
+		trackBlockMetadataFrom = chainInfo.TrackBlockMetadataFrom
+	}
+
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:      config.DBEngine,
+		Directory: config.Data,
+		Namespace: "arbitrumdata/",
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	streamerConfig := arbnode.DefaultTransactionStreamerConfig
+	streamerConfig.TrackBlockMetadataFrom = trackBlockMetadataFrom
+	streamer, err := arbnode.NewTransactionStreamer(db, chainInfo.ChainConfig, nil, nil, nil, func() *arbnode.TransactionStreamerConfig { return &streamerConfig }, &arbnode.DefaultSnapSyncConfig)
+	if err != nil {
+		return err
+	}
+	messageCount, err := streamer.GetMessageCount()
+	if err != nil {
+		return err
+	}
+	from, to := arbutil.MessageIndex(config.FromMessage), arbutil.MessageIndex(config.ToMessage)
+	if to == 0 || to > messageCount {
+		to = messageCount
+	}
+	if from >= to {
+		return fmt.Errorf("nothing to replay, the database has %v messages", messageCount)
+	}
+
+	feedErrChan := make(chan error, 10)
+	// Replayed messages are unsigned, as they can't be signed by the original sequencer
+	feed := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config.Output }, chainInfo.ChainConfig.ChainID.Uint64(), feedErrChan, nil)
+	if err := feed.Initialize(); err != nil {
+		return err
+	}
+	if err := feed.Start(ctx); err != nil {
+		return err
+	}
+	defer feed.StopAndWait()
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigint:
+			log.Info("shutting down because of sigint")
+		case err := <-feedErrChan:
+			log.Error("feed error, exiting", "err", err)
+		}
+		cancel()
+	}()
+
+	log.Info("Replaying feed", "address", feed.ListenerAddr(), "from", from, "to", to)
+	if err := replay(ctx, config, streamer, feed, from, to); err != nil {
+		return err
+	}
+	log.Info("Replayed all messages, serving them from the backlog until shut down", "from", from, "to", to)
+	<-ctx.Done()
+	return nil
+}
+
+func replay(ctx context.Context, config *FeedReplayConfig, streamer *arbnode.TransactionStreamer, feed *broadcaster.Broadcaster, from, to arbutil.MessageIndex) error {
+	start := time.Now()
+	for pos := from; pos < to; {
+		end := min(pos+replayBatchSize, to)
+		if config.MessagesPerSecond > 0 {
+			// Broadcast one message at a time to keep the rate steady
+			end = pos + 1
+		}
+		messages, err := streamer.GetMessagesWithBlockInfo(pos, end)
+		if err != nil {
+			return err
+		}
+		if err := feed.BroadcastMessages(messages, pos); err != nil {
+			return err
+		}
+		pos = end
+		if config.ConfirmLag > 0 && uint64(pos-from) > config.ConfirmLag {
+			feed.Confirm(pos - arbutil.MessageIndex(config.ConfirmLag) - 1)
+		}
+		if config.MessagesPerSecond > 0 {
+			// #nosec G115
+			next := start.Add(time.Duration(uint64(pos-from) * uint64(time.Second) / config.MessagesPerSecond))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Until(next)):
+			}
+		} else if ctx.Err() != nil {
+			return nil
+		}
+	}
+	return nil
+}
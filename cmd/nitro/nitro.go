@@ -30,12 +30,14 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
 	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -54,6 +56,8 @@ import (
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
 	"github.com/offchainlabs/nitro/das"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
 	"github.com/offchainlabs/nitro/execution/gethexec"
 	_ "github.com/offchainlabs/nitro/execution/nodeInterface"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
@@ -247,7 +251,7 @@ func mainImpl() int {
 	var l1TransactionOptsBatchPoster *bind.TransactOpts
 	// If sequencer and signing is enabled or batchposter is enabled without
 	// external signing sequencer will need a key.
-	sequencerNeedsKey := (nodeConfig.Node.Sequencer && !nodeConfig.Node.Feed.Output.DisableSigning) ||
+	sequencerNeedsKey := (nodeConfig.Node.Sequencer && (!nodeConfig.Node.Feed.Output.DisableSigning || nodeConfig.Execution.Sequencer.EnablePreconfs)) ||
 		(nodeConfig.Node.BatchPoster.Enable && (nodeConfig.Node.BatchPoster.DataPoster.ExternalSigner.URL == "" || nodeConfig.Node.DataAvailability.Enable))
 	validatorNeedsKey := nodeConfig.Node.Staker.OnlyCreateWalletContract ||
 		(nodeConfig.Node.Staker.Enable && !strings.EqualFold(nodeConfig.Node.Staker.Strategy, "watchtower") && nodeConfig.Node.Staker.DataPoster.ExternalSigner.URL == "")
@@ -310,6 +314,10 @@ func mainImpl() int {
 		return nodeConfig, err
 	})
 
+	// Execution only, driven over RPC by a consensus node in another process. The execution node
+	// still needs the parent chain client, but none of the consensus node's parent chain readers.
+	executionOnly := nodeConfig.Execution.ConsensusClient.URL != ""
+
 	var rollupAddrs chaininfo.RollupAddresses
 	var l1Client *ethclient.Client
 	var l1Reader *headerreader.HeaderReader
@@ -336,7 +344,10 @@ func mainImpl() int {
 		if err != nil {
 			log.Crit("error getting rollup addresses", "err", err)
 		}
+	}
+	if nodeConfig.Node.ParentChainReader.Enable && !executionOnly {
 		arbSys, _ := precompilesgen.NewArbSys(types.ArbSysAddress, l1Client)
+		var err error
 		l1Reader, err = headerreader.New(ctx, l1Client, func() *headerreader.Config { return &liveNodeConfig.Get().Node.ParentChainReader }, arbSys)
 		if err != nil {
 			log.Crit("failed to get L1 headerreader", "err", err)
@@ -396,10 +407,16 @@ func mainImpl() int {
 	}
 
 	var sameProcessValidationNodeEnabled bool
-	if nodeConfig.Node.BlockValidator.Enable && (nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self" || nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self-auth") {
+	if !executionOnly && nodeConfig.Node.BlockValidator.Enable && (nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self" || nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self-auth") {
 		sameProcessValidationNodeEnabled = true
 		valnode.EnsureValidationExposedViaAuthRPC(&stackConf)
 	}
+	if nodeConfig.Node.ExecutionClient.URL != "" {
+		execrpc.EnsureExposedViaAuthRPC(&stackConf, execrpc.ConsensusNamespace)
+	}
+	if executionOnly {
+		execrpc.EnsureExposedViaAuthRPC(&stackConf, execrpc.ExecutionNamespace)
+	}
 	stack, err := node.New(&stackConf)
 	if err != nil {
 		flag.Usage()
@@ -433,34 +450,44 @@ func mainImpl() int {
 	}()
 
 	// Check that node is compatible with on-chain WASM module root on startup and before any ArbOS upgrades take effect to prevent divergences
-	if nodeConfig.Node.ParentChainReader.Enable && nodeConfig.Validation.Wasm.EnableWasmrootsCheck {
+	if nodeConfig.Node.ParentChainReader.Enable && nodeConfig.Validation.Wasm.EnableWasmrootsCheck && !executionOnly {
 		err := checkWasmModuleRootCompatibility(ctx, nodeConfig.Validation.Wasm, l1Client, rollupAddrs)
 		if err != nil {
 			log.Warn("failed to check if node is compatible with on-chain WASM module root", "err", err)
 		}
 	}
 
-	chainDb, l2BlockChain, err := openInitializeChainDb(ctx, stack, nodeConfig, new(big.Int).SetUint64(nodeConfig.Chain.ID), gethexec.DefaultCacheConfigFor(stack, &nodeConfig.Execution.Caching), &nodeConfig.Execution.StylusTarget, &nodeConfig.Persistent, l1Client, rollupAddrs)
-	if l2BlockChain != nil {
-		deferFuncs = append(deferFuncs, func() { l2BlockChain.Stop() })
-	}
-	deferFuncs = append(deferFuncs, func() { closeDb(chainDb, "chainDb") })
-	if err != nil {
-		flag.Usage()
-		log.Error("error initializing database", "err", err)
-		return 1
+	// With a remote execution client, the chain database belongs to the execution node's process
+	consensusOnly := nodeConfig.Node.ExecutionClient.URL != ""
+	var chainDb ethdb.Database
+	var l2BlockChain *core.BlockChain
+	if !consensusOnly {
+		chainDb, l2BlockChain, err = openInitializeChainDb(ctx, stack, nodeConfig, new(big.Int).SetUint64(nodeConfig.Chain.ID), gethexec.DefaultCacheConfigFor(stack, &nodeConfig.Execution.Caching), &nodeConfig.Execution.StylusTarget, &nodeConfig.Persistent, l1Client, rollupAddrs)
+		if l2BlockChain != nil {
+			deferFuncs = append(deferFuncs, func() { l2BlockChain.Stop() })
+		}
+		deferFuncs = append(deferFuncs, func() { closeDb(chainDb, "chainDb") })
+		if err != nil {
+			flag.Usage()
+			log.Error("error initializing database", "err", err)
+			return 1
+		}
 	}
 
-	arbDb, err := stack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", false, nodeConfig.Persistent.Pebble.ExtraOptions("arbitrumdata"))
-	deferFuncs = append(deferFuncs, func() { closeDb(arbDb, "arbDb") })
-	if err != nil {
-		log.Error("failed to open database", "err", err)
-		log.Error("database is corrupt; delete it and try again", "database-directory", stack.InstanceDir())
-		return 1
-	}
-	if err := dbutil.UnfinishedConversionCheck(arbDb); err != nil {
-		log.Error("arbitrumdata unfinished conversion check error", "err", err)
-		return 1
+	// The consensus database belongs to the consensus node's process
+	var arbDb ethdb.Database
+	if !executionOnly {
+		arbDb, err = stack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", false, nodeConfig.Persistent.Pebble.ExtraOptions("arbitrumdata"))
+		deferFuncs = append(deferFuncs, func() { closeDb(arbDb, "arbDb") })
+		if err != nil {
+			log.Error("failed to open database", "err", err)
+			log.Error("database is corrupt; delete it and try again", "database-directory", stack.InstanceDir())
+			return 1
+		}
+		if err := dbutil.UnfinishedConversionCheck(arbDb); err != nil {
+			log.Error("arbitrumdata unfinished conversion check error", "err", err)
+			return 1
+		}
 	}
 
 	fatalErrChan := make(chan error, 10)
@@ -500,14 +527,18 @@ func mainImpl() int {
 		log.Error("error processing l2 chain info", "err", err)
 		return 1
 	}
-	if err := validateBlockChain(l2BlockChain, chainInfo.ChainConfig); err != nil {
-		log.Error("user provided chain config is not compatible with onchain chain config", "err", err)
-		return 1
+	l2ChainConfig := chainInfo.ChainConfig
+	if l2BlockChain != nil {
+		if err := validateBlockChain(l2BlockChain, chainInfo.ChainConfig); err != nil {
+			log.Error("user provided chain config is not compatible with onchain chain config", "err", err)
+			return 1
+		}
+		l2ChainConfig = l2BlockChain.Config()
 	}
 
-	if l2BlockChain.Config().ArbitrumChainParams.DataAvailabilityCommittee != nodeConfig.Node.DataAvailability.Enable {
+	if !executionOnly && l2ChainConfig.ArbitrumChainParams.DataAvailabilityCommittee != nodeConfig.Node.DataAvailability.Enable {
 		flag.Usage()
-		log.Error(fmt.Sprintf("data availability service usage for this chain is set to %v but --node.data-availability.enable is set to %v", l2BlockChain.Config().ArbitrumChainParams.DataAvailabilityCommittee, nodeConfig.Node.DataAvailability.Enable))
+		log.Error(fmt.Sprintf("data availability service usage for this chain is set to %v but --node.data-availability.enable is set to %v", l2ChainConfig.ArbitrumChainParams.DataAvailabilityCommittee, nodeConfig.Node.DataAvailability.Enable))
 		return 1
 	}
 
@@ -524,26 +555,45 @@ func mainImpl() int {
 		}
 	}
 
-	execNode, err := gethexec.CreateExecutionNode(
-		ctx,
-		stack,
-		chainDb,
-		l2BlockChain,
-		l1Client,
-		func() *gethexec.Config { return &liveNodeConfig.Get().Execution },
-	)
-	if err != nil {
-		log.Error("failed to create execution node", "err", err)
-		return 1
+	var execNode *gethexec.ExecutionNode
+	var execClient execution.FullExecutionClient
+	if consensusOnly {
+		execClient, err = execrpc.NewExecutionClient(ctx, func() *rpcclient.ClientConfig { return &liveNodeConfig.Get().Node.ExecutionClient }, stack)
+		if err != nil {
+			log.Error("failed to connect to execution node", "err", err)
+			return 1
+		}
+	} else {
+		execNode, err = gethexec.CreateExecutionNode(
+			ctx,
+			stack,
+			chainDb,
+			l2BlockChain,
+			l1Client,
+			func() *gethexec.Config { return &liveNodeConfig.Get().Execution },
+		)
+		if err != nil {
+			log.Error("failed to create execution node", "err", err)
+			return 1
+		}
+		if execNode.Sequencer != nil && nodeConfig.Execution.Sequencer.EnablePreconfs {
+			// Preconfs are signed with the same key as the feed, so they can be verified the same way
+			execNode.Sequencer.SetPreconfSigner(dataSigner)
+		}
+		execClient = execNode
+	}
+
+	if executionOnly {
+		return runExecutionOnly(ctx, stack, execNode, nodeConfig.GraphQL, fatalErrChan)
 	}
 
 	currentNode, err := arbnode.CreateNode(
 		ctx,
 		stack,
-		execNode,
+		execClient,
 		arbDb,
 		&NodeConfigFetcher{liveNodeConfig},
-		l2BlockChain.Config(),
+		l2ChainConfig,
 		l1Client,
 		&rollupAddrs,
 		l1TransactionOptsValidator,
@@ -655,9 +705,8 @@ func mainImpl() int {
 		}
 	}
 
-	gqlConf := nodeConfig.GraphQL
-	if gqlConf.Enable {
-		if err := graphql.New(stack, execNode.Backend.APIBackend(), execNode.FilterSystem, gqlConf.CORSDomain, gqlConf.VHosts); err != nil {
+	if execNode != nil {
+		if err := registerGraphQL(stack, execNode, nodeConfig.GraphQL); err != nil {
 			log.Error("failed to register the GraphQL service", "err", err)
 			return 1
 		}
@@ -692,6 +741,21 @@ func mainImpl() int {
 		}
 	}
 
+	if execNode != nil {
+		startExpressLaneService(ctx, execNode)
+	}
+
+	return waitForShutdown(sigint, fatalErrChan)
+}
+
+func registerGraphQL(stack *node.Node, execNode *gethexec.ExecutionNode, gqlConf genericconf.GraphQLConfig) error {
+	if !gqlConf.Enable {
+		return nil
+	}
+	return graphql.New(stack, execNode.Backend.APIBackend(), execNode.FilterSystem, gqlConf.CORSDomain, gqlConf.VHosts)
+}
+
+func startExpressLaneService(ctx context.Context, execNode *gethexec.ExecutionNode) {
 	execNodeConfig := execNode.ConfigFetcher()
 	if execNodeConfig.Sequencer.Enable && execNodeConfig.Sequencer.Dangerous.Timeboost.Enable {
 		err := execNode.Sequencer.InitializeExpressLaneService(
@@ -706,8 +770,10 @@ func mainImpl() int {
 		}
 		execNode.Sequencer.StartExpressLaneService(ctx)
 	}
+}
 
-	err = nil
+func waitForShutdown(sigint chan os.Signal, fatalErrChan chan error) int {
+	var err error
 	select {
 	case err = <-fatalErrChan:
 	case <-sigint:
@@ -728,6 +794,41 @@ func mainImpl() int {
 	return 0
 }
 
+// runExecutionOnly runs the execution node without consensus, serving the execution API to a
+// consensus node in another process. It's the execution-only entrypoint: rather than a separate
+// binary, it's cmd/nitro with --execution.consensus-client.url set, so that the execution process
+// opens, initializes and validates the chain database exactly like a full node, and both processes
+// ship in the same image and share one config format.
+func runExecutionOnly(ctx context.Context, stack *node.Node, execNode *gethexec.ExecutionNode, gqlConf genericconf.GraphQLConfig, fatalErrChan chan error) int {
+	if err := registerGraphQL(stack, execNode, gqlConf); err != nil {
+		log.Error("failed to register the GraphQL service", "err", err)
+		return 1
+	}
+	if err := execNode.Initialize(ctx); err != nil {
+		log.Error("error initializing execution node", "err", err)
+		return 1
+	}
+	if err := stack.Start(); err != nil {
+		log.Error("error starting geth stack", "err", err)
+		return 1
+	}
+	defer func() {
+		if err := stack.Close(); err != nil {
+			log.Error("error on stack close", "err", err)
+		}
+	}()
+	defer execNode.StopAndWait()
+	if err := execNode.Start(ctx); err != nil {
+		log.Error("error starting execution node", "err", err)
+		return 1
+	}
+	startExpressLaneService(ctx, execNode)
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	return waitForShutdown(sigint, fatalErrChan)
+}
+
 type NodeConfig struct {
 	Conf                   genericconf.ConfConfig          `koanf:"conf" reload:"hot"`
 	Node                   arbnode.Config                  `koanf:"node" reload:"hot"`
@@ -868,8 +969,14 @@ func (c *NodeConfig) Validate() error {
 	if err := c.Node.Validate(); err != nil {
 		return err
 	}
-	if err := c.Execution.Validate(); err != nil {
-		return err
+	if c.Node.ExecutionClient.URL != "" && c.Execution.ConsensusClient.URL != "" {
+		return errors.New("node.execution-client and execution.consensus-client can't both be set")
+	}
+	if c.Node.ExecutionClient.URL == "" {
+		// Otherwise execution runs in another process, with its own config
+		if err := c.Execution.Validate(); err != nil {
+			return err
+		}
 	}
 	if err := c.BlocksReExecutor.Validate(); err != nil {
 		return err
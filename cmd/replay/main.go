@@ -64,7 +64,11 @@ func (c WavmChainContext) GetHeader(hash common.Hash, num uint64) *types.Header
 	return header
 }
 
-type WavmInbox struct{}
+type WavmInbox struct {
+	lastBlockHeader *types.Header
+	// nil when producing the genesis block
+	initialArbosState *arbosState.ArbosState
+}
 
 func (i WavmInbox) PeekSequencerInbox() ([]byte, common.Hash, error) {
 	pos := wavmio.GetInboxPosition()
@@ -104,6 +108,19 @@ func (i WavmInbox) ReadDelayedInbox(seqNum uint64) (*arbostypes.L1IncomingMessag
 	})
 }
 
+func (i WavmInbox) BatchCompressionEnabled() (bool, error) {
+	if i.initialArbosState == nil || i.initialArbosState.ArbOSVersion() < arbosState.ArbosVersion_BatchCompression {
+		return false, nil
+	}
+	enabledBlock, err := i.initialArbosState.BatchCompressionBlock()
+	if err != nil {
+		return false, err
+	}
+	// each message of the batch before this one produced a block
+	batchStartBlock := i.lastBlockHeader.Number.Uint64() - i.GetPositionWithinMessage()
+	return batchStartBlock >= enabledBlock, nil
+}
+
 type PreimageDASReader struct {
 }
 
@@ -126,6 +143,13 @@ func (dasReader *PreimageDASReader) ExpirationPolicy(ctx context.Context) (dapro
 	return daprovider.DiscardImmediately, nil
 }
 
+type PreimageDictionaryReader struct {
+}
+
+func (r *PreimageDictionaryReader) GetDictionary(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return wavmio.ResolveTypedPreimage(arbutil.Keccak256PreimageType, hash)
+}
+
 type BlobPreimageReader struct {
 }
 
@@ -211,7 +235,7 @@ func main() {
 		}
 		return wavmio.ReadInboxMessage(batchNum), nil
 	}
-	readMessage := func(dasEnabled bool) *arbostypes.MessageWithMetadata {
+	readMessage := func(dasEnabled bool, initialArbosState *arbosState.ArbosState) *arbostypes.MessageWithMetadata {
 		var delayedMessagesRead uint64
 		if lastBlockHeader != nil {
 			delayedMessagesRead = lastBlockHeader.Nonce.Uint64()
@@ -223,7 +247,10 @@ func main() {
 			dasReader = &PreimageDASReader{}
 			dasKeysetFetcher = &PreimageDASReader{}
 		}
-		backend := WavmInbox{}
+		backend := WavmInbox{
+			lastBlockHeader:   lastBlockHeader,
+			initialArbosState: initialArbosState,
+		}
 		var keysetValidationMode = daprovider.KeysetPanicIfInvalid
 		if backend.GetPositionWithinMessage() > 0 {
 			keysetValidationMode = daprovider.KeysetDontValidate
@@ -233,7 +260,7 @@ func main() {
 			dapReaders = append(dapReaders, daprovider.NewReaderForDAS(dasReader, dasKeysetFetcher))
 		}
 		dapReaders = append(dapReaders, daprovider.NewReaderForBlobReader(&BlobPreimageReader{}))
-		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, keysetValidationMode)
+		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, &PreimageDictionaryReader{}, keysetValidationMode)
 		ctx := context.Background()
 		message, err := inboxMultiplexer.Pop(ctx)
 		if err != nil {
@@ -289,7 +316,7 @@ func main() {
 			}
 		}
 
-		message := readMessage(chainConfig.ArbitrumChainParams.DataAvailabilityCommittee)
+		message := readMessage(chainConfig.ArbitrumChainParams.DataAvailabilityCommittee, initialArbosState)
 
 		chainContext := WavmChainContext{}
 		newBlock, _, err = arbos.ProduceBlock(message.Message, message.DelayedMessagesRead, lastBlockHeader, statedb, chainContext, chainConfig, false, core.MessageReplayMode)
@@ -299,7 +326,7 @@ func main() {
 	} else {
 		// Initialize ArbOS with this init message and create the genesis block.
 
-		message := readMessage(false)
+		message := readMessage(false, nil)
 
 		initMessage, err := message.Message.ParseInitMessage()
 		if err != nil {
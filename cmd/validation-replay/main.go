@@ -0,0 +1,102 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/cmd/validation-replay/validationreplay"
+)
+
+func parseConfig(args []string) (*validationreplay.Config, error) {
+	f := flag.NewFlagSet("validation-replay", flag.ContinueOnError)
+	validationreplay.ConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config validationreplay.Config
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --inputs target/validation_inputs --root-path target/machines\n\n", name)
+}
+
+func main() {
+	os.Exit(mainImpl())
+}
+
+func mainImpl() int {
+	config, err := parseConfig(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		return 1
+	}
+
+	files, err := validationreplay.FindInputFiles(config.Inputs)
+	if err != nil {
+		log.Error("error finding inputs", "err", err)
+		return 1
+	}
+	if len(files) == 0 {
+		log.Error("no input files found", "inputs", config.Inputs)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fatalErrChan := make(chan error, 10)
+	replayer, err := validationreplay.NewReplayer(config, fatalErrChan)
+	if err != nil {
+		log.Error("error creating validators", "err", err)
+		return 1
+	}
+	if err := replayer.Start(ctx); err != nil {
+		log.Error("error starting validators", "err", err)
+		return 1
+	}
+	defer replayer.Stop()
+	log.Info("replaying validation inputs", "files", len(files), "moduleRoot", replayer.ModuleRoot())
+
+	failed := 0
+	for _, file := range files {
+		select {
+		case err := <-fatalErrChan:
+			log.Error("fatal error", "err", err)
+			return 1
+		default:
+		}
+		result, err := replayer.Replay(ctx, file)
+		if err != nil {
+			log.Error("error replaying input", "file", file, "err", err)
+			failed++
+			continue
+		}
+		fmt.Println(result)
+		if !result.Ok() {
+			failed++
+		}
+	}
+	fmt.Printf("%d of %d inputs passed\n", len(files)-failed, len(files))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
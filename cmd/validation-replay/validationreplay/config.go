@@ -0,0 +1,58 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validationreplay
+
+import (
+	"errors"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/validator/server_arb"
+	"github.com/offchainlabs/nitro/validator/server_jit"
+)
+
+type Config struct {
+	Inputs         []string                           `koanf:"inputs"`
+	RootPath       string                             `koanf:"root-path"`
+	ModuleRoot     string                             `koanf:"module-root"`
+	FindDivergence bool                               `koanf:"find-divergence"`
+	Jit            server_jit.JitSpawnerConfig        `koanf:"jit"`
+	Arbitrator     server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator"`
+	LogLevel       string                             `koanf:"log-level"`
+	LogType        string                             `koanf:"log-type"`
+}
+
+var DefaultConfig = Config{
+	Inputs:         []string{},
+	RootPath:       "",
+	ModuleRoot:     "",
+	FindDivergence: true,
+	Jit:            server_jit.DefaultJitSpawnerConfig,
+	Arbitrator:     server_arb.DefaultArbitratorSpawnerConfig,
+	LogLevel:       "INFO",
+	LogType:        "plaintext",
+}
+
+func ConfigAddOptions(f *flag.FlagSet) {
+	f.StringSlice("inputs", DefaultConfig.Inputs, "validation input files written by the block validator, or directories to search for them")
+	f.String("root-path", DefaultConfig.RootPath, "path to machine folders, each containing wasm files (machine.wavm.br, replay.wasm)")
+	f.String("module-root", DefaultConfig.ModuleRoot, "wasm module root to validate with (empty = latest in root-path)")
+	f.Bool("find-divergence", DefaultConfig.FindDivergence, "when the JIT and arbitrator disagree, search the arbitrator's execution for the step where they diverge")
+	server_jit.JitSpawnerConfigAddOptions("jit", f)
+	server_arb.ArbitratorSpawnerConfigAddOptions("arbitrator", f)
+	f.String("log-level", DefaultConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *Config) Validate() error {
+	if len(c.Inputs) == 0 {
+		return errors.New("no inputs given")
+	}
+	if c.ModuleRoot != "" && len(common.FromHex(c.ModuleRoot)) != common.HashLength {
+		return errors.New("invalid module-root")
+	}
+	return nil
+}
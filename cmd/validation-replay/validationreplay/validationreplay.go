@@ -0,0 +1,250 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package validationreplay runs validation inputs dumped by the block validator through both the
+// JIT and arbitrator validators, to reproduce validation failures without a node.
+package validationreplay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_arb"
+	"github.com/offchainlabs/nitro/validator/server_common"
+	"github.com/offchainlabs/nitro/validator/server_jit"
+)
+
+// FindInputFiles expands directories in paths to the json files under them, so a directory of
+// inputs can be replayed as a regression run.
+func FindInputFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+				found = append(found, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+func ReadInputFile(path string) (*validator.ValidationInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var inputJson server_api.InputJSON
+	if err := json.Unmarshal(data, &inputJson); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return server_api.ValidationInputFromJson(&inputJson)
+}
+
+// Divergence is where the arbitrator's execution stops agreeing with the JIT's end state.
+type Divergence struct {
+	// The global state fields the JIT and arbitrator disagree on
+	Fields []string
+	// The first step at which the arbitrator's global state holds its own value for one of the fields,
+	// or the last step if the arbitrator never changed them from the start state
+	Step          uint64
+	ArbitratorSet bool
+	MachineHash   common.Hash
+}
+
+type Result struct {
+	Path       string
+	Id         uint64
+	Jit        validator.GoGlobalState
+	Arbitrator validator.GoGlobalState
+	JitErr     error
+	ArbErr     error
+	Divergence *Divergence
+}
+
+func (r *Result) Ok() bool {
+	return r.JitErr == nil && r.ArbErr == nil && r.Jit == r.Arbitrator
+}
+
+func (r *Result) String() string {
+	if r.JitErr != nil || r.ArbErr != nil {
+		return fmt.Sprintf("%s (id %d): jit error: %v, arbitrator error: %v", r.Path, r.Id, r.JitErr, r.ArbErr)
+	}
+	if r.Ok() {
+		return fmt.Sprintf("%s (id %d): ok, %v", r.Path, r.Id, r.Jit)
+	}
+	res := fmt.Sprintf("%s (id %d): MISMATCH\n\tjit:        %v\n\tarbitrator: %v", r.Path, r.Id, r.Jit, r.Arbitrator)
+	if r.Divergence != nil {
+		if r.Divergence.ArbitratorSet {
+			res += fmt.Sprintf("\n\tarbitrator set %s at step %d (machine hash %v)", strings.Join(r.Divergence.Fields, ", "), r.Divergence.Step, r.Divergence.MachineHash)
+		} else {
+			res += fmt.Sprintf("\n\tarbitrator never set %s, finished at step %d", strings.Join(r.Divergence.Fields, ", "), r.Divergence.Step)
+		}
+	}
+	return res
+}
+
+func differingFields(a, b validator.GoGlobalState) []string {
+	var fields []string
+	if a.BlockHash != b.BlockHash {
+		fields = append(fields, "BlockHash")
+	}
+	if a.SendRoot != b.SendRoot {
+		fields = append(fields, "SendRoot")
+	}
+	if a.Batch != b.Batch {
+		fields = append(fields, "Batch")
+	}
+	if a.PosInBatch != b.PosInBatch {
+		fields = append(fields, "PosInBatch")
+	}
+	return fields
+}
+
+// findDivergence binary searches the arbitrator's execution for the first step at which it has set one of
+// the fields the JIT disagrees with. The global state is only written once the block is produced, so
+// earlier steps all hold the start state in those fields.
+func findDivergence(ctx context.Context, run validator.ExecutionRun, start, jit validator.GoGlobalState) (*Divergence, error) {
+	last, err := run.GetLastStep().Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields := differingFields(jit, last.GlobalState)
+	divergence := &Divergence{
+		Fields:      fields,
+		Step:        last.Position,
+		MachineHash: last.Hash,
+	}
+	isSet := func(state validator.GoGlobalState) bool {
+		changed := differingFields(start, state)
+		for _, field := range fields {
+			for _, other := range changed {
+				if field == other {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if !isSet(last.GlobalState) {
+		return divergence, nil
+	}
+	divergence.ArbitratorSet = true
+	low, high := uint64(0), last.Position
+	for low < high {
+		mid := low + (high-low)/2
+		step, err := run.GetStepAt(mid).Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if isSet(step.GlobalState) {
+			high = mid
+			divergence.MachineHash = step.Hash
+		} else {
+			low = mid + 1
+		}
+	}
+	divergence.Step = low
+	return divergence, nil
+}
+
+type Replayer struct {
+	config     *Config
+	jit        *server_jit.JitSpawner
+	arb        *server_arb.ArbitratorSpawner
+	moduleRoot common.Hash
+}
+
+func NewReplayer(config *Config, fatalErrChan chan error) (*Replayer, error) {
+	locator, err := server_common.NewMachineLocator(config.RootPath)
+	if err != nil {
+		return nil, err
+	}
+	moduleRoot := locator.LatestWasmModuleRoot()
+	if config.ModuleRoot != "" {
+		moduleRoot = common.HexToHash(config.ModuleRoot)
+	}
+	arb, err := server_arb.NewArbitratorSpawner(locator, func() *server_arb.ArbitratorSpawnerConfig { return &config.Arbitrator })
+	if err != nil {
+		return nil, err
+	}
+	jit, err := server_jit.NewJitSpawner(locator, func() *server_jit.JitSpawnerConfig { return &config.Jit }, fatalErrChan)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		config:     config,
+		jit:        jit,
+		arb:        arb,
+		moduleRoot: moduleRoot,
+	}, nil
+}
+
+func (r *Replayer) Start(ctx context.Context) error {
+	if err := r.arb.Start(ctx); err != nil {
+		return err
+	}
+	return r.jit.Start(ctx)
+}
+
+func (r *Replayer) Stop() {
+	r.jit.Stop()
+	r.arb.Stop()
+}
+
+func (r *Replayer) ModuleRoot() common.Hash {
+	return r.moduleRoot
+}
+
+// Replay runs the input through both validators and compares their end states.
+func (r *Replayer) Replay(ctx context.Context, path string) (*Result, error) {
+	input, err := ReadInputFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Path: path, Id: input.Id}
+	jitRun := r.jit.Launch(input, r.moduleRoot)
+	arbRun := r.arb.Launch(input, r.moduleRoot)
+	result.Jit, result.JitErr = jitRun.Await(ctx)
+	result.Arbitrator, result.ArbErr = arbRun.Await(ctx)
+	if result.Ok() || result.JitErr != nil || result.ArbErr != nil || !r.config.FindDivergence {
+		return result, nil
+	}
+	run, err := r.arb.CreateExecutionRun(r.moduleRoot, input, false).Await(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating arbitrator execution run: %w", err)
+	}
+	defer run.Close()
+	result.Divergence, err = findDivergence(ctx, run, input.StartState, result.Jit)
+	if err != nil {
+		return nil, fmt.Errorf("error searching for divergence: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validationreplay
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/inputs"
+	"github.com/offchainlabs/nitro/validator/server_api"
+)
+
+// testExecutionRun sets the global state at setStep, and only serves steps
+type testExecutionRun struct {
+	validator.ExecutionRun
+	start, end validator.GoGlobalState
+	setStep    uint64
+	lastStep   uint64
+}
+
+func (r *testExecutionRun) GetStepAt(position uint64) containers.PromiseInterface[*validator.MachineStepResult] {
+	if position > r.lastStep {
+		position = r.lastStep
+	}
+	state := r.start
+	if position >= r.setStep {
+		state = r.end
+	}
+	return containers.NewReadyPromise(&validator.MachineStepResult{
+		Hash:        common.BigToHash(new(big.Int).SetUint64(position)),
+		Position:    position,
+		GlobalState: state,
+	}, nil)
+}
+
+func (r *testExecutionRun) GetLastStep() containers.PromiseInterface[*validator.MachineStepResult] {
+	return r.GetStepAt(r.lastStep)
+}
+
+func TestFindDivergence(t *testing.T) {
+	ctx := context.Background()
+	start := validator.GoGlobalState{Batch: 5, PosInBatch: 1}
+	jit := validator.GoGlobalState{BlockHash: common.HexToHash("0x01"), Batch: 5, PosInBatch: 2}
+	arb := validator.GoGlobalState{BlockHash: common.HexToHash("0x02"), Batch: 5, PosInBatch: 2}
+
+	run := &testExecutionRun{start: start, end: arb, setStep: 12345, lastStep: 20000}
+	divergence, err := findDivergence(ctx, run, start, jit)
+	require.NoError(t, err)
+	require.Equal(t, []string{"BlockHash"}, divergence.Fields)
+	require.True(t, divergence.ArbitratorSet)
+	require.Equal(t, uint64(12345), divergence.Step)
+	require.Equal(t, common.BigToHash(new(big.Int).SetUint64(12345)), divergence.MachineHash)
+
+	// The arbitrator left the field at its start value, which the JIT changed
+	run = &testExecutionRun{start: start, end: start, setStep: 0, lastStep: 20000}
+	divergence, err = findDivergence(ctx, run, start, jit)
+	require.NoError(t, err)
+	require.False(t, divergence.ArbitratorSet)
+	require.Equal(t, uint64(20000), divergence.Step)
+}
+
+func TestFindInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := inputs.NewWriter(inputs.WithBaseDir(dir), inputs.WithSlug("BlockValidator"), inputs.WithTimestampDirEnabled(false))
+	require.NoError(t, err)
+	for _, id := range []uint64{2, 1} {
+		require.NoError(t, writer.Write(&server_api.InputJSON{Id: id, StartState: validator.GoGlobalState{Batch: id}}))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an input"), 0600))
+
+	files, err := FindInputFiles([]string{dir})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "block_inputs_1.json", filepath.Base(files[0]))
+
+	input, err := ReadInputFile(files[1])
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), input.Id)
+	require.Equal(t, uint64(2), input.StartState.Batch)
+}
@@ -333,6 +333,38 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 	return &aggCert, nil
 }
 
+// HealthCheck returns an error if fewer backends than needed for a successful Store pass their
+// health checks. Backends that can't be health checked are assumed healthy.
+func (a *Aggregator) HealthCheck(ctx context.Context) error {
+	errs := make(chan error, len(a.services))
+	for _, d := range a.services {
+		go func(d ServiceDetails) {
+			checker, ok := d.service.(DataAvailabilityServiceHealthChecker)
+			if !ok {
+				errs <- nil
+				return
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
+			defer cancel()
+			err := checker.HealthCheck(checkCtx)
+			if err != nil {
+				err = fmt.Errorf("backend %v: %w", d.metricName, err)
+			}
+			errs <- err
+		}(d)
+	}
+	var failures []error
+	for range a.services {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > a.maxAllowedServiceStoreFailures {
+		return fmt.Errorf("%d of %d DAS backends failed their health check, at most %d may fail: %w", len(failures), len(a.services), a.maxAllowedServiceStoreFailures, errors.Join(failures...))
+	}
+	return nil
+}
+
 func (a *Aggregator) String() string {
 	var b bytes.Buffer
 	b.WriteString("das.Aggregator{")
@@ -35,6 +35,13 @@ func (w *WriterPanicWrapper) Store(ctx context.Context, message []byte, timeout
 	return cert, nil
 }
 
+func (w *WriterPanicWrapper) HealthCheck(ctx context.Context) error {
+	if checker, ok := w.DataAvailabilityServiceWriter.(DataAvailabilityServiceHealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
 type ReaderPanicWrapper struct {
 	DataAvailabilityServiceReader
 }
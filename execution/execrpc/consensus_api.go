@@ -0,0 +1,80 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+)
+
+// ConsensusServerAPI serves a consensus node to an execution node in another process.
+type ConsensusServerAPI struct {
+	consensus execution.FullConsensusClient
+}
+
+func NewConsensusServerAPI(consensus execution.FullConsensusClient) *ConsensusServerAPI {
+	return &ConsensusServerAPI{consensus}
+}
+
+type BatchContainingMessage struct {
+	Batch uint64
+	Found bool
+}
+
+func (a *ConsensusServerAPI) FindInboxBatchContainingMessage(ctx context.Context, message arbutil.MessageIndex) (*BatchContainingMessage, error) {
+	batch, found, err := a.consensus.FindInboxBatchContainingMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchContainingMessage{Batch: batch, Found: found}, nil
+}
+
+func (a *ConsensusServerAPI) GetBatchParentChainBlock(ctx context.Context, seqNum uint64) (uint64, error) {
+	return a.consensus.GetBatchParentChainBlock(seqNum)
+}
+
+func (a *ConsensusServerAPI) Synced(ctx context.Context) bool {
+	return a.consensus.Synced()
+}
+
+func (a *ConsensusServerAPI) FullSyncProgressMap(ctx context.Context) map[string]interface{} {
+	return a.consensus.FullSyncProgressMap()
+}
+
+func (a *ConsensusServerAPI) SyncTargetMessageCount(ctx context.Context) arbutil.MessageIndex {
+	return a.consensus.SyncTargetMessageCount()
+}
+
+func (a *ConsensusServerAPI) BlockMetadataAtCount(ctx context.Context, count arbutil.MessageIndex) (common.BlockMetadata, error) {
+	return a.consensus.BlockMetadataAtCount(count)
+}
+
+func (a *ConsensusServerAPI) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.GetSafeMsgCount(ctx)
+}
+
+func (a *ConsensusServerAPI) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.GetFinalizedMsgCount(ctx)
+}
+
+func (a *ConsensusServerAPI) ValidatedMessageCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.ValidatedMessageCount()
+}
+
+func (a *ConsensusServerAPI) ConfirmedMessageCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.ConfirmedMessageCount()
+}
+
+func (a *ConsensusServerAPI) WriteMessageFromSequencer(ctx context.Context, pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata, msgResult execution.MessageResult, blockMetadata common.BlockMetadata) error {
+	return a.consensus.WriteMessageFromSequencer(pos, msgWithMeta, msgResult, blockMetadata)
+}
+
+func (a *ConsensusServerAPI) ExpectChosenSequencer(ctx context.Context) error {
+	return a.consensus.ExpectChosenSequencer()
+}
@@ -0,0 +1,179 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// ConsensusClient is an execution.FullConsensusClient served by a consensus node in another process.
+type ConsensusClient struct {
+	stopwaiter.StopWaiter
+	client    *rpcclient.RpcClient
+	connected atomic.Bool
+}
+
+var errConsensusNotConnected = errors.New("not connected to the consensus node yet")
+
+var _ execution.FullConsensusClient = (*ConsensusClient)(nil)
+
+func NewConsensusClient(config rpcclient.ClientConfigFetcher, stack *node.Node) *ConsensusClient {
+	return &ConsensusClient{
+		client: rpcclient.NewRpcClient(config, stack),
+	}
+}
+
+// Start connects to the consensus node in the background. The consensus node only serves RPC once it
+// connected to this execution node, so waiting for it here would deadlock the two processes.
+func (c *ConsensusClient) Start(ctx context.Context) error {
+	c.StopWaiter.Start(ctx, c)
+	return c.LaunchThreadSafe(func(ctx context.Context) {
+		for {
+			err := c.client.Start(ctx)
+			if err == nil {
+				c.connected.Store(true)
+				log.Info("connected to consensus node")
+				return
+			}
+			log.Warn("failed to connect to consensus node, retrying", "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	})
+}
+
+func (c *ConsensusClient) StopAndWait() {
+	c.StopWaiter.StopAndWait()
+	c.client.Close()
+}
+
+// remoteError restores the errors the sequencer checks for with errors.Is,
+// as only their message survives the trip over RPC.
+func remoteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, known := range []error{execution.ErrRetrySequencer, execution.ErrSequencerInsertLockTaken} {
+		if strings.Contains(err.Error(), known.Error()) {
+			return fmt.Errorf("%w: %w", known, err)
+		}
+	}
+	return err
+}
+
+func (c *ConsensusClient) callContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if !c.connected.Load() {
+		return errConsensusNotConnected
+	}
+	return remoteError(c.client.CallContext(ctx, result, ConsensusNamespace+"_"+method, args...))
+}
+
+func (c *ConsensusClient) call(result interface{}, method string, args ...interface{}) error {
+	ctx, err := c.GetContextSafe()
+	if err != nil {
+		// Not started yet
+		ctx = context.Background()
+	}
+	return c.callContext(ctx, result, method, args...)
+}
+
+func (c *ConsensusClient) FindInboxBatchContainingMessage(message arbutil.MessageIndex) (uint64, bool, error) {
+	var res BatchContainingMessage
+	if err := c.call(&res, "findInboxBatchContainingMessage", message); err != nil {
+		return 0, false, err
+	}
+	return res.Batch, res.Found, nil
+}
+
+func (c *ConsensusClient) GetBatchParentChainBlock(seqNum uint64) (uint64, error) {
+	var res uint64
+	err := c.call(&res, "getBatchParentChainBlock", seqNum)
+	return res, err
+}
+
+func (c *ConsensusClient) Synced() bool {
+	var res bool
+	if err := c.call(&res, "synced"); err != nil {
+		log.Warn("failed to get consensus node sync status", "err", err)
+		return false
+	}
+	return res
+}
+
+func (c *ConsensusClient) FullSyncProgressMap() map[string]interface{} {
+	var res map[string]interface{}
+	if err := c.call(&res, "fullSyncProgressMap"); err != nil {
+		return map[string]interface{}{"consensusRpcError": err.Error()}
+	}
+	return res
+}
+
+func (c *ConsensusClient) SyncTargetMessageCount() arbutil.MessageIndex {
+	var res arbutil.MessageIndex
+	if err := c.call(&res, "syncTargetMessageCount"); err != nil {
+		log.Warn("failed to get consensus node sync target", "err", err)
+		return 0
+	}
+	return res
+}
+
+func (c *ConsensusClient) BlockMetadataAtCount(count arbutil.MessageIndex) (common.BlockMetadata, error) {
+	var res common.BlockMetadata
+	err := c.call(&res, "blockMetadataAtCount", count)
+	return res, err
+}
+
+func (c *ConsensusClient) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.callContext(ctx, &res, "getSafeMsgCount")
+	return res, err
+}
+
+func (c *ConsensusClient) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.callContext(ctx, &res, "getFinalizedMsgCount")
+	return res, err
+}
+
+func (c *ConsensusClient) ValidatedMessageCount() (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(&res, "validatedMessageCount")
+	return res, err
+}
+
+func (c *ConsensusClient) ConfirmedMessageCount() (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(&res, "confirmedMessageCount")
+	return res, err
+}
+
+func (c *ConsensusClient) WriteMessageFromSequencer(pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata, msgResult execution.MessageResult, blockMetadata common.BlockMetadata) error {
+	return c.call(nil, "writeMessageFromSequencer", pos, msgWithMeta, msgResult, blockMetadata)
+}
+
+func (c *ConsensusClient) ExpectChosenSequencer() error {
+	if !c.connected.Load() {
+		// The sequencer keeps its transactions queued until it can write their blocks
+		return fmt.Errorf("%w: %w", execution.ErrRetrySequencer, errConsensusNotConnected)
+	}
+	return c.call(nil, "expectChosenSequencer")
+}
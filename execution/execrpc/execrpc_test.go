@@ -0,0 +1,159 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+)
+
+// Only the methods called by the tests are implemented
+type testExecution struct {
+	execution.FullExecutionClient
+	digested []*arbostypes.MessageWithMetadata
+}
+
+func (e *testExecution) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	if num != arbutil.MessageIndex(len(e.digested)) {
+		return nil, fmt.Errorf("expected message %d got %d", len(e.digested), num)
+	}
+	e.digested = append(e.digested, msg)
+	return &execution.MessageResult{BlockHash: common.BigToHash(big.NewInt(int64(num)))}, nil
+}
+
+func (e *testExecution) MessageIndexToBlockNumber(messageNum arbutil.MessageIndex) uint64 {
+	return uint64(messageNum) + 10
+}
+
+func (e *testExecution) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	return &execution.RecordResult{
+		Pos:       pos,
+		BlockHash: common.HexToHash("0x1234"),
+		Preimages: map[common.Hash][]byte{common.HexToHash("0x01"): {1, 2, 3}},
+	}, nil
+}
+
+type testConsensus struct {
+	execution.FullConsensusClient
+}
+
+func (c *testConsensus) ExpectChosenSequencer() error {
+	return fmt.Errorf("%w: not main sequencer", execution.ErrRetrySequencer)
+}
+
+func (c *testConsensus) FindInboxBatchContainingMessage(message arbutil.MessageIndex) (uint64, bool, error) {
+	return uint64(message) / 2, message < 100, nil
+}
+
+func createTestStack(t *testing.T, ctx context.Context, apis []rpc.API) *node.Node {
+	stackConf := node.DefaultConfig
+	stackConf.HTTPPort = 0
+	stackConf.DataDir = ""
+	stackConf.WSHost = "127.0.0.1"
+	stackConf.WSPort = 0
+	stackConf.WSModules = []string{ExecutionNamespace, ConsensusNamespace}
+	stackConf.P2P.NoDiscovery = true
+	stackConf.P2P.ListenAddr = ""
+
+	stack, err := node.New(&stackConf)
+	require.NoError(t, err)
+	stack.RegisterAPIs(apis)
+	require.NoError(t, stack.Start())
+	go func() {
+		<-ctx.Done()
+		stack.Close()
+	}()
+	return stack
+}
+
+func TestExecutionClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exec := &testExecution{}
+	stack := createTestStack(t, ctx, []rpc.API{{
+		Namespace: ExecutionNamespace,
+		Version:   "1.0",
+		Service:   NewExecutionServerAPI(exec),
+		Public:    true,
+	}})
+	config := rpcclient.TestClientConfig
+	client, err := NewExecutionClient(ctx, func() *rpcclient.ClientConfig { return &config }, stack)
+	require.NoError(t, err)
+	require.NoError(t, client.Start(ctx))
+	defer client.StopAndWait()
+
+	require.Equal(t, uint64(15), client.MessageIndexToBlockNumber(5))
+
+	msg := &arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:      arbostypes.L1MessageType_L2Message,
+				Timestamp: 1000,
+				L1BaseFee: big.NewInt(7),
+			},
+			L2msg: []byte{4, 5, 6},
+		},
+		DelayedMessagesRead: 3,
+	}
+	result, err := client.DigestMessage(0, msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(big.NewInt(0)), result.BlockHash)
+	require.Len(t, exec.digested, 1)
+	require.Equal(t, msg, exec.digested[0])
+
+	// Errors of the execution node are returned to consensus
+	_, err = client.DigestMessage(5, msg, nil)
+	require.ErrorContains(t, err, "expected message 1 got 5")
+
+	record, err := client.RecordBlockCreation(ctx, 3, msg)
+	require.NoError(t, err)
+	require.Equal(t, arbutil.MessageIndex(3), record.Pos)
+	require.Equal(t, common.HexToHash("0x1234"), record.BlockHash)
+	require.Equal(t, []byte{1, 2, 3}, record.Preimages[common.HexToHash("0x01")])
+}
+
+func TestConsensusClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stack := createTestStack(t, ctx, []rpc.API{{
+		Namespace: ConsensusNamespace,
+		Version:   "1.0",
+		Service:   NewConsensusServerAPI(&testConsensus{}),
+		Public:    true,
+	}})
+	config := rpcclient.TestClientConfig
+	client := NewConsensusClient(func() *rpcclient.ClientConfig { return &config }, stack)
+	// Until connected, the sequencer keeps its transactions queued
+	err := client.ExpectChosenSequencer()
+	require.True(t, errors.Is(err, execution.ErrRetrySequencer), err)
+	require.NoError(t, client.Start(ctx))
+	defer client.StopAndWait()
+	require.Eventually(t, client.connected.Load, 10*time.Second, 10*time.Millisecond)
+
+	batch, found, err := client.FindInboxBatchContainingMessage(42)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(21), batch)
+
+	// The sequencer retries on this error, so it has to survive the trip
+	err = client.ExpectChosenSequencer()
+	require.True(t, errors.Is(err, execution.ErrRetrySequencer), err)
+}
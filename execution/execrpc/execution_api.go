@@ -0,0 +1,103 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+)
+
+// ExecutionServerAPI serves an execution client to a consensus node in another process.
+type ExecutionServerAPI struct {
+	exec execution.FullExecutionClient
+}
+
+func NewExecutionServerAPI(exec execution.FullExecutionClient) *ExecutionServerAPI {
+	return &ExecutionServerAPI{exec}
+}
+
+func (a *ExecutionServerAPI) DigestMessage(ctx context.Context, num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	return a.exec.DigestMessage(num, msg, msgForPrefetch)
+}
+
+func (a *ExecutionServerAPI) Reorg(ctx context.Context, count arbutil.MessageIndex, newMessages []arbostypes.MessageWithMetadataAndBlockInfo, oldMessages []*arbostypes.MessageWithMetadata) ([]*execution.MessageResult, error) {
+	return a.exec.Reorg(count, newMessages, oldMessages)
+}
+
+func (a *ExecutionServerAPI) HeadMessageNumber(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.exec.HeadMessageNumber()
+}
+
+func (a *ExecutionServerAPI) ResultAtPos(ctx context.Context, pos arbutil.MessageIndex) (*execution.MessageResult, error) {
+	return a.exec.ResultAtPos(pos)
+}
+
+func (a *ExecutionServerAPI) MessageIndexToBlockNumber(ctx context.Context, messageNum arbutil.MessageIndex) (uint64, error) {
+	return a.exec.MessageIndexToBlockNumber(messageNum), nil
+}
+
+func (a *ExecutionServerAPI) BlockNumberToMessageIndex(ctx context.Context, blockNum uint64) (arbutil.MessageIndex, error) {
+	return a.exec.BlockNumberToMessageIndex(blockNum)
+}
+
+func (a *ExecutionServerAPI) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*RecordResultJson, error) {
+	result, err := a.exec.RecordBlockCreation(ctx, pos, msg)
+	if err != nil {
+		return nil, err
+	}
+	return RecordResultToJson(result), nil
+}
+
+func (a *ExecutionServerAPI) MarkValid(ctx context.Context, pos arbutil.MessageIndex, resultHash common.Hash) {
+	a.exec.MarkValid(pos, resultHash)
+}
+
+func (a *ExecutionServerAPI) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
+	return a.exec.PrepareForRecord(ctx, start, end)
+}
+
+func (a *ExecutionServerAPI) Pause(ctx context.Context) {
+	a.exec.Pause()
+}
+
+func (a *ExecutionServerAPI) Activate(ctx context.Context) {
+	a.exec.Activate()
+}
+
+func (a *ExecutionServerAPI) ForwardTo(ctx context.Context, url string) error {
+	return a.exec.ForwardTo(url)
+}
+
+func (a *ExecutionServerAPI) SequenceDelayedMessage(ctx context.Context, message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error {
+	return a.exec.SequenceDelayedMessage(message, delayedSeqNum)
+}
+
+func (a *ExecutionServerAPI) NextDelayedMessageNumber(ctx context.Context) (uint64, error) {
+	return a.exec.NextDelayedMessageNumber()
+}
+
+func (a *ExecutionServerAPI) MarkFeedStart(ctx context.Context, to arbutil.MessageIndex) {
+	a.exec.MarkFeedStart(to)
+}
+
+func (a *ExecutionServerAPI) Synced(ctx context.Context) bool {
+	return a.exec.Synced()
+}
+
+func (a *ExecutionServerAPI) FullSyncProgressMap(ctx context.Context) map[string]interface{} {
+	return a.exec.FullSyncProgressMap()
+}
+
+func (a *ExecutionServerAPI) Maintenance(ctx context.Context) error {
+	return a.exec.Maintenance()
+}
+
+func (a *ExecutionServerAPI) ArbOSVersionForMessageNumber(ctx context.Context, messageNum arbutil.MessageIndex) (uint64, error) {
+	return a.exec.ArbOSVersionForMessageNumber(messageNum)
+}
@@ -0,0 +1,186 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// ExecutionClient is an execution.FullExecutionClient served by an execution node in another process.
+type ExecutionClient struct {
+	stopwaiter.StopWaiter
+	client *rpcclient.RpcClient
+
+	genesisBlockNum uint64
+}
+
+var _ execution.FullExecutionClient = (*ExecutionClient)(nil)
+
+// NewExecutionClient connects to the execution node straight away, as the consensus node queries it
+// while being created.
+func NewExecutionClient(ctx context.Context, config rpcclient.ClientConfigFetcher, stack *node.Node) (*ExecutionClient, error) {
+	c := &ExecutionClient{
+		client: rpcclient.NewRpcClient(config, stack),
+	}
+	if err := c.client.Start(ctx); err != nil {
+		return nil, err
+	}
+	// Block numbers are offset from message indexes by the genesis block number, which never changes
+	if err := c.client.CallContext(ctx, &c.genesisBlockNum, ExecutionNamespace+"_messageIndexToBlockNumber", arbutil.MessageIndex(0)); err != nil {
+		c.client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *ExecutionClient) Start(ctx context.Context) error {
+	c.StopWaiter.Start(ctx, c)
+	return nil
+}
+
+func (c *ExecutionClient) StopAndWait() {
+	c.StopWaiter.StopAndWait()
+	c.client.Close()
+}
+
+func (c *ExecutionClient) call(result interface{}, method string, args ...interface{}) error {
+	ctx, err := c.GetContextSafe()
+	if err != nil {
+		// Not started yet
+		ctx = context.Background()
+	}
+	return c.client.CallContext(ctx, result, ExecutionNamespace+"_"+method, args...)
+}
+
+func (c *ExecutionClient) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	var res execution.MessageResult
+	if err := c.call(&res, "digestMessage", num, msg, msgForPrefetch); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *ExecutionClient) Reorg(count arbutil.MessageIndex, newMessages []arbostypes.MessageWithMetadataAndBlockInfo, oldMessages []*arbostypes.MessageWithMetadata) ([]*execution.MessageResult, error) {
+	var res []*execution.MessageResult
+	if err := c.call(&res, "reorg", count, newMessages, oldMessages); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *ExecutionClient) HeadMessageNumber() (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(&res, "headMessageNumber")
+	return res, err
+}
+
+func (c *ExecutionClient) HeadMessageNumberSync(t *testing.T) (arbutil.MessageIndex, error) {
+	return c.HeadMessageNumber()
+}
+
+func (c *ExecutionClient) ResultAtPos(pos arbutil.MessageIndex) (*execution.MessageResult, error) {
+	var res execution.MessageResult
+	if err := c.call(&res, "resultAtPos", pos); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *ExecutionClient) MessageIndexToBlockNumber(messageNum arbutil.MessageIndex) uint64 {
+	return uint64(messageNum) + c.genesisBlockNum
+}
+
+func (c *ExecutionClient) BlockNumberToMessageIndex(blockNum uint64) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(&res, "blockNumberToMessageIndex", blockNum)
+	return res, err
+}
+
+func (c *ExecutionClient) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	var res RecordResultJson
+	if err := c.client.CallContext(ctx, &res, ExecutionNamespace+"_recordBlockCreation", pos, msg); err != nil {
+		return nil, err
+	}
+	return RecordResultFromJson(&res), nil
+}
+
+func (c *ExecutionClient) MarkValid(pos arbutil.MessageIndex, resultHash common.Hash) {
+	if err := c.call(nil, "markValid", pos, resultHash); err != nil {
+		log.Warn("failed to mark block valid on execution node", "pos", pos, "err", err)
+	}
+}
+
+func (c *ExecutionClient) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
+	return c.client.CallContext(ctx, nil, ExecutionNamespace+"_prepareForRecord", start, end)
+}
+
+func (c *ExecutionClient) Pause() {
+	if err := c.call(nil, "pause"); err != nil {
+		log.Error("failed to pause execution node sequencer", "err", err)
+	}
+}
+
+func (c *ExecutionClient) Activate() {
+	if err := c.call(nil, "activate"); err != nil {
+		log.Error("failed to activate execution node sequencer", "err", err)
+	}
+}
+
+func (c *ExecutionClient) ForwardTo(url string) error {
+	return c.call(nil, "forwardTo", url)
+}
+
+func (c *ExecutionClient) SequenceDelayedMessage(message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error {
+	return c.call(nil, "sequenceDelayedMessage", message, delayedSeqNum)
+}
+
+func (c *ExecutionClient) NextDelayedMessageNumber() (uint64, error) {
+	var res uint64
+	err := c.call(&res, "nextDelayedMessageNumber")
+	return res, err
+}
+
+func (c *ExecutionClient) MarkFeedStart(to arbutil.MessageIndex) {
+	if err := c.call(nil, "markFeedStart", to); err != nil {
+		log.Warn("failed to mark feed start on execution node", "to", to, "err", err)
+	}
+}
+
+func (c *ExecutionClient) Synced() bool {
+	var res bool
+	if err := c.call(&res, "synced"); err != nil {
+		log.Warn("failed to get execution node sync status", "err", err)
+		return false
+	}
+	return res
+}
+
+func (c *ExecutionClient) FullSyncProgressMap() map[string]interface{} {
+	var res map[string]interface{}
+	if err := c.call(&res, "fullSyncProgressMap"); err != nil {
+		return map[string]interface{}{"executionRpcError": err.Error()}
+	}
+	return res
+}
+
+func (c *ExecutionClient) Maintenance() error {
+	return c.call(nil, "maintenance")
+}
+
+func (c *ExecutionClient) ArbOSVersionForMessageNumber(messageNum arbutil.MessageIndex) (uint64, error) {
+	var res uint64
+	err := c.call(&res, "arbOSVersionForMessageNumber", messageNum)
+	return res, err
+}
@@ -0,0 +1,70 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package execrpc carries the execution and consensus client interfaces over JSON-RPC, so the
+// execution client and the consensus node can run in separate processes.
+package execrpc
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/jsonapi"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+)
+
+const (
+	ExecutionNamespace string = "execution"
+	ConsensusNamespace string = "consensus"
+)
+
+// DefaultClientConfig leaves the url empty, which keeps the other side in-process. The consensus and
+// execution processes are usually started together, so each waits for the other to come up.
+var DefaultClientConfig = func() rpcclient.ClientConfig {
+	config := rpcclient.DefaultClientConfig
+	config.URL = ""
+	config.ConnectionWait = time.Minute
+	return config
+}()
+
+// EnsureExposedViaAuthRPC serves the namespace on the stack's authenticated RPC, where the other process
+// connects to it.
+func EnsureExposedViaAuthRPC(stackConf *node.Config, namespace string) {
+	if !slices.Contains(stackConf.AuthModules, namespace) {
+		stackConf.AuthModules = append(stackConf.AuthModules, namespace)
+	}
+}
+
+type RecordResultJson struct {
+	Pos       arbutil.MessageIndex
+	BlockHash common.Hash
+	Preimages *jsonapi.PreimagesMapJson
+	UserWasms state.UserWasms
+}
+
+func RecordResultToJson(result *execution.RecordResult) *RecordResultJson {
+	return &RecordResultJson{
+		Pos:       result.Pos,
+		BlockHash: result.BlockHash,
+		Preimages: jsonapi.NewPreimagesMapJson(result.Preimages),
+		UserWasms: result.UserWasms,
+	}
+}
+
+func RecordResultFromJson(result *RecordResultJson) *execution.RecordResult {
+	res := &execution.RecordResult{
+		Pos:       result.Pos,
+		BlockHash: result.BlockHash,
+		UserWasms: result.UserWasms,
+	}
+	if result.Preimages != nil {
+		res.Preimages = result.Preimages.Map
+	}
+	return res
+}
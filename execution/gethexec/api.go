@@ -25,17 +25,22 @@ import (
 	"github.com/offchainlabs/nitro/arbos/retryables"
 	"github.com/offchainlabs/nitro/timeboost"
 	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 type ArbAPI struct {
 	txPublisher              TransactionPublisher
 	bulkBlockMetadataFetcher *BulkBlockMetadataFetcher
+	txStatusFetcher          *TxStatusFetcher
+	pricingHistory           *PricingHistory
 }
 
-func NewArbAPI(publisher TransactionPublisher, bulkBlockMetadataFetcher *BulkBlockMetadataFetcher) *ArbAPI {
+func NewArbAPI(publisher TransactionPublisher, bulkBlockMetadataFetcher *BulkBlockMetadataFetcher, txStatusFetcher *TxStatusFetcher, pricingHistory *PricingHistory) *ArbAPI {
 	return &ArbAPI{
 		txPublisher:              publisher,
 		bulkBlockMetadataFetcher: bulkBlockMetadataFetcher,
+		txStatusFetcher:          txStatusFetcher,
+		pricingHistory:           pricingHistory,
 	}
 }
 
@@ -55,6 +60,209 @@ func (a *ArbAPI) GetRawBlockMetadata(ctx context.Context, fromBlock, toBlock rpc
 	return a.bulkBlockMetadataFetcher.Fetch(fromBlock, toBlock)
 }
 
+// SendBundle sequences the transactions contiguously in one block, or none of them, and returns their hashes.
+func (a *ArbAPI) SendBundle(ctx context.Context, args SendBundleArgs) ([]common.Hash, error) {
+	bundle, err := args.ToBundle()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.txPublisher.PublishBundle(ctx, bundle); err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(bundle.Transactions))
+	for i, tx := range bundle.Transactions {
+		hashes[i] = tx.Hash()
+	}
+	return hashes, nil
+}
+
+// GetTransactionStatus reports how far a transaction has got, from the sequencer's queue to being confirmed on the parent chain.
+func (a *ArbAPI) GetTransactionStatus(ctx context.Context, txHash common.Hash) (*TransactionStatus, error) {
+	if a.txStatusFetcher == nil {
+		return nil, errors.New("arb_getTransactionStatus is not available")
+	}
+	return a.txStatusFetcher.Fetch(txHash)
+}
+
+// SendRawTransactionConditional is like eth_sendRawTransactionConditional, but also accepts a validUntil
+// deadline and a maxL1DataFee, which are checked when the transaction is sequenced.
+func (a *ArbAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, options ExtendedConditionalOptions) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), a.txPublisher.PublishConditionalTransaction(ctx, tx, &options)
+}
+
+// SendRawTransactionWithPreconf sequences the transaction and returns the sequencer's signed
+// promise of where it was sequenced. It accepts the same options as arb_sendRawTransactionConditional.
+func (a *ArbAPI) SendRawTransactionWithPreconf(ctx context.Context, input hexutil.Bytes, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return nil, err
+	}
+	return a.txPublisher.PublishTransactionWithPreconf(ctx, tx, options)
+}
+
+// CheckPreconf checks whether a preconf was honored by the batch posted to the parent chain,
+// and if not returns evidence of the violation. Until the batch is posted, it reports preconfs
+// the feed contradicts as conflicting.
+func (a *ArbAPI) CheckPreconf(ctx context.Context, preconf signature.Preconf) (*PreconfCheckResult, error) {
+	if a.txStatusFetcher == nil {
+		return nil, errors.New("arb_checkPreconf is not available")
+	}
+	return a.txStatusFetcher.CheckPreconf(ctx, &preconf)
+}
+
+// PricingHistory returns the L1 and L2 pricing state after each block in the range, from the index
+// kept as blocks are appended. Blocks from before the index was enabled are left out.
+func (a *ArbAPI) PricingHistory(ctx context.Context, fromBlock, toBlock rpc.BlockNumber) ([]PricingHistoryEntry, error) {
+	if a.pricingHistory == nil {
+		return nil, ErrPricingHistoryDisabled
+	}
+	return a.pricingHistory.Fetch(fromBlock, toBlock)
+}
+
+type BlockMetadataAPI struct {
+	blockchain  *core.BlockChain
+	fetcher     BlockMetadataFetcher
+	indexer     *BlockMetadataIndexer
+	roundTiming *roundTimingFetcher
+}
+
+func NewBlockMetadataAPI(blockchain *core.BlockChain, fetcher BlockMetadataFetcher, indexer *BlockMetadataIndexer, roundTiming *roundTimingFetcher) *BlockMetadataAPI {
+	return &BlockMetadataAPI{blockchain, fetcher, indexer, roundTiming}
+}
+
+type TimeboostedTransaction struct {
+	Index uint64      `json:"index"`
+	Hash  common.Hash `json:"hash"`
+}
+
+type BlockTimeboostedTransactions struct {
+	BlockNumber  uint64                   `json:"blockNumber"`
+	BlockHash    common.Hash              `json:"blockHash"`
+	Transactions []TimeboostedTransaction `json:"transactions"`
+}
+
+type TimeboostedBlocksResult struct {
+	// IndexedTo is the last indexed block, blocks after it aren't included yet
+	IndexedTo uint64             `json:"indexedTo"`
+	Blocks    []TimeboostedBlock `json:"blocks"`
+}
+
+type RoundStatistics struct {
+	Round             uint64 `json:"round"`
+	StartTimestamp    uint64 `json:"startTimestamp"`
+	EndTimestamp      uint64 `json:"endTimestamp"`
+	FromBlock         uint64 `json:"fromBlock,omitempty"`
+	ToBlock           uint64 `json:"toBlock,omitempty"`
+	Blocks            uint64 `json:"blocks"`
+	TimeboostedBlocks uint64 `json:"timeboostedBlocks"`
+	TimeboostedTxs    uint64 `json:"timeboostedTxs"`
+	Complete          bool   `json:"complete"`
+}
+
+// GetTimeboostedTransactions returns the transactions of the block that were sequenced through the express lane,
+// read directly from the block metadata.
+func (a *BlockMetadataAPI) GetTimeboostedTransactions(ctx context.Context, number rpc.BlockNumber) (*BlockTimeboostedTransactions, error) {
+	number, _ = a.blockchain.ClipToPostNitroGenesis(number)
+	// #nosec G115
+	block := a.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	msgIdx, err := a.fetcher.BlockNumberToMessageIndex(block.NumberU64())
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := a.fetcher.BlockMetadataAtCount(msgIdx + 1)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, fmt.Errorf("no block metadata for block %d", block.NumberU64())
+	}
+	indices, err := TimeboostedTxIndices(metadata)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	result := &BlockTimeboostedTransactions{
+		BlockNumber:  block.NumberU64(),
+		BlockHash:    block.Hash(),
+		Transactions: []TimeboostedTransaction{},
+	}
+	for _, index := range indices {
+		if index >= uint64(len(txs)) {
+			return nil, fmt.Errorf("block metadata of block %d marks tx %d as timeboosted but the block has %d txs", block.NumberU64(), index, len(txs))
+		}
+		result.Transactions = append(result.Transactions, TimeboostedTransaction{Index: index, Hash: txs[index].Hash()})
+	}
+	return result, nil
+}
+
+// GetTimeboostedBlocks returns the blocks in the range that have timeboosted transactions.
+func (a *BlockMetadataAPI) GetTimeboostedBlocks(ctx context.Context, fromBlock, toBlock rpc.BlockNumber) (*TimeboostedBlocksResult, error) {
+	if a.indexer == nil {
+		return nil, ErrBlockMetadataIndexDisabled
+	}
+	fromBlock, _ = a.blockchain.ClipToPostNitroGenesis(fromBlock)
+	toBlock, _ = a.blockchain.ClipToPostNitroGenesis(toBlock)
+	indexedTo, _, err := a.indexer.IndexedTo()
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G115
+	blocks, err := a.indexer.TimeboostedBlocks(uint64(fromBlock), uint64(toBlock))
+	if err != nil {
+		return nil, err
+	}
+	if blocks == nil {
+		blocks = []TimeboostedBlock{}
+	}
+	return &TimeboostedBlocksResult{IndexedTo: indexedTo, Blocks: blocks}, nil
+}
+
+// GetRoundStatistics returns how many blocks and transactions were timeboosted during an express lane auction round.
+func (a *BlockMetadataAPI) GetRoundStatistics(ctx context.Context, round hexutil.Uint64) (*RoundStatistics, error) {
+	if a.indexer == nil {
+		return nil, ErrBlockMetadataIndexDisabled
+	}
+	info, err := a.roundTiming.RoundTimingInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting round timing info: %w", err)
+	}
+	start := info.Offset.Add(info.Round * arbmath.SaturatingCast[time.Duration](uint64(round)))
+	end := start.Add(info.Round)
+	stats := &RoundStatistics{
+		Round: uint64(round),
+		// #nosec G115
+		StartTimestamp: uint64(start.Unix()),
+		// #nosec G115
+		EndTimestamp: uint64(end.Unix()),
+	}
+	from, to, ok := a.indexer.RoundBlocks(info, uint64(round))
+	indexedTo, indexed, err := a.indexer.IndexedTo()
+	if err != nil {
+		return nil, err
+	}
+	stats.Complete = time.Now().After(end) && indexed && (!ok || indexedTo >= to)
+	if !ok {
+		return stats, nil
+	}
+	stats.FromBlock, stats.ToBlock, stats.Blocks = from, to, to-from+1
+	blocks, err := a.indexer.TimeboostedBlocks(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		stats.TimeboostedBlocks++
+		stats.TimeboostedTxs += uint64(len(block.TimeboostedTxs))
+	}
+	return stats, nil
+}
+
 type ArbTimeboostAuctioneerAPI struct {
 	txPublisher TransactionPublisher
 }
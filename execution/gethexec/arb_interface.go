@@ -11,12 +11,16 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/offchainlabs/nitro/timeboost"
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 type TransactionPublisher interface {
 	PublishAuctionResolutionTransaction(ctx context.Context, tx *types.Transaction) error
 	PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error
 	PublishTransaction(ctx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions) error
+	PublishConditionalTransaction(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error
+	PublishBundle(ctx context.Context, bundle *Bundle) error
+	PublishTransactionWithPreconf(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error)
 	CheckHealth(ctx context.Context) error
 	Initialize(context.Context) error
 	Start(context.Context) error
@@ -147,6 +147,13 @@ func (r *BlockRecorder) RecordBlockCreation(
 		if genesisNum != expectedNum {
 			return nil, fmt.Errorf("unexpected genesis block number %v in ArbOS state, expected %v", genesisNum, expectedNum)
 		}
+		// The replay binary reads it to parse batches using the newer compression formats
+		if initialArbosState.ArbOSVersion() >= arbosState.ArbosVersion_BatchCompression {
+			_, err = initialArbosState.BatchCompressionBlock()
+			if err != nil {
+				return nil, fmt.Errorf("error getting batch compression block from initial ArbOS state: %w", err)
+			}
+		}
 	}
 
 	var blockHash common.Hash
@@ -0,0 +1,425 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/arbitrum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/solgen/go/express_lane_auctiongen"
+	"github.com/offchainlabs/nitro/timeboost"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/dbutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	blockMetadataIndexPrefix  = []byte("\x00tbidx-b") // block number -> indexedTimeboostedBlock, only for blocks with timeboosted txs
+	blockMetadataIndexHeadKey = []byte("\x00tbidx-head")
+
+	ErrBlockMetadataIndexDisabled = errors.New("block metadata index is not enabled")
+)
+
+type BlockMetadataIndexConfig struct {
+	Enable                 bool          `koanf:"enable"`
+	StartBlock             uint64        `koanf:"start-block"`
+	UpdateInterval         time.Duration `koanf:"update-interval" reload:"hot"`
+	BlocksPerUpdate        uint64        `koanf:"blocks-per-update" reload:"hot"`
+	MissingMetadataWarning time.Duration `koanf:"missing-metadata-warning" reload:"hot"`
+	MaxQueryRange          uint64        `koanf:"max-query-range" reload:"hot"`
+	AuctionContractAddress string        `koanf:"auction-contract-address"`
+}
+
+var DefaultBlockMetadataIndexConfig = BlockMetadataIndexConfig{
+	Enable:                 false,
+	StartBlock:             0,
+	UpdateInterval:         time.Second,
+	BlocksPerUpdate:        10_000,
+	MissingMetadataWarning: time.Minute,
+	MaxQueryRange:          100_000,
+	AuctionContractAddress: "",
+}
+
+func BlockMetadataIndexConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultBlockMetadataIndexConfig.Enable, "index the timeboosted transactions of each block and serve them over the blockmetadata RPC namespace")
+	f.Uint64(prefix+".start-block", DefaultBlockMetadataIndexConfig.StartBlock, "block to start indexing from, usually the block from which block metadata is tracked")
+	f.Duration(prefix+".update-interval", DefaultBlockMetadataIndexConfig.UpdateInterval, "how often to index new blocks")
+	f.Uint64(prefix+".blocks-per-update", DefaultBlockMetadataIndexConfig.BlocksPerUpdate, "maximum number of blocks to index per update")
+	f.Duration(prefix+".missing-metadata-warning", DefaultBlockMetadataIndexConfig.MissingMetadataWarning, "how long the block metadata of a block can be missing before warning that indexing is waiting for it")
+	f.Uint64(prefix+".max-query-range", DefaultBlockMetadataIndexConfig.MaxQueryRange, "maximum number of blocks that can be queried at once (0 = unlimited)")
+	f.String(prefix+".auction-contract-address", DefaultBlockMetadataIndexConfig.AuctionContractAddress, "address of the express lane auction contract, to read the round timing from for per round statistics")
+}
+
+func (c *BlockMetadataIndexConfig) Validate() error {
+	if len(c.AuctionContractAddress) > 0 && !common.IsHexAddress(c.AuctionContractAddress) {
+		return fmt.Errorf("invalid block-metadata-index.auction-contract-address \"%v\"", c.AuctionContractAddress)
+	}
+	return nil
+}
+
+type BlockMetadataIndexConfigFetcher func() *BlockMetadataIndexConfig
+
+// blockMetadataIndexChain is the part of core.BlockChain the indexer reads from.
+type blockMetadataIndexChain interface {
+	CurrentBlock() *types.Header
+	GetCanonicalHash(number uint64) common.Hash
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
+type indexedTimeboostedBlock struct {
+	Hash           common.Hash
+	TimeboostedTxs []uint64
+	// The previous indexed block with timeboosted txs, nil if there's none
+	Prev *uint64 `rlp:"optional"`
+}
+
+type blockMetadataIndexHead struct {
+	Number uint64
+	Hash   common.Hash
+	// The last indexed block with timeboosted txs, nil if there's none
+	LastEntry *uint64 `rlp:"optional"`
+}
+
+// TimeboostedTxIndices returns the indices of the transactions the block metadata marks as timeboosted.
+func TimeboostedTxIndices(metadata common.BlockMetadata) ([]uint64, error) {
+	var indices []uint64
+	if len(metadata) < 2 {
+		return nil, nil
+	}
+	for txIndex := 0; txIndex < 8*(len(metadata)-1); txIndex++ {
+		timeboosted, err := metadata.IsTxTimeboosted(txIndex)
+		if err != nil {
+			return nil, err
+		}
+		if timeboosted {
+			indices = append(indices, uint64(txIndex))
+		}
+	}
+	return indices, nil
+}
+
+// BlockMetadataIndexer keeps an index of the blocks with timeboosted transactions, so that ranges of blocks
+// can be queried without reading the block metadata of every block.
+type BlockMetadataIndexer struct {
+	stopwaiter.StopWaiter
+	config  BlockMetadataIndexConfigFetcher
+	db      ethdb.Database
+	chain   blockMetadataIndexChain
+	fetcher BlockMetadataFetcher
+
+	// the indexer thread is the only writer, the mutex keeps readers from seeing a partially rewound index
+	mutex sync.RWMutex
+	// the last block warned about for missing block metadata, only used by the indexer thread
+	warnedMissing *uint64
+}
+
+func NewBlockMetadataIndexer(config BlockMetadataIndexConfigFetcher, db ethdb.Database, chain blockMetadataIndexChain, fetcher BlockMetadataFetcher) *BlockMetadataIndexer {
+	return &BlockMetadataIndexer{
+		config:  config,
+		db:      db,
+		chain:   chain,
+		fetcher: fetcher,
+	}
+}
+
+func blockMetadataIndexKey(blockNum uint64) []byte {
+	key := make([]byte, 0, len(blockMetadataIndexPrefix)+8)
+	key = append(key, blockMetadataIndexPrefix...)
+	return binary.BigEndian.AppendUint64(key, blockNum)
+}
+
+func (x *BlockMetadataIndexer) readHead() (*blockMetadataIndexHead, error) {
+	data, err := x.db.Get(blockMetadataIndexHeadKey)
+	if dbutil.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var head blockMetadataIndexHead
+	if err := rlp.DecodeBytes(data, &head); err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+func writeBlockMetadataIndexHead(batch ethdb.KeyValueWriter, head *blockMetadataIndexHead) error {
+	if head == nil {
+		return batch.Delete(blockMetadataIndexHeadKey)
+	}
+	data, err := rlp.EncodeToBytes(head)
+	if err != nil {
+		return err
+	}
+	return batch.Put(blockMetadataIndexHeadKey, data)
+}
+
+func (x *BlockMetadataIndexer) readEntry(blockNum uint64) (*indexedTimeboostedBlock, error) {
+	data, err := x.db.Get(blockMetadataIndexKey(blockNum))
+	if dbutil.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry indexedTimeboostedBlock
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// rewind handles reorgs by removing the indexed blocks that are no longer canonical. Blocks are reindexed
+// from the last indexed block that's still canonical, as every block before it must be canonical too.
+// The indexed blocks are linked to their predecessors, so only the indexed blocks are visited.
+func (x *BlockMetadataIndexer) rewind(head *blockMetadataIndexHead) (*blockMetadataIndexHead, error) {
+	start := x.config().StartBlock
+	batch := x.db.NewBatch()
+	var newHead *blockMetadataIndexHead
+	for prev := head.LastEntry; prev != nil && *prev >= start; {
+		blockNum := *prev
+		entry, err := x.readEntry(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, fmt.Errorf("missing indexed block %d", blockNum)
+		}
+		if canonical := x.chain.GetCanonicalHash(blockNum); canonical == entry.Hash {
+			newHead = &blockMetadataIndexHead{Number: blockNum, Hash: canonical, LastEntry: prev}
+			break
+		}
+		if err := batch.Delete(blockMetadataIndexKey(blockNum)); err != nil {
+			return nil, err
+		}
+		prev = entry.Prev
+	}
+	if err := writeBlockMetadataIndexHead(batch, newHead); err != nil {
+		return nil, err
+	}
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return newHead, batch.Write()
+}
+
+func (x *BlockMetadataIndexer) indexNewBlocks() error {
+	config := x.config()
+	head, err := x.readHead()
+	if err != nil {
+		return err
+	}
+	if head != nil && x.chain.GetCanonicalHash(head.Number) != head.Hash {
+		log.Info("Block metadata index head is no longer canonical, rewinding", "block", head.Number, "hash", head.Hash)
+		head, err = x.rewind(head)
+		if err != nil {
+			return fmt.Errorf("error rewinding block metadata index: %w", err)
+		}
+	}
+	next := config.StartBlock
+	var lastEntry *uint64
+	if head != nil {
+		next = head.Number + 1
+		lastEntry = head.LastEntry
+	}
+	current := x.chain.CurrentBlock()
+	if current == nil {
+		return nil
+	}
+	last := current.Number.Uint64()
+	if config.BlocksPerUpdate > 0 && last >= next+config.BlocksPerUpdate {
+		last = next + config.BlocksPerUpdate - 1
+	}
+
+	genesis := x.fetcher.MessageIndexToBlockNumber(0)
+	batch := x.db.NewBatch()
+	var newHead *blockMetadataIndexHead
+	for blockNum := next; blockNum <= last; blockNum++ {
+		header := x.chain.GetHeaderByNumber(blockNum)
+		if header == nil {
+			break
+		}
+		if blockNum < genesis {
+			// Blocks before the nitro genesis have no block metadata
+			newHead = &blockMetadataIndexHead{Number: blockNum, Hash: header.Hash(), LastEntry: lastEntry}
+			continue
+		}
+		msgIdx, err := x.fetcher.BlockNumberToMessageIndex(blockNum)
+		if err != nil {
+			return err
+		}
+		metadata, err := x.fetcher.BlockMetadataAtCount(msgIdx + 1)
+		if err != nil {
+			return err
+		}
+		if metadata == nil {
+			// Indexing stops until the metadata arrives, as indexing the block without it would
+			// permanently record it as having no timeboosted txs
+			// #nosec G115
+			age := time.Since(time.Unix(int64(header.Time), 0))
+			if age >= config.MissingMetadataWarning && (x.warnedMissing == nil || *x.warnedMissing != blockNum) {
+				log.Warn("Block metadata index waiting for missing block metadata", "block", blockNum, "age", age)
+				x.warnedMissing = &blockNum
+			}
+			break
+		}
+		txs, err := TimeboostedTxIndices(metadata)
+		if err != nil {
+			return fmt.Errorf("error decoding block metadata of block %d: %w", blockNum, err)
+		}
+		if len(txs) > 0 {
+			data, err := rlp.EncodeToBytes(&indexedTimeboostedBlock{Hash: header.Hash(), TimeboostedTxs: txs, Prev: lastEntry})
+			if err != nil {
+				return err
+			}
+			if err := batch.Put(blockMetadataIndexKey(blockNum), data); err != nil {
+				return err
+			}
+			entryNum := blockNum
+			lastEntry = &entryNum
+		}
+		newHead = &blockMetadataIndexHead{Number: blockNum, Hash: header.Hash(), LastEntry: lastEntry}
+	}
+	if newHead == nil {
+		return nil
+	}
+	if err := writeBlockMetadataIndexHead(batch, newHead); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (x *BlockMetadataIndexer) update(ctx context.Context) time.Duration {
+	if err := x.indexNewBlocks(); err != nil {
+		log.Error("Error indexing block metadata", "err", err)
+	}
+	return x.config().UpdateInterval
+}
+
+func (x *BlockMetadataIndexer) Start(ctx context.Context) {
+	x.StopWaiter.Start(ctx, x)
+	x.CallIteratively(x.update)
+}
+
+// TimeboostedBlock is a block with timeboosted transactions.
+type TimeboostedBlock struct {
+	BlockNumber    uint64      `json:"blockNumber"`
+	BlockHash      common.Hash `json:"blockHash"`
+	TimeboostedTxs []uint64    `json:"timeboostedTxs"`
+}
+
+// IndexedTo returns the last indexed block, and false if no block has been indexed yet.
+func (x *BlockMetadataIndexer) IndexedTo() (uint64, bool, error) {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+	head, err := x.readHead()
+	if err != nil || head == nil {
+		return 0, false, err
+	}
+	return head.Number, true, nil
+}
+
+// TimeboostedBlocks returns the indexed blocks with timeboosted transactions in [from, to].
+func (x *BlockMetadataIndexer) TimeboostedBlocks(from, to uint64) ([]TimeboostedBlock, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid inputs, fromBlock: %d is greater than toBlock: %d", from, to)
+	}
+	if limit := x.config().MaxQueryRange; limit > 0 && to-from+1 > limit {
+		return nil, fmt.Errorf("number of blocks requested exceeded. Range requested- %d, Limit- %d", to-from+1, limit)
+	}
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+	iter := x.db.NewIterator(blockMetadataIndexPrefix, binary.BigEndian.AppendUint64(nil, from))
+	defer iter.Release()
+	var blocks []TimeboostedBlock
+	for iter.Next() {
+		blockNum := binary.BigEndian.Uint64(iter.Key()[len(blockMetadataIndexPrefix):])
+		if blockNum > to {
+			break
+		}
+		var entry indexedTimeboostedBlock
+		if err := rlp.DecodeBytes(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, TimeboostedBlock{
+			BlockNumber:    blockNum,
+			BlockHash:      entry.Hash,
+			TimeboostedTxs: entry.TimeboostedTxs,
+		})
+	}
+	return blocks, iter.Error()
+}
+
+// firstBlockAtOrAfter returns the first block with a timestamp at or after t, in [from, to+1].
+func (x *BlockMetadataIndexer) firstBlockAtOrAfter(t time.Time, from, to uint64) uint64 {
+	// #nosec G115
+	return from + uint64(sort.Search(int(to-from+1), func(i int) bool {
+		header := x.chain.GetHeaderByNumber(from + uint64(i))
+		// #nosec G115
+		return header == nil || !time.Unix(int64(header.Time), 0).Before(t)
+	}))
+}
+
+// RoundBlocks returns the range of blocks [from, to] produced during the given auction round,
+// with ok false if the round has no blocks.
+func (x *BlockMetadataIndexer) RoundBlocks(info *timeboost.RoundTimingInfo, round uint64) (uint64, uint64, bool) {
+	current := x.chain.CurrentBlock()
+	if current == nil {
+		return 0, 0, false
+	}
+	roundStart := info.Offset.Add(info.Round * arbmath.SaturatingCast[time.Duration](round))
+	start := x.config().StartBlock
+	last := current.Number.Uint64()
+	from := x.firstBlockAtOrAfter(roundStart, start, last)
+	end := x.firstBlockAtOrAfter(roundStart.Add(info.Round), from, last)
+	if end == from {
+		return 0, 0, false
+	}
+	return from, end - 1, true
+}
+
+// roundTimingFetcher lazily reads the round timing from the express lane auction contract.
+type roundTimingFetcher struct {
+	mutex        sync.Mutex
+	auctionAddr  common.Address
+	filterSystem *filters.FilterSystem
+	apiBackend   *arbitrum.APIBackend
+	info         *timeboost.RoundTimingInfo
+}
+
+func (f *roundTimingFetcher) RoundTimingInfo(ctx context.Context) (*timeboost.RoundTimingInfo, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.info != nil {
+		return f.info, nil
+	}
+	if f.auctionAddr == (common.Address{}) {
+		return nil, errors.New("block-metadata-index.auction-contract-address is not set")
+	}
+	auctionContract, err := express_lane_auctiongen.NewExpressLaneAuction(f.auctionAddr, &contractAdapter{filters.NewFilterAPI(f.filterSystem), nil, f.apiBackend})
+	if err != nil {
+		return nil, err
+	}
+	rawRoundTimingInfo, err := auctionContract.RoundTimingInfo(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	f.info, err = timeboost.NewRoundTimingInfo(rawRoundTimingInfo)
+	return f.info, err
+}
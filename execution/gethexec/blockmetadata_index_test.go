@@ -0,0 +1,182 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/timeboost"
+)
+
+type testIndexChain struct {
+	headers  []*types.Header
+	metadata []common.BlockMetadata
+}
+
+func (c *testIndexChain) addBlock(metadata common.BlockMetadata, extra byte) {
+	// #nosec G115
+	c.headers = append(c.headers, &types.Header{
+		Number: big.NewInt(int64(len(c.headers))),
+		Time:   uint64(len(c.headers)) * 10,
+		Extra:  []byte{extra},
+	})
+	c.metadata = append(c.metadata, metadata)
+}
+
+func (c *testIndexChain) reorg(blockNum uint64) {
+	c.headers = c.headers[:blockNum]
+	c.metadata = c.metadata[:blockNum]
+}
+
+func (c *testIndexChain) CurrentBlock() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *testIndexChain) GetCanonicalHash(number uint64) common.Hash {
+	if number >= uint64(len(c.headers)) {
+		return common.Hash{}
+	}
+	return c.headers[number].Hash()
+}
+
+func (c *testIndexChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+func (c *testIndexChain) BlockMetadataAtCount(count arbutil.MessageIndex) (common.BlockMetadata, error) {
+	return c.metadata[count-1], nil
+}
+
+func (c *testIndexChain) BlockNumberToMessageIndex(blockNum uint64) (arbutil.MessageIndex, error) {
+	return arbutil.MessageIndex(blockNum), nil
+}
+
+func (c *testIndexChain) MessageIndexToBlockNumber(messageNum arbutil.MessageIndex) uint64 {
+	return uint64(messageNum)
+}
+
+func (c *testIndexChain) SetReorgEventsNotifier(chan struct{}) {}
+
+func TestTimeboostedTxIndices(t *testing.T) {
+	indices, err := TimeboostedTxIndices(common.BlockMetadata{0, 86, 145})
+	require.NoError(t, err)
+	if !reflect.DeepEqual(indices, []uint64{1, 2, 4, 6, 8, 12, 15}) {
+		t.Fatal("unexpected timeboosted tx indices", indices)
+	}
+	indices, err = TimeboostedTxIndices(nil)
+	require.NoError(t, err)
+	if len(indices) != 0 {
+		t.Fatal("expected no timeboosted txs without metadata", indices)
+	}
+}
+
+func TestBlockMetadataIndexer(t *testing.T) {
+	chain := &testIndexChain{}
+	for i := 0; i < 10; i++ {
+		var metadata common.BlockMetadata = []byte{0, 0}
+		if i%3 == 0 {
+			metadata = []byte{0, 2}
+		}
+		chain.addBlock(metadata, 0)
+	}
+	config := DefaultBlockMetadataIndexConfig
+	indexer := NewBlockMetadataIndexer(func() *BlockMetadataIndexConfig { return &config }, rawdb.NewMemoryDatabase(), chain, chain)
+
+	require.NoError(t, indexer.indexNewBlocks())
+	indexedTo, ok, err := indexer.IndexedTo()
+	require.NoError(t, err)
+	if !ok || indexedTo != 9 {
+		t.Fatal("unexpected indexed head", indexedTo, ok)
+	}
+	blocks, err := indexer.TimeboostedBlocks(1, 9)
+	require.NoError(t, err)
+	if len(blocks) != 3 || blocks[0].BlockNumber != 3 || blocks[2].BlockNumber != 9 || !reflect.DeepEqual(blocks[0].TimeboostedTxs, []uint64{1}) {
+		t.Fatal("unexpected timeboosted blocks", blocks)
+	}
+
+	// Reorg out blocks 7 to 9, with the new block 8 having timeboosted txs and the new block 9 not
+	chain.reorg(7)
+	chain.addBlock([]byte{0, 0}, 1)
+	chain.addBlock([]byte{0, 6}, 1)
+	chain.addBlock([]byte{0, 0}, 1)
+	require.NoError(t, indexer.indexNewBlocks())
+	blocks, err = indexer.TimeboostedBlocks(0, 9)
+	require.NoError(t, err)
+	if len(blocks) != 4 || blocks[3].BlockNumber != 8 || !reflect.DeepEqual(blocks[3].TimeboostedTxs, []uint64{1, 2}) {
+		t.Fatal("unexpected timeboosted blocks after reorg", blocks)
+	}
+
+	// Reorg out every indexed block but the first, rewinding through the links between indexed blocks
+	chain.reorg(2)
+	chain.addBlock([]byte{0, 0}, 2)
+	chain.addBlock([]byte{0, 0}, 2)
+	chain.addBlock([]byte{0, 2}, 2)
+	chain.addBlock([]byte{0, 0}, 2)
+	chain.addBlock([]byte{0, 0}, 2)
+	chain.addBlock([]byte{0, 2}, 2)
+	chain.addBlock([]byte{0, 0}, 2)
+	chain.addBlock([]byte{0, 0}, 2)
+	require.NoError(t, indexer.indexNewBlocks())
+	blocks, err = indexer.TimeboostedBlocks(0, 9)
+	require.NoError(t, err)
+	if len(blocks) != 3 || blocks[0].BlockNumber != 0 || blocks[1].BlockNumber != 4 || blocks[2].BlockNumber != 7 {
+		t.Fatal("unexpected timeboosted blocks after deep reorg", blocks)
+	}
+	if blocks[1].BlockHash != chain.GetCanonicalHash(4) {
+		t.Fatal("indexed block isn't canonical after deep reorg", blocks[1])
+	}
+
+	info := &timeboost.RoundTimingInfo{Offset: time.Unix(0, 0), Round: 30 * time.Second}
+	from, to, ok := indexer.RoundBlocks(info, 1)
+	if !ok || from != 3 || to != 5 {
+		t.Fatal("unexpected round blocks", from, to, ok)
+	}
+	if _, _, ok := indexer.RoundBlocks(info, 5); ok {
+		t.Fatal("expected no blocks in a future round")
+	}
+}
+
+func TestBlockMetadataIndexerMissingMetadata(t *testing.T) {
+	chain := &testIndexChain{}
+	chain.addBlock([]byte{0, 2}, 0)
+	chain.addBlock(nil, 0)
+	chain.addBlock([]byte{0, 2}, 0)
+	config := DefaultBlockMetadataIndexConfig
+	indexer := NewBlockMetadataIndexer(func() *BlockMetadataIndexConfig { return &config }, rawdb.NewMemoryDatabase(), chain, chain)
+
+	// indexing stops before the block with missing metadata, however old it is
+	require.NoError(t, indexer.indexNewBlocks())
+	indexedTo, ok, err := indexer.IndexedTo()
+	require.NoError(t, err)
+	if !ok || indexedTo != 0 {
+		t.Fatal("unexpected indexed head with missing metadata", indexedTo, ok)
+	}
+
+	// and resumes once it arrives
+	chain.metadata[1] = []byte{0, 4}
+	require.NoError(t, indexer.indexNewBlocks())
+	indexedTo, ok, err = indexer.IndexedTo()
+	require.NoError(t, err)
+	if !ok || indexedTo != 2 {
+		t.Fatal("unexpected indexed head after metadata arrived", indexedTo, ok)
+	}
+	blocks, err := indexer.TimeboostedBlocks(0, 2)
+	require.NoError(t, err)
+	if len(blocks) != 3 || !reflect.DeepEqual(blocks[1].TimeboostedTxs, []uint64{2}) {
+		t.Fatal("unexpected timeboosted blocks", blocks)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	ErrBundleTooEarly = errors.New("bundle minimum block number not reached yet")
+	ErrBundleTooLate  = errors.New("bundle maximum block number passed")
+	ErrBundleEmpty    = errors.New("bundle has no transactions")
+)
+
+// Bundle is an ordered list of transactions that are sequenced contiguously in the same block, or not at all.
+type Bundle struct {
+	Transactions []*types.Transaction
+	// Options are checked before the first transaction of the bundle
+	Options *arbitrum_types.ConditionalOptions
+	// RevertProtection drops the bundle if any of its transactions reverts
+	RevertProtection bool
+	// The block number range the bundle can be included in, either end can be nil for unbounded
+	MinBlockNumber *uint64
+	MaxBlockNumber *uint64
+}
+
+// BundleError is returned when a bundle isn't sequenced because one of its transactions failed.
+type BundleError struct {
+	Index  int
+	TxHash common.Hash
+	Err    error
+}
+
+func (e *BundleError) Error() string {
+	return fmt.Sprintf("bundle dropped, transaction %v (%v) failed: %v", e.Index, e.TxHash, e.Err)
+}
+
+func (e *BundleError) Unwrap() error {
+	return e.Err
+}
+
+// checkBlockNumber returns an error if the bundle can't be included in the given block.
+func (b *Bundle) checkBlockNumber(blockNum uint64) error {
+	if b.MinBlockNumber != nil && blockNum < *b.MinBlockNumber {
+		return ErrBundleTooEarly
+	}
+	if b.MaxBlockNumber != nil && blockNum > *b.MaxBlockNumber {
+		return ErrBundleTooLate
+	}
+	return nil
+}
+
+// checkResults returns a *BundleError for the first transaction of the bundle that failed,
+// given the errors and receipts of sequencing it.
+func (b *Bundle) checkResults(txErrors []error, receipts types.Receipts) error {
+	for i, err := range txErrors {
+		if err != nil {
+			return &BundleError{Index: i, TxHash: b.Transactions[i].Hash(), Err: err}
+		}
+	}
+	if !b.RevertProtection {
+		return nil
+	}
+	reverted := make(map[common.Hash]struct{})
+	for _, receipt := range receipts {
+		if receipt.Status == types.ReceiptStatusFailed {
+			reverted[receipt.TxHash] = struct{}{}
+		}
+	}
+	for i, tx := range b.Transactions {
+		if _, ok := reverted[tx.Hash()]; ok {
+			return &BundleError{Index: i, TxHash: tx.Hash(), Err: errors.New("execution reverted")}
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) size() (int, error) {
+	size := 0
+	for _, tx := range b.Transactions {
+		txBytes, err := tx.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		size += len(txBytes)
+	}
+	return size, nil
+}
+
+// SendBundleArgs are the arguments of arb_sendBundle.
+type SendBundleArgs struct {
+	Txs              []hexutil.Bytes                    `json:"txs"`
+	Options          *arbitrum_types.ConditionalOptions `json:"options,omitempty"`
+	RevertProtection bool                               `json:"revertProtection,omitempty"`
+	MinBlockNumber   *hexutil.Uint64                    `json:"minBlockNumber,omitempty"`
+	MaxBlockNumber   *hexutil.Uint64                    `json:"maxBlockNumber,omitempty"`
+}
+
+func (a *SendBundleArgs) ToBundle() (*Bundle, error) {
+	if len(a.Txs) == 0 {
+		return nil, ErrBundleEmpty
+	}
+	bundle := &Bundle{
+		Transactions:     make([]*types.Transaction, len(a.Txs)),
+		Options:          a.Options,
+		RevertProtection: a.RevertProtection,
+		MinBlockNumber:   (*uint64)(a.MinBlockNumber),
+		MaxBlockNumber:   (*uint64)(a.MaxBlockNumber),
+	}
+	for i, txBytes := range a.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(txBytes); err != nil {
+			return nil, fmt.Errorf("invalid bundle transaction %v: %w", i, err)
+		}
+		bundle.Transactions[i] = tx
+	}
+	return bundle, nil
+}
+
+func (b *Bundle) ToArgs() (*SendBundleArgs, error) {
+	args := &SendBundleArgs{
+		Txs:              make([]hexutil.Bytes, len(b.Transactions)),
+		Options:          b.Options,
+		RevertProtection: b.RevertProtection,
+		MinBlockNumber:   (*hexutil.Uint64)(b.MinBlockNumber),
+		MaxBlockNumber:   (*hexutil.Uint64)(b.MaxBlockNumber),
+	}
+	for i, tx := range b.Transactions {
+		txBytes, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		args.Txs[i] = txBytes
+	}
+	return args, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBundleChecks(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	first := types.NewTx(&types.LegacyTx{Nonce: 0, To: &to})
+	second := types.NewTx(&types.LegacyTx{Nonce: 1, To: &to})
+	minBlock, maxBlock := hexutil.Uint64(10), hexutil.Uint64(20)
+
+	args := &SendBundleArgs{RevertProtection: true, MinBlockNumber: &minBlock, MaxBlockNumber: &maxBlock}
+	_, err := args.ToBundle()
+	require.ErrorIs(t, err, ErrBundleEmpty)
+	for _, tx := range []*types.Transaction{first, second} {
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		args.Txs = append(args.Txs, txBytes)
+	}
+	bundle, err := args.ToBundle()
+	require.NoError(t, err)
+	require.Equal(t, second.Hash(), bundle.Transactions[1].Hash())
+	roundTrip, err := bundle.ToArgs()
+	require.NoError(t, err)
+	require.Equal(t, args, roundTrip)
+
+	require.ErrorIs(t, bundle.checkBlockNumber(9), ErrBundleTooEarly)
+	require.NoError(t, bundle.checkBlockNumber(10))
+	require.NoError(t, bundle.checkBlockNumber(20))
+	require.ErrorIs(t, bundle.checkBlockNumber(21), ErrBundleTooLate)
+
+	receipts := types.Receipts{
+		{TxHash: first.Hash(), Status: types.ReceiptStatusSuccessful},
+		{TxHash: second.Hash(), Status: types.ReceiptStatusSuccessful},
+	}
+	require.NoError(t, bundle.checkResults([]error{nil, nil}, receipts))
+
+	var bundleErr *BundleError
+	require.True(t, errors.As(bundle.checkResults([]error{nil, core.ErrNonceTooHigh}, receipts), &bundleErr))
+	require.Equal(t, 1, bundleErr.Index)
+	require.ErrorIs(t, bundleErr, core.ErrNonceTooHigh)
+
+	receipts[0].Status = types.ReceiptStatusFailed
+	require.True(t, errors.As(bundle.checkResults([]error{nil, nil}, receipts), &bundleErr))
+	require.Equal(t, first.Hash(), bundleErr.TxHash)
+	bundle.RevertProtection = false
+	require.NoError(t, bundle.checkResults([]error{nil, nil}, receipts))
+}
+
+func TestParkedBundles(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	newItem := func(ctx context.Context, nonce uint64, minBlock *uint64) txQueueItem {
+		tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to})
+		return txQueueItem{
+			tx:             tx,
+			ctx:            ctx,
+			resultChan:     make(chan error, 1),
+			returnedResult: &atomic.Bool{},
+			bundle:         &Bundle{Transactions: []*types.Transaction{tx}, MinBlockNumber: minBlock},
+		}
+	}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	minBlock := uint64(10)
+	s := &Sequencer{}
+	tooEarly := newItem(context.Background(), 0, &minBlock)
+	deferred := newItem(context.Background(), 1, nil)
+	canceled := newItem(canceledCtx, 2, nil)
+	second := newItem(context.Background(), 3, nil)
+	for _, item := range []txQueueItem{tooEarly, deferred, canceled, second} {
+		s.parkBundle(item, 5)
+	}
+
+	// bundles are only retried on top of a later block, and the ones no one waits for are dropped
+	s.unparkBundle(5)
+	require.Equal(t, 0, s.txRetryQueue.Len())
+	require.ErrorIs(t, <-canceled.resultChan, context.Canceled)
+	require.Len(t, s.parkedBundles, 3)
+
+	// one bundle is retried per block
+	s.unparkBundle(6)
+	require.Equal(t, 1, s.txRetryQueue.Len())
+	require.Equal(t, deferred.tx.Hash(), s.txRetryQueue.Pop().tx.Hash())
+	s.unparkBundle(6)
+	require.Equal(t, 0, s.txRetryQueue.Len())
+	s.unparkBundle(7)
+	require.Equal(t, second.tx.Hash(), s.txRetryQueue.Pop().tx.Hash())
+
+	// and only once its minimum block number can be reached
+	s.unparkBundle(8)
+	require.Equal(t, 0, s.txRetryQueue.Len())
+	s.unparkBundle(9)
+	require.Equal(t, tooEarly.tx.Hash(), s.txRetryQueue.Pop().tx.Hash())
+	require.Empty(t, s.parkedBundles)
+}
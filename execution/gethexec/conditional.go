@@ -0,0 +1,96 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+var (
+	ErrConditionalTxExpired = errors.New("conditional transaction expired")
+	ErrL1DataFeeTooHigh     = errors.New("conditional transaction L1 data fee too high")
+)
+
+var (
+	conditionalTxExpiredCounter       = metrics.NewRegisteredCounter("arb/sequencer/conditionaltx/expired", nil)
+	conditionalTxL1DataFeeHighCounter = metrics.NewRegisteredCounter("arb/sequencer/conditionaltx/l1datafeehigh", nil)
+)
+
+// ConditionalExtensions are conditions on a transaction's inclusion checked by nitro in addition to
+// the arbitrum_types.ConditionalOptions of eth_sendRawTransactionConditional.
+type ConditionalExtensions struct {
+	// The unix timestamp after which the transaction is dropped instead of sequenced
+	ValidUntil *hexutil.Uint64 `json:"validUntil,omitempty"`
+	// The maximum L1 data fee in wei, as charged to the poster of the block including the transaction
+	MaxL1DataFee *hexutil.Big `json:"maxL1DataFee,omitempty"`
+}
+
+// ExtendedConditionalOptions are the options accepted by arb_sendRawTransactionConditional.
+type ExtendedConditionalOptions struct {
+	arbitrum_types.ConditionalOptions
+	ConditionalExtensions
+}
+
+// split returns the options and extensions to queue the transaction with, with nil extensions
+// if none are set.
+func (o *ExtendedConditionalOptions) split() (*arbitrum_types.ConditionalOptions, *ConditionalExtensions) {
+	if o == nil {
+		return nil, nil
+	}
+	if o.ConditionalExtensions.empty() {
+		return &o.ConditionalOptions, nil
+	}
+	return &o.ConditionalOptions, &o.ConditionalExtensions
+}
+
+func (c *ConditionalExtensions) empty() bool {
+	return c == nil || (c.ValidUntil == nil && c.MaxL1DataFee == nil)
+}
+
+// Check returns an error if a transaction included in a block with the given timestamp would break the
+// conditions. It's a no-op on nil extensions.
+func (c *ConditionalExtensions) Check(timestamp uint64, arbos *arbosState.ArbosState, tx *types.Transaction) error {
+	if c == nil {
+		return nil
+	}
+	if err := c.checkDeadline(timestamp); err != nil {
+		return err
+	}
+	if c.MaxL1DataFee != nil {
+		brotliCompressionLevel, err := arbos.BrotliCompressionLevel()
+		if err != nil {
+			return fmt.Errorf("failed to get brotli compression level: %w", err)
+		}
+		dataFee, _ := arbos.L1PricingState().GetPosterInfo(tx, l1pricing.BatchPosterAddress, brotliCompressionLevel)
+		return c.checkL1DataFee(dataFee)
+	}
+	return nil
+}
+
+func (c *ConditionalExtensions) checkDeadline(timestamp uint64) error {
+	if c.ValidUntil != nil && timestamp > uint64(*c.ValidUntil) {
+		conditionalTxExpiredCounter.Inc(1)
+		return fmt.Errorf("%w: valid until %v, now %v", ErrConditionalTxExpired, uint64(*c.ValidUntil), timestamp)
+	}
+	return nil
+}
+
+func (c *ConditionalExtensions) checkL1DataFee(dataFee *big.Int) error {
+	if c.MaxL1DataFee != nil && arbmath.BigGreaterThan(dataFee, c.MaxL1DataFee.ToInt()) {
+		conditionalTxL1DataFeeHighCounter.Inc(1)
+		return fmt.Errorf("%w: max %v, would be charged %v", ErrL1DataFeeTooHigh, c.MaxL1DataFee.ToInt(), dataFee)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalExtensions(t *testing.T) {
+	var options ExtendedConditionalOptions
+	err := json.Unmarshal([]byte(`{"blockNumberMax":"0x10","validUntil":"0x64","maxL1DataFee":"0x3e8"}`), &options)
+	require.NoError(t, err)
+	require.NotNil(t, options.BlockNumberMax)
+	require.Equal(t, uint64(16), uint64(*options.BlockNumberMax))
+	require.False(t, options.ConditionalExtensions.empty())
+
+	conditions := &options.ConditionalExtensions
+	require.NoError(t, conditions.checkDeadline(100))
+	err = conditions.checkDeadline(101)
+	require.True(t, errors.Is(err, ErrConditionalTxExpired), err)
+
+	require.NoError(t, conditions.checkL1DataFee(big.NewInt(1000)))
+	err = conditions.checkL1DataFee(big.NewInt(1001))
+	require.True(t, errors.Is(err, ErrL1DataFeeTooHigh), err)
+
+	var plain ExtendedConditionalOptions
+	require.NoError(t, json.Unmarshal([]byte(`{"timestampMax":"0x10"}`), &plain))
+	require.True(t, plain.ConditionalExtensions.empty())
+	plainOptions, plainConditions := plain.split()
+	require.Equal(t, &plain.ConditionalOptions, plainOptions)
+	require.Nil(t, plainConditions)
+	var nilOptions *ExtendedConditionalOptions
+	nilOptionsSplit, nilConditionsSplit := nilOptions.split()
+	require.Nil(t, nilOptionsSplit)
+	require.Nil(t, nilConditionsSplit)
+
+	// queued transactions are forwarded with both their options and extensions
+	item := &txQueueItem{options: &plain.ConditionalOptions, conditions: conditions}
+	require.Equal(t, options.ConditionalExtensions, item.extendedOptions().ConditionalExtensions)
+	require.Equal(t, plain.ConditionalOptions, item.extendedOptions().ConditionalOptions)
+	require.Nil(t, (&txQueueItem{}).extendedOptions())
+
+	var nilConditions *ConditionalExtensions
+	require.NoError(t, nilConditions.Check(1000, nil, nil))
+}
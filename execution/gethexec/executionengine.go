@@ -98,6 +98,8 @@ type ExecutionEngine struct {
 
 	prefetchBlock bool
 
+	pricingHistory *PricingHistory
+
 	cachedL1PriceData *L1PriceData
 }
 
@@ -251,6 +253,16 @@ func (s *ExecutionEngine) EnablePrefetchBlock() {
 	s.prefetchBlock = true
 }
 
+func (s *ExecutionEngine) EnablePricingHistory(pricingHistory *PricingHistory) {
+	if s.Started() {
+		panic("trying to enable pricing history after start")
+	}
+	if s.pricingHistory != nil {
+		panic("trying to enable pricing history when already set")
+	}
+	s.pricingHistory = pricingHistory
+}
+
 func (s *ExecutionEngine) SetConsensus(consensus execution.FullConsensusClient) {
 	if s.Started() {
 		panic("trying to set transaction consensus after start")
@@ -442,7 +454,7 @@ func (s *ExecutionEngine) resequenceReorgedMessages(messages []*arbostypes.Messa
 		}
 		hooks := arbos.NoopSequencingHooks()
 		hooks.DiscardInvalidTxsEarly = true
-		_, err = s.sequenceTransactionsWithBlockMutex(msg.Message.Header, txes, hooks, nil)
+		_, err = s.sequenceTransactionsWithBlockMutex(msg.Message.Header, txes, hooks, nil, nil)
 		if err != nil {
 			log.Error("failed to re-sequence old user message removed by reorg", "err", err)
 			return
@@ -482,7 +494,16 @@ func (s *ExecutionEngine) sequencerWrapper(sequencerFunc func() (*types.Block, e
 func (s *ExecutionEngine) SequenceTransactions(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks, timeboostedTxs map[common.Hash]struct{}) (*types.Block, error) {
 	return s.sequencerWrapper(func() (*types.Block, error) {
 		hooks.TxErrors = nil
-		return s.sequenceTransactionsWithBlockMutex(header, txes, hooks, timeboostedTxs)
+		return s.sequenceTransactionsWithBlockMutex(header, txes, hooks, timeboostedTxs, nil)
+	})
+}
+
+// SequenceBundle creates a block of just the bundle's transactions, but only if all of them succeed.
+// Otherwise no block is created and a *BundleError is returned.
+func (s *ExecutionEngine) SequenceBundle(header *arbostypes.L1IncomingMessageHeader, bundle *Bundle, hooks *arbos.SequencingHooks) (*types.Block, error) {
+	return s.sequencerWrapper(func() (*types.Block, error) {
+		hooks.TxErrors = nil
+		return s.sequenceTransactionsWithBlockMutex(header, bundle.Transactions, hooks, nil, bundle)
 	})
 }
 
@@ -524,11 +545,16 @@ func writeAndLog(pprof, trace *bytes.Buffer) {
 	log.Info("Transactions sequencing took longer than 2 seconds, created pprof and trace files", "pprof", pprofFile, "traceFile", traceFile)
 }
 
-func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks, timeboostedTxs map[common.Hash]struct{}) (*types.Block, error) {
+func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks, timeboostedTxs map[common.Hash]struct{}, bundle *Bundle) (*types.Block, error) {
 	lastBlockHeader, err := s.getCurrentHeader()
 	if err != nil {
 		return nil, err
 	}
+	if bundle != nil {
+		if err := bundle.checkBlockNumber(lastBlockHeader.Number.Uint64() + 1); err != nil {
+			return nil, err
+		}
+	}
 
 	statedb, err := s.bc.StateAt(lastBlockHeader.Root)
 	if err != nil {
@@ -560,6 +586,12 @@ func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.
 	if len(hooks.TxErrors) != len(txes) {
 		return nil, fmt.Errorf("unexpected number of error results: %v vs number of txes %v", len(hooks.TxErrors), len(txes))
 	}
+	if bundle != nil {
+		// Nothing has been written yet, so dropping the block drops the whole bundle
+		if err := bundle.checkResults(hooks.TxErrors, receipts); err != nil {
+			return nil, err
+		}
+	}
 
 	if len(receipts) == 0 {
 		return nil, nil
@@ -772,6 +804,11 @@ func (s *ExecutionEngine) appendBlock(block *types.Block, statedb *state.StateDB
 	blockGasUsedHistogram.Update(int64(blockGasused))
 	gasUsedSinceStartupCounter.Inc(int64(blockGasused))
 	s.updateL1GasPriceEstimateMetric()
+	if s.pricingHistory != nil {
+		if err := s.pricingHistory.record(block); err != nil {
+			log.Error("failed to index pricing history", "block", block.NumberU64(), "err", err)
+		}
+	}
 	return nil
 }
 
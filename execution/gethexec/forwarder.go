@@ -18,23 +18,29 @@ import (
 
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/timeboost"
 	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
 type ForwarderConfig struct {
-	ConnectionTimeout     time.Duration `koanf:"connection-timeout"`
-	IdleConnectionTimeout time.Duration `koanf:"idle-connection-timeout"`
-	MaxIdleConnections    int           `koanf:"max-idle-connections"`
-	RedisUrl              string        `koanf:"redis-url"`
-	UpdateInterval        time.Duration `koanf:"update-interval"`
-	RetryInterval         time.Duration `koanf:"retry-interval"`
+	ConnectionTimeout     time.Duration                `koanf:"connection-timeout"`
+	IdleConnectionTimeout time.Duration                `koanf:"idle-connection-timeout"`
+	MaxIdleConnections    int                          `koanf:"max-idle-connections"`
+	RedisUrl              string                       `koanf:"redis-url"`
+	UpdateInterval        time.Duration                `koanf:"update-interval"`
+	RetryInterval         time.Duration                `koanf:"retry-interval"`
+	LoadBalancing         ForwarderLoadBalancingConfig `koanf:"load-balancing"`
+}
+
+func (c *ForwarderConfig) Validate() error {
+	return c.LoadBalancing.Validate()
 }
 
 var DefaultNodeForwarderConfig = ForwarderConfig{
@@ -44,6 +50,7 @@ var DefaultNodeForwarderConfig = ForwarderConfig{
 	RedisUrl:              "",
 	UpdateInterval:        time.Second,
 	RetryInterval:         100 * time.Millisecond,
+	LoadBalancing:         DefaultForwarderLoadBalancingConfig,
 }
 
 var DefaultSequencerForwarderConfig = ForwarderConfig{
@@ -53,6 +60,7 @@ var DefaultSequencerForwarderConfig = ForwarderConfig{
 	RedisUrl:              "",
 	UpdateInterval:        time.Second,
 	RetryInterval:         100 * time.Millisecond,
+	LoadBalancing:         DefaultForwarderLoadBalancingConfig,
 }
 
 func AddOptionsForNodeForwarderConfig(prefix string, f *flag.FlagSet) {
@@ -70,12 +78,16 @@ func AddOptionsForForwarderConfigImpl(prefix string, defaultConfig *ForwarderCon
 	f.String(prefix+".redis-url", defaultConfig.RedisUrl, "the Redis URL to recomend target via")
 	f.Duration(prefix+".update-interval", defaultConfig.UpdateInterval, "forwarding target update interval")
 	f.Duration(prefix+".retry-interval", defaultConfig.RetryInterval, "minimal time between update retries")
+	ForwarderLoadBalancingConfigAddOptions(prefix+".load-balancing", f)
 }
 
 type TxForwarder struct {
+	stopwaiter.StopWaiterSafe
+
 	ctx context.Context
 
 	enabled   atomic.Bool
+	config    *ForwarderConfig
 	timeout   time.Duration
 	transport *http.Transport
 
@@ -84,8 +96,8 @@ type TxForwarder struct {
 	healthChecked time.Time
 
 	targets               []string
-	rpcClients            []*rpc.Client
-	ethClients            []*ethclient.Client
+	forwardTargets        []*forwardingTarget
+	roundRobin            atomic.Uint64
 	tryNewForwarderErrors *regexp.Regexp
 }
 
@@ -115,12 +127,18 @@ func NewForwarder(targets []string, config *ForwarderConfig) *TxForwarder {
 	}
 	return &TxForwarder{
 		targets:               targets,
+		config:                config,
 		timeout:               config.ConnectionTimeout,
 		transport:             transport,
 		tryNewForwarderErrors: regexp.MustCompile(`(?i)(^http:|^json:|^i/0|timeout exceeded|no such host)`),
 	}
 }
 
+// isConnectionFailure returns whether the error means the target couldn't be reached, so the next one should be tried.
+func (f *TxForwarder) isConnectionFailure(err error) bool {
+	return f.tryNewForwarderErrors.MatchString(err.Error())
+}
+
 func (f *TxForwarder) ctxWithTimeout() (context.Context, context.CancelFunc) {
 	if f.timeout == time.Duration(0) {
 		return context.WithCancel(f.ctx)
@@ -134,21 +152,27 @@ func (f *TxForwarder) PublishTransaction(inctx context.Context, tx *types.Transa
 	}
 	ctx, cancelFunc := f.ctxWithTimeout()
 	defer cancelFunc()
-	for pos, rpcClient := range f.rpcClients {
-		var err error
+	return f.forwardHedged(ctx, "transaction", func(ctx context.Context, target *forwardingTarget) error {
 		if options == nil {
-			err = f.ethClients[pos].SendTransaction(ctx, tx)
-		} else {
-			err = arbitrum.SendConditionalTransactionRPC(ctx, rpcClient, tx, options)
-		}
-		if err != nil {
-			log.Warn("error forwarding transaction to a backup target", "target", f.targets[pos], "err", err)
-		}
-		if err == nil || !f.tryNewForwarderErrors.MatchString(err.Error()) {
-			return err
+			return target.ethClient.SendTransaction(ctx, tx)
 		}
+		return arbitrum.SendConditionalTransactionRPC(ctx, target.rpcClient, tx, options)
+	})
+}
+
+func (f *TxForwarder) PublishConditionalTransaction(inctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error {
+	if !f.enabled.Load() {
+		return ErrNoSequencer
+	}
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return err
 	}
-	return errors.New("failed to publish transaction to any of the forwarding targets")
+	ctx, cancelFunc := f.ctxWithTimeout()
+	defer cancelFunc()
+	return f.forwardHedged(ctx, "conditional transaction", func(ctx context.Context, target *forwardingTarget) error {
+		return target.rpcClient.CallContext(ctx, nil, "arb_sendRawTransactionConditional", hexutil.Bytes(txBytes), options)
+	})
 }
 
 func (f *TxForwarder) PublishExpressLaneTransaction(inctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
@@ -157,16 +181,44 @@ func (f *TxForwarder) PublishExpressLaneTransaction(inctx context.Context, msg *
 	}
 	ctx, cancelFunc := f.ctxWithTimeout()
 	defer cancelFunc()
-	for pos, rpcClient := range f.rpcClients {
-		err := sendExpressLaneTransactionRPC(ctx, rpcClient, msg)
-		if err != nil {
-			log.Warn("error forwarding express lane transaction to a backup target", "target", f.targets[pos], "err", err)
-		}
-		if err == nil || !f.tryNewForwarderErrors.MatchString(err.Error()) {
-			return err
-		}
+	return f.forward(ctx, "express lane transaction", func(ctx context.Context, target *forwardingTarget) error {
+		return sendExpressLaneTransactionRPC(ctx, target.rpcClient, msg)
+	})
+}
+
+func (f *TxForwarder) PublishBundle(inctx context.Context, bundle *Bundle) error {
+	if !f.enabled.Load() {
+		return ErrNoSequencer
 	}
-	return errors.New("failed to publish transaction to any of the forwarding targets")
+	args, err := bundle.ToArgs()
+	if err != nil {
+		return err
+	}
+	ctx, cancelFunc := f.ctxWithTimeout()
+	defer cancelFunc()
+	return f.forward(ctx, "bundle", func(ctx context.Context, target *forwardingTarget) error {
+		return target.rpcClient.CallContext(ctx, nil, "arb_sendBundle", args)
+	})
+}
+
+func (f *TxForwarder) PublishTransactionWithPreconf(inctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	if !f.enabled.Load() {
+		return nil, ErrNoSequencer
+	}
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancelFunc := f.ctxWithTimeout()
+	defer cancelFunc()
+	var preconf signature.Preconf
+	err = f.forward(ctx, "transaction with preconf", func(ctx context.Context, target *forwardingTarget) error {
+		return target.rpcClient.CallContext(ctx, &preconf, "arb_sendRawTransactionWithPreconf", hexutil.Bytes(txBytes), options)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &preconf, nil
 }
 
 func sendExpressLaneTransactionRPC(ctx context.Context, rpcClient *rpc.Client, msg *timeboost.ExpressLaneSubmission) error {
@@ -183,16 +235,9 @@ func (f *TxForwarder) PublishAuctionResolutionTransaction(inctx context.Context,
 	}
 	ctx, cancelFunc := f.ctxWithTimeout()
 	defer cancelFunc()
-	for pos, rpcClient := range f.rpcClients {
-		err := sendAuctionResolutionTransactionRPC(ctx, rpcClient, tx)
-		if err != nil {
-			log.Warn("error forwarding auction resolution transaction to a backup target", "target", f.targets[pos], "err", err)
-		}
-		if err == nil || !f.tryNewForwarderErrors.MatchString(err.Error()) {
-			return err
-		}
-	}
-	return errors.New("failed to publish transaction to any of the forwarding targets")
+	return f.forward(ctx, "auction resolution transaction", func(ctx context.Context, target *forwardingTarget) error {
+		return sendAuctionResolutionTransactionRPC(ctx, target.rpcClient, tx)
+	})
 }
 
 func sendAuctionResolutionTransactionRPC(ctx context.Context, rpcClient *rpc.Client, tx *types.Transaction) error {
@@ -204,9 +249,9 @@ const maxHealthTimeout = 10 * time.Second
 
 // CheckHealth returns health of the highest priority forwarding target
 func (f *TxForwarder) CheckHealth(inctx context.Context) error {
-	// If f.enabled is true, len(f.rpcClients) should always be greater than zero,
+	// If f.enabled is true, len(f.forwardTargets) should always be greater than zero,
 	// but better safe than sorry.
-	if !f.enabled.Load() || len(f.rpcClients) == 0 {
+	if !f.enabled.Load() || len(f.forwardTargets) == 0 {
 		return ErrNoSequencer
 	}
 	f.healthMutex.Lock()
@@ -218,7 +263,7 @@ func (f *TxForwarder) CheckHealth(inctx context.Context) error {
 		}
 		ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
 		defer cancelFunc()
-		f.healthErr = f.rpcClients[0].CallContext(ctx, nil, "arb_checkPublisherHealth")
+		f.healthErr = f.forwardTargets[0].rpcClient.CallContext(ctx, nil, "arb_checkPublisherHealth")
 		f.healthChecked = time.Now()
 	}
 	return f.healthErr
@@ -242,13 +287,11 @@ func (f *TxForwarder) Initialize(inctx context.Context) error {
 			lastError = err
 			continue
 		}
+		f.forwardTargets = append(f.forwardTargets, newForwardingTarget(target, rpcClient))
 		targets = append(targets, target)
-		ethClient := ethclient.NewClient(rpcClient)
-		f.rpcClients = append(f.rpcClients, rpcClient)
-		f.ethClients = append(f.ethClients, ethClient)
 	}
 	f.targets = targets
-	if len(f.rpcClients) > 0 {
+	if len(f.forwardTargets) > 0 {
 		f.enabled.Store(true)
 	} else {
 		return lastError
@@ -262,12 +305,23 @@ func (f *TxForwarder) Disable() {
 }
 
 func (f *TxForwarder) Start(ctx context.Context) error {
-	return nil
+	if f.config.LoadBalancing.HealthCheckInterval == 0 || len(f.forwardTargets) < 2 {
+		return nil
+	}
+	if err := f.StopWaiterSafe.Start(ctx, f); err != nil {
+		return err
+	}
+	return f.CallIterativelySafe(f.probeTargets)
 }
 
 func (f *TxForwarder) StopAndWait() {
-	for _, ethClient := range f.ethClients {
-		ethClient.Close() // internally closes also the rpc client
+	if f.StopWaiterSafe.Started() {
+		if err := f.StopWaiterSafe.StopAndWait(); err != nil {
+			log.Warn("error stopping forwarder health checks", "err", err)
+		}
+	}
+	for _, target := range f.forwardTargets {
+		target.ethClient.Close() // internally closes also the rpc client
 	}
 }
 
@@ -295,6 +349,18 @@ func (f *TxDropper) PublishTransaction(ctx context.Context, tx *types.Transactio
 	return txDropperErr
 }
 
+func (f *TxDropper) PublishConditionalTransaction(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error {
+	return txDropperErr
+}
+
+func (f *TxDropper) PublishBundle(ctx context.Context, bundle *Bundle) error {
+	return txDropperErr
+}
+
+func (f *TxDropper) PublishTransactionWithPreconf(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	return nil, txDropperErr
+}
+
 func (f *TxDropper) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	return txDropperErr
 }
@@ -346,6 +412,30 @@ func (f *RedisTxForwarder) PublishTransaction(ctx context.Context, tx *types.Tra
 	return forwarder.PublishTransaction(ctx, tx, options)
 }
 
+func (f *RedisTxForwarder) PublishConditionalTransaction(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error {
+	forwarder := f.getForwarder()
+	if forwarder == nil {
+		return ErrNoSequencer
+	}
+	return forwarder.PublishConditionalTransaction(ctx, tx, options)
+}
+
+func (f *RedisTxForwarder) PublishBundle(ctx context.Context, bundle *Bundle) error {
+	forwarder := f.getForwarder()
+	if forwarder == nil {
+		return ErrNoSequencer
+	}
+	return forwarder.PublishBundle(ctx, bundle)
+}
+
+func (f *RedisTxForwarder) PublishTransactionWithPreconf(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	forwarder := f.getForwarder()
+	if forwarder == nil {
+		return nil, ErrNoSequencer
+	}
+	return forwarder.PublishTransactionWithPreconf(ctx, tx, options)
+}
+
 func (f *RedisTxForwarder) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	forwarder := f.getForwarder()
 	if forwarder == nil {
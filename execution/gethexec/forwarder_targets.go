@@ -0,0 +1,364 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/metricsutil"
+)
+
+var hedgedRequestsCounter = metrics.NewRegisteredCounter("arb/forwarder/hedged", nil)
+
+// The policies for choosing which forwarding target to send to first.
+const (
+	// Always try the targets in the configured order
+	ForwardingPolicyFailover   = "failover"
+	ForwardingPolicyRoundRobin = "round-robin"
+	// Prefer the target with the fewest requests in flight
+	ForwardingPolicyLeastInFlight = "least-in-flight"
+	// Pick targets at random, weighted by the inverse of their recent latency
+	ForwardingPolicyLatencyWeighted = "latency-weighted"
+)
+
+type ForwarderLoadBalancingConfig struct {
+	Policy                 string        `koanf:"policy"`
+	HealthCheckInterval    time.Duration `koanf:"health-check-interval"`
+	CircuitBreakerFailures int           `koanf:"circuit-breaker-failures"`
+	CircuitBreakerCooldown time.Duration `koanf:"circuit-breaker-cooldown"`
+	HedgeDelay             time.Duration `koanf:"hedge-delay"`
+}
+
+var DefaultForwarderLoadBalancingConfig = ForwarderLoadBalancingConfig{
+	Policy:                 ForwardingPolicyFailover,
+	HealthCheckInterval:    0,
+	CircuitBreakerFailures: 0,
+	CircuitBreakerCooldown: 10 * time.Second,
+	HedgeDelay:             0,
+}
+
+func ForwarderLoadBalancingConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".policy", DefaultForwarderLoadBalancingConfig.Policy, "how to choose the forwarding target to send to first, one of \"failover\", \"round-robin\", \"least-in-flight\" or \"latency-weighted\"")
+	f.Duration(prefix+".health-check-interval", DefaultForwarderLoadBalancingConfig.HealthCheckInterval, "how often to check the health of each forwarding target in the background (0 = disabled)")
+	f.Int(prefix+".circuit-breaker-failures", DefaultForwarderLoadBalancingConfig.CircuitBreakerFailures, "consecutive connection failures after which a forwarding target is only used if all others fail (0 = disabled)")
+	f.Duration(prefix+".circuit-breaker-cooldown", DefaultForwarderLoadBalancingConfig.CircuitBreakerCooldown, "how long a forwarding target's circuit breaker stays open before the target is tried again")
+	f.Duration(prefix+".hedge-delay", DefaultForwarderLoadBalancingConfig.HedgeDelay, "if a transaction isn't accepted by a forwarding target within this delay, also send it to the next target and use the first response (0 = disabled)")
+}
+
+func (c *ForwarderLoadBalancingConfig) Validate() error {
+	switch c.Policy {
+	case ForwardingPolicyFailover, ForwardingPolicyRoundRobin, ForwardingPolicyLeastInFlight, ForwardingPolicyLatencyWeighted:
+	default:
+		return fmt.Errorf("invalid forwarder load-balancing policy \"%v\"", c.Policy)
+	}
+	if c.HealthCheckInterval < 0 || c.CircuitBreakerCooldown < 0 || c.HedgeDelay < 0 {
+		return errors.New("forwarder load-balancing durations cannot be negative")
+	}
+	if c.CircuitBreakerFailures < 0 {
+		return errors.New("forwarder load-balancing circuit-breaker-failures cannot be negative")
+	}
+	return nil
+}
+
+// latencyDecay is the weight of the previous average in a target's exponentially weighted latency
+const latencyDecay = 0.8
+
+type forwardingTarget struct {
+	url       string
+	rpcClient *rpc.Client
+	ethClient *ethclient.Client
+
+	inFlight atomic.Int64
+	// exponentially weighted average latency of successful requests, zero until the first one
+	latency atomic.Int64
+	// set by the background health probes
+	unhealthy atomic.Bool
+
+	breakerMutex        sync.Mutex
+	consecutiveFailures int
+	breakerOpenUntil    time.Time
+
+	requestsCounter  metrics.Counter
+	failuresCounter  metrics.Counter
+	inFlightGauge    metrics.Gauge
+	latencyHistogram metrics.Histogram
+	breakerGauge     metrics.Gauge
+	healthyGauge     metrics.Gauge
+}
+
+// forwardingTargetMetricName names a target's metrics after its host and port, so they're stable when the
+// targets are reconfigured. The rest of the URL is left out, as paths and queries may carry credentials.
+func forwardingTargetMetricName(target string) string {
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		target = parsed.Host
+	}
+	return metricsutil.CanonicalizeMetricName(target)
+}
+
+func newForwardingTarget(url string, rpcClient *rpc.Client) *forwardingTarget {
+	metricBase := "arb/forwarder/target/" + forwardingTargetMetricName(url)
+	return &forwardingTarget{
+		url:              url,
+		rpcClient:        rpcClient,
+		ethClient:        ethclient.NewClient(rpcClient),
+		requestsCounter:  metrics.GetOrRegisterCounter(metricBase+"/requests", nil),
+		failuresCounter:  metrics.GetOrRegisterCounter(metricBase+"/failures", nil),
+		inFlightGauge:    metrics.GetOrRegisterGauge(metricBase+"/inflight", nil),
+		latencyHistogram: metrics.GetOrRegisterHistogram(metricBase+"/latency", nil, metrics.NewBoundedHistogramSample()),
+		breakerGauge:     metrics.GetOrRegisterGauge(metricBase+"/breaker/open", nil),
+		healthyGauge:     metrics.GetOrRegisterGauge(metricBase+"/healthy", nil),
+	}
+}
+
+func (t *forwardingTarget) available(now time.Time) bool {
+	if t.unhealthy.Load() {
+		return false
+	}
+	t.breakerMutex.Lock()
+	defer t.breakerMutex.Unlock()
+	return !now.Before(t.breakerOpenUntil)
+}
+
+// call makes a request to the target, recording its metrics. connectionFailure reports whether an
+// error means the target itself is failing, as opposed to the request being rejected.
+func (t *forwardingTarget) call(ctx context.Context, config *ForwarderLoadBalancingConfig, connectionFailure func(error) bool, fn func(context.Context, *forwardingTarget) error) error {
+	t.requestsCounter.Inc(1)
+	t.inFlightGauge.Update(t.inFlight.Add(1))
+	start := time.Now()
+	err := fn(ctx, t)
+	elapsed := time.Since(start)
+	t.inFlightGauge.Update(t.inFlight.Add(-1))
+
+	if err != nil && connectionFailure(err) {
+		t.failuresCounter.Inc(1)
+		t.recordFailure(config)
+		return err
+	}
+	if ctx.Err() != nil {
+		// Canceled by the caller, e.g. because a hedged request to another target succeeded first
+		return err
+	}
+	t.latencyHistogram.Update(elapsed.Microseconds())
+	if previous := t.latency.Load(); previous == 0 {
+		t.latency.Store(int64(elapsed))
+	} else {
+		t.latency.Store(int64(latencyDecay*float64(previous) + (1-latencyDecay)*float64(elapsed)))
+	}
+	t.breakerMutex.Lock()
+	t.consecutiveFailures = 0
+	t.breakerMutex.Unlock()
+	t.breakerGauge.Update(0)
+	return err
+}
+
+func (t *forwardingTarget) recordFailure(config *ForwarderLoadBalancingConfig) {
+	if config.CircuitBreakerFailures == 0 {
+		return
+	}
+	t.breakerMutex.Lock()
+	defer t.breakerMutex.Unlock()
+	t.consecutiveFailures++
+	// Once open, a single failed attempt after the cooldown reopens the breaker
+	if t.consecutiveFailures >= config.CircuitBreakerFailures {
+		if time.Now().After(t.breakerOpenUntil) {
+			log.Warn("opening circuit breaker of forwarding target", "target", t.url, "failures", t.consecutiveFailures, "cooldown", config.CircuitBreakerCooldown)
+		}
+		t.breakerOpenUntil = time.Now().Add(config.CircuitBreakerCooldown)
+		t.breakerGauge.Update(1)
+	}
+}
+
+// orderTargets returns the targets in the order to try them in under the given policy. Targets that
+// are unhealthy or whose circuit breaker is open go last, in their configured order.
+func orderTargets(targets []*forwardingTarget, policy string, roundRobin uint64) []*forwardingTarget {
+	now := time.Now()
+	var available, unavailable []*forwardingTarget
+	for _, target := range targets {
+		if target.available(now) {
+			available = append(available, target)
+		} else {
+			unavailable = append(unavailable, target)
+		}
+	}
+	switch policy {
+	case ForwardingPolicyRoundRobin:
+		if len(available) > 0 {
+			// #nosec G115
+			start := int(roundRobin % uint64(len(available)))
+			rotated := make([]*forwardingTarget, 0, len(targets))
+			rotated = append(rotated, available[start:]...)
+			available = append(rotated, available[:start]...)
+		}
+	case ForwardingPolicyLeastInFlight:
+		sort.SliceStable(available, func(i, j int) bool {
+			return available[i].inFlight.Load() < available[j].inFlight.Load()
+		})
+	case ForwardingPolicyLatencyWeighted:
+		sort.SliceStable(available, func(i, j int) bool {
+			return available[i].latency.Load() < available[j].latency.Load()
+		})
+		if chosen := pickByLatency(available); chosen > 0 {
+			first := available[chosen]
+			copy(available[1:chosen+1], available[:chosen])
+			available[0] = first
+		}
+	}
+	return append(available, unavailable...)
+}
+
+// pickByLatency chooses a target at random with a weight of the inverse of its latency. Targets without a
+// latency yet get the weight of the fastest target, so they're tried too.
+func pickByLatency(targets []*forwardingTarget) int {
+	if len(targets) < 2 {
+		return 0
+	}
+	var fastest int64
+	for _, target := range targets {
+		if latency := target.latency.Load(); latency > 0 && (fastest == 0 || latency < fastest) {
+			fastest = latency
+		}
+	}
+	if fastest == 0 {
+		// #nosec G404
+		return rand.Intn(len(targets))
+	}
+	weights := make([]float64, len(targets))
+	var total float64
+	for i, target := range targets {
+		latency := target.latency.Load()
+		if latency == 0 {
+			latency = fastest
+		}
+		weights[i] = 1 / float64(latency)
+		total += weights[i]
+	}
+	// #nosec G404
+	choice := rand.Float64() * total
+	for i, weight := range weights {
+		choice -= weight
+		if choice < 0 {
+			return i
+		}
+	}
+	return len(targets) - 1
+}
+
+// probeTargets checks the health of every target, so unhealthy ones are tried last.
+func (f *TxForwarder) probeTargets(ctx context.Context) time.Duration {
+	for _, target := range f.forwardTargets {
+		timeout := f.config.LoadBalancing.HealthCheckInterval
+		if timeout >= maxHealthTimeout {
+			timeout = maxHealthTimeout
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := target.rpcClient.CallContext(probeCtx, nil, "arb_checkPublisherHealth")
+		cancel()
+		if ctx.Err() != nil {
+			return 0
+		}
+		wasUnhealthy := target.unhealthy.Swap(err != nil)
+		if err != nil {
+			target.healthyGauge.Update(0)
+			if !wasUnhealthy {
+				log.Warn("forwarding target failed health check", "target", target.url, "err", err)
+			}
+		} else {
+			target.healthyGauge.Update(1)
+			if wasUnhealthy {
+				log.Info("forwarding target is healthy again", "target", target.url)
+			}
+		}
+	}
+	return f.config.LoadBalancing.HealthCheckInterval
+}
+
+// forward calls fn on the targets in the order chosen by the load-balancing policy, moving on to the next
+// target if the previous one couldn't be reached. It returns the result of the last target tried.
+func (f *TxForwarder) forward(ctx context.Context, what string, fn func(context.Context, *forwardingTarget) error) error {
+	config := &f.config.LoadBalancing
+	for _, target := range orderTargets(f.forwardTargets, config.Policy, f.roundRobin.Add(1)) {
+		err := target.call(ctx, config, f.isConnectionFailure, fn)
+		if err != nil {
+			log.Warn("error forwarding "+what+" to a backup target", "target", target.url, "err", err)
+		}
+		if err == nil || !f.isConnectionFailure(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to publish %v to any of the forwarding targets", what)
+}
+
+// forwardHedged is like forward, but if a target hasn't answered within the hedge delay, the request is also
+// sent to the next target. The first success is returned, or the last error if every target fails.
+// The request must be safe to repeat, as more than one target may accept it.
+func (f *TxForwarder) forwardHedged(ctx context.Context, what string, fn func(context.Context, *forwardingTarget) error) error {
+	config := &f.config.LoadBalancing
+	targets := orderTargets(f.forwardTargets, config.Policy, f.roundRobin.Add(1))
+	if config.HedgeDelay == 0 || len(targets) < 2 {
+		return f.forward(ctx, what, fn)
+	}
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan error, len(targets))
+	launch := func(target *forwardingTarget) {
+		go func() {
+			results <- target.call(hedgeCtx, config, f.isConnectionFailure, fn)
+		}()
+	}
+
+	launch(targets[0])
+	launched, pending := 1, 1
+	var lastErr error
+	hedgeTimer := time.NewTimer(config.HedgeDelay)
+	defer hedgeTimer.Stop()
+	for pending > 0 {
+		select {
+		case err := <-results:
+			pending--
+			if err == nil {
+				return nil
+			}
+			lastErr = err
+			log.Warn("error forwarding "+what+" to a hedged target", "err", err)
+			if !f.isConnectionFailure(err) && pending == 0 {
+				return err
+			}
+			if launched < len(targets) && pending == 0 {
+				launch(targets[launched])
+				launched++
+				pending++
+				hedgeTimer.Reset(config.HedgeDelay)
+			}
+		case <-hedgeTimer.C:
+			if launched < len(targets) {
+				hedgedRequestsCounter.Inc(1)
+				launch(targets[launched])
+				launched++
+				pending++
+				hedgeTimer.Reset(config.HedgeDelay)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if lastErr != nil && !f.isConnectionFailure(lastErr) {
+		return lastErr
+	}
+	return fmt.Errorf("failed to publish %v to any of the forwarding targets", what)
+}
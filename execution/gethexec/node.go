@@ -28,10 +28,13 @@ import (
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/dbutil"
 	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 type StylusTargetConfig struct {
@@ -85,21 +88,25 @@ func StylusTargetConfigAddOptions(prefix string, f *flag.FlagSet) {
 }
 
 type Config struct {
-	ParentChainReader           headerreader.Config `koanf:"parent-chain-reader" reload:"hot"`
-	Sequencer                   SequencerConfig     `koanf:"sequencer" reload:"hot"`
-	RecordingDatabase           BlockRecorderConfig `koanf:"recording-database"`
-	TxPreChecker                TxPreCheckerConfig  `koanf:"tx-pre-checker" reload:"hot"`
-	Forwarder                   ForwarderConfig     `koanf:"forwarder"`
-	ForwardingTarget            string              `koanf:"forwarding-target"`
-	SecondaryForwardingTarget   []string            `koanf:"secondary-forwarding-target"`
-	Caching                     CachingConfig       `koanf:"caching"`
-	RPC                         arbitrum.Config     `koanf:"rpc"`
-	TxLookupLimit               uint64              `koanf:"tx-lookup-limit"`
-	EnablePrefetchBlock         bool                `koanf:"enable-prefetch-block"`
-	SyncMonitor                 SyncMonitorConfig   `koanf:"sync-monitor"`
-	StylusTarget                StylusTargetConfig  `koanf:"stylus-target"`
-	BlockMetadataApiCacheSize   uint64              `koanf:"block-metadata-api-cache-size"`
-	BlockMetadataApiBlocksLimit uint64              `koanf:"block-metadata-api-blocks-limit"`
+	ParentChainReader           headerreader.Config      `koanf:"parent-chain-reader" reload:"hot"`
+	Sequencer                   SequencerConfig          `koanf:"sequencer" reload:"hot"`
+	RecordingDatabase           BlockRecorderConfig      `koanf:"recording-database"`
+	TxPreChecker                TxPreCheckerConfig       `koanf:"tx-pre-checker" reload:"hot"`
+	Forwarder                   ForwarderConfig          `koanf:"forwarder"`
+	ForwardingTarget            string                   `koanf:"forwarding-target"`
+	SecondaryForwardingTarget   []string                 `koanf:"secondary-forwarding-target"`
+	Caching                     CachingConfig            `koanf:"caching"`
+	RPC                         arbitrum.Config          `koanf:"rpc"`
+	TxLookupLimit               uint64                   `koanf:"tx-lookup-limit"`
+	EnablePrefetchBlock         bool                     `koanf:"enable-prefetch-block"`
+	SyncMonitor                 SyncMonitorConfig        `koanf:"sync-monitor"`
+	StylusTarget                StylusTargetConfig       `koanf:"stylus-target"`
+	BlockMetadataApiCacheSize   uint64                   `koanf:"block-metadata-api-cache-size"`
+	BlockMetadataApiBlocksLimit uint64                   `koanf:"block-metadata-api-blocks-limit"`
+	BlockMetadataIndex          BlockMetadataIndexConfig `koanf:"block-metadata-index"`
+	PreconfSigners              []string                 `koanf:"preconf-signers"`
+	PricingHistory              PricingHistoryConfig     `koanf:"pricing-history"`
+	ConsensusClient             rpcclient.ClientConfig   `koanf:"consensus-client" reload:"hot"`
 
 	forwardingTarget string
 }
@@ -122,10 +129,23 @@ func (c *Config) Validate() error {
 	if c.forwardingTarget != "" && c.Sequencer.Enable {
 		return errors.New("ForwardingTarget set and sequencer enabled")
 	}
+	if err := c.Forwarder.Validate(); err != nil {
+		return err
+	}
 	if err := c.StylusTarget.Validate(); err != nil {
 		return err
 	}
-	return nil
+	if c.ConsensusClient.URL != "" {
+		if err := c.ConsensusClient.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, signer := range c.PreconfSigners {
+		if !common.IsHexAddress(signer) {
+			return fmt.Errorf("invalid preconf signer address \"%v\"", signer)
+		}
+	}
+	return c.BlockMetadataIndex.Validate()
 }
 
 func ConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -144,6 +164,10 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	StylusTargetConfigAddOptions(prefix+".stylus-target", f)
 	f.Uint64(prefix+".block-metadata-api-cache-size", ConfigDefault.BlockMetadataApiCacheSize, "size (in bytes) of lru cache storing the blockMetadata to service arb_getRawBlockMetadata")
 	f.Uint64(prefix+".block-metadata-api-blocks-limit", ConfigDefault.BlockMetadataApiBlocksLimit, "maximum number of blocks allowed to be queried for blockMetadata per arb_getRawBlockMetadata query. Enabled by default, set 0 to disable the limit")
+	BlockMetadataIndexConfigAddOptions(prefix+".block-metadata-index", f)
+	f.StringSlice(prefix+".preconf-signers", ConfigDefault.PreconfSigners, "addresses signing the sequencer feed, the only signers whose preconfs arb_checkPreconf accepts")
+	PricingHistoryConfigAddOptions(prefix+".pricing-history", f)
+	rpcclient.RPCClientAddOptions(prefix+".consensus-client", f, &ConfigDefault.ConsensusClient)
 }
 
 var ConfigDefault = Config{
@@ -161,6 +185,10 @@ var ConfigDefault = Config{
 	StylusTarget:                DefaultStylusTargetConfig,
 	BlockMetadataApiCacheSize:   100 * 1024 * 1024,
 	BlockMetadataApiBlocksLimit: 100,
+	BlockMetadataIndex:          DefaultBlockMetadataIndexConfig,
+	PreconfSigners:              []string{},
+	PricingHistory:              DefaultPricingHistoryConfig,
+	ConsensusClient:             execrpc.DefaultClientConfig,
 }
 
 type ConfigFetcher func() *Config
@@ -180,6 +208,8 @@ type ExecutionNode struct {
 	ClassicOutbox            *ClassicOutboxRetriever
 	started                  atomic.Bool
 	bulkBlockMetadataFetcher *BulkBlockMetadataFetcher
+	blockMetadataIndexer     *BlockMetadataIndexer
+	consensusClient          *execrpc.ConsensusClient
 }
 
 func CreateExecutionNode(
@@ -270,13 +300,39 @@ func CreateExecutionNode(
 	}
 
 	bulkBlockMetadataFetcher := NewBulkBlockMetadataFetcher(l2BlockChain, execEngine, config.BlockMetadataApiCacheSize, config.BlockMetadataApiBlocksLimit)
+	var blockMetadataIndexer *BlockMetadataIndexer
+	if config.BlockMetadataIndex.Enable {
+		blockMetadataIndexer = NewBlockMetadataIndexer(func() *BlockMetadataIndexConfig { return &configFetcher().BlockMetadataIndex }, chainDB, l2BlockChain, execEngine)
+	}
+	var pricingHistory *PricingHistory
+	if config.PricingHistory.Enable {
+		pricingHistory = NewPricingHistory(func() *PricingHistoryConfig { return &configFetcher().PricingHistory }, chainDB, l2BlockChain)
+		execEngine.EnablePricingHistory(pricingHistory)
+	}
+	roundTiming := &roundTimingFetcher{
+		auctionAddr:  common.HexToAddress(config.BlockMetadataIndex.AuctionContractAddress),
+		filterSystem: filterSystem,
+		apiBackend:   backend.APIBackend(),
+	}
+
+	preconfVerifier, err := signature.NewVerifier(&signature.VerifierConfig{AllowedAddresses: config.PreconfSigners}, nil)
+	if err != nil {
+		return nil, err
+	}
+	txStatusFetcher := NewTxStatusFetcher(chainDB, l2BlockChain, execEngine, sequencer, preconfVerifier)
 
 	apis := []rpc.API{{
 		Namespace: "arb",
 		Version:   "1.0",
-		Service:   NewArbAPI(txPublisher, bulkBlockMetadataFetcher),
+		Service:   NewArbAPI(txPublisher, bulkBlockMetadataFetcher, txStatusFetcher, pricingHistory),
 		Public:    false,
 	}}
+	apis = append(apis, rpc.API{
+		Namespace: "blockmetadata",
+		Version:   "1.0",
+		Service:   NewBlockMetadataAPI(l2BlockChain, execEngine, blockMetadataIndexer, roundTiming),
+		Public:    false,
+	})
 	apis = append(apis, rpc.API{
 		Namespace:     "auctioneer",
 		Version:       "1.0",
@@ -316,9 +372,7 @@ func CreateExecutionNode(
 		Public:    false,
 	})
 
-	stack.RegisterAPIs(apis)
-
-	return &ExecutionNode{
+	execNode := &ExecutionNode{
 		ChainDB:                  chainDB,
 		Backend:                  backend,
 		FilterSystem:             filterSystem,
@@ -332,8 +386,24 @@ func CreateExecutionNode(
 		ParentChainReader:        parentChainReader,
 		ClassicOutbox:            classicOutbox,
 		bulkBlockMetadataFetcher: bulkBlockMetadataFetcher,
-	}, nil
+		blockMetadataIndexer:     blockMetadataIndexer,
+	}
+
+	if config.ConsensusClient.URL != "" {
+		// Consensus runs in another process, and drives this node over RPC
+		execNode.consensusClient = execrpc.NewConsensusClient(func() *rpcclient.ClientConfig { return &configFetcher().ConsensusClient }, stack)
+		apis = append(apis, rpc.API{
+			Namespace:     execrpc.ExecutionNamespace,
+			Version:       "1.0",
+			Service:       execrpc.NewExecutionServerAPI(execNode),
+			Public:        false,
+			Authenticated: true,
+		})
+	}
 
+	stack.RegisterAPIs(apis)
+
+	return execNode, nil
 }
 
 func (n *ExecutionNode) MarkFeedStart(to arbutil.MessageIndex) {
@@ -373,6 +443,12 @@ func (n *ExecutionNode) Start(ctx context.Context) error {
 	// if err != nil {
 	// 	return fmt.Errorf("error starting geth stack: %w", err)
 	// }
+	if n.consensusClient != nil {
+		if err := n.consensusClient.Start(ctx); err != nil {
+			return fmt.Errorf("error connecting to consensus node: %w", err)
+		}
+		n.SetConsensusClient(n.consensusClient)
+	}
 	n.ExecEngine.Start(ctx)
 	err := n.TxPublisher.Start(ctx)
 	if err != nil {
@@ -382,6 +458,9 @@ func (n *ExecutionNode) Start(ctx context.Context) error {
 		n.ParentChainReader.Start(ctx)
 	}
 	n.bulkBlockMetadataFetcher.Start(ctx)
+	if n.blockMetadataIndexer != nil {
+		n.blockMetadataIndexer.Start(ctx)
+	}
 	return nil
 }
 
@@ -390,6 +469,9 @@ func (n *ExecutionNode) StopAndWait() {
 		return
 	}
 	n.bulkBlockMetadataFetcher.StopAndWait()
+	if n.blockMetadataIndexer != nil {
+		n.blockMetadataIndexer.StopAndWait()
+	}
 	// TODO after separation
 	// n.Stack.StopRPC() // does nothing if not running
 	if n.TxPublisher.Started() {
@@ -402,6 +484,9 @@ func (n *ExecutionNode) StopAndWait() {
 	if n.ExecEngine.Started() {
 		n.ExecEngine.StopAndWait()
 	}
+	if n.consensusClient != nil && n.consensusClient.Started() {
+		n.consensusClient.StopAndWait()
+	}
 	n.ArbInterface.BlockChain().Stop() // does nothing if not running
 	if err := n.Backend.Stop(); err != nil {
 		log.Error("backend stop", "err", err)
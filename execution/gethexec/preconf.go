@@ -0,0 +1,192 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/signature"
+)
+
+var ErrPreconfsDisabled = errors.New("preconfirmations are not enabled on this sequencer")
+
+// The outcomes of checking a preconf with arb_checkPreconf.
+const (
+	// The preconf's message isn't in a batch posted to the parent chain yet
+	PreconfPending = "pending"
+	// The posted batch agrees with the preconf
+	PreconfHonored = "honored"
+	// The posted batch contradicts the preconf, see the evidence
+	PreconfViolated = "violated"
+	// The preconf's message isn't in a posted batch yet, but the block this node got from the feed
+	// contradicts the preconf. It's an early warning rather than evidence, as the feed could still be reorged.
+	PreconfConflicting = "conflicting"
+)
+
+// preconfRequest is shared between a queued transaction and the caller waiting on it.
+// The sequencer sets header before returning the transaction's result, or forwarded if the
+// transaction was forwarded to the new chosen sequencer while queued.
+type preconfRequest struct {
+	header    *types.Header
+	forwarded *signature.Preconf
+}
+
+// SetPreconfSigner sets the key used to sign preconfirmations. It must be called before Start.
+func (s *Sequencer) SetPreconfSigner(signer signature.DataSignerFunc) {
+	s.preconfSigner = signer
+}
+
+// PublishTransactionWithPreconf sequences the transaction and returns a signed promise of the
+// message index and block it was sequenced in.
+func (s *Sequencer) PublishTransactionWithPreconf(parentCtx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	_, forwarder := s.GetPauseAndForwarder()
+	if forwarder != nil {
+		preconf, err := forwarder.PublishTransactionWithPreconf(parentCtx, tx, options)
+		if err == nil {
+			s.txStatuses.set(tx.Hash(), TxStatusForwarded, nil, forwarder.PrimaryTarget())
+		}
+		if !errors.Is(err, ErrNoSequencer) {
+			return preconf, err
+		}
+	}
+	if !s.config().EnablePreconfs || s.preconfSigner == nil {
+		return nil, ErrPreconfsDisabled
+	}
+
+	request := &preconfRequest{}
+	conditionalOptions, conditions := options.split()
+	if err := s.sequenceTransaction(parentCtx, tx, conditionalOptions, conditions, request); err != nil {
+		return nil, err
+	}
+	if request.forwarded != nil {
+		return request.forwarded, nil
+	}
+	if request.header == nil {
+		log.Error("sequenced transaction without recording its block", "txHash", tx.Hash())
+		return nil, sequencerInternalError
+	}
+	msgIdx, err := s.execEngine.BlockNumberToMessageIndex(request.header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	preconf := &signature.Preconf{
+		ChainId:      hexutil.Uint64(s.execEngine.bc.Config().ChainID.Uint64()),
+		TxHash:       tx.Hash(),
+		MessageIndex: hexutil.Uint64(msgIdx),
+		BlockHash:    request.header.Hash(),
+		Timestamp:    hexutil.Uint64(request.header.Time),
+	}
+	if err := preconf.Sign(s.preconfSigner); err != nil {
+		log.Error("error signing preconf", "txHash", tx.Hash(), "err", err)
+		return nil, sequencerInternalError
+	}
+	return preconf, nil
+}
+
+// PreconfViolation is evidence that the signer of a preconf didn't honor it. Anyone can check
+// it against the batch posted to the parent chain at ParentChainBlock.
+type PreconfViolation struct {
+	Preconf *signature.Preconf `json:"preconf"`
+	Signer  common.Address     `json:"signer"`
+	Reason  string             `json:"reason"`
+	// The block at the preconf's message index according to the posted batch
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	ActualBlockHash common.Hash    `json:"actualBlockHash"`
+	ActualTimestamp hexutil.Uint64 `json:"actualTimestamp"`
+	// The block the transaction is actually in, if any
+	TxBlockNumber    *hexutil.Uint64 `json:"txBlockNumber,omitempty"`
+	BatchNumber      hexutil.Uint64  `json:"batchNumber"`
+	ParentChainBlock *hexutil.Uint64 `json:"parentChainBlock,omitempty"`
+}
+
+type PreconfCheckResult struct {
+	Status string         `json:"status"`
+	Signer common.Address `json:"signer"`
+	// Only set if the status is violated or conflicting, in which case it has no batch
+	Violation *PreconfViolation `json:"violation,omitempty"`
+}
+
+// CheckPreconf compares a preconf with this node's chain. It only reports a violation once the
+// preconf's message is in a posted batch, as until then the sequencer's feed could still be reorged.
+// Before that, a block from the feed contradicting the preconf is reported as conflicting.
+// Only preconfs signed by one of the configured preconf signers are checked.
+func (f *TxStatusFetcher) CheckPreconf(ctx context.Context, preconf *signature.Preconf) (*PreconfCheckResult, error) {
+	if err := f.preconfVerifier.VerifyPreconf(ctx, preconf); err != nil {
+		return nil, err
+	}
+	signer, err := preconf.Signer()
+	if err != nil {
+		return nil, err
+	}
+	if chainId := f.bc.Config().ChainID.Uint64(); uint64(preconf.ChainId) != chainId {
+		return nil, fmt.Errorf("preconf is for chain %v, not %v", preconf.ChainId, chainId)
+	}
+	result := &PreconfCheckResult{Status: PreconfPending, Signer: signer}
+	consensus := f.execEngine.consensus
+	if consensus == nil {
+		return result, nil
+	}
+	msgIdx := arbutil.MessageIndex(preconf.MessageIndex)
+	batch, found, err := consensus.FindInboxBatchContainingMessage(msgIdx)
+	if err != nil {
+		return nil, err
+	}
+	blockNum := f.execEngine.MessageIndexToBlockNumber(msgIdx)
+	header := f.bc.GetHeaderByNumber(blockNum)
+	if header == nil {
+		// The message wasn't received or executed yet
+		return result, nil
+	}
+
+	violation := &PreconfViolation{
+		Preconf:         preconf,
+		Signer:          signer,
+		BlockNumber:     hexutil.Uint64(blockNum),
+		ActualBlockHash: header.Hash(),
+		ActualTimestamp: hexutil.Uint64(header.Time),
+	}
+	txBlockNum := rawdb.ReadTxLookupEntry(f.chainDB, preconf.TxHash)
+	if txBlockNum != nil {
+		violation.TxBlockNumber = (*hexutil.Uint64)(txBlockNum)
+	}
+	switch {
+	case header.Hash() != preconf.BlockHash:
+		violation.Reason = "block hash mismatch"
+	case header.Time != uint64(preconf.Timestamp):
+		violation.Reason = "timestamp mismatch"
+	case txBlockNum == nil || *txBlockNum != blockNum:
+		violation.Reason = "transaction not in block"
+	default:
+		if found {
+			result.Status = PreconfHonored
+		}
+		return result, nil
+	}
+	if !found {
+		log.Warn("sequencer preconf conflicts with feed", "signer", signer, "txHash", preconf.TxHash, "msgIdx", msgIdx, "reason", violation.Reason)
+		result.Status = PreconfConflicting
+		result.Violation = violation
+		return result, nil
+	}
+	violation.BatchNumber = hexutil.Uint64(batch)
+	parentChainBlock, err := consensus.GetBatchParentChainBlock(batch)
+	if err != nil {
+		log.Warn("error getting parent chain block of batch", "batch", batch, "err", err)
+	} else {
+		violation.ParentChainBlock = (*hexutil.Uint64)(&parentChainBlock)
+	}
+	log.Warn("sequencer preconf not honored", "signer", signer, "txHash", preconf.TxHash, "msgIdx", msgIdx, "reason", violation.Reason)
+	result.Status = PreconfViolated
+	result.Violation = violation
+	return result, nil
+}
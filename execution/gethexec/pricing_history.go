@@ -0,0 +1,250 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+)
+
+var (
+	pricingHistoryPrefix = []byte("\x00prhist-b") // block number -> indexedPricing
+
+	ErrPricingHistoryDisabled = errors.New("pricing history is not enabled")
+)
+
+type PricingHistoryConfig struct {
+	Enable        bool   `koanf:"enable"`
+	MaxQueryRange uint64 `koanf:"max-query-range" reload:"hot"`
+	Retention     uint64 `koanf:"retention" reload:"hot"`
+}
+
+var DefaultPricingHistoryConfig = PricingHistoryConfig{
+	Enable:        false,
+	MaxQueryRange: 10_000,
+	Retention:     2_000_000,
+}
+
+func PricingHistoryConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultPricingHistoryConfig.Enable, "index the L1 and L2 pricing state of each new block and serve it with arb_pricingHistory")
+	f.Uint64(prefix+".max-query-range", DefaultPricingHistoryConfig.MaxQueryRange, "maximum number of blocks that can be queried at once (0 = unlimited)")
+	f.Uint64(prefix+".retention", DefaultPricingHistoryConfig.Retention, "number of most recent blocks to keep the pricing state of, older entries are pruned as new blocks are indexed (0 = keep all)")
+}
+
+type PricingHistoryConfigFetcher func() *PricingHistoryConfig
+
+// indexedPricing is the pricing state after a block. Surplus is stored as its absolute value and sign,
+// as rlp doesn't support negative numbers.
+type indexedPricing struct {
+	Hash               common.Hash
+	Timestamp          uint64
+	L1PricePerUnit     *big.Int
+	L1UnitsSinceUpdate uint64
+	L1Surplus          *big.Int
+	L1SurplusNegative  bool
+	L1FundsDue         *big.Int
+	L2BaseFee          *big.Int
+	L2GasBacklog       uint64
+	L2SpeedLimitPerSec uint64
+}
+
+// PricingHistoryEntry is the L1 and L2 pricing state after a block, as returned by arb_pricingHistory.
+type PricingHistoryEntry struct {
+	BlockNumber        hexutil.Uint64 `json:"blockNumber"`
+	BlockHash          common.Hash    `json:"blockHash"`
+	Timestamp          hexutil.Uint64 `json:"timestamp"`
+	L1PricePerUnit     *hexutil.Big   `json:"l1PricePerUnit"`
+	L1UnitsSinceUpdate hexutil.Uint64 `json:"l1UnitsSinceUpdate"`
+	// Negative if the batch posters are owed more than has been collected
+	L1Surplus             *hexutil.Big   `json:"l1Surplus"`
+	L1FundsDue            *hexutil.Big   `json:"l1FundsDue"`
+	L2BaseFee             *hexutil.Big   `json:"l2BaseFee"`
+	L2GasBacklog          hexutil.Uint64 `json:"l2GasBacklog"`
+	L2SpeedLimitPerSecond hexutil.Uint64 `json:"l2SpeedLimitPerSecond"`
+}
+
+// PricingHistory indexes the pricing state of blocks as they're appended, so past ranges can be
+// served without the state of old blocks.
+type PricingHistory struct {
+	config PricingHistoryConfigFetcher
+	db     ethdb.Database
+	bc     *core.BlockChain
+	chain  blockMetadataIndexChain
+}
+
+func NewPricingHistory(config PricingHistoryConfigFetcher, db ethdb.Database, bc *core.BlockChain) *PricingHistory {
+	return &PricingHistory{
+		config: config,
+		db:     db,
+		bc:     bc,
+		chain:  bc,
+	}
+}
+
+func pricingHistoryKey(blockNum uint64) []byte {
+	key := make([]byte, 0, len(pricingHistoryPrefix)+8)
+	key = append(key, pricingHistoryPrefix...)
+	return binary.BigEndian.AppendUint64(key, blockNum)
+}
+
+// record indexes the pricing state after the block, which must already be written, and prunes the
+// entries that fell out of the retention window. A block replacing another at the same height in a
+// reorg overwrites its entry.
+func (h *PricingHistory) record(block *types.Block) error {
+	statedb, err := h.bc.StateAt(block.Root())
+	if err != nil {
+		return err
+	}
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return err
+	}
+	l1Pricing := arbState.L1PricingState()
+	l2Pricing := arbState.L2PricingState()
+	entry := indexedPricing{
+		Hash:      block.Hash(),
+		Timestamp: block.Time(),
+	}
+	if entry.L1PricePerUnit, err = l1Pricing.PricePerUnit(); err != nil {
+		return err
+	}
+	if entry.L1UnitsSinceUpdate, err = l1Pricing.UnitsSinceUpdate(); err != nil {
+		return err
+	}
+	surplus, err := l1Pricing.GetL1PricingSurplus()
+	if err != nil {
+		return err
+	}
+	entry.L1Surplus = new(big.Int).Abs(surplus)
+	entry.L1SurplusNegative = surplus.Sign() < 0
+	if entry.L1FundsDue, err = l1Pricing.BatchPosterTable().TotalFundsDue(); err != nil {
+		return err
+	}
+	if entry.L2BaseFee, err = l2Pricing.BaseFeeWei(); err != nil {
+		return err
+	}
+	if entry.L2GasBacklog, err = l2Pricing.GasBacklog(); err != nil {
+		return err
+	}
+	if entry.L2SpeedLimitPerSec, err = l2Pricing.SpeedLimitPerSecond(); err != nil {
+		return err
+	}
+	if err := writeIndexedPricing(h.db, block.NumberU64(), &entry); err != nil {
+		return err
+	}
+	return h.prune(block.NumberU64())
+}
+
+// prune deletes the entries of blocks older than the retention window ending at head. The pruned
+// entries are the first ones under the prefix, so once caught up it only seeks to the oldest kept entry.
+func (h *PricingHistory) prune(head uint64) error {
+	retention := h.config().Retention
+	if retention == 0 || head < retention {
+		return nil
+	}
+	keepFrom := head - retention + 1
+	iter := h.db.NewIterator(pricingHistoryPrefix, nil)
+	defer iter.Release()
+	batch := h.db.NewBatch()
+	for iter.Next() {
+		if binary.BigEndian.Uint64(iter.Key()[len(pricingHistoryPrefix):]) >= keepFrom {
+			break
+		}
+		if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func writeIndexedPricing(db ethdb.KeyValueWriter, blockNum uint64, entry *indexedPricing) error {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		return err
+	}
+	return db.Put(pricingHistoryKey(blockNum), data)
+}
+
+// Fetch returns the indexed pricing state of the canonical blocks in the range, resolving tags like latest.
+func (h *PricingHistory) Fetch(fromBlock, toBlock rpc.BlockNumber) ([]PricingHistoryEntry, error) {
+	fromBlock, _ = h.bc.ClipToPostNitroGenesis(fromBlock)
+	toBlock, _ = h.bc.ClipToPostNitroGenesis(toBlock)
+	// #nosec G115
+	entries, err := h.Range(uint64(fromBlock), uint64(toBlock))
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []PricingHistoryEntry{}
+	}
+	return entries, nil
+}
+
+// Range returns the indexed pricing state of the canonical blocks in [from, to]. Blocks appended before
+// the index was enabled are missing.
+func (h *PricingHistory) Range(from, to uint64) ([]PricingHistoryEntry, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid inputs, fromBlock: %d is greater than toBlock: %d", from, to)
+	}
+	if limit := h.config().MaxQueryRange; limit > 0 && to-from+1 > limit {
+		return nil, fmt.Errorf("number of blocks requested exceeded. Range requested- %d, Limit- %d", to-from+1, limit)
+	}
+	iter := h.db.NewIterator(pricingHistoryPrefix, binary.BigEndian.AppendUint64(nil, from))
+	defer iter.Release()
+	var entries []PricingHistoryEntry
+	for iter.Next() {
+		blockNum := binary.BigEndian.Uint64(iter.Key()[len(pricingHistoryPrefix):])
+		if blockNum > to {
+			break
+		}
+		var entry indexedPricing
+		if err := rlp.DecodeBytes(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		if h.chain.GetCanonicalHash(blockNum) != entry.Hash {
+			// Left over from a block that was reorged out
+			continue
+		}
+		surplus := entry.L1Surplus
+		if entry.L1SurplusNegative {
+			surplus = new(big.Int).Neg(surplus)
+		}
+		entries = append(entries, PricingHistoryEntry{
+			BlockNumber:           hexutil.Uint64(blockNum),
+			BlockHash:             entry.Hash,
+			Timestamp:             hexutil.Uint64(entry.Timestamp),
+			L1PricePerUnit:        (*hexutil.Big)(entry.L1PricePerUnit),
+			L1UnitsSinceUpdate:    hexutil.Uint64(entry.L1UnitsSinceUpdate),
+			L1Surplus:             (*hexutil.Big)(surplus),
+			L1FundsDue:            (*hexutil.Big)(entry.L1FundsDue),
+			L2BaseFee:             (*hexutil.Big)(entry.L2BaseFee),
+			L2GasBacklog:          hexutil.Uint64(entry.L2GasBacklog),
+			L2SpeedLimitPerSecond: hexutil.Uint64(entry.L2SpeedLimitPerSec),
+		})
+	}
+	return entries, iter.Error()
+}
@@ -0,0 +1,84 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestPricingHistoryRange(t *testing.T) {
+	chain := &testIndexChain{}
+	db := rawdb.NewMemoryDatabase()
+	config := &PricingHistoryConfig{MaxQueryRange: 5}
+	history := &PricingHistory{
+		config: func() *PricingHistoryConfig { return config },
+		db:     db,
+		chain:  chain,
+	}
+	write := func(blockNum uint64, surplus int64) {
+		err := writeIndexedPricing(db, blockNum, &indexedPricing{
+			Hash:              chain.GetCanonicalHash(blockNum),
+			Timestamp:         chain.headers[blockNum].Time,
+			L1PricePerUnit:    big.NewInt(50),
+			L1Surplus:         new(big.Int).Abs(big.NewInt(surplus)),
+			L1SurplusNegative: surplus < 0,
+			L1FundsDue:        big.NewInt(0),
+			L2BaseFee:         big.NewInt(100_000_000),
+			L2GasBacklog:      blockNum,
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 6; i++ {
+		chain.addBlock(nil, 0)
+	}
+	// Blocks before the index was enabled are missing
+	for i := uint64(2); i < 6; i++ {
+		write(i, int64(i)-3)
+	}
+
+	entries, err := history.Range(0, 4)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, uint64(2), uint64(entries[0].BlockNumber))
+	require.Equal(t, big.NewInt(-1), entries[0].L1Surplus.ToInt())
+	require.Equal(t, big.NewInt(1), entries[2].L1Surplus.ToInt())
+	require.Equal(t, uint64(40), uint64(entries[2].Timestamp))
+
+	_, err = history.Range(0, 5)
+	require.Error(t, err)
+	_, err = history.Range(3, 2)
+	require.Error(t, err)
+
+	// Entries of reorged out blocks are skipped until overwritten
+	chain.reorg(4)
+	chain.addBlock(nil, 1)
+	chain.addBlock(nil, 1)
+	entries, err = history.Range(2, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	write(4, 7)
+	entries, err = history.Range(2, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, chain.GetCanonicalHash(4), entries[2].BlockHash)
+	require.Equal(t, big.NewInt(7), entries[2].L1Surplus.ToInt())
+
+	// Entries older than the retention window are pruned
+	config.Retention = 2
+	require.NoError(t, history.prune(5))
+	entries, err = history.Range(0, 4)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, uint64(4), uint64(entries[0].BlockNumber))
+	config.Retention = 0
+	require.NoError(t, history.prune(5))
+	entries, err = history.Range(1, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 	"math/big"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -41,6 +42,7 @@ import (
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
@@ -64,22 +66,28 @@ var (
 )
 
 type SequencerConfig struct {
-	Enable                       bool            `koanf:"enable"`
-	MaxBlockSpeed                time.Duration   `koanf:"max-block-speed" reload:"hot"`
-	MaxRevertGasReject           uint64          `koanf:"max-revert-gas-reject" reload:"hot"`
-	MaxAcceptableTimestampDelta  time.Duration   `koanf:"max-acceptable-timestamp-delta" reload:"hot"`
-	SenderWhitelist              []string        `koanf:"sender-whitelist"`
-	Forwarder                    ForwarderConfig `koanf:"forwarder"`
-	QueueSize                    int             `koanf:"queue-size"`
-	QueueTimeout                 time.Duration   `koanf:"queue-timeout" reload:"hot"`
-	NonceCacheSize               int             `koanf:"nonce-cache-size" reload:"hot"`
-	MaxTxDataSize                int             `koanf:"max-tx-data-size" reload:"hot"`
-	NonceFailureCacheSize        int             `koanf:"nonce-failure-cache-size" reload:"hot"`
-	NonceFailureCacheExpiry      time.Duration   `koanf:"nonce-failure-cache-expiry" reload:"hot"`
-	ExpectedSurplusSoftThreshold string          `koanf:"expected-surplus-soft-threshold" reload:"hot"`
-	ExpectedSurplusHardThreshold string          `koanf:"expected-surplus-hard-threshold" reload:"hot"`
-	EnableProfiling              bool            `koanf:"enable-profiling" reload:"hot"`
-	Dangerous                    DangerousConfig `koanf:"dangerous"`
+	Enable                       bool             `koanf:"enable"`
+	MaxBlockSpeed                time.Duration    `koanf:"max-block-speed" reload:"hot"`
+	MaxRevertGasReject           uint64           `koanf:"max-revert-gas-reject" reload:"hot"`
+	MaxAcceptableTimestampDelta  time.Duration    `koanf:"max-acceptable-timestamp-delta" reload:"hot"`
+	SenderWhitelist              []string         `koanf:"sender-whitelist"`
+	Forwarder                    ForwarderConfig  `koanf:"forwarder"`
+	QueueSize                    int              `koanf:"queue-size"`
+	QueueTimeout                 time.Duration    `koanf:"queue-timeout" reload:"hot"`
+	NonceCacheSize               int              `koanf:"nonce-cache-size" reload:"hot"`
+	MaxTxDataSize                int              `koanf:"max-tx-data-size" reload:"hot"`
+	MaxBundleTxs                 int              `koanf:"max-bundle-txs" reload:"hot"`
+	NonceFailureCacheSize        int              `koanf:"nonce-failure-cache-size" reload:"hot"`
+	NonceFailureCacheExpiry      time.Duration    `koanf:"nonce-failure-cache-expiry" reload:"hot"`
+	ExpectedSurplusSoftThreshold string           `koanf:"expected-surplus-soft-threshold" reload:"hot"`
+	ExpectedSurplusHardThreshold string           `koanf:"expected-surplus-hard-threshold" reload:"hot"`
+	EnableProfiling              bool             `koanf:"enable-profiling" reload:"hot"`
+	EnablePreconfs               bool             `koanf:"enable-preconfs"`
+	TxOrdering                   TxOrderingConfig `koanf:"tx-ordering" reload:"hot"`
+	TxFilters                    TxFiltersConfig  `koanf:"tx-filters"`
+	TxJournal                    TxJournalConfig  `koanf:"tx-journal"`
+	TxStatusCacheSize            int              `koanf:"tx-status-cache-size" reload:"hot"`
+	Dangerous                    DangerousConfig  `koanf:"dangerous"`
 	expectedSurplusSoftThreshold int
 	expectedSurplusHardThreshold int
 }
@@ -138,6 +146,18 @@ func (c *SequencerConfig) Validate() error {
 	if c.MaxTxDataSize > arbostypes.MaxL2MessageSize-50000 {
 		return errors.New("max-tx-data-size too large for MaxL2MessageSize")
 	}
+	if err := c.Forwarder.Validate(); err != nil {
+		return err
+	}
+	if err := c.TxOrdering.Validate(); err != nil {
+		return err
+	}
+	if err := c.TxFilters.Validate(); err != nil {
+		return err
+	}
+	if err := c.TxJournal.Validate(); err != nil {
+		return err
+	}
 	return c.Dangerous.Timeboost.Validate()
 }
 
@@ -175,11 +195,17 @@ var DefaultSequencerConfig = SequencerConfig{
 	// 95% of the default batch poster limit, leaving 5KB for headers and such
 	// This default is overridden for L3 chains in applyChainParameters in cmd/nitro/nitro.go
 	MaxTxDataSize:                95000,
+	MaxBundleTxs:                 16,
 	NonceFailureCacheSize:        1024,
 	NonceFailureCacheExpiry:      time.Second,
 	ExpectedSurplusSoftThreshold: "default",
 	ExpectedSurplusHardThreshold: "default",
 	EnableProfiling:              false,
+	EnablePreconfs:               false,
+	TxOrdering:                   DefaultTxOrderingConfig,
+	TxFilters:                    DefaultTxFiltersConfig,
+	TxJournal:                    DefaultTxJournalConfig,
+	TxStatusCacheSize:            100_000,
 	Dangerous:                    DefaultDangerousConfig,
 }
 
@@ -200,11 +226,17 @@ func SequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Duration(prefix+".queue-timeout", DefaultSequencerConfig.QueueTimeout, "maximum amount of time transaction can wait in queue")
 	f.Int(prefix+".nonce-cache-size", DefaultSequencerConfig.NonceCacheSize, "size of the tx sender nonce cache")
 	f.Int(prefix+".max-tx-data-size", DefaultSequencerConfig.MaxTxDataSize, "maximum transaction size the sequencer will accept")
+	f.Int(prefix+".max-bundle-txs", DefaultSequencerConfig.MaxBundleTxs, "maximum number of transactions in a bundle submitted with arb_sendBundle (0 = bundles disabled)")
 	f.Int(prefix+".nonce-failure-cache-size", DefaultSequencerConfig.NonceFailureCacheSize, "number of transactions with too high of a nonce to keep in memory while waiting for their predecessor")
 	f.Duration(prefix+".nonce-failure-cache-expiry", DefaultSequencerConfig.NonceFailureCacheExpiry, "maximum amount of time to wait for a predecessor before rejecting a tx with nonce too high")
 	f.String(prefix+".expected-surplus-soft-threshold", DefaultSequencerConfig.ExpectedSurplusSoftThreshold, "if expected surplus is lower than this value, warnings are posted")
 	f.String(prefix+".expected-surplus-hard-threshold", DefaultSequencerConfig.ExpectedSurplusHardThreshold, "if expected surplus is lower than this value, new incoming transactions will be denied")
 	f.Bool(prefix+".enable-profiling", DefaultSequencerConfig.EnableProfiling, "enable CPU profiling and tracing")
+	f.Bool(prefix+".enable-preconfs", DefaultSequencerConfig.EnablePreconfs, "sign preconfirmations of sequenced transactions for arb_sendRawTransactionWithPreconf, with the key that signs the sequencer feed")
+	TxOrderingConfigAddOptions(prefix+".tx-ordering", f)
+	TxFiltersConfigAddOptions(prefix+".tx-filters", f)
+	TxJournalConfigAddOptions(prefix+".tx-journal", f)
+	f.Int(prefix+".tx-status-cache-size", DefaultSequencerConfig.TxStatusCacheSize, "number of recently submitted transactions to remember the sequencer status of for arb_getTransactionStatus")
 }
 
 func TimeboostAddOptions(prefix string, f *flag.FlagSet) {
@@ -232,6 +264,11 @@ type txQueueItem struct {
 	ctx             context.Context
 	firstAppearance time.Time
 	isTimeboosted   bool
+	journal         *txJournal // nil if the item isn't journaled
+	statuses        *txStatusCache
+	bundle          *Bundle // if set, tx is the first transaction of the bundle
+	preconf         *preconfRequest
+	conditions      *ConditionalExtensions
 }
 
 func (i *txQueueItem) returnResult(err error) {
@@ -239,10 +276,57 @@ func (i *txQueueItem) returnResult(err error) {
 		log.Error("attempting to return result to already finished queue item", "err", err)
 		return
 	}
+	i.journal.remove(i.tx.Hash(), err == nil)
+	if err != nil {
+		i.setStatus(TxStatusRejected, err, "")
+	}
 	i.resultChan <- err
 	close(i.resultChan)
 }
 
+func (i *txQueueItem) setStatus(status string, err error, forwardedTo string) {
+	if i.bundle == nil {
+		i.statuses.set(i.tx.Hash(), status, err, forwardedTo)
+		return
+	}
+	for _, tx := range i.bundle.Transactions {
+		i.statuses.set(tx.Hash(), status, err, forwardedTo)
+	}
+}
+
+func (i *txQueueItem) forward(forwarder *TxForwarder) error {
+	if i.bundle != nil {
+		return forwarder.PublishBundle(i.ctx, i.bundle)
+	}
+	if i.preconf != nil {
+		// The caller is waiting on a preconf, which the new chosen sequencer signs instead
+		preconf, err := forwarder.PublishTransactionWithPreconf(i.ctx, i.tx, i.extendedOptions())
+		if err == nil {
+			i.preconf.forwarded = preconf
+		}
+		return err
+	}
+	if i.conditions != nil {
+		return forwarder.PublishConditionalTransaction(i.ctx, i.tx, i.extendedOptions())
+	}
+	return forwarder.PublishTransaction(i.ctx, i.tx, i.options)
+}
+
+// extendedOptions recombines the item's options and conditional extensions to forward them.
+func (i *txQueueItem) extendedOptions() *ExtendedConditionalOptions {
+	if i.options == nil && i.conditions == nil {
+		return nil
+	}
+	options := &ExtendedConditionalOptions{}
+	if i.options != nil {
+		options.ConditionalOptions = *i.options
+	}
+	if i.conditions != nil {
+		options.ConditionalExtensions = *i.conditions
+	}
+	return options
+}
+
 type nonceCache struct {
 	cache *containers.LruCache[common.Address, uint64]
 	block common.Hash
@@ -351,6 +435,7 @@ func (c nonceFailureCache) Add(err NonceError, queueItem txQueueItem) {
 		return
 	}
 	key := addressAndNonce{err.sender, err.txNonce}
+	queueItem.statuses.set(queueItem.tx.Hash(), TxStatusWaitingForNonce, err, "")
 	val := &nonceFailure{
 		queueItem: queueItem,
 		nonceErr:  err,
@@ -396,11 +481,22 @@ type Sequencer struct {
 	l1Reader           *headerreader.HeaderReader
 	config             SequencerConfigFetcher
 	senderWhitelist    map[common.Address]struct{}
+	txFilters          *TxFilterChain
+	txJournal          *txJournal
+	txStatuses         *txStatusCache
+	preconfSigner      signature.DataSignerFunc
 	nonceCache         *nonceCache
 	nonceFailures      *nonceFailureCache
 	expressLaneService *expressLaneService
 	onForwarderSet     chan struct{}
 
+	// bundles that can't be sequenced yet, only accessed by createBlock and after the sequencer stopped
+	parkedBundles        []parkedBundle
+	lastBundleRetryBlock uint64
+
+	importedTxJournalsMutex sync.Mutex
+	importedTxJournals      map[string]*importedTxJournal
+
 	L1BlockAndTimeMutex sync.Mutex
 	l1BlockNumber       atomic.Uint64
 	l1Timestamp         uint64
@@ -431,12 +527,27 @@ func NewSequencer(execEngine *ExecutionEngine, l1Reader *headerreader.HeaderRead
 		}
 		senderWhitelist[common.HexToAddress(address)] = struct{}{}
 	}
+	txFilters, err := NewTxFilterChain(&config.TxFilters)
+	if err != nil {
+		return nil, err
+	}
+	var journal *txJournal
+	if config.TxJournal.File != "" {
+		journal, err = openTxJournal(&config.TxJournal)
+		if err != nil {
+			return nil, err
+		}
+	}
 	s := &Sequencer{
 		execEngine:                        execEngine,
 		txQueue:                           make(chan txQueueItem, config.QueueSize),
 		l1Reader:                          l1Reader,
 		config:                            configFetcher,
 		senderWhitelist:                   senderWhitelist,
+		txFilters:                         txFilters,
+		txJournal:                         journal,
+		importedTxJournals:                make(map[string]*importedTxJournal),
+		txStatuses:                        newTxStatusCache(config.TxStatusCacheSize),
 		nonceCache:                        newNonceCache(config.NonceCacheSize),
 		l1Timestamp:                       0,
 		pauseChan:                         nil,
@@ -472,7 +583,10 @@ func (s *Sequencer) onNonceFailureEvict(_ addressAndNonce, failure *nonceFailure
 		//   - We don't need the context because queueItem has its own.
 		//   - The RPC handler is on a separate StopWaiter anyways -- we should respect its context.
 		s.LaunchUntrackedThread(func() {
-			err = forwarder.PublishTransaction(queueItem.ctx, queueItem.tx, queueItem.options)
+			err = queueItem.forward(forwarder)
+			if err == nil {
+				queueItem.setStatus(TxStatusForwarded, nil, forwarder.PrimaryTarget())
+			}
 			queueItem.returnResult(err)
 		})
 	} else {
@@ -492,22 +606,50 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 	_, forwarder := s.GetPauseAndForwarder()
 	if forwarder != nil {
 		err := forwarder.PublishTransaction(parentCtx, tx, options)
+		if err == nil {
+			s.txStatuses.set(tx.Hash(), TxStatusForwarded, nil, forwarder.PrimaryTarget())
+		}
+		if !errors.Is(err, ErrNoSequencer) {
+			return err
+		}
+	}
+	return s.sequenceTransaction(parentCtx, tx, options, nil, nil)
+}
+
+// PublishConditionalTransaction is like PublishTransaction, but also checks the conditional extensions
+// when the transaction is sequenced.
+func (s *Sequencer) PublishConditionalTransaction(parentCtx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error {
+	_, forwarder := s.GetPauseAndForwarder()
+	if forwarder != nil {
+		err := forwarder.PublishConditionalTransaction(parentCtx, tx, options)
+		if err == nil {
+			s.txStatuses.set(tx.Hash(), TxStatusForwarded, nil, forwarder.PrimaryTarget())
+		}
 		if !errors.Is(err, ErrNoSequencer) {
 			return err
 		}
 	}
+	conditionalOptions, conditions := options.split()
+	return s.sequenceTransaction(parentCtx, tx, conditionalOptions, conditions, nil)
+}
 
+// sequenceTransaction queues the transaction on this sequencer and waits for it to be sequenced.
+// If preconf is set, it's filled in with the header of the transaction's block.
+func (s *Sequencer) sequenceTransaction(parentCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, conditions *ConditionalExtensions, preconf *preconfRequest) error {
 	config := s.config()
 	queueTimeout := config.QueueTimeout
 	queueCtx, cancelFunc := ctxWithTimeout(parentCtx, queueTimeout+config.Dangerous.Timeboost.ExpressLaneAdvantage) // Include timeboost delay in ctx timeout
 	defer cancelFunc()
 
 	resultChan := make(chan error, 1)
-	err := s.publishTransactionToQueue(queueCtx, tx, options, resultChan, false /* delay tx if express lane is active */)
+	err := s.publishTransactionToQueue(queueCtx, tx, options, conditions, resultChan, false /* delay tx if express lane is active */, preconf)
 	if err != nil {
 		return err
 	}
+	return s.awaitQueueResult(parentCtx, queueTimeout, resultChan, tx.Hash())
+}
 
+func (s *Sequencer) awaitQueueResult(parentCtx context.Context, queueTimeout time.Duration, resultChan <-chan error, txHash common.Hash) error {
 	now := time.Now()
 	// Just to be safe, make sure we don't run over twice the queue timeout
 	abortCtx, cancel := ctxWithTimeout(parentCtx, queueTimeout*2)
@@ -522,12 +664,76 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 		err := abortCtx.Err()
 		if parentCtx.Err() == nil {
 			// If we've hit the abort deadline (as opposed to parentCtx being canceled), something went wrong.
-			log.Warn("Transaction sequencing hit abort deadline", "err", err, "submittedAt", now, "queueTimeout", queueTimeout*2, "txHash", tx.Hash())
+			log.Warn("Transaction sequencing hit abort deadline", "err", err, "submittedAt", now, "queueTimeout", queueTimeout*2, "txHash", txHash)
 		}
 		return err
 	}
 }
 
+// PublishBundle queues a bundle, which is sequenced alone in a block that's only created if all of its
+// transactions succeed. Bundles aren't journaled.
+func (s *Sequencer) PublishBundle(parentCtx context.Context, bundle *Bundle) error {
+	config := s.config()
+	if len(bundle.Transactions) == 0 {
+		return ErrBundleEmpty
+	}
+	if config.MaxBundleTxs == 0 {
+		return errors.New("bundles are disabled on this sequencer")
+	}
+	if len(bundle.Transactions) > config.MaxBundleTxs {
+		return fmt.Errorf("bundle has %v transactions, more than the maximum of %v", len(bundle.Transactions), config.MaxBundleTxs)
+	}
+	_, forwarder := s.GetPauseAndForwarder()
+	if forwarder != nil {
+		err := forwarder.PublishBundle(parentCtx, bundle)
+		if err == nil {
+			for _, tx := range bundle.Transactions {
+				s.txStatuses.set(tx.Hash(), TxStatusForwarded, nil, forwarder.PrimaryTarget())
+			}
+		}
+		if !errors.Is(err, ErrNoSequencer) {
+			return err
+		}
+	}
+
+	queueTimeout := config.QueueTimeout
+	queueCtx, cancelFunc := ctxWithTimeout(parentCtx, queueTimeout)
+	defer cancelFunc()
+
+	size, err := bundle.size()
+	if err != nil {
+		return err
+	}
+	for _, tx := range bundle.Transactions {
+		if err := s.checkSenderAndType(tx); err != nil {
+			return err
+		}
+	}
+	sequencerBacklogGauge.Inc(1)
+	defer sequencerBacklogGauge.Dec(1)
+
+	resultChan := make(chan error, 1)
+	queueItem := txQueueItem{
+		tx:              bundle.Transactions[0],
+		txSize:          size,
+		options:         bundle.Options,
+		resultChan:      resultChan,
+		returnedResult:  &atomic.Bool{},
+		ctx:             queueCtx,
+		firstAppearance: time.Now(),
+		statuses:        s.txStatuses,
+		bundle:          bundle,
+	}
+	queueItem.setStatus(TxStatusQueued, nil, "")
+	select {
+	case s.txQueue <- queueItem:
+	case <-queueCtx.Done():
+		queueItem.setStatus(TxStatusRejected, queueCtx.Err(), "")
+		return queueCtx.Err()
+	}
+	return s.awaitQueueResult(parentCtx, queueTimeout, resultChan, bundle.Transactions[0].Hash())
+}
+
 func (s *Sequencer) PublishAuctionResolutionTransaction(ctx context.Context, tx *types.Transaction) error {
 	if !s.config().Dangerous.Timeboost.Enable {
 		return errors.New("timeboost not enabled")
@@ -616,12 +822,12 @@ func (s *Sequencer) PublishExpressLaneTransaction(ctx context.Context, msg *time
 }
 
 func (s *Sequencer) PublishTimeboostedTransaction(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, resultChan chan error) {
-	if err := s.publishTransactionToQueue(queueCtx, tx, options, resultChan, true); err != nil {
+	if err := s.publishTransactionToQueue(queueCtx, tx, options, nil, resultChan, true, nil); err != nil {
 		resultChan <- err
 	}
 }
 
-func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, resultChan chan error, isExpressLaneController bool) error {
+func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, conditions *ConditionalExtensions, resultChan chan error, isExpressLaneController bool, preconf *preconfRequest) error {
 	config := s.config()
 	// Only try to acquire Rlock and check for hard threshold if l1reader is not nil
 	// And hard threshold was enabled, this prevents spamming of read locks when not needed
@@ -636,21 +842,8 @@ func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *type
 	sequencerBacklogGauge.Inc(1)
 	defer sequencerBacklogGauge.Dec(1)
 
-	if len(s.senderWhitelist) > 0 {
-		signer := types.LatestSigner(s.execEngine.bc.Config())
-		sender, err := types.Sender(signer, tx)
-		if err != nil {
-			return err
-		}
-		_, authorized := s.senderWhitelist[sender]
-		if !authorized {
-			return errors.New("transaction sender is not on the whitelist")
-		}
-	}
-	if tx.Type() >= types.ArbitrumDepositTxType || tx.Type() == types.BlobTxType {
-		// Should be unreachable for Arbitrum types due to UnmarshalBinary not accepting Arbitrum internal txs
-		// and we want to disallow BlobTxType since Arbitrum doesn't support EIP-4844 txs yet.
-		return types.ErrTxTypeNotSupported
+	if err := s.checkSenderAndType(tx); err != nil {
+		return err
 	}
 
 	txBytes, err := tx.MarshalBinary()
@@ -673,16 +866,51 @@ func (s *Sequencer) publishTransactionToQueue(queueCtx context.Context, tx *type
 		queueCtx,
 		time.Now(),
 		isExpressLaneController,
+		s.txJournal,
+		s.txStatuses,
+		nil,
+		preconf,
+		conditions,
+	}
+	if s.txJournal != nil {
+		if err := s.txJournal.add(tx, options, conditions, queueItem.firstAppearance, queueItem.isTimeboosted); err != nil {
+			log.Error("error journaling transaction", "txHash", tx.Hash(), "err", err)
+			return sequencerInternalError
+		}
 	}
+	// Set before queueing, as the sequencer may finish with the tx before we return from the send
+	s.txStatuses.set(tx.Hash(), TxStatusQueued, nil, "")
 	select {
 	case s.txQueue <- queueItem:
 	case <-queueCtx.Done():
+		s.txJournal.remove(tx.Hash(), false)
+		s.txStatuses.set(tx.Hash(), TxStatusRejected, queueCtx.Err(), "")
 		return queueCtx.Err()
 	}
 
 	return nil
 }
 
+func (s *Sequencer) checkSenderAndType(tx *types.Transaction) error {
+	if len(s.senderWhitelist) > 0 {
+		signer := types.LatestSigner(s.execEngine.bc.Config())
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return err
+		}
+		_, authorized := s.senderWhitelist[sender]
+		if !authorized {
+			return errors.New("transaction sender is not on the whitelist")
+		}
+	}
+	if tx.Type() >= types.ArbitrumDepositTxType || tx.Type() == types.BlobTxType {
+		// Should be unreachable for Arbitrum types due to UnmarshalBinary not accepting Arbitrum internal txs
+		// and we want to disallow BlobTxType since Arbitrum doesn't support EIP-4844 txs yet.
+		return types.ErrTxTypeNotSupported
+	}
+	return nil
+}
+
 func (s *Sequencer) preTxFilter(_ *params.ChainConfig, header *types.Header, statedb *state.StateDB, _ *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, sender common.Address, l1Info *arbos.L1Info) error {
 	if s.nonceCache.Caching() {
 		stateNonce := s.nonceCache.Get(header, statedb, sender)
@@ -700,6 +928,9 @@ func (s *Sequencer) preTxFilter(_ *params.ChainConfig, header *types.Header, sta
 		}
 		conditionalTxAcceptedBySequencerCounter.Inc(1)
 	}
+	if s.txFilters.hasPreFilters() {
+		return s.txFilters.FilterPreTx(newTxFilterInfo(tx, sender))
+	}
 	return nil
 }
 
@@ -710,6 +941,15 @@ func (s *Sequencer) postTxFilter(header *types.Header, statedb *state.StateDB, _
 	if result.Err != nil && result.UsedGas > dataGas && result.UsedGas-dataGas <= s.config().MaxRevertGasReject {
 		return arbitrum.NewRevertReason(result)
 	}
+	if s.txFilters.hasPostFilters() {
+		info := newTxFilterInfo(tx, sender)
+		info.GasUsed = result.UsedGas
+		info.Result = result
+		info.Touched = statedb.AddressInAccessList
+		if err := s.txFilters.FilterPostTx(info); err != nil {
+			return err
+		}
+	}
 	newNonce := tx.Nonce() + 1
 	s.nonceCache.Update(header, sender, newNonce)
 	newAddrAndNonce := addressAndNonce{sender, newNonce}
@@ -790,6 +1030,9 @@ func (s *Sequencer) Activate() {
 		close(s.pauseChan)
 		s.pauseChan = nil
 	}
+	if len(s.config().TxJournal.ImportFiles) > 0 {
+		s.LaunchThread(s.importTxJournals)
+	}
 	if s.expressLaneService != nil {
 		s.LaunchThread(func(context.Context) {
 			// We launch redis sync (which is best effort) in parallel to avoid blocking sequencer activation
@@ -850,11 +1093,14 @@ func (s *Sequencer) handleInactive(ctx context.Context, queueItems []txQueueItem
 	for _, item := range queueItems {
 		item := item
 		go func() {
-			res := forwarder.PublishTransaction(item.ctx, item.tx, item.options)
+			res := item.forward(forwarder)
 			if errors.Is(res, ErrNoSequencer) {
 				publishResults <- &item
 			} else {
 				publishResults <- nil
+				if res == nil {
+					item.setStatus(TxStatusForwarded, nil, forwarder.PrimaryTarget())
+				}
 				item.returnResult(res)
 			}
 		}()
@@ -872,9 +1118,20 @@ func (s *Sequencer) handleInactive(ctx context.Context, queueItems []txQueueItem
 
 var sequencerInternalError = errors.New("sequencer internal error")
 
-func (s *Sequencer) makeSequencingHooks() *arbos.SequencingHooks {
+// makeSequencingHooks returns the hooks for creating a block, which also check the conditional extensions
+// of the transactions with them.
+func (s *Sequencer) makeSequencingHooks(conditions map[common.Hash]*ConditionalExtensions) *arbos.SequencingHooks {
+	preTxFilter := s.preTxFilter
+	if len(conditions) > 0 {
+		preTxFilter = func(chainConfig *params.ChainConfig, header *types.Header, statedb *state.StateDB, arbState *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, sender common.Address, l1Info *arbos.L1Info) error {
+			if err := conditions[tx.Hash()].Check(header.Time, arbState, tx); err != nil {
+				return err
+			}
+			return s.preTxFilter(chainConfig, header, statedb, arbState, tx, options, sender, l1Info)
+		}
+	}
 	return &arbos.SequencingHooks{
-		PreTxFilter:             s.preTxFilter,
+		PreTxFilter:             preTxFilter,
 		PostTxFilter:            s.postTxFilter,
 		DiscardInvalidTxsEarly:  true,
 		TxErrors:                []error{},
@@ -989,6 +1246,87 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem, totalBlockSize int)
 	return outputQueueItems
 }
 
+// orderQueueItems orders the collected queue items with the configured policy, and returns the ones
+// that fit in a block along with their total size. The rest go back to the retry queue in policy order.
+// Express lane transactions keep their place ahead of the reordered transactions.
+func (s *Sequencer) orderQueueItems(config *TxOrderingConfig, queueItems []txQueueItem, maxBlockSize int) ([]txQueueItem, int) {
+	policy, err := NewTxOrderingPolicy(config)
+	if err != nil {
+		log.Error("invalid tx ordering policy, falling back to fifo", "err", err)
+		policy = fifoTxOrdering{}
+	}
+	bc := s.execEngine.bc
+	latestHeader := bc.CurrentBlock()
+	signer := types.MakeSigner(bc.Config(), arbmath.BigAdd(latestHeader.Number, common.Big1), latestHeader.Time)
+
+	ordered := make([]txQueueItem, 0, len(queueItems))
+	var candidateItems []txQueueItem
+	var candidates []TxOrderingCandidate
+	for _, queueItem := range queueItems {
+		if queueItem.isTimeboosted {
+			ordered = append(ordered, queueItem)
+			continue
+		}
+		sender, err := types.Sender(signer, queueItem.tx)
+		if err != nil {
+			queueItem.returnResult(err)
+			continue
+		}
+		candidateItems = append(candidateItems, queueItem)
+		candidates = append(candidates, TxOrderingCandidate{
+			Tx:              queueItem.tx,
+			Sender:          sender,
+			FirstAppearance: queueItem.firstAppearance,
+		})
+	}
+	for _, i := range policy.Order(candidates, time.Now(), latestHeader.BaseFee) {
+		ordered = append(ordered, candidateItems[i])
+	}
+
+	totalBlockSize := 0
+	for i, queueItem := range ordered {
+		if totalBlockSize+queueItem.txSize > maxBlockSize {
+			for _, deferred := range ordered[i:] {
+				s.txRetryQueue.Push(deferred)
+			}
+			return ordered[:i], totalBlockSize
+		}
+		totalBlockSize += queueItem.txSize
+	}
+	return ordered, totalBlockSize
+}
+
+// parkedBundle is a bundle waiting for a later block, as it can't be sequenced on top of the current one.
+type parkedBundle struct {
+	item txQueueItem
+	// the head block when the bundle was parked
+	head uint64
+}
+
+func (s *Sequencer) parkBundle(queueItem txQueueItem, head uint64) {
+	s.parkedBundles = append(s.parkedBundles, parkedBundle{item: queueItem, head: head})
+}
+
+// unparkBundle moves a parked bundle that may be sequenced on top of the head into the retry queue,
+// dropping the bundles whose senders stopped waiting. At most one bundle is retried per block, so
+// that bundles which can't be sequenced yet don't keep other transactions from being sequenced.
+func (s *Sequencer) unparkBundle(head uint64) {
+	retried := false
+	s.parkedBundles = slices.DeleteFunc(s.parkedBundles, func(parked parkedBundle) bool {
+		if err := parked.item.ctx.Err(); err != nil {
+			parked.item.returnResult(err)
+			return true
+		}
+		if retried || head <= parked.head || head <= s.lastBundleRetryBlock || parked.item.bundle.checkBlockNumber(head+1) != nil {
+			return false
+		}
+		s.txRetryQueue.Push(parked.item)
+		s.lastBundleRetryBlock = head
+		retried = true
+		return true
+	})
+}
+
 func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 	var queueItems []txQueueItem
 	var totalBlockSize int
@@ -1022,6 +1360,15 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		}
 	}()
 
+	head := s.execEngine.bc.CurrentBlock().Number.Uint64()
+	s.unparkBundle(head)
+
+	// Policies other than FIFO choose among more transactions than fit in a block
+	collectSizeLimit := config.MaxTxDataSize
+	if config.TxOrdering.Policy != TxOrderingFIFO {
+		collectSizeLimit = arbmath.SaturatingMul(config.MaxTxDataSize, config.TxOrdering.CollectBlocks)
+	}
+
 	for {
 		var queueItem txQueueItem
 
@@ -1092,22 +1439,47 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			queueItem.returnResult(txpool.ErrOversizedData)
 			continue
 		}
-		if totalBlockSize+queueItem.txSize > config.MaxTxDataSize {
-			// This tx would be too large to add to this batch
+		if queueItem.bundle != nil && errors.Is(queueItem.bundle.checkBlockNumber(head+1), ErrBundleTooEarly) {
+			s.parkBundle(queueItem, head)
+			continue
+		}
+		if totalBlockSize+queueItem.txSize > collectSizeLimit || (queueItem.bundle != nil && len(queueItems) > 0) {
+			// This tx would be too large to add to this batch, or is a bundle which gets a block of its own
 			s.txRetryQueue.Push(queueItem)
 			// End the batch here to put this tx in the next one
 			break
 		}
 		totalBlockSize += queueItem.txSize
 		queueItems = append(queueItems, queueItem)
+		if queueItem.bundle != nil {
+			break
+		}
+	}
+	var bundle *Bundle
+	if len(queueItems) == 1 {
+		bundle = queueItems[0].bundle
+	}
+
+	if config.TxOrdering.Policy != TxOrderingFIFO && bundle == nil {
+		queueItems, totalBlockSize = s.orderQueueItems(&config.TxOrdering, queueItems, config.MaxTxDataSize)
 	}
 
 	s.nonceCache.Resize(config.NonceCacheSize) // Would probably be better in a config hook but this is basically free
+	s.txStatuses.resize(config.TxStatusCacheSize)
 	s.nonceCache.BeginNewBlock()
-	queueItems = s.precheckNonces(queueItems, totalBlockSize)
+	if bundle == nil {
+		// The nonces of a bundle's transactions are checked when it's sequenced, as the bundle fails as a whole
+		queueItems = s.precheckNonces(queueItems, totalBlockSize)
+	}
 	txes := make([]*types.Transaction, len(queueItems))
 	timeboostedTxs := make(map[common.Hash]struct{})
-	hooks := s.makeSequencingHooks()
+	conditions := make(map[common.Hash]*ConditionalExtensions)
+	for _, queueItem := range queueItems {
+		if queueItem.conditions != nil {
+			conditions[queueItem.tx.Hash()] = queueItem.conditions
+		}
+	}
+	hooks := s.makeSequencingHooks(conditions)
 	hooks.ConditionalOptionsForTx = make([]*arbitrum_types.ConditionalOptions, len(queueItems))
 	totalBlockSize = 0 // recompute the totalBlockSize to double check it
 	for i, queueItem := range queueItems {
@@ -1118,6 +1490,12 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			timeboostedTxs[queueItem.tx.Hash()] = struct{}{}
 		}
 	}
+	if bundle != nil {
+		txes = bundle.Transactions
+		// The options are checked against the state before the bundle
+		hooks.ConditionalOptionsForTx = make([]*arbitrum_types.ConditionalOptions, len(txes))
+		hooks.ConditionalOptionsForTx[0] = bundle.Options
+	}
 
 	if totalBlockSize > config.MaxTxDataSize {
 		for _, queueItem := range queueItems {
@@ -1170,7 +1548,9 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		block *types.Block
 		err   error
 	)
-	if config.EnableProfiling {
+	if bundle != nil {
+		block, err = s.execEngine.SequenceBundle(header, bundle, hooks)
+	} else if config.EnableProfiling {
 		block, err = s.execEngine.SequenceTransactionsWithProfiling(header, txes, hooks, timeboostedTxs)
 	} else {
 		block, err = s.execEngine.SequenceTransactions(header, txes, hooks, timeboostedTxs)
@@ -1208,6 +1588,10 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			}
 			return true // don't return failure to avoid retrying immediately
 		}
+		if bundle != nil {
+			s.handleBundleFailure(queueItems[0], head, err)
+			return false
+		}
 		log.Error("error sequencing transactions", "err", err)
 		for _, queueItem := range queueItems {
 			queueItem.returnResult(err)
@@ -1220,7 +1604,13 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		s.nonceCache.Finalize(block)
 	}
 
+	if bundle != nil {
+		queueItems[0].returnResult(nil)
+		return true
+	}
+
 	madeBlock := false
+	deferred := false
 	for i, err := range hooks.TxErrors {
 		if err == nil {
 			madeBlock = true
@@ -1243,9 +1633,39 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			s.nonceFailures.Add(nonceError, queueItem)
 			continue
 		}
+		var deferral *TxFilterDeferral
+		if errors.As(err, &deferral) {
+			s.txRetryQueue.Push(queueItem)
+			deferred = true
+			continue
+		}
+		if err == nil && queueItem.preconf != nil {
+			queueItem.preconf.header = block.Header()
+		}
 		queueItem.returnResult(err)
 	}
-	return madeBlock
+	if err := s.txJournal.compact(); err != nil {
+		txJournalErrorCounter.Inc(1)
+		log.Error("error compacting sequencer tx journal", "err", err)
+	}
+	// Wait before retrying deferred transactions instead of spinning on them
+	return madeBlock || deferred
+}
+
+// handleBundleFailure parks a bundle that can't be sequenced yet until a later block, or drops it.
+func (s *Sequencer) handleBundleFailure(queueItem txQueueItem, head uint64, err error) {
+	var deferral *TxFilterDeferral
+	if errors.Is(err, ErrBundleTooEarly) || errors.As(err, &deferral) {
+		s.parkBundle(queueItem, head)
+		return
+	}
+	log.Info("dropping bundle", "txHash", queueItem.tx.Hash(), "txs", len(queueItem.bundle.Transactions), "err", err)
+	queueItem.returnResult(err)
+}
+
+// RegisterTxFilter adds an admission filter to the end of the sequencer's filter chain.
+func (s *Sequencer) RegisterTxFilter(filter TxFilter) {
+	s.txFilters.Register(filter)
 }
 
 func (s *Sequencer) updateLatestParentChainBlock(header *types.Header) {
@@ -1402,6 +1822,7 @@ func (s *Sequencer) Start(ctxIn context.Context) error {
 		})
 	}
 
+	s.requeueRecoveredTxs()
 	s.CallIteratively(func(ctx context.Context) time.Duration {
 		nextBlock := time.Now().Add(s.config().MaxBlockSpeed)
 		if s.createBlock(ctx) {
@@ -1417,9 +1838,22 @@ func (s *Sequencer) Start(ctxIn context.Context) error {
 
 func (s *Sequencer) StopAndWait() {
 	s.StopWaiter.StopAndWait()
+	defer func() {
+		if err := s.txJournal.close(); err != nil {
+			log.Error("error closing sequencer tx journal", "err", err)
+		}
+	}()
+	if err := s.txFilters.Close(); err != nil {
+		log.Error("error closing sequencer tx filter audit file", "err", err)
+	}
 	if s.config().Dangerous.Timeboost.Enable && s.expressLaneService != nil {
 		s.expressLaneService.StopAndWait()
 	}
+	// parked bundles are forwarded with the rest of the queue
+	for _, parked := range s.parkedBundles {
+		s.txRetryQueue.Push(parked.item)
+	}
+	s.parkedBundles = nil
 	if s.txRetryQueue.Len() == 0 &&
 		len(s.txQueue) == 0 &&
 		s.nonceFailures.Len() == 0 &&
@@ -1461,10 +1895,13 @@ func (s *Sequencer) StopAndWait() {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				err := forwarder.PublishTransaction(item.ctx, item.tx, item.options)
+				err := item.forward(forwarder)
 				if err != nil {
 					log.Warn("failed to forward transaction while shutting down", "source", source, "err", err)
+					return
 				}
+				// forwarded transactions are the other sequencer's to sequence now
+				item.journal.remove(item.tx.Hash(), false)
 			}()
 		}
 		wg.Wait()
@@ -0,0 +1,456 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type TxFiltersConfig struct {
+	DenyList  DenyListFilterConfig        `koanf:"deny-list"`
+	RateLimit SenderRateLimitFilterConfig `koanf:"rate-limit"`
+	AuditFile string                      `koanf:"audit-file"`
+}
+
+type DenyListFilterConfig struct {
+	File           string        `koanf:"file"`
+	ReloadInterval time.Duration `koanf:"reload-interval"`
+	CheckTouched   bool          `koanf:"check-touched"`
+}
+
+type SenderRateLimitFilterConfig struct {
+	TxsPerSecond float64 `koanf:"txs-per-second"`
+	Burst        int     `koanf:"burst"`
+}
+
+var DefaultTxFiltersConfig = TxFiltersConfig{
+	DenyList: DenyListFilterConfig{
+		File:           "",
+		ReloadInterval: time.Minute,
+		CheckTouched:   false,
+	},
+	RateLimit: SenderRateLimitFilterConfig{
+		TxsPerSecond: 0,
+		Burst:        10,
+	},
+	AuditFile: "",
+}
+
+func TxFiltersConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".deny-list.file", DefaultTxFiltersConfig.DenyList.File, "file with one denied address per line, optionally followed by a comma and the reason (empty = disabled)")
+	f.Duration(prefix+".deny-list.reload-interval", DefaultTxFiltersConfig.DenyList.ReloadInterval, "how often to check the deny list file for changes")
+	f.Bool(prefix+".deny-list.check-touched", DefaultTxFiltersConfig.DenyList.CheckTouched, "after execution, also reject transactions that touched a denied address")
+	f.Float64(prefix+".rate-limit.txs-per-second", DefaultTxFiltersConfig.RateLimit.TxsPerSecond, "maximum rate of transactions sequenced per sender, further transactions are deferred (0 = disabled)")
+	f.Int(prefix+".rate-limit.burst", DefaultTxFiltersConfig.RateLimit.Burst, "number of transactions a sender can send at once above the rate limit")
+	f.String(prefix+".audit-file", DefaultTxFiltersConfig.AuditFile, "file to append a JSON line to for every transaction rejected by a filter (empty = log only)")
+}
+
+func (c *TxFiltersConfig) Validate() error {
+	if c.DenyList.File != "" && c.DenyList.ReloadInterval <= 0 {
+		return errors.New("tx-filters deny-list reload-interval must be positive")
+	}
+	if c.DenyList.CheckTouched && c.DenyList.File == "" {
+		return errors.New("tx-filters deny-list check-touched requires a deny-list file")
+	}
+	if c.RateLimit.TxsPerSecond < 0 {
+		return errors.New("tx-filters rate-limit txs-per-second cannot be negative")
+	}
+	if c.RateLimit.TxsPerSecond > 0 && c.RateLimit.Burst < 1 {
+		return errors.New("tx-filters rate-limit burst must be at least 1")
+	}
+	return nil
+}
+
+// TxFilterInfo is what filters know about a transaction.
+type TxFilterInfo struct {
+	Tx     *types.Transaction
+	Sender common.Address
+	// To is nil for contract creations
+	To *common.Address
+	// Selector is the first four bytes of the calldata, or nil if there are fewer
+	Selector []byte
+	// The following are only set for post-execution filters
+	GasUsed uint64
+	Result  *core.ExecutionResult
+	// Touched reports whether the transaction accessed the address during execution
+	Touched func(common.Address) bool
+}
+
+func newTxFilterInfo(tx *types.Transaction, sender common.Address) *TxFilterInfo {
+	info := &TxFilterInfo{
+		Tx:     tx,
+		Sender: sender,
+		To:     tx.To(),
+	}
+	if data := tx.Data(); len(data) >= 4 {
+		info.Selector = data[:4]
+	}
+	return info
+}
+
+// TxFilterRejection rejects a transaction, the reason is returned to the submitter and audited.
+type TxFilterRejection struct {
+	Filter string
+	Reason string
+}
+
+func (e *TxFilterRejection) Error() string {
+	return fmt.Sprintf("transaction rejected by sequencer filter %v: %v", e.Filter, e.Reason)
+}
+
+// TxFilterDeferral puts a transaction back in the queue to be retried in a later block.
+// It's rejected once it has been queued for longer than the queue timeout.
+type TxFilterDeferral struct {
+	Filter string
+	Reason string
+}
+
+func (e *TxFilterDeferral) Error() string {
+	return fmt.Sprintf("transaction deferred by sequencer filter %v: %v", e.Filter, e.Reason)
+}
+
+// TxFilter is a named admission filter for the sequencer. A filter implements PreTxFilter,
+// PostTxFilter or both, returning a *TxFilterRejection or *TxFilterDeferral to stop a transaction.
+type TxFilter interface {
+	Name() string
+}
+
+// PreTxFilter is consulted before a transaction is executed.
+type PreTxFilter interface {
+	TxFilter
+	FilterPreTx(info *TxFilterInfo) error
+}
+
+// PostTxFilter is consulted after a transaction is executed, before its effects are committed.
+type PostTxFilter interface {
+	TxFilter
+	FilterPostTx(info *TxFilterInfo) error
+}
+
+type txFilterAuditRecord struct {
+	Time   time.Time       `json:"time"`
+	Filter string          `json:"filter"`
+	Stage  string          `json:"stage"`
+	TxHash common.Hash     `json:"txHash"`
+	Sender common.Address  `json:"sender"`
+	To     *common.Address `json:"to,omitempty"`
+	Reason string          `json:"reason"`
+}
+
+// TxFilterChain runs the registered filters in registration order, stopping at the first one that
+// rejects or defers a transaction.
+type TxFilterChain struct {
+	mutex       sync.RWMutex
+	preFilters  []PreTxFilter
+	postFilters []PostTxFilter
+
+	auditMutex sync.Mutex
+	auditFile  *os.File
+}
+
+func NewTxFilterChain(config *TxFiltersConfig) (*TxFilterChain, error) {
+	chain := &TxFilterChain{}
+	if config.AuditFile != "" {
+		// #nosec G304
+		file, err := os.OpenFile(config.AuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("error opening tx filter audit file: %w", err)
+		}
+		chain.auditFile = file
+	}
+	if config.DenyList.File != "" {
+		denyList, err := NewDenyListFilter(&config.DenyList)
+		if err != nil {
+			return nil, err
+		}
+		chain.Register(denyList)
+	}
+	if config.RateLimit.TxsPerSecond > 0 {
+		chain.Register(NewSenderRateLimitFilter(&config.RateLimit))
+	}
+	return chain, nil
+}
+
+// Register adds a filter to the end of the chain. The filter must implement PreTxFilter, PostTxFilter or both.
+func (c *TxFilterChain) Register(filter TxFilter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	pre, isPre := filter.(PreTxFilter)
+	if isPre {
+		c.preFilters = append(c.preFilters, pre)
+	}
+	post, isPost := filter.(PostTxFilter)
+	if isPost {
+		c.postFilters = append(c.postFilters, post)
+	}
+	if !isPre && !isPost {
+		log.Warn("sequencer tx filter implements neither pre nor post filtering, ignoring it", "filter", filter.Name())
+	}
+}
+
+func (c *TxFilterChain) record(stage string, filter TxFilter, info *TxFilterInfo, err error) error {
+	var deferral *TxFilterDeferral
+	if errors.As(err, &deferral) {
+		metrics.GetOrRegisterCounter("arb/sequencer/txfilter/"+filter.Name()+"/deferred", nil).Inc(1)
+		return err
+	}
+	var rejection *TxFilterRejection
+	if !errors.As(err, &rejection) {
+		// Any other error is a rejection by the filter
+		rejection = &TxFilterRejection{Filter: filter.Name(), Reason: err.Error()}
+		err = rejection
+	}
+	metrics.GetOrRegisterCounter("arb/sequencer/txfilter/"+filter.Name()+"/rejected", nil).Inc(1)
+	log.Info("sequencer filter rejected transaction", "filter", rejection.Filter, "stage", stage, "txHash", info.Tx.Hash(), "sender", info.Sender, "to", info.To, "reason", rejection.Reason)
+	if c.auditFile != nil {
+		line, jsonErr := json.Marshal(&txFilterAuditRecord{
+			Time:   time.Now().UTC(),
+			Filter: rejection.Filter,
+			Stage:  stage,
+			TxHash: info.Tx.Hash(),
+			Sender: info.Sender,
+			To:     info.To,
+			Reason: rejection.Reason,
+		})
+		if jsonErr == nil {
+			c.auditMutex.Lock()
+			_, jsonErr = c.auditFile.Write(append(line, '\n'))
+			c.auditMutex.Unlock()
+		}
+		if jsonErr != nil {
+			log.Error("error writing tx filter audit record", "txHash", info.Tx.Hash(), "err", jsonErr)
+		}
+	}
+	return err
+}
+
+func (c *TxFilterChain) FilterPreTx(info *TxFilterInfo) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, filter := range c.preFilters {
+		if err := filter.FilterPreTx(info); err != nil {
+			return c.record("pre", filter, info, err)
+		}
+	}
+	return nil
+}
+
+func (c *TxFilterChain) FilterPostTx(info *TxFilterInfo) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, filter := range c.postFilters {
+		if err := filter.FilterPostTx(info); err != nil {
+			return c.record("post", filter, info, err)
+		}
+	}
+	return nil
+}
+
+func (c *TxFilterChain) hasPreFilters() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.preFilters) > 0
+}
+
+func (c *TxFilterChain) hasPostFilters() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.postFilters) > 0
+}
+
+func (c *TxFilterChain) Close() error {
+	if c.auditFile == nil {
+		return nil
+	}
+	c.auditMutex.Lock()
+	defer c.auditMutex.Unlock()
+	return c.auditFile.Close()
+}
+
+// DenyListFilter rejects transactions from or to addresses in a file, and optionally transactions that
+// touched any of them during execution. The file is reloaded when it changes.
+type DenyListFilter struct {
+	config *DenyListFilterConfig
+
+	mutex     sync.Mutex
+	denied    map[common.Address]string
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func NewDenyListFilter(config *DenyListFilterConfig) (*DenyListFilter, error) {
+	f := &DenyListFilter{config: config}
+	if err := f.reload(time.Now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseDenyList(path string) (map[common.Address]string, error) {
+	// #nosec G304
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	denied := make(map[common.Address]string)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		address, reason, _ := strings.Cut(line, ",")
+		address = strings.TrimSpace(address)
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address \"%v\" on line %v of deny list %v", address, lineNum, path)
+		}
+		denied[common.HexToAddress(address)] = strings.TrimSpace(reason)
+	}
+	return denied, scanner.Err()
+}
+
+// reload re-reads the deny list if the file changed, the caller must hold the mutex or own f exclusively.
+func (f *DenyListFilter) reload(now time.Time) error {
+	f.lastCheck = now
+	info, err := os.Stat(f.config.File)
+	if err != nil {
+		return err
+	}
+	if f.denied != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	denied, err := parseDenyList(f.config.File)
+	if err != nil {
+		return err
+	}
+	f.denied = denied
+	f.modTime = info.ModTime()
+	log.Info("loaded sequencer deny list", "file", f.config.File, "addresses", len(denied))
+	return nil
+}
+
+func (f *DenyListFilter) deniedAddresses() map[common.Address]string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if now.Sub(f.lastCheck) >= f.config.ReloadInterval {
+		if err := f.reload(now); err != nil {
+			// Keep enforcing the last good list
+			log.Error("error reloading sequencer deny list", "file", f.config.File, "err", err)
+		}
+	}
+	return f.denied
+}
+
+func (f *DenyListFilter) Name() string {
+	return "deny-list"
+}
+
+func denyReason(address common.Address, role string, reason string) *TxFilterRejection {
+	rejection := &TxFilterRejection{Filter: "deny-list", Reason: fmt.Sprintf("%v %v is denied", role, address)}
+	if reason != "" {
+		rejection.Reason += ": " + reason
+	}
+	return rejection
+}
+
+func (f *DenyListFilter) FilterPreTx(info *TxFilterInfo) error {
+	denied := f.deniedAddresses()
+	if reason, ok := denied[info.Sender]; ok {
+		return denyReason(info.Sender, "sender", reason)
+	}
+	if info.To != nil {
+		if reason, ok := denied[*info.To]; ok {
+			return denyReason(*info.To, "recipient", reason)
+		}
+	}
+	return nil
+}
+
+func (f *DenyListFilter) FilterPostTx(info *TxFilterInfo) error {
+	if !f.config.CheckTouched || info.Touched == nil {
+		return nil
+	}
+	for address, reason := range f.deniedAddresses() {
+		if info.Touched(address) {
+			return denyReason(address, "touched address", reason)
+		}
+	}
+	return nil
+}
+
+// SenderRateLimitFilter defers transactions of senders exceeding a rate, using a token bucket per sender.
+type SenderRateLimitFilter struct {
+	config *SenderRateLimitFilterConfig
+
+	mutex     sync.Mutex
+	buckets   map[common.Address]*senderBucket
+	lastSweep time.Time
+}
+
+type senderBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewSenderRateLimitFilter(config *SenderRateLimitFilterConfig) *SenderRateLimitFilter {
+	return &SenderRateLimitFilter{
+		config:  config,
+		buckets: make(map[common.Address]*senderBucket),
+	}
+}
+
+func (f *SenderRateLimitFilter) Name() string {
+	return "rate-limit"
+}
+
+func (f *SenderRateLimitFilter) allow(sender common.Address, now time.Time) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	burst := float64(f.config.Burst)
+	if now.Sub(f.lastSweep) >= time.Second {
+		// Full buckets carry no information, drop them to bound memory
+		for address, bucket := range f.buckets {
+			if bucket.tokens+now.Sub(bucket.updated).Seconds()*f.config.TxsPerSecond >= burst {
+				delete(f.buckets, address)
+			}
+		}
+		f.lastSweep = now
+	}
+	bucket, ok := f.buckets[sender]
+	if !ok {
+		bucket = &senderBucket{tokens: burst, updated: now}
+		f.buckets[sender] = bucket
+	}
+	bucket.tokens = min(burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*f.config.TxsPerSecond)
+	bucket.updated = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+func (f *SenderRateLimitFilter) FilterPreTx(info *TxFilterInfo) error {
+	if !f.allow(info.Sender, time.Now()) {
+		return &TxFilterDeferral{Filter: f.Name(), Reason: fmt.Sprintf("sender %v exceeded %v transactions per second", info.Sender, f.config.TxsPerSecond)}
+	}
+	return nil
+}
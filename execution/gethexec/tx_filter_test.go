@@ -0,0 +1,76 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTxFilterChain(t *testing.T) {
+	dir := t.TempDir()
+	denied := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	sender := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	denyListFile := filepath.Join(dir, "denylist.txt")
+	require.NoError(t, os.WriteFile(denyListFile, []byte("# sanctioned\n"+denied.Hex()+", OFAC\n"), 0o600))
+
+	config := DefaultTxFiltersConfig
+	config.DenyList.File = denyListFile
+	config.DenyList.CheckTouched = true
+	config.DenyList.ReloadInterval = time.Nanosecond
+	config.RateLimit.TxsPerSecond = 0.001
+	config.RateLimit.Burst = 1
+	config.AuditFile = filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, config.Validate())
+	chain, err := NewTxFilterChain(&config)
+	require.NoError(t, err)
+
+	toDenied := types.NewTx(&types.LegacyTx{To: &denied, Data: []byte{1, 2, 3, 4, 5}})
+	info := newTxFilterInfo(toDenied, sender)
+	require.Equal(t, []byte{1, 2, 3, 4}, info.Selector)
+	err = chain.FilterPreTx(info)
+	var rejection *TxFilterRejection
+	require.True(t, errors.As(err, &rejection))
+	require.Equal(t, "deny-list", rejection.Filter)
+	require.Contains(t, rejection.Reason, "OFAC")
+
+	other := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	tx := types.NewTx(&types.LegacyTx{To: &other})
+	require.NoError(t, chain.FilterPreTx(newTxFilterInfo(tx, sender)))
+
+	postInfo := newTxFilterInfo(tx, sender)
+	postInfo.Touched = func(address common.Address) bool { return address == denied }
+	require.True(t, errors.As(chain.FilterPostTx(postInfo), &rejection))
+	require.Contains(t, rejection.Reason, "touched address")
+
+	// The rate limit allows a burst of one, which the accepted transaction above used
+	err = chain.FilterPreTx(newTxFilterInfo(tx, sender))
+	var deferral *TxFilterDeferral
+	require.True(t, errors.As(err, &deferral), "expected deferral, got %v", err)
+
+	// Reloading the deny list without the address lets transactions through
+	require.NoError(t, os.WriteFile(denyListFile, []byte("\n"), 0o600))
+	require.NoError(t, os.Chtimes(denyListFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	require.NoError(t, chain.FilterPostTx(postInfo))
+
+	require.NoError(t, chain.Close())
+	audit, err := os.ReadFile(config.AuditFile)
+	require.NoError(t, err)
+	var record txFilterAuditRecord
+	lines := strings.Split(strings.TrimSpace(string(audit)), "\n")
+	require.Len(t, lines, 2)
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, toDenied.Hash(), record.TxHash)
+	require.Equal(t, "pre", record.Stage)
+}
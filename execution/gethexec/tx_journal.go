@@ -0,0 +1,468 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	txJournalPendingGauge = metrics.NewRegisteredGauge("arb/sequencer/journal/pending", nil)
+	txJournalErrorCounter = metrics.NewRegisteredCounter("arb/sequencer/journal/errors", nil)
+)
+
+type TxJournalConfig struct {
+	File        string   `koanf:"file"`
+	Sync        bool     `koanf:"sync"`
+	ImportFiles []string `koanf:"import-files"`
+	MinCompact  int      `koanf:"min-compact"`
+}
+
+var DefaultTxJournalConfig = TxJournalConfig{
+	File:        "",
+	Sync:        true,
+	ImportFiles: []string{},
+	MinCompact:  1024,
+}
+
+func TxJournalConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".file", DefaultTxJournalConfig.File, "file to journal accepted but not yet sequenced transactions to, which are requeued on restart (empty = disabled)")
+	f.Bool(prefix+".sync", DefaultTxJournalConfig.Sync, "fsync the journal after every accepted transaction")
+	f.StringSlice(prefix+".import-files", DefaultTxJournalConfig.ImportFiles, "journals of other sequencers to import transactions from when this sequencer becomes the chosen one")
+	f.Int(prefix+".min-compact", DefaultTxJournalConfig.MinCompact, "minimum number of finished transactions in the journal before it's compacted")
+}
+
+func (c *TxJournalConfig) Validate() error {
+	if len(c.ImportFiles) > 0 && c.File == "" {
+		return errors.New("tx-journal import-files requires a journal file")
+	}
+	if c.MinCompact < 0 {
+		return errors.New("tx-journal min-compact cannot be negative")
+	}
+	return nil
+}
+
+// txJournalRecord is a line of the journal. A record either adds a transaction, with its
+// options, or marks the transaction with the hash as finished.
+type txJournalRecord struct {
+	Tx          hexutil.Bytes                      `json:"tx,omitempty"`
+	Options     *arbitrum_types.ConditionalOptions `json:"options,omitempty"`
+	Conditions  *ConditionalExtensions             `json:"conditions,omitempty"`
+	Received    time.Time                          `json:"received,omitempty"`
+	Timeboosted bool                               `json:"timeboosted,omitempty"`
+	Done        *common.Hash                       `json:"done,omitempty"`
+
+	tx    *types.Transaction
+	index uint64
+}
+
+// readTxJournal returns the transactions added to the journal at path and not finished, in the order they were added.
+// A partially written last line, as left by a crash, is ignored.
+func readTxJournal(path string) ([]*txJournalRecord, error) {
+	// #nosec G304
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, _, err := readTxJournalFrom(file, 0)
+	return records, err
+}
+
+// readTxJournalFrom reads the journal file from the byte offset, ignoring the transactions added before it.
+// It also returns the offset after the last complete line, from which appended records can be read later.
+func readTxJournalFrom(file *os.File, offset int64) ([]*txJournalRecord, int64, error) {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	pending := make(map[common.Hash]*txJournalRecord)
+	var index uint64
+	var badLine int
+	reader := bufio.NewReader(file)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				log.Warn("ignoring partially written last record of tx journal", "file", file.Name(), "offset", offset)
+			}
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if badLine != 0 {
+			return nil, 0, fmt.Errorf("invalid record on line %v after offset %v of tx journal %v", badLine, offset, file.Name())
+		}
+		lineOffset := offset
+		offset += int64(len(line))
+		var record txJournalRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			badLine = lineNum
+			continue
+		}
+		if record.Done != nil {
+			delete(pending, *record.Done)
+			continue
+		}
+		record.tx = new(types.Transaction)
+		if err := record.tx.UnmarshalBinary(record.Tx); err != nil {
+			return nil, 0, fmt.Errorf("invalid transaction at offset %v of tx journal %v: %w", lineOffset, file.Name(), err)
+		}
+		record.index = index
+		index++
+		pending[record.tx.Hash()] = &record
+	}
+	if badLine != 0 {
+		log.Warn("ignoring invalid last record of tx journal", "file", file.Name(), "line", badLine)
+	}
+	records := make([]*txJournalRecord, 0, len(pending))
+	for _, record := range pending {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].index < records[j].index })
+	return records, offset, nil
+}
+
+// txJournal is a write-ahead log of the transactions accepted into the sequencer's queue.
+// Transactions are marked finished once a result is returned for them, and the journal is
+// rewritten without the finished ones once they outnumber the pending ones.
+type txJournal struct {
+	config *TxJournalConfig
+
+	mutex     sync.Mutex
+	file      *os.File
+	pending   map[common.Hash]*txJournalRecord
+	nextIndex uint64
+	finished  int
+}
+
+func openTxJournal(config *TxJournalConfig) (*txJournal, error) {
+	records, err := readTxJournal(config.File)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tx journal: %w", err)
+	}
+	j := &txJournal{
+		config:  config,
+		pending: make(map[common.Hash]*txJournalRecord, len(records)),
+	}
+	for _, record := range records {
+		record.index = j.nextIndex
+		j.nextIndex++
+		j.pending[record.tx.Hash()] = record
+	}
+	if err := j.rewrite(); err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		log.Info("recovered transactions from sequencer tx journal", "file", config.File, "txs", len(records))
+	}
+	return j, nil
+}
+
+// recovered returns the pending transactions in the order they were accepted.
+func (j *txJournal) recovered() []*txJournalRecord {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	records := make([]*txJournalRecord, 0, len(j.pending))
+	for _, record := range j.pending {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(a, b int) bool { return records[a].index < records[b].index })
+	return records
+}
+
+func (j *txJournal) contains(txHash common.Hash) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	_, ok := j.pending[txHash]
+	return ok
+}
+
+func (j *txJournal) write(record *txJournalRecord, sync bool) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if sync {
+		return j.file.Sync()
+	}
+	return nil
+}
+
+// add journals a transaction before it's queued.
+func (j *txJournal) add(tx *types.Transaction, options *arbitrum_types.ConditionalOptions, conditions *ConditionalExtensions, received time.Time, timeboosted bool) error {
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return errors.New("tx journal closed")
+	}
+	record := &txJournalRecord{
+		Tx:          txBytes,
+		Options:     options,
+		Conditions:  conditions,
+		Received:    received,
+		Timeboosted: timeboosted,
+		tx:          tx,
+		index:       j.nextIndex,
+	}
+	if err := j.write(record, j.config.Sync); err != nil {
+		txJournalErrorCounter.Inc(1)
+		return fmt.Errorf("error writing to tx journal: %w", err)
+	}
+	j.nextIndex++
+	j.pending[tx.Hash()] = record
+	txJournalPendingGauge.Update(int64(len(j.pending)))
+	return nil
+}
+
+// remove marks a transaction finished. With sync enabled, the record is synced unless the transaction
+// landed in a block, as replaying a rejected or timed out transaction would sequence it after all.
+// It's a no-op on a nil journal.
+func (j *txJournal) remove(txHash common.Hash, landed bool) {
+	if j == nil {
+		return
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, ok := j.pending[txHash]; !ok || j.file == nil {
+		return
+	}
+	delete(j.pending, txHash)
+	j.finished++
+	txJournalPendingGauge.Update(int64(len(j.pending)))
+	// Not syncing landed transactions is fine, as replaying them just has them rejected by their nonce
+	if err := j.write(&txJournalRecord{Done: &txHash}, j.config.Sync && !landed); err != nil {
+		txJournalErrorCounter.Inc(1)
+		log.Error("error marking transaction finished in tx journal", "txHash", txHash, "err", err)
+	}
+}
+
+// compact rewrites the journal without finished transactions once there are enough of them.
+// It's called after every block the sequencer produces, and is a no-op on a nil journal.
+func (j *txJournal) compact() error {
+	if j == nil {
+		return nil
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil || j.finished < j.config.MinCompact || j.finished < len(j.pending) {
+		return nil
+	}
+	return j.rewrite()
+}
+
+// rewrite replaces the journal file with one holding only the pending transactions.
+// The caller must hold the mutex or own j exclusively.
+func (j *txJournal) rewrite() error {
+	tmpPath := j.config.File + ".tmp"
+	// #nosec G304
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error creating compacted tx journal: %w", err)
+	}
+	records := make([]*txJournalRecord, 0, len(j.pending))
+	for _, record := range j.pending {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(a, b int) bool { return records[a].index < records[b].index })
+	writer := bufio.NewWriter(tmp)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err == nil {
+			_, err = writer.Write(append(line, '\n'))
+		}
+		if err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("error writing compacted tx journal: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, j.config.File); err != nil {
+		return fmt.Errorf("error replacing tx journal: %w", err)
+	}
+	if j.file != nil {
+		_ = j.file.Close()
+	}
+	// #nosec G304
+	j.file, err = os.OpenFile(j.config.File, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening tx journal: %w", err)
+	}
+	j.finished = 0
+	txJournalPendingGauge.Update(int64(len(j.pending)))
+	return nil
+}
+
+func (j *txJournal) close() error {
+	if j == nil {
+		return nil
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func (s *Sequencer) newJournaledQueueItem(record *txJournalRecord) txQueueItem {
+	return txQueueItem{
+		tx:              record.tx,
+		txSize:          len(record.Tx),
+		options:         record.Options,
+		conditions:      record.Conditions,
+		resultChan:      make(chan error, 1),
+		returnedResult:  &atomic.Bool{},
+		ctx:             s.GetContext(),
+		firstAppearance: time.Now(),
+		isTimeboosted:   record.Timeboosted,
+		journal:         s.txJournal,
+		statuses:        s.txStatuses,
+	}
+}
+
+// requeueRecoveredTxs puts the transactions recovered from the journal at the front of the queue.
+// It must be called before the block creation loop is started, as that owns the retry queue.
+func (s *Sequencer) requeueRecoveredTxs() {
+	if s.txJournal == nil {
+		return
+	}
+	for _, record := range s.txJournal.recovered() {
+		s.txRetryQueue.Push(s.newJournaledQueueItem(record))
+		s.txStatuses.set(record.tx.Hash(), TxStatusQueued, nil, "")
+	}
+}
+
+// importedTxJournal is how far another sequencer's journal has been imported.
+type importedTxJournal struct {
+	info   os.FileInfo
+	offset int64
+	// the transactions imported from the journal, in case it's compacted and has to be read from the start
+	txs map[common.Hash]struct{}
+}
+
+// ImportTxJournal queues the pending transactions of another sequencer's journal, such as the
+// one of the previously chosen sequencer on failover. Only the records appended since the last
+// import are read, unless the journal was compacted since. Transactions it already sequenced are
+// rejected by their nonces. It returns the number of transactions queued.
+func (s *Sequencer) ImportTxJournal(ctx context.Context, path string) (int, error) {
+	if s.txJournal == nil {
+		return 0, errors.New("sequencer tx journal not enabled")
+	}
+	s.importedTxJournalsMutex.Lock()
+	defer s.importedTxJournalsMutex.Unlock()
+	// #nosec G304
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	state := s.importedTxJournals[path]
+	fromStart := state == nil || !os.SameFile(state.info, info) || info.Size() < state.offset
+	if state == nil {
+		state = &importedTxJournal{txs: make(map[common.Hash]struct{})}
+		s.importedTxJournals[path] = state
+	}
+	offset := state.offset
+	if fromStart {
+		offset = 0
+	}
+	records, newOffset, err := readTxJournalFrom(file, offset)
+	if err != nil {
+		return 0, fmt.Errorf("error reading tx journal %v: %w", path, err)
+	}
+	if fromStart {
+		// Transactions no longer in the journal can't come back, so they don't need to be remembered
+		txs := make(map[common.Hash]struct{}, len(records))
+		for _, record := range records {
+			if _, ok := state.txs[record.tx.Hash()]; ok {
+				txs[record.tx.Hash()] = struct{}{}
+			}
+		}
+		state.txs = txs
+	}
+	imported := 0
+	for _, record := range records {
+		txHash := record.tx.Hash()
+		if _, ok := state.txs[txHash]; ok || s.txJournal.contains(txHash) {
+			continue
+		}
+		if err := s.txJournal.add(record.tx, record.Options, record.Conditions, record.Received, record.Timeboosted); err != nil {
+			return imported, err
+		}
+		s.txStatuses.set(txHash, TxStatusQueued, nil, "")
+		select {
+		case s.txQueue <- s.newJournaledQueueItem(record):
+		case <-ctx.Done():
+			s.txJournal.remove(txHash, false)
+			return imported, ctx.Err()
+		}
+		state.txs[txHash] = struct{}{}
+		imported++
+	}
+	state.info = info
+	state.offset = newOffset
+	return imported, nil
+}
+
+func (s *Sequencer) importTxJournals(ctx context.Context) {
+	for _, path := range s.config().TxJournal.ImportFiles {
+		imported, err := s.ImportTxJournal(ctx, path)
+		if err != nil {
+			log.Error("error importing sequencer tx journal", "file", path, "imported", imported, "err", err)
+			continue
+		}
+		if imported > 0 {
+			log.Info("imported transactions from sequencer tx journal", "file", path, "txs", imported)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTxJournalRecovery(t *testing.T) {
+	config := DefaultTxJournalConfig
+	config.File = filepath.Join(t.TempDir(), "txjournal")
+	config.MinCompact = 2
+	require.NoError(t, config.Validate())
+
+	journal, err := openTxJournal(&config)
+	require.NoError(t, err)
+	require.Empty(t, journal.recovered())
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	var txs []*types.Transaction
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to})
+		txs = append(txs, tx)
+		require.NoError(t, journal.add(tx, nil, nil, time.Now(), nonce == 3))
+	}
+	journal.remove(txs[0].Hash(), true)
+	journal.remove(txs[2].Hash(), false)
+	require.NoError(t, journal.compact())
+	require.NoError(t, journal.close())
+
+	// A crash can leave a partially written record at the end
+	file, err := os.OpenFile(config.File, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"tx":"0x`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// Other sequencers read the journal the same way on failover
+	imported, err := readTxJournal(config.File)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+
+	journal, err = openTxJournal(&config)
+	require.NoError(t, err)
+	recovered := journal.recovered()
+	require.Len(t, recovered, 2)
+	require.Equal(t, txs[1].Hash(), recovered[0].tx.Hash())
+	require.Equal(t, txs[3].Hash(), recovered[1].tx.Hash())
+	require.False(t, recovered[0].Timeboosted)
+	require.True(t, recovered[1].Timeboosted)
+	require.True(t, journal.contains(txs[3].Hash()))
+	require.False(t, journal.contains(txs[2].Hash()))
+
+	// Importing resumes from the end of the last complete record
+	file, err = os.Open(config.File)
+	require.NoError(t, err)
+	_, offset, err := readTxJournalFrom(file, 0)
+	require.NoError(t, err)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 4, To: &to})
+	require.NoError(t, journal.add(tx, nil, nil, time.Now(), false))
+	appended, _, err := readTxJournalFrom(file, offset)
+	require.NoError(t, err)
+	require.Len(t, appended, 1)
+	require.Equal(t, tx.Hash(), appended[0].tx.Hash())
+	require.NoError(t, file.Close())
+	require.NoError(t, journal.close())
+
+	records, err := readTxJournal(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
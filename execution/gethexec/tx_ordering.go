@@ -0,0 +1,162 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+const (
+	TxOrderingFIFO        = "fifo"
+	TxOrderingSenderFair  = "sender-fair"
+	TxOrderingPriorityTip = "priority-tip"
+)
+
+type TxOrderingConfig struct {
+	Policy         string        `koanf:"policy" reload:"hot"`
+	CollectBlocks  int           `koanf:"collect-blocks" reload:"hot"`
+	PriorityWindow time.Duration `koanf:"priority-window" reload:"hot"`
+}
+
+var DefaultTxOrderingConfig = TxOrderingConfig{
+	Policy:         TxOrderingFIFO,
+	CollectBlocks:  4,
+	PriorityWindow: time.Second,
+}
+
+func TxOrderingConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".policy", DefaultTxOrderingConfig.Policy, "order in which queued transactions are sequenced, one of \""+TxOrderingFIFO+"\", \""+TxOrderingSenderFair+"\" (round-robin between senders) or \""+TxOrderingPriorityTip+"\" (highest tip first within the priority window)")
+	f.Int(prefix+".collect-blocks", DefaultTxOrderingConfig.CollectBlocks, "when not using the fifo policy, number of blocks worth of queued transactions to consider when ordering a block")
+	f.Duration(prefix+".priority-window", DefaultTxOrderingConfig.PriorityWindow, "maximum time the priority-tip policy can delay a transaction in favor of transactions with higher tips")
+}
+
+func (c *TxOrderingConfig) Validate() error {
+	if _, ok := txOrderingPolicies[c.Policy]; !ok {
+		return fmt.Errorf("unknown tx-ordering policy \"%v\"", c.Policy)
+	}
+	if c.CollectBlocks < 1 {
+		return errors.New("tx-ordering collect-blocks must be at least 1")
+	}
+	if c.Policy == TxOrderingPriorityTip && c.PriorityWindow <= 0 {
+		return errors.New("tx-ordering priority-window must be positive for the priority-tip policy")
+	}
+	return nil
+}
+
+// TxOrderingCandidate is a queued transaction the sequencer is about to include in a block.
+type TxOrderingCandidate struct {
+	Tx              *types.Transaction
+	Sender          common.Address
+	FirstAppearance time.Time
+}
+
+// TxOrderingPolicy decides the order in which the sequencer includes the transactions it collected
+// for a block. Candidates of the same sender are in nonce order, and policies must keep them that way.
+type TxOrderingPolicy interface {
+	// Order returns the indices of the candidates in the order they should be sequenced.
+	Order(candidates []TxOrderingCandidate, now time.Time, baseFee *big.Int) []int
+}
+
+var txOrderingPolicies = map[string]func(config *TxOrderingConfig) TxOrderingPolicy{
+	TxOrderingFIFO:       func(*TxOrderingConfig) TxOrderingPolicy { return fifoTxOrdering{} },
+	TxOrderingSenderFair: func(*TxOrderingConfig) TxOrderingPolicy { return senderFairTxOrdering{} },
+	TxOrderingPriorityTip: func(config *TxOrderingConfig) TxOrderingPolicy {
+		return priorityTipTxOrdering{window: config.PriorityWindow}
+	},
+}
+
+func NewTxOrderingPolicy(config *TxOrderingConfig) (TxOrderingPolicy, error) {
+	newPolicy, ok := txOrderingPolicies[config.Policy]
+	if !ok {
+		return nil, fmt.Errorf("unknown tx-ordering policy \"%v\"", config.Policy)
+	}
+	return newPolicy(config), nil
+}
+
+type fifoTxOrdering struct{}
+
+func (fifoTxOrdering) Order(candidates []TxOrderingCandidate, _ time.Time, _ *big.Int) []int {
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// senderFairTxOrdering takes one transaction from each sender in turn, so that a sender flooding
+// the queue only gets as many transactions into a block as any other sender with queued transactions.
+type senderFairTxOrdering struct{}
+
+func (senderFairTxOrdering) Order(candidates []TxOrderingCandidate, _ time.Time, _ *big.Int) []int {
+	var senders []common.Address
+	bySender := make(map[common.Address][]int)
+	for i, candidate := range candidates {
+		if _, ok := bySender[candidate.Sender]; !ok {
+			senders = append(senders, candidate.Sender)
+		}
+		bySender[candidate.Sender] = append(bySender[candidate.Sender], i)
+	}
+	order := make([]int, 0, len(candidates))
+	for len(order) < len(candidates) {
+		for _, sender := range senders {
+			if queue := bySender[sender]; len(queue) > 0 {
+				order = append(order, queue[0])
+				bySender[sender] = queue[1:]
+			}
+		}
+	}
+	return order
+}
+
+// priorityTipTxOrdering sequences transactions with higher tips first, but never delays a transaction
+// by more than the window: transactions that have been waiting longer go first, in arrival order.
+type priorityTipTxOrdering struct {
+	window time.Duration
+}
+
+func (p priorityTipTxOrdering) Order(candidates []TxOrderingCandidate, now time.Time, baseFee *big.Int) []int {
+	// A transaction can't be sequenced before an earlier nonce of its sender,
+	// so it's prioritized by the lowest tip among itself and those.
+	tips := make([]*big.Int, len(candidates))
+	senderTips := make(map[common.Address]*big.Int)
+	for i, candidate := range candidates {
+		tip := candidate.Tx.GasTipCap()
+		if baseFee != nil {
+			tip = arbmath.BigMin(tip, arbmath.BigSub(candidate.Tx.GasFeeCap(), baseFee))
+		}
+		if senderTip, ok := senderTips[candidate.Sender]; ok {
+			tip = arbmath.BigMin(tip, senderTip)
+		}
+		senderTips[candidate.Sender] = tip
+		tips[i] = tip
+	}
+	// Likewise, earlier nonces of an overdue transaction are overdue too
+	overdue := make([]bool, len(candidates))
+	senderOverdue := make(map[common.Address]bool)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		sender := candidates[i].Sender
+		overdue[i] = senderOverdue[sender] || now.Sub(candidates[i].FirstAppearance) >= p.window
+		senderOverdue[sender] = overdue[i]
+	}
+	order := fifoTxOrdering{}.Order(candidates, now, baseFee)
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if overdue[i] || overdue[j] {
+			return overdue[i] && !overdue[j]
+		}
+		return tips[i].Cmp(tips[j]) > 0
+	})
+	return order
+}
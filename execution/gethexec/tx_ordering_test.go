@@ -0,0 +1,60 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func orderingCandidate(sender byte, tip int64, firstAppearance time.Time) TxOrderingCandidate {
+	return TxOrderingCandidate{
+		Tx: types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(100 + tip),
+		}),
+		Sender:          common.Address{sender},
+		FirstAppearance: firstAppearance,
+	}
+}
+
+func TestTxOrderingPolicies(t *testing.T) {
+	now := time.Now()
+	candidates := []TxOrderingCandidate{
+		orderingCandidate(1, 1, now),
+		orderingCandidate(1, 1, now),
+		orderingCandidate(1, 1, now),
+		orderingCandidate(2, 5, now),
+		orderingCandidate(3, 3, now),
+		orderingCandidate(3, 9, now),
+	}
+
+	fifo, err := NewTxOrderingPolicy(&TxOrderingConfig{Policy: TxOrderingFIFO})
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5}, fifo.Order(candidates, now, nil))
+
+	fair, err := NewTxOrderingPolicy(&TxOrderingConfig{Policy: TxOrderingSenderFair})
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 3, 4, 1, 5, 2}, fair.Order(candidates, now, nil))
+
+	priority, err := NewTxOrderingPolicy(&TxOrderingConfig{Policy: TxOrderingPriorityTip, PriorityWindow: time.Second})
+	require.NoError(t, err)
+	// Sender 3's second tx has a high tip but can't go before its first
+	require.Equal(t, []int{3, 4, 5, 0, 1, 2}, priority.Order(candidates, now, nil))
+	// With a base fee of 102, sender 2 can only pay a tip of 3
+	require.Equal(t, []int{3, 4, 5, 0, 1, 2}, priority.Order(candidates, now, big.NewInt(102)))
+
+	// Overdue transactions go first regardless of their tip
+	candidates[1].FirstAppearance = now.Add(-2 * time.Second)
+	require.Equal(t, []int{0, 1, 3, 4, 5, 2}, priority.Order(candidates, now, nil))
+
+	_, err = NewTxOrderingPolicy(&TxOrderingConfig{Policy: "random"})
+	require.Error(t, err)
+}
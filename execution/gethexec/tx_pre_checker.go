@@ -23,6 +23,7 @@ import (
 	"github.com/offchainlabs/nitro/timeboost"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 var (
@@ -112,7 +113,7 @@ func MakeNonceError(sender common.Address, txNonce uint64, stateNonce uint64) er
 	}
 }
 
-func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *types.Header, statedb *state.StateDB, arbos *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, config *TxPreCheckerConfig) error {
+func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *types.Header, statedb *state.StateDB, arbos *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, conditions *ConditionalExtensions, config *TxPreCheckerConfig) error {
 	if config.Strictness < TxPreCheckerStrictnessAlwaysCompatible {
 		return nil
 	}
@@ -153,6 +154,12 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 	if config.Strictness < TxPreCheckerStrictnessLikelyCompatible {
 		return nil
 	}
+	if conditions != nil {
+		// #nosec G115
+		if err := conditions.checkDeadline(uint64(time.Now().Unix())); err != nil {
+			return err
+		}
+	}
 	if options != nil {
 		if err := options.Check(extraInfo.L1BlockNumber, header.Time, statedb); err != nil {
 			conditionalTxRejectedByTxPreCheckerCurrentStateCounter.Inc(1)
@@ -206,6 +213,9 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 	if tx.Gas() < intrinsic+dataGas.Uint64() {
 		return core.ErrIntrinsicGas
 	}
+	if conditions != nil {
+		return conditions.checkL1DataFee(dataCost)
+	}
 	return nil
 }
 
@@ -219,13 +229,70 @@ func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transac
 	if err != nil {
 		return err
 	}
-	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, options, c.config())
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, options, nil, c.config())
 	if err != nil {
 		return err
 	}
 	return c.TransactionPublisher.PublishTransaction(ctx, tx, options)
 }
 
+func (c *TxPreChecker) PublishTransactionWithPreconf(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) (*signature.Preconf, error) {
+	block := c.bc.CurrentBlock()
+	statedb, err := c.bc.StateAt(block.Root)
+	if err != nil {
+		return nil, err
+	}
+	arbos, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	conditionalOptions, conditions := options.split()
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, conditionalOptions, conditions, c.config())
+	if err != nil {
+		return nil, err
+	}
+	return c.TransactionPublisher.PublishTransactionWithPreconf(ctx, tx, options)
+}
+
+func (c *TxPreChecker) PublishConditionalTransaction(ctx context.Context, tx *types.Transaction, options *ExtendedConditionalOptions) error {
+	block := c.bc.CurrentBlock()
+	statedb, err := c.bc.StateAt(block.Root)
+	if err != nil {
+		return err
+	}
+	arbos, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return err
+	}
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, &options.ConditionalOptions, &options.ConditionalExtensions, c.config())
+	if err != nil {
+		return err
+	}
+	return c.TransactionPublisher.PublishConditionalTransaction(ctx, tx, options)
+}
+
+// PublishBundle only pre-checks the first transaction of the bundle, as the later ones may depend
+// on the state changes of the earlier ones. The sequencer drops the bundle if any of them fails.
+func (c *TxPreChecker) PublishBundle(ctx context.Context, bundle *Bundle) error {
+	if len(bundle.Transactions) == 0 {
+		return ErrBundleEmpty
+	}
+	block := c.bc.CurrentBlock()
+	statedb, err := c.bc.StateAt(block.Root)
+	if err != nil {
+		return err
+	}
+	arbos, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return err
+	}
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, bundle.Transactions[0], bundle.Options, nil, c.config())
+	if err != nil {
+		return err
+	}
+	return c.TransactionPublisher.PublishBundle(ctx, bundle)
+}
+
 func (c *TxPreChecker) PublishExpressLaneTransaction(ctx context.Context, msg *timeboost.ExpressLaneSubmission) error {
 	if msg == nil || msg.Transaction == nil {
 		return timeboost.ErrMalformedData
@@ -239,7 +306,7 @@ func (c *TxPreChecker) PublishExpressLaneTransaction(ctx context.Context, msg *t
 	if err != nil {
 		return err
 	}
-	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, msg.Transaction, msg.Options, c.config())
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, msg.Transaction, msg.Options, nil, c.config())
 	if err != nil {
 		return err
 	}
@@ -256,7 +323,7 @@ func (c *TxPreChecker) PublishAuctionResolutionTransaction(ctx context.Context,
 	if err != nil {
 		return err
 	}
-	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, nil, c.config())
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, nil, nil, c.config())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,192 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/util/signature"
+)
+
+// The stages of a transaction reported by arb_getTransactionStatus, from earliest to latest.
+const (
+	TxStatusUnknown         = "unknown"
+	TxStatusQueued          = "queued"
+	TxStatusWaitingForNonce = "waiting-for-nonce"
+	TxStatusForwarded       = "forwarded"
+	TxStatusRejected        = "rejected"
+	TxStatusSequenced       = "sequenced"
+	TxStatusPosted          = "posted"
+	TxStatusValidated       = "validated"
+	TxStatusConfirmed       = "confirmed"
+)
+
+type sequencerTxStatus struct {
+	status      string
+	err         error
+	forwardedTo string
+	updated     time.Time
+}
+
+// txStatusCache remembers what the sequencer last did with recently submitted transactions.
+// Once a transaction is in a block its status comes from the chain instead.
+type txStatusCache struct {
+	mutex sync.Mutex
+	cache *containers.LruCache[common.Hash, sequencerTxStatus]
+}
+
+func newTxStatusCache(size int) *txStatusCache {
+	return &txStatusCache{
+		cache: containers.NewLruCache[common.Hash, sequencerTxStatus](size),
+	}
+}
+
+// set records the status of a transaction. It's a no-op on a nil cache.
+func (c *txStatusCache) set(txHash common.Hash, status string, err error, forwardedTo string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cache.Add(txHash, sequencerTxStatus{
+		status:      status,
+		err:         err,
+		forwardedTo: forwardedTo,
+		updated:     time.Now(),
+	})
+}
+
+func (c *txStatusCache) get(txHash common.Hash) (sequencerTxStatus, bool) {
+	if c == nil {
+		return sequencerTxStatus{}, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cache.Get(txHash)
+}
+
+func (c *txStatusCache) resize(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cache.Resize(size)
+}
+
+type TransactionStatus struct {
+	Status string `json:"status"`
+	// Set if the sequencer rejected or dropped the transaction
+	Error string `json:"error,omitempty"`
+	// The sequencer the transaction was forwarded to, if this node isn't the chosen sequencer
+	ForwardedTo      string          `json:"forwardedTo,omitempty"`
+	UpdatedAt        *time.Time      `json:"updatedAt,omitempty"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	BlockHash        *common.Hash    `json:"blockHash,omitempty"`
+	MessageIndex     *hexutil.Uint64 `json:"messageIndex,omitempty"`
+	BatchNumber      *hexutil.Uint64 `json:"batchNumber,omitempty"`
+	ParentChainBlock *hexutil.Uint64 `json:"parentChainBlock,omitempty"`
+	Validated        bool            `json:"validated"`
+	Confirmed        bool            `json:"confirmed"`
+}
+
+// TxStatusFetcher combines the sequencer's view of a transaction with where it is on the chain,
+// in batches posted to the parent chain, and in validation and confirmation.
+type TxStatusFetcher struct {
+	chainDB         ethdb.Database
+	bc              *core.BlockChain
+	execEngine      *ExecutionEngine
+	sequencer       *Sequencer
+	preconfVerifier *signature.Verifier
+}
+
+func NewTxStatusFetcher(chainDB ethdb.Database, bc *core.BlockChain, execEngine *ExecutionEngine, sequencer *Sequencer, preconfVerifier *signature.Verifier) *TxStatusFetcher {
+	return &TxStatusFetcher{
+		chainDB:         chainDB,
+		bc:              bc,
+		execEngine:      execEngine,
+		sequencer:       sequencer,
+		preconfVerifier: preconfVerifier,
+	}
+}
+
+func (f *TxStatusFetcher) sequencerStatus(txHash common.Hash) *TransactionStatus {
+	if f.sequencer == nil {
+		return &TransactionStatus{Status: TxStatusUnknown}
+	}
+	cached, ok := f.sequencer.txStatuses.get(txHash)
+	if !ok {
+		return &TransactionStatus{Status: TxStatusUnknown}
+	}
+	status := &TransactionStatus{
+		Status:      cached.status,
+		ForwardedTo: cached.forwardedTo,
+		UpdatedAt:   &cached.updated,
+	}
+	if cached.err != nil {
+		status.Error = cached.err.Error()
+	}
+	return status
+}
+
+func (f *TxStatusFetcher) Fetch(txHash common.Hash) (*TransactionStatus, error) {
+	blockNum := rawdb.ReadTxLookupEntry(f.chainDB, txHash)
+	if blockNum == nil {
+		return f.sequencerStatus(txHash), nil
+	}
+	// Lookup entries of reorged out transactions are left behind, so check that the canonical block has the tx
+	block := f.bc.GetBlockByNumber(*blockNum)
+	if block == nil || block.Transaction(txHash) == nil {
+		return f.sequencerStatus(txHash), nil
+	}
+	blockHash := block.Hash()
+	status := &TransactionStatus{
+		Status:      TxStatusSequenced,
+		BlockNumber: (*hexutil.Uint64)(blockNum),
+		BlockHash:   &blockHash,
+	}
+	msgIdx, err := f.execEngine.BlockNumberToMessageIndex(*blockNum)
+	if err != nil {
+		// Blocks before the nitro genesis aren't part of any message
+		return status, nil
+	}
+	status.MessageIndex = (*hexutil.Uint64)(&msgIdx)
+
+	consensus := f.execEngine.consensus
+	if consensus == nil {
+		return status, nil
+	}
+	batch, found, err := consensus.FindInboxBatchContainingMessage(msgIdx)
+	if err != nil {
+		log.Warn("error finding batch containing message", "msgIdx", msgIdx, "err", err)
+		return status, nil
+	}
+	if !found {
+		return status, nil
+	}
+	status.Status = TxStatusPosted
+	status.BatchNumber = (*hexutil.Uint64)(&batch)
+	parentChainBlock, err := consensus.GetBatchParentChainBlock(batch)
+	if err != nil {
+		log.Warn("error getting parent chain block of batch", "batch", batch, "err", err)
+	} else {
+		status.ParentChainBlock = (*hexutil.Uint64)(&parentChainBlock)
+	}
+	// Either of these fails if the node isn't running a validator or staker, leaving the status at posted
+	if validated, err := consensus.ValidatedMessageCount(); err == nil && validated > msgIdx {
+		status.Status = TxStatusValidated
+		status.Validated = true
+	}
+	if confirmed, err := consensus.ConfirmedMessageCount(); err == nil && confirmed > msgIdx {
+		status.Status = TxStatusConfirmed
+		status.Confirmed = true
+	}
+	return status, nil
+}
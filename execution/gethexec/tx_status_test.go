@@ -0,0 +1,38 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTxStatusCache(t *testing.T) {
+	cache := newTxStatusCache(2)
+	first := common.HexToHash("0x01")
+	second := common.HexToHash("0x02")
+	third := common.HexToHash("0x03")
+
+	cache.set(first, TxStatusQueued, nil, "")
+	cache.set(first, TxStatusRejected, errors.New("nonce too low"), "")
+	fetcher := &TxStatusFetcher{sequencer: &Sequencer{txStatuses: cache}}
+	status := fetcher.sequencerStatus(first)
+	require.Equal(t, TxStatusRejected, status.Status)
+	require.Equal(t, "nonce too low", status.Error)
+
+	cache.set(second, TxStatusForwarded, nil, "http://sequencer:8547")
+	cache.set(third, TxStatusWaitingForNonce, nil, "")
+	// The oldest transaction is forgotten once the cache is full
+	require.Equal(t, TxStatusUnknown, fetcher.sequencerStatus(first).Status)
+	status = fetcher.sequencerStatus(second)
+	require.Equal(t, TxStatusForwarded, status.Status)
+	require.Equal(t, "http://sequencer:8547", status.ForwardedTo)
+
+	// Nodes without a sequencer only know about transactions on the chain
+	require.Equal(t, TxStatusUnknown, (&TxStatusFetcher{}).sequencerStatus(third).Status)
+}
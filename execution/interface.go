@@ -36,6 +36,7 @@ type ExecutionClient interface {
 	ResultAtPos(pos arbutil.MessageIndex) (*MessageResult, error)
 	MessageIndexToBlockNumber(messageNum arbutil.MessageIndex) uint64
 	BlockNumberToMessageIndex(blockNum uint64) (arbutil.MessageIndex, error)
+	ArbOSVersionForMessageNumber(messageNum arbutil.MessageIndex) (uint64, error)
 }
 
 // needed for validators / stakers
@@ -71,8 +72,6 @@ type FullExecutionClient interface {
 	StopAndWait()
 
 	Maintenance() error
-
-	ArbOSVersionForMessageNumber(messageNum arbutil.MessageIndex) (uint64, error)
 }
 
 // not implemented in execution, used as input
@@ -92,6 +91,7 @@ type ConsensusInfo interface {
 	GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error)
 	GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error)
 	ValidatedMessageCount() (arbutil.MessageIndex, error)
+	ConfirmedMessageCount() (arbutil.MessageIndex, error)
 }
 
 type ConsensusSequencer interface {
@@ -2,6 +2,7 @@ package pubsub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,42 @@ import (
 
 func ResultKeyFor(streamName, id string) string { return fmt.Sprintf("%s.%s", streamName, id) }
 
+// errorResultPrefix marks results that are errors rather than responses. It can't start a json value.
+const errorResultPrefix = "error:"
+
+var (
+	ErrDeadLettered    = errors.New("request moved to dead-letter stream")
+	ErrNoConsumerGroup = errors.New("request sent to a stream without consumer group")
+	ErrProducerStopped = errors.New("producer stopped before sending request")
+)
+
+// PriorityStreamFor returns the stream requests with the priority are sent to. Priority 0 uses the stream
+// itself, so producers and consumers without priorities keep working with each other.
+func PriorityStreamFor(streamName string, priority uint8) string {
+	if priority == 0 {
+		return streamName
+	}
+	return fmt.Sprintf("%s:priority-%d", streamName, priority)
+}
+
+// PriorityStreams returns the streams of each priority level, lowest priority first.
+func PriorityStreams(streamName string, levels uint8) []string {
+	streams := []string{streamName}
+	for priority := uint8(1); priority < levels; priority++ {
+		streams = append(streams, PriorityStreamFor(streamName, priority))
+	}
+	return streams
+}
+
+// DeadLetterStreamFor returns the stream requests that were delivered too many times are moved to.
+func DeadLetterStreamFor(streamName string) string {
+	return streamName + ":dead-letter"
+}
+
+func isNoGroupErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "NOGROUP")
+}
+
 // CreateStream tries to create stream with given name, if it already exists
 // does not return an error.
 func CreateStream(ctx context.Context, streamName string, client redis.UniversalClient) error {
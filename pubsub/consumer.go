@@ -24,21 +24,36 @@ type ConsumerConfig struct {
 	ResponseEntryTimeout time.Duration `koanf:"response-entry-timeout"`
 	// Minimum idle time after which messages will be autoclaimed
 	IdletimeToAutoclaim time.Duration `koanf:"idletime-to-autoclaim"`
+	// Number of priority levels to read, highest first. Must match the producers.
+	PriorityLevels uint8 `koanf:"priority-levels"`
+	// Number of deliveries after which an unfinished message is moved to the dead-letter stream
+	MaxDeliveries int64 `koanf:"max-deliveries"`
+	// Approximate number of messages kept in the dead-letter stream
+	DeadLetterMaxLen int64 `koanf:"dead-letter-max-len"`
 }
 
 var DefaultConsumerConfig = ConsumerConfig{
 	ResponseEntryTimeout: time.Hour,
 	IdletimeToAutoclaim:  5 * time.Minute,
+	PriorityLevels:       1,
+	MaxDeliveries:        0,
+	DeadLetterMaxLen:     1000,
 }
 
 var TestConsumerConfig = ConsumerConfig{
 	ResponseEntryTimeout: time.Minute,
 	IdletimeToAutoclaim:  30 * time.Millisecond,
+	PriorityLevels:       1,
+	MaxDeliveries:        0,
+	DeadLetterMaxLen:     100,
 }
 
-func ConsumerConfigAddOptions(prefix string, f *pflag.FlagSet) {
-	f.Duration(prefix+".response-entry-timeout", DefaultConsumerConfig.ResponseEntryTimeout, "timeout for response entry")
-	f.Duration(prefix+".idletime-to-autoclaim", DefaultConsumerConfig.IdletimeToAutoclaim, "After a message spends this amount of time in PEL (Pending Entries List i.e claimed by another consumer but not Acknowledged) it will be allowed to be autoclaimed by other consumers")
+func ConsumerConfigAddOptions(prefix string, f *pflag.FlagSet, defaultConfig *ConsumerConfig) {
+	f.Duration(prefix+".response-entry-timeout", defaultConfig.ResponseEntryTimeout, "timeout for response entry")
+	f.Duration(prefix+".idletime-to-autoclaim", defaultConfig.IdletimeToAutoclaim, "After a message spends this amount of time in PEL (Pending Entries List i.e claimed by another consumer but not Acknowledged) it will be allowed to be autoclaimed by other consumers")
+	f.Uint8(prefix+".priority-levels", defaultConfig.PriorityLevels, "number of priority levels to read, highest first (must match the producers)")
+	f.Int64(prefix+".max-deliveries", defaultConfig.MaxDeliveries, "number of times a message can be delivered without a result before it's moved to the dead-letter stream and its producer is sent an error (0 = unlimited)")
+	f.Int64(prefix+".dead-letter-max-len", defaultConfig.DeadLetterMaxLen, "approximate number of messages kept in the dead-letter stream")
 }
 
 // Consumer implements a consumer for redis stream provides heartbeat to
@@ -48,14 +63,17 @@ type Consumer[Request any, Response any] struct {
 	id          string
 	client      redis.UniversalClient
 	redisStream string
-	redisGroup  string
-	cfg         *ConsumerConfig
+	// Streams of each priority level, lowest first. Each stream has a consumer group of the same name.
+	streams []string
+	cfg     *ConsumerConfig
 }
 
 type Message[Request any] struct {
-	ID    string
-	Value Request
-	Ack   func()
+	ID string
+	// The priority stream the message was read from
+	Stream string
+	Value  Request
+	Ack    func()
 }
 
 func NewConsumer[Request any, Response any](client redis.UniversalClient, streamName string, cfg *ConsumerConfig) (*Consumer[Request, Response], error) {
@@ -66,7 +84,7 @@ func NewConsumer[Request any, Response any](client redis.UniversalClient, stream
 		id:          uuid.NewString(),
 		client:      client,
 		redisStream: streamName,
-		redisGroup:  streamName, // There is 1-1 mapping of redis stream and consumer group.
+		streams:     PriorityStreams(streamName, cfg.PriorityLevels),
 		cfg:         cfg,
 	}, nil
 }
@@ -74,6 +92,18 @@ func NewConsumer[Request any, Response any](client redis.UniversalClient, stream
 // Start starts the consumer to iteratively perform heartbeat in configured intervals.
 func (c *Consumer[Request, Response]) Start(ctx context.Context) {
 	c.StopWaiter.Start(ctx, c)
+	c.StopWaiter.CallIteratively(c.checkPriorityLevels)
+}
+
+// checkPriorityLevels logs an error while the stream of the priority level above the ones this consumer
+// reads exists, as producers configured with more levels send requests to it that won't be read.
+func (c *Consumer[Request, Response]) checkPriorityLevels(ctx context.Context) time.Duration {
+	// #nosec G115
+	unread := PriorityStreamFor(c.redisStream, uint8(len(c.streams)))
+	if StreamExists(ctx, unread, c.client) {
+		log.Error("consumer doesn't read the stream of a priority level producers use, priority-levels must match the producers", "stream", unread, "priorityLevels", len(c.streams))
+	}
+	return time.Minute
 }
 
 func (c *Consumer[Request, Response]) Id() string {
@@ -106,30 +136,48 @@ func decrementMsgIdByOne(msgId string) string {
 	return "0"
 }
 
-// Consumer first checks it there exists pending message that is claimed by
-// unresponsive consumer, if not then reads from the stream.
+// Consume reads the next message from the highest priority stream that has one.
 func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Request], error) {
+	for i := len(c.streams) - 1; i >= 0; i-- {
+		msg, err := c.consumeFrom(ctx, c.streams[i])
+		if i > 0 && isNoGroupErr(err) {
+			// The producers haven't created this priority's stream
+			continue
+		}
+		if err != nil || msg != nil {
+			return msg, err
+		}
+	}
+	return nil, nil
+}
+
+// consumeFrom first checks it there exists pending message that is claimed by
+// unresponsive consumer, if not then reads from the stream.
+func (c *Consumer[Request, Response]) consumeFrom(ctx context.Context, stream string) (*Message[Request], error) {
 	// First try to XAUTOCLAIM, with start as a random messageID from PEL with MinIdle as IdletimeToAutoclaim
 	// this prioritizes processing PEL messages that have been waiting for more than IdletimeToAutoclaim duration
 	var messages []redis.XMessage
 	if pendingMsgs, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
-		Stream: c.redisStream,
-		Group:  c.redisGroup,
+		Stream: stream,
+		Group:  stream,
 		Start:  "-",
 		End:    "+",
 		Count:  50,
 		Idle:   c.cfg.IdletimeToAutoclaim,
 	}).Result(); err != nil {
+		if isNoGroupErr(err) {
+			return nil, err
+		}
 		if !errors.Is(err, redis.Nil) {
 			log.Error("Error from XpendingExt in getting PEL for auto claim", "err", err, "penindlen", len(pendingMsgs))
 		}
-	} else if len(pendingMsgs) > 0 {
+	} else if pendingMsgs = c.deadLetter(ctx, stream, pendingMsgs); len(pendingMsgs) > 0 {
 		idx := rand.Intn(len(pendingMsgs))
 		messages, _, err = c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
-			Group:    c.redisGroup,
+			Group:    stream,
 			Consumer: c.id,
 			MinIdle:  c.cfg.IdletimeToAutoclaim, // Minimum idle time for messages to claim (in milliseconds)
-			Stream:   c.redisStream,
+			Stream:   stream,
 			Start:    decrementMsgIdByOne(pendingMsgs[idx].ID),
 			Count:    1,
 		}).Result()
@@ -140,11 +188,11 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 	if len(messages) == 0 {
 		// If we fail to autoclaim then we do not retry but instead fallback to reading new messages
 		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    c.redisGroup,
+			Group:    stream,
 			Consumer: c.id,
 			// Receive only messages that were never delivered to any other consumer,
 			// that is, only new messages.
-			Streams: []string{c.redisStream, ">"},
+			Streams: []string{stream, ">"},
 			Count:   1,
 			Block:   time.Millisecond, // 0 seems to block the read instead of immediately returning
 		}).Result()
@@ -177,8 +225,8 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 			// Use XClaimJustID so that we would have clear difference between invalid requests that are claimed multiple times due to xautoclaim and
 			// valid requests that are just being claimed in regular intervals to indicate heartbeat
 			if ids, err := c.client.XClaimJustID(ctx, &redis.XClaimArgs{
-				Stream:   c.redisStream,
-				Group:    c.redisGroup,
+				Stream:   stream,
+				Group:    stream,
 				Consumer: c.id,
 				MinIdle:  0,
 				Messages: []string{messages[0].ID},
@@ -196,7 +244,7 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 				log.Info("Context done while claiming message to indicate hearbeat", "messageID", messages[0].ID, "error", ctx.Err().Error())
 				if c.StopWaiter.GetParentContext().Err() == nil {
 					// Proceeding to set the Idle time of message to IdletimeToAutoclaim to allow it to be picked by other consumers
-					if err := c.client.Do(c.StopWaiter.GetParentContext(), "XCLAIM", c.redisStream, c.redisGroup, c.id, 0, messages[0].ID, "IDLE", c.cfg.IdletimeToAutoclaim.Milliseconds()).Err(); err != nil {
+					if err := c.client.Do(c.StopWaiter.GetParentContext(), "XCLAIM", stream, stream, c.id, 0, messages[0].ID, "IDLE", c.cfg.IdletimeToAutoclaim.Milliseconds()).Err(); err != nil {
 						log.Error("error when trying to set the idle time of currently worked on message to IdletimeToAutoclaim", "messageID", messages[0].ID, "err", err)
 					}
 				}
@@ -205,31 +253,88 @@ func (c *Consumer[Request, Response]) Consume(ctx context.Context) (*Message[Req
 			}
 		}
 	})
-	log.Debug("Redis stream consuming", "consumer_id", c.id, "message_id", messages[0].ID)
+	log.Debug("Redis stream consuming", "consumer_id", c.id, "message_id", messages[0].ID, "stream", stream)
 	return &Message[Request]{
-		ID:    messages[0].ID,
-		Value: req,
-		Ack:   func() { close(ackNotifier) },
+		ID:     messages[0].ID,
+		Stream: stream,
+		Value:  req,
+		Ack:    func() { close(ackNotifier) },
 	}, nil
 }
 
-func (c *Consumer[Request, Response]) SetResult(ctx context.Context, messageID string, result Response) error {
-	resp, err := json.Marshal(result)
-	if err != nil {
-		return fmt.Errorf("marshaling result: %w", err)
+// deadLetter moves the pending messages that were delivered too many times to the dead-letter stream,
+// and returns the others. The producers of the moved messages get an error result.
+func (c *Consumer[Request, Response]) deadLetter(ctx context.Context, stream string, pendingMsgs []redis.XPendingExt) []redis.XPendingExt {
+	if c.cfg.MaxDeliveries <= 0 {
+		return pendingMsgs
 	}
-	resultKey := ResultKeyFor(c.StreamName(), messageID)
-	log.Debug("consumer: setting result", "cid", c.id, "msgIdInStream", messageID, "resultKeyInRedis", resultKey)
-	acquired, err := c.client.SetNX(ctx, resultKey, resp, c.cfg.ResponseEntryTimeout).Result()
-	if err != nil || !acquired {
-		return fmt.Errorf("setting result for message with message-id in stream: %v, error: %w", messageID, err)
+	var remaining []redis.XPendingExt
+	for _, pending := range pendingMsgs {
+		if pending.RetryCount < c.cfg.MaxDeliveries {
+			remaining = append(remaining, pending)
+			continue
+		}
+		// Claiming it first makes sure only one consumer moves it, and that it's still idle
+		claimed, err := c.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   stream,
+			Group:    stream,
+			Consumer: c.id,
+			MinIdle:  c.cfg.IdletimeToAutoclaim,
+			Messages: []string{pending.ID},
+		}).Result()
+		if err != nil {
+			log.Error("error claiming message to move to dead-letter stream", "msgID", pending.ID, "err", err)
+			continue
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+		if err := c.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: DeadLetterStreamFor(stream),
+			MaxLen: c.cfg.DeadLetterMaxLen,
+			Approx: true,
+			Values: map[string]any{
+				messageKey:   claimed[0].Values[messageKey],
+				"id":         pending.ID,
+				"deliveries": pending.RetryCount,
+			},
+		}).Err(); err != nil {
+			log.Error("error adding message to dead-letter stream", "msgID", pending.ID, "err", err)
+			continue
+		}
+		reason := fmt.Sprintf("delivered %d times without a result", pending.RetryCount)
+		if err := c.client.SetNX(ctx, ResultKeyFor(stream, pending.ID), errorResultPrefix+reason, c.cfg.ResponseEntryTimeout).Err(); err != nil {
+			log.Error("error setting dead-letter result", "msgID", pending.ID, "err", err)
+		}
+		if err := c.ackAndDelete(ctx, stream, pending.ID); err != nil {
+			log.Error("error removing dead-lettered message", "msgID", pending.ID, "err", err)
+		}
+		log.Warn("moved message to dead-letter stream", "stream", stream, "msgID", pending.ID, "deliveries", pending.RetryCount)
 	}
-	log.Debug("consumer: xack", "cid", c.id, "messageId", messageID)
-	if _, err := c.client.XAck(ctx, c.redisStream, c.redisGroup, messageID).Result(); err != nil {
+	return remaining
+}
+
+func (c *Consumer[Request, Response]) ackAndDelete(ctx context.Context, stream string, messageID string) error {
+	if _, err := c.client.XAck(ctx, stream, stream, messageID).Result(); err != nil {
 		return fmt.Errorf("acking message: %v, error: %w", messageID, err)
 	}
-	if _, err := c.client.XDel(ctx, c.redisStream, messageID).Result(); err != nil {
+	if _, err := c.client.XDel(ctx, stream, messageID).Result(); err != nil {
 		return fmt.Errorf("deleting message: %v, error: %w", messageID, err)
 	}
 	return nil
 }
+
+func (c *Consumer[Request, Response]) SetResult(ctx context.Context, msg *Message[Request], result Response) error {
+	resp, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshaling result: %w", err)
+	}
+	resultKey := ResultKeyFor(msg.Stream, msg.ID)
+	log.Debug("consumer: setting result", "cid", c.id, "msgIdInStream", msg.ID, "resultKeyInRedis", resultKey)
+	acquired, err := c.client.SetNX(ctx, resultKey, resp, c.cfg.ResponseEntryTimeout).Result()
+	if err != nil || !acquired {
+		return fmt.Errorf("setting result for message with message-id in stream: %v, error: %w", msg.ID, err)
+	}
+	log.Debug("consumer: xack", "cid", c.id, "messageId", msg.ID)
+	return c.ackAndDelete(ctx, msg.Stream, msg.ID)
+}
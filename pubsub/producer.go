@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,14 +36,16 @@ const (
 
 type Producer[Request any, Response any] struct {
 	stopwaiter.StopWaiter
-	id          string
-	client      redis.UniversalClient
-	redisStream string
-	redisGroup  string
-	cfg         *ProducerConfig
+	id     string
+	client redis.UniversalClient
+	// Streams of each priority level, lowest first. Each stream has a consumer group of the same name.
+	streams []string
+	cfg     *ProducerConfig
 
 	promisesLock sync.RWMutex
-	promises     map[string]*containers.Promise[Response]
+	promises     map[messageRef]*containers.Promise[Response]
+	// Requests waiting for fewer requests to be in flight, by priority
+	queued [][]queuedRequest[Response]
 
 	// Used for checking responses from consumers iteratively
 	// For the first time when Produce is called.
@@ -54,21 +57,44 @@ type ProducerConfig struct {
 	CheckResultInterval time.Duration `koanf:"check-result-interval"`
 	// RequestTimeout is a TTL for any message sent to the redis stream
 	RequestTimeout time.Duration `koanf:"request-timeout"`
+	// Number of priority levels, each sent to its own stream. Consumers must read the same number of levels.
+	PriorityLevels uint8 `koanf:"priority-levels"`
+	// Maximum number of requests in the streams at once. Producers sharing consumers with the same limit each
+	// get a fair share of them, as none can fill the streams ahead of the others.
+	MaxInFlight int `koanf:"max-in-flight"`
 }
 
 var DefaultProducerConfig = ProducerConfig{
 	CheckResultInterval: 5 * time.Second,
 	RequestTimeout:      3 * time.Hour,
+	PriorityLevels:      1,
+	MaxInFlight:         0,
 }
 
 var TestProducerConfig = ProducerConfig{
 	CheckResultInterval: 5 * time.Millisecond,
 	RequestTimeout:      time.Minute,
+	PriorityLevels:      1,
+	MaxInFlight:         0,
 }
 
-func ProducerAddConfigAddOptions(prefix string, f *pflag.FlagSet) {
-	f.Duration(prefix+".check-result-interval", DefaultProducerConfig.CheckResultInterval, "interval in which producer checks pending messages whether consumer processing them is inactive")
-	f.Duration(prefix+".request-timeout", DefaultProducerConfig.RequestTimeout, "timeout after which the message in redis stream is considered as errored, this prevents workers from working on wrong requests indefinitely")
+func ProducerAddConfigAddOptions(prefix string, f *pflag.FlagSet, defaultConfig *ProducerConfig) {
+	f.Duration(prefix+".check-result-interval", defaultConfig.CheckResultInterval, "interval in which producer checks pending messages whether consumer processing them is inactive")
+	f.Duration(prefix+".request-timeout", defaultConfig.RequestTimeout, "timeout after which the message in redis stream is considered as errored, this prevents workers from working on wrong requests indefinitely")
+	f.Uint8(prefix+".priority-levels", defaultConfig.PriorityLevels, "number of priority levels, each with its own stream (consumers must be configured with the same number)")
+	f.Int(prefix+".max-in-flight", defaultConfig.MaxInFlight, "maximum number of requests waiting in the streams at once, further requests are queued locally by priority and time out like sent ones (0 = unlimited)")
+}
+
+// messageRef identifies a message across the priority streams, as ids are only unique within a stream.
+type messageRef struct {
+	stream string
+	id     string
+}
+
+type queuedRequest[Response any] struct {
+	value    []byte
+	promise  *containers.Promise[Response]
+	queuedAt time.Time
 }
 
 func NewProducer[Request any, Response any](client redis.UniversalClient, streamName string, cfg *ProducerConfig) (*Producer[Request, Response], error) {
@@ -78,13 +104,14 @@ func NewProducer[Request any, Response any](client redis.UniversalClient, stream
 	if streamName == "" {
 		return nil, fmt.Errorf("stream name cannot be empty")
 	}
+	streams := PriorityStreams(streamName, cfg.PriorityLevels)
 	return &Producer[Request, Response]{
-		id:          uuid.NewString(),
-		client:      client,
-		redisStream: streamName,
-		redisGroup:  streamName, // There is 1-1 mapping of redis stream and consumer group.
-		cfg:         cfg,
-		promises:    make(map[string]*containers.Promise[Response]),
+		id:       uuid.NewString(),
+		client:   client,
+		streams:  streams,
+		cfg:      cfg,
+		promises: make(map[messageRef]*containers.Promise[Response]),
+		queued:   make([][]queuedRequest[Response], len(streams)),
 	}, nil
 }
 
@@ -137,28 +164,31 @@ func (p *Producer[Request, Response]) checkResponses(ctx context.Context) time.D
 	errored := 0
 	checked := 0
 	allowedOldestID := fmt.Sprintf("%d-0", time.Now().Add(-p.cfg.RequestTimeout).UnixMilli())
-	for id, promise := range p.promises {
+	for ref, promise := range p.promises {
 		if ctx.Err() != nil {
 			return 0
 		}
 		checked++
-		resultKey := ResultKeyFor(p.redisStream, id)
+		resultKey := ResultKeyFor(ref.stream, ref.id)
 		res, err := p.client.Get(ctx, resultKey).Result()
 		if err != nil {
 			if !errors.Is(err, redis.Nil) {
 				log.Error("Error reading value in redis", "key", resultKey, "error", err)
-			} else if cmpMsgId(id, allowedOldestID) == -1 {
+			} else if cmpMsgId(ref.id, allowedOldestID) == -1 {
 				// The request this producer is waiting for has been past its TTL or is older than current PEL's lower,
 				// so safe to error and stop tracking this promise
 				promise.ProduceError(errors.New("error getting response, request has been waiting for too long"))
 				log.Error("error getting response, request has been waiting past its TTL")
 				errored++
-				delete(p.promises, id)
+				delete(p.promises, ref)
 			}
 			continue
 		}
 		var resp Response
-		if err := json.Unmarshal([]byte(res), &resp); err != nil {
+		if reason, isErr := strings.CutPrefix(res, errorResultPrefix); isErr {
+			promise.ProduceError(fmt.Errorf("%w: %s", ErrDeadLettered, reason))
+			errored++
+		} else if err := json.Unmarshal([]byte(res), &resp); err != nil {
 			promise.ProduceError(fmt.Errorf("error unmarshalling: %w", err))
 			log.Error("redis producer: Error unmarshaling", "value", res, "error", err)
 			errored++
@@ -167,29 +197,108 @@ func (p *Producer[Request, Response]) checkResponses(ctx context.Context) time.D
 			responded++
 		}
 		p.client.Del(ctx, resultKey)
-		delete(p.promises, id)
+		delete(p.promises, ref)
+	}
+	errored += p.expireQueued()
+	if _, err := p.sendQueued(ctx); err != nil {
+		log.Error("error sending queued requests", "err", err)
 	}
 	log.Debug("checkResponses", "responded", responded, "errored", errored, "checked", checked)
 	return p.cfg.CheckResultInterval
 }
 
+// expireQueued fails the queued requests that have been waiting past the request timeout, and returns
+// how many it failed. Must be called with promisesLock held.
+func (p *Producer[Request, Response]) expireQueued() int {
+	expired := 0
+	oldest := time.Now().Add(-p.cfg.RequestTimeout)
+	for priority, queued := range p.queued {
+		remaining := queued[:0]
+		for _, request := range queued {
+			if request.queuedAt.Before(oldest) {
+				request.promise.ProduceError(errors.New("error sending request, request has been queued for too long"))
+				expired++
+				continue
+			}
+			remaining = append(remaining, request)
+		}
+		p.queued[priority] = remaining
+	}
+	if expired > 0 {
+		log.Error("error sending requests, requests have been queued past their TTL", "expired", expired)
+	}
+	return expired
+}
+
+// checkStreams fails the requests sent to streams without their consumer group, which no consumer would
+// ever read. That happens when the groups weren't created, for example for priority levels the consumers
+// weren't configured with.
+func (p *Producer[Request, Response]) checkStreams(ctx context.Context) time.Duration {
+	unread := make(map[string]bool)
+	for _, stream := range p.streams {
+		if !p.hasGroup(ctx, stream) {
+			unread[stream] = true
+		}
+	}
+	if len(unread) == 0 {
+		return 5 * p.cfg.CheckResultInterval
+	}
+	p.promisesLock.Lock()
+	defer p.promisesLock.Unlock()
+	for ref, promise := range p.promises {
+		if unread[ref.stream] {
+			log.Error("redis stream has no consumer group, failing request sent to it", "stream", ref.stream, "msgID", ref.id)
+			promise.ProduceError(fmt.Errorf("%w: %s", ErrNoConsumerGroup, ref.stream))
+			delete(p.promises, ref)
+		}
+	}
+	return 5 * p.cfg.CheckResultInterval
+}
+
+// hasGroup returns whether the stream has its consumer group. Errors are logged and assumed not to mean
+// the group is missing.
+func (p *Producer[Request, Response]) hasGroup(ctx context.Context, stream string) bool {
+	groups, err := p.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return false
+		}
+		log.Error("error getting consumer groups of redis stream", "stream", stream, "err", err)
+		return true
+	}
+	for _, group := range groups {
+		if group.Name == stream {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Producer[Request, Response]) clearMessages(ctx context.Context) time.Duration {
-	pelData, err := p.client.XPending(ctx, p.redisStream, p.redisGroup).Result()
+	interval := 5 * p.cfg.CheckResultInterval
+	for _, stream := range p.streams {
+		interval = min(interval, p.clearStream(ctx, stream))
+	}
+	return interval
+}
+
+func (p *Producer[Request, Response]) clearStream(ctx context.Context, stream string) time.Duration {
+	pelData, err := p.client.XPending(ctx, stream, stream).Result()
 	if err != nil {
 		log.Error("error getting PEL data from xpending, xtrimming is disabled", "err", err)
 	}
 	// XDEL on consumer side already deletes acked messages (mark as deleted) but doesnt claim the memory back, XTRIM helps in claiming this memory in normal conditions
 	// pelData might be outdated when we do the xtrim, but thats ok as the messages are also being trimmed by other producers
 	if pelData != nil && pelData.Lower != "" {
-		trimmed, trimErr := p.client.XTrimMinID(ctx, p.redisStream, pelData.Lower).Result()
+		trimmed, trimErr := p.client.XTrimMinID(ctx, stream, pelData.Lower).Result()
 		log.Debug("trimming", "xTrimMinID", pelData.Lower, "trimmed", trimmed, "trim-err", trimErr)
 		// Check if pelData.Lower has been past its TTL and if it is then ack it to remove from PEL and delete it, once
 		// its taken out from PEL the producer that sent this request will handle the corresponding promise accordingly (as its past TTL)
 		allowedOldestID := fmt.Sprintf("%d-0", time.Now().Add(-p.cfg.RequestTimeout).UnixMilli())
 		if cmpMsgId(pelData.Lower, allowedOldestID) == -1 {
 			if err := p.client.XClaim(ctx, &redis.XClaimArgs{
-				Stream:   p.redisStream,
-				Group:    p.redisGroup,
+				Stream:   stream,
+				Group:    stream,
 				Consumer: p.id,
 				MinIdle:  0,
 				Messages: []string{pelData.Lower},
@@ -197,11 +306,11 @@ func (p *Producer[Request, Response]) clearMessages(ctx context.Context) time.Du
 				log.Error("error claiming PEL's lower message thats past its TTL", "msgID", pelData.Lower, "err", err)
 				return 5 * p.cfg.CheckResultInterval
 			}
-			if _, err := p.client.XAck(ctx, p.redisStream, p.redisGroup, pelData.Lower).Result(); err != nil {
+			if _, err := p.client.XAck(ctx, stream, stream, pelData.Lower).Result(); err != nil {
 				log.Error("error acking PEL's lower message thats past its TTL", "msgID", pelData.Lower, "err", err)
 				return 5 * p.cfg.CheckResultInterval
 			}
-			if _, err := p.client.XDel(ctx, p.redisStream, pelData.Lower).Result(); err != nil {
+			if _, err := p.client.XDel(ctx, stream, pelData.Lower).Result(); err != nil {
 				log.Error("error deleting PEL's lower message thats past its TTL", "msgID", pelData.Lower, "err", err)
 				return 5 * p.cfg.CheckResultInterval
 			}
@@ -215,37 +324,108 @@ func (p *Producer[Request, Response]) Start(ctx context.Context) {
 	p.StopWaiter.Start(ctx, p)
 }
 
+// StopAndWait stops the producer and fails the requests still queued, which won't be sent anymore.
+func (p *Producer[Request, Response]) StopAndWait() {
+	p.StopWaiter.StopAndWait()
+	p.promisesLock.Lock()
+	defer p.promisesLock.Unlock()
+	for priority, queued := range p.queued {
+		for _, request := range queued {
+			request.promise.ProduceError(ErrProducerStopped)
+		}
+		p.queued[priority] = nil
+	}
+}
+
 func (p *Producer[Request, Response]) promisesLen() int {
 	p.promisesLock.Lock()
 	defer p.promisesLock.Unlock()
 	return len(p.promises)
 }
 
-func (p *Producer[Request, Response]) produce(ctx context.Context, value Request) (*containers.Promise[Response], error) {
+// produce sends the request to the stream of its priority, or queues it if the producer has too many
+// requests in flight or requests of at least the same priority are already queued.
+func (p *Producer[Request, Response]) produce(ctx context.Context, value Request, priority uint8) (*containers.Promise[Response], error) {
 	val, err := json.Marshal(value)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling value: %w", err)
 	}
+	if int(priority) >= len(p.streams) {
+		// #nosec G115
+		priority = uint8(len(p.streams) - 1)
+	}
+	promise := containers.NewPromise[Response](nil)
 	// catching the promiseLock before we sendXadd makes sure promise ids will be always ascending
 	p.promisesLock.Lock()
 	defer p.promisesLock.Unlock()
+	if p.cfg.MaxInFlight > 0 {
+		if p.Stopped() {
+			return nil, ErrProducerStopped
+		}
+		p.queued[priority] = append(p.queued[priority], queuedRequest[Response]{value: val, promise: &promise, queuedAt: time.Now()})
+		failed, err := p.sendQueued(ctx)
+		if err != nil && failed == &promise {
+			// The caller gets the error instead of the promise, so the request mustn't be sent later
+			p.queued[priority] = slices.DeleteFunc(p.queued[priority], func(request queuedRequest[Response]) bool {
+				return request.promise == &promise
+			})
+			return nil, err
+		}
+		if err != nil {
+			// Another queued request failed, this one stays queued and is retried like it
+			log.Warn("error sending queued requests", "err", err)
+		}
+		return &promise, nil
+	}
+	if err := p.send(ctx, priority, val, &promise); err != nil {
+		return nil, err
+	}
+	return &promise, nil
+}
+
+// send adds the request to the stream. Must be called with promisesLock held.
+func (p *Producer[Request, Response]) send(ctx context.Context, priority uint8, value []byte, promise *containers.Promise[Response]) error {
+	stream := p.streams[priority]
 	msgId, err := p.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: p.redisStream,
-		Values: map[string]any{messageKey: val},
+		Stream: stream,
+		Values: map[string]any{messageKey: value},
 	}).Result()
 	if err != nil {
-		return nil, fmt.Errorf("adding values to redis: %w", err)
+		return fmt.Errorf("adding values to redis: %w", err)
 	}
-	promise := containers.NewPromise[Response](nil)
-	p.promises[msgId] = &promise
-	return &promise, nil
+	p.promises[messageRef{stream: stream, id: msgId}] = promise
+	return nil
+}
+
+// sendQueued sends queued requests, highest priority first, while there's room. It stops at the first
+// request that fails to send, which stays queued, and returns its promise with the error. Must be called
+// with promisesLock held.
+func (p *Producer[Request, Response]) sendQueued(ctx context.Context) (*containers.Promise[Response], error) {
+	for priority := len(p.queued) - 1; priority >= 0; priority-- {
+		for len(p.queued[priority]) > 0 && len(p.promises) < p.cfg.MaxInFlight {
+			request := p.queued[priority][0]
+			// #nosec G115
+			if err := p.send(ctx, uint8(priority), request.value, request.promise); err != nil {
+				return request.promise, err
+			}
+			p.queued[priority] = p.queued[priority][1:]
+		}
+	}
+	return nil, nil
 }
 
 func (p *Producer[Request, Response]) Produce(ctx context.Context, value Request) (*containers.Promise[Response], error) {
-	log.Debug("Redis stream producing", "value", value)
+	return p.ProduceWithPriority(ctx, value, 0)
+}
+
+// ProduceWithPriority sends a request that consumers will pick up ahead of any requests of lower priority.
+// Priorities above the configured levels are treated as the highest level.
+func (p *Producer[Request, Response]) ProduceWithPriority(ctx context.Context, value Request, priority uint8) (*containers.Promise[Response], error) {
+	log.Debug("Redis stream producing", "value", value, "priority", priority)
 	p.once.Do(func() {
 		p.StopWaiter.CallIteratively(p.checkResponses)
 		p.StopWaiter.CallIteratively(p.clearMessages)
+		p.StopWaiter.CallIteratively(p.checkStreams)
 	})
-	return p.produce(ctx, value)
+	return p.produce(ctx, value, priority)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -182,7 +183,7 @@ func consume(ctx context.Context, t *testing.T, consumers []*Consumer[testReques
 					gotMessages[idx][res.ID] = res.Value.Request
 					if !res.Value.IsInvalid {
 						resp := fmt.Sprintf("result for: %v", res.ID)
-						if err := c.SetResult(ctx, res.ID, testResponse{Response: resp}); err != nil {
+						if err := c.SetResult(ctx, res, testResponse{Response: resp}); err != nil {
 							t.Errorf("Error setting a result: %v", err)
 						}
 						wantResponses[idx] = append(wantResponses[idx], resp)
@@ -395,3 +396,289 @@ func mergeValues(messages []map[string]string, withInvalidEntries bool) ([]strin
 	sort.Strings(ret)
 	return ret, nil
 }
+
+func newPriorityProducerConsumer(ctx context.Context, t *testing.T, prodCfg *ProducerConfig, consCfg *ConsumerConfig) (redis.UniversalClient, string, *Producer[testRequest, testResponse], *Consumer[testRequest, testResponse]) {
+	t.Helper()
+	redisClient, err := redisutil.RedisClientFromURL(redisutil.CreateTestRedis(ctx, t))
+	if err != nil {
+		t.Fatalf("RedisClientFromURL() unexpected error: %v", err)
+	}
+	streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+	producer, err := NewProducer[testRequest, testResponse](redisClient, streamName, prodCfg)
+	if err != nil {
+		t.Fatalf("Error creating new producer: %v", err)
+	}
+	consumer, err := NewConsumer[testRequest, testResponse](redisClient, streamName, consCfg)
+	if err != nil {
+		t.Fatalf("Error creating new consumer: %v", err)
+	}
+	for _, stream := range PriorityStreams(streamName, prodCfg.PriorityLevels) {
+		createRedisGroup(ctx, t, stream, redisClient)
+	}
+	t.Cleanup(func() {
+		for _, stream := range PriorityStreams(streamName, prodCfg.PriorityLevels) {
+			destroyRedisGroup(context.Background(), t, stream, redisClient)
+		}
+	})
+	producer.Start(ctx)
+	consumer.Start(ctx)
+	return redisClient, streamName, producer, consumer
+}
+
+// consumeNext consumes until a message is read, as messages are only sent to the stream once the
+// producer has room for them.
+func consumeNext(ctx context.Context, t *testing.T, consumer *Consumer[testRequest, testResponse]) *Message[testRequest] {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		msg, err := consumer.Consume(ctx)
+		if err != nil {
+			t.Fatalf("Consume() unexpected error: %v", err)
+		}
+		if msg != nil {
+			return msg
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Didn't consume any message")
+	return nil
+}
+
+func TestRedisProducePriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	prodCfg.PriorityLevels = 3
+	consCfg.PriorityLevels = 3
+	_, _, producer, consumer := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	var promises []*containers.Promise[testResponse]
+	for _, req := range []struct {
+		request  string
+		priority uint8
+	}{{"low", 0}, {"high", 2}, {"mid", 1}, {"above highest", 7}} {
+		promise, err := producer.ProduceWithPriority(ctx, testRequest{Request: req.request}, req.priority)
+		if err != nil {
+			t.Fatalf("Error producing message: %v", err)
+		}
+		promises = append(promises, promise)
+	}
+
+	var got []string
+	for range promises {
+		msg := consumeNext(ctx, t, consumer)
+		got = append(got, msg.Value.Request)
+		if err := consumer.SetResult(ctx, msg, testResponse{Response: msg.Value.Request}); err != nil {
+			t.Fatalf("Error setting a result: %v", err)
+		}
+		msg.Ack()
+	}
+	if diff := cmp.Diff([]string{"high", "above highest", "mid", "low"}, got); diff != "" {
+		t.Errorf("Unexpected diff in consume order (-want +got):\n%s\n", diff)
+	}
+	responses, errs := awaitResponses(ctx, promises)
+	if len(errs) != 0 {
+		t.Fatalf("Error awaiting responses: %v", errs)
+	}
+	if diff := cmp.Diff([]string{"low", "high", "mid", "above highest"}, responses); diff != "" {
+		t.Errorf("Unexpected diff in responses (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestRedisProduceMaxInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	prodCfg.PriorityLevels = 2
+	prodCfg.MaxInFlight = 1
+	consCfg.PriorityLevels = 2
+	redisClient, streamName, producer, consumer := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	var promises []*containers.Promise[testResponse]
+	for _, req := range []struct {
+		request  string
+		priority uint8
+	}{{"first", 0}, {"second", 0}, {"urgent", 1}} {
+		promise, err := producer.ProduceWithPriority(ctx, testRequest{Request: req.request}, req.priority)
+		if err != nil {
+			t.Fatalf("Error producing message: %v", err)
+		}
+		promises = append(promises, promise)
+	}
+	// Only the first request is in the streams, the others wait for its result
+	msgs, err := redisClient.XRange(ctx, streamName, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange failed: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Expected one message in the stream, got %d", len(msgs))
+	}
+
+	var got []string
+	for range promises {
+		msg := consumeNext(ctx, t, consumer)
+		got = append(got, msg.Value.Request)
+		if err := consumer.SetResult(ctx, msg, testResponse{Response: msg.Value.Request}); err != nil {
+			t.Fatalf("Error setting a result: %v", err)
+		}
+		msg.Ack()
+	}
+	if diff := cmp.Diff([]string{"first", "urgent", "second"}, got); diff != "" {
+		t.Errorf("Unexpected diff in consume order (-want +got):\n%s\n", diff)
+	}
+	if _, errs := awaitResponses(ctx, promises); len(errs) != 0 {
+		t.Fatalf("Error awaiting responses: %v", errs)
+	}
+}
+
+func TestRedisDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	consCfg.MaxDeliveries = 2
+	consCfg.DeadLetterMaxLen = TestConsumerConfig.DeadLetterMaxLen
+	redisClient, streamName, producer, consumer := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	promise, err := producer.Produce(ctx, testRequest{Request: "crashes consumers"})
+	if err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	// Each delivery is given up on without a result, as if the consumer crashed. Miniredis counts heartbeats
+	// as deliveries too, so the message may be dead-lettered sooner than with redis.
+	deliveries := 0
+	for i := 0; i < 50 && !promise.Ready(); i++ {
+		msg, err := consumer.Consume(ctx)
+		if err != nil {
+			t.Fatalf("Consume() unexpected error: %v", err)
+		}
+		if msg != nil {
+			deliveries++
+			msg.Ack()
+		}
+		time.Sleep(2 * consCfg.IdletimeToAutoclaim)
+	}
+	if deliveries == 0 || deliveries > int(consCfg.MaxDeliveries) {
+		t.Fatalf("Message delivered %d times, max deliveries %d", deliveries, consCfg.MaxDeliveries)
+	}
+	if _, err := promise.Await(ctx); !errors.Is(err, ErrDeadLettered) {
+		t.Fatalf("Expected dead-letter error, got: %v", err)
+	}
+	deadLetters, err := redisClient.XRange(ctx, DeadLetterStreamFor(streamName), "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange failed: %v", err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("Expected one message in the dead-letter stream, got %d", len(deadLetters))
+	}
+	msgs, err := redisClient.XRange(ctx, streamName, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange failed: %v", err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("redis still has %v messages", len(msgs))
+	}
+}
+
+func TestRedisProduceQueuedTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	prodCfg.MaxInFlight = 1
+	prodCfg.RequestTimeout = 100 * time.Millisecond
+	_, _, producer, _ := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	// Nothing consumes the requests, so the queued one times out without ever being sent
+	if _, err := producer.Produce(ctx, testRequest{Request: "sent"}); err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	promise, err := producer.Produce(ctx, testRequest{Request: "queued"})
+	if err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	awaitCtx, awaitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer awaitCancel()
+	if _, err := promise.Await(awaitCtx); err == nil || !strings.Contains(err.Error(), "queued for too long") {
+		t.Fatalf("Expected queued request to time out, got: %v", err)
+	}
+}
+
+func TestRedisProduceQueuedStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	prodCfg.MaxInFlight = 1
+	_, _, producer, _ := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	if _, err := producer.Produce(ctx, testRequest{Request: "sent"}); err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	promise, err := producer.Produce(ctx, testRequest{Request: "queued"})
+	if err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	producer.StopAndWait()
+	if _, err := promise.Await(ctx); !errors.Is(err, ErrProducerStopped) {
+		t.Fatalf("Expected producer stopped error, got: %v", err)
+	}
+	if _, err := producer.Produce(ctx, testRequest{Request: "after stop"}); !errors.Is(err, ErrProducerStopped) {
+		t.Fatalf("Expected producer stopped error, got: %v", err)
+	}
+}
+
+func TestRedisProduceQueuedSendError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prodCfg, consCfg := producerCfg(), consumerCfg()
+	prodCfg.MaxInFlight = 1
+	_, _, producer, _ := newPriorityProducerConsumer(ctx, t, prodCfg, consCfg)
+
+	// A request that fails to send is returned as an error, and not left queued to be sent later
+	canceledCtx, cancelProduce := context.WithCancel(ctx)
+	cancelProduce()
+	if _, err := producer.Produce(canceledCtx, testRequest{Request: "failed"}); err == nil {
+		t.Fatal("Expected error producing message with canceled context")
+	}
+	producer.promisesLock.Lock()
+	queued := len(producer.queued[0])
+	producer.promisesLock.Unlock()
+	if queued != 0 {
+		t.Fatalf("Expected failed request to be dequeued, %v requests queued", queued)
+	}
+	if _, err := producer.Produce(ctx, testRequest{Request: "sent"}); err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	if got := producer.promisesLen(); got != 1 {
+		t.Fatalf("Expected only the later request in flight, got %v", got)
+	}
+}
+
+func TestRedisProduceNoConsumerGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisClient, err := redisutil.RedisClientFromURL(redisutil.CreateTestRedis(ctx, t))
+	if err != nil {
+		t.Fatalf("RedisClientFromURL() unexpected error: %v", err)
+	}
+	prodCfg := producerCfg()
+	prodCfg.PriorityLevels = 2
+	streamName := fmt.Sprintf("stream:%s", uuid.NewString())
+	producer, err := NewProducer[testRequest, testResponse](redisClient, streamName, prodCfg)
+	if err != nil {
+		t.Fatalf("Error creating new producer: %v", err)
+	}
+	// Only the lowest priority has a consumer group, as if consumers read a single level
+	createRedisGroup(ctx, t, streamName, redisClient)
+	t.Cleanup(func() {
+		destroyRedisGroup(context.Background(), t, streamName, redisClient)
+	})
+	producer.Start(ctx)
+
+	promise, err := producer.ProduceWithPriority(ctx, testRequest{Request: "urgent"}, 1)
+	if err != nil {
+		t.Fatalf("Error producing message: %v", err)
+	}
+	awaitCtx, awaitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer awaitCancel()
+	if _, err := promise.Await(awaitCtx); !errors.Is(err, ErrNoConsumerGroup) {
+		t.Fatalf("Expected no consumer group error, got: %v", err)
+	}
+}
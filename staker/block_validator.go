@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"regexp"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -31,9 +32,10 @@ import (
 	"github.com/offchainlabs/nitro/util/rpcclient"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
+	validatorclient "github.com/offchainlabs/nitro/validator/client"
 	"github.com/offchainlabs/nitro/validator/client/redis"
+	"github.com/offchainlabs/nitro/validator/client/resultcache"
 	"github.com/offchainlabs/nitro/validator/inputs"
-	"github.com/offchainlabs/nitro/validator/server_api"
 )
 
 var (
@@ -89,6 +91,7 @@ type BlockValidator struct {
 	progressValidationsChan chan struct{}
 
 	chosenValidator map[common.Hash]validator.ValidationSpawner
+	validationPools []*validatorclient.ValidationPool
 
 	// wasmModuleRoot
 	moduleMutex           sync.Mutex
@@ -100,6 +103,8 @@ type BlockValidator struct {
 
 	// For troubleshooting failed validations
 	validationInputsWriter *inputs.Writer
+	divergenceAlerters     []DivergenceAlerter
+	lastDivergencePos      *arbutil.MessageIndex
 
 	fatalErr chan<- error
 
@@ -122,6 +127,9 @@ type BlockValidatorConfig struct {
 	Dangerous                   BlockValidatorDangerousConfig `koanf:"dangerous"`
 	MemoryFreeLimit             string                        `koanf:"memory-free-limit" reload:"hot"`
 	ValidationServerConfigsList string                        `koanf:"validation-server-configs-list"`
+	ResultCache                 resultcache.Config            `koanf:"result-cache" reload:"hot"`
+	LaggingPriorityThreshold    uint64                        `koanf:"lagging-priority-threshold" reload:"hot"`
+	Divergence                  DivergenceConfig              `koanf:"divergence" reload:"hot"`
 	// The directory to which the BlockValidator will write the
 	// block_inputs_<id>.json files when WriteToFile() is called.
 	BlockInputsFilePath string `koanf:"block-inputs-file-path"`
@@ -142,6 +150,9 @@ func (c *BlockValidatorConfig) Validate() error {
 	if err := c.RedisValidationClientConfig.Validate(); err != nil {
 		return fmt.Errorf("failed to validate redis validation client config: %w", err)
 	}
+	if err := c.ResultCache.Validate(); err != nil {
+		return fmt.Errorf("failed to validate block-validator result-cache config: %w", err)
+	}
 	streamsEnabled := c.RedisValidationClientConfig.Enabled()
 	if len(c.ValidationServerConfigs) == 0 {
 		c.ValidationServerConfigs = []rpcclient.ClientConfig{c.ValidationServer}
@@ -182,6 +193,8 @@ func BlockValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	rpcclient.RPCClientAddOptions(prefix+".validation-server", f, &DefaultBlockValidatorConfig.ValidationServer)
 	redis.ValidationClientConfigAddOptions(prefix+".redis-validation-client-config", f)
 	f.String(prefix+".validation-server-configs-list", DefaultBlockValidatorConfig.ValidationServerConfigsList, "array of execution rpc configs given as a json string. time duration should be supplied in number indicating nanoseconds")
+	resultcache.ConfigAddOptions(prefix+".result-cache", f)
+	f.Uint64(prefix+".lagging-priority-threshold", DefaultBlockValidatorConfig.LaggingPriorityThreshold, "number of messages validation can be behind the chain before validations are sent with a higher priority to shared validation workers (0 = never)")
 	f.Duration(prefix+".validation-poll", DefaultBlockValidatorConfig.ValidationPoll, "poll time to check validations")
 	f.Uint64(prefix+".forward-blocks", DefaultBlockValidatorConfig.ForwardBlocks, "prepare entries for up to that many blocks ahead of validation (stores batch-copy per block)")
 	f.Uint64(prefix+".prerecorded-blocks", DefaultBlockValidatorConfig.PrerecordedBlocks, "record that many blocks ahead of validation (larger footprint)")
@@ -193,6 +206,7 @@ func BlockValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	BlockValidatorDangerousConfigAddOptions(prefix+".dangerous", f)
 	f.String(prefix+".memory-free-limit", DefaultBlockValidatorConfig.MemoryFreeLimit, "minimum free-memory limit after reaching which the blockvalidator pauses validation. Enabled by default as 1GB, to disable provide empty string")
 	f.String(prefix+".block-inputs-file-path", DefaultBlockValidatorConfig.BlockInputsFilePath, "directory to write block validation inputs files")
+	DivergenceConfigAddOptions(prefix+".divergence", f)
 }
 
 func BlockValidatorDangerousConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -204,6 +218,9 @@ var DefaultBlockValidatorConfig = BlockValidatorConfig{
 	ValidationServerConfigsList: "default",
 	ValidationServer:            rpcclient.DefaultClientConfig,
 	RedisValidationClientConfig: redis.DefaultValidationClientConfig,
+	ResultCache:                 resultcache.DefaultConfig,
+	LaggingPriorityThreshold:    1000,
+	Divergence:                  DefaultDivergenceConfig,
 	ValidationPoll:              time.Second,
 	ForwardBlocks:               128,
 	PrerecordedBlocks:           uint64(2 * runtime.NumCPU()),
@@ -222,6 +239,9 @@ var TestBlockValidatorConfig = BlockValidatorConfig{
 	ValidationServer:            rpcclient.TestClientConfig,
 	ValidationServerConfigs:     []rpcclient.ClientConfig{rpcclient.TestClientConfig},
 	RedisValidationClientConfig: redis.TestValidationClientConfig,
+	ResultCache:                 resultcache.DefaultConfig,
+	LaggingPriorityThreshold:    1000,
+	Divergence:                  DefaultDivergenceConfig,
 	ValidationPoll:              100 * time.Millisecond,
 	ForwardBlocks:               128,
 	BatchCacheLimit:             20,
@@ -527,17 +547,16 @@ func (v *BlockValidator) sendRecord(s *validationStatus) error {
 	return nil
 }
 
-//nolint:gosec
-func (v *BlockValidator) writeToFile(validationEntry *validationEntry) error {
-	input, err := validationEntry.ToInput([]ethdb.WasmTarget{rawdb.TargetWavm})
-	if err != nil {
-		return err
-	}
-	inputJson := server_api.ValidationInputToJson(input)
-	if err := v.validationInputsWriter.Write(inputJson); err != nil {
-		return err
+// dumpedStylusArchs are the targets of the user wasms in dumped validation inputs, so they can be
+// replayed by both the arbitrator, which runs wavm, and the JIT machine, which runs the local target.
+func dumpedStylusArchs(extra ...ethdb.WasmTarget) []ethdb.WasmTarget {
+	archs := []ethdb.WasmTarget{rawdb.TargetWavm, rawdb.LocalTarget()}
+	for _, arch := range extra {
+		if !slices.Contains(archs, arch) {
+			archs = append(archs, arch)
+		}
 	}
-	return nil
+	return archs
 }
 
 func (v *BlockValidator) SetCurrentWasmModuleRoot(hash common.Hash) error {
@@ -841,10 +860,7 @@ validationsLoop:
 				runEnd, err := run.Current()
 				if err == nil && runEnd != validationStatus.Entry.End {
 					err = fmt.Errorf("validation failed: expected %v got %v", validationStatus.Entry.End, runEnd)
-					writeErr := v.writeToFile(validationStatus.Entry)
-					if writeErr != nil {
-						log.Warn("failed to write debug results file", "err", writeErr)
-					}
+					v.reportDivergence(validationStatus.Entry, run.WasmModuleRoot(), runEnd)
 				}
 				if err != nil {
 					validatorFailedValidationsCounter.Inc(1)
@@ -891,6 +907,7 @@ validationsLoop:
 			}
 			validatorProfileWaitToLaunchHist.Update(validationStatus.profileStep())
 			validatorPendingValidationsGauge.Inc(1)
+			priority := v.validationPriority()
 			var runs []validator.ValidationRun
 			for _, moduleRoot := range wasmRoots {
 				spawner := v.chosenValidator[moduleRoot]
@@ -902,8 +919,8 @@ validationsLoop:
 				if ctx.Err() != nil {
 					return nil, ctx.Err()
 				}
-				run := spawner.Launch(input, moduleRoot)
-				log.Trace("advanceValidations: launched", "pos", validationStatus.Entry.Pos, "moduleRoot", moduleRoot)
+				run := validator.LaunchWithPriority(spawner, input, moduleRoot, priority)
+				log.Trace("advanceValidations: launched", "pos", validationStatus.Entry.Pos, "moduleRoot", moduleRoot, "priority", priority)
 				runs = append(runs, run)
 			}
 			validatorProfileLaunchingHist.Update(validationStatus.profileStep())
@@ -934,6 +951,24 @@ validationsLoop:
 	}
 }
 
+// validationPriority is the priority to send validations with, which is raised while validation is far
+// behind the chain so that validation workers shared with other nodes help it catch up.
+func (v *BlockValidator) validationPriority() validator.Priority {
+	threshold := v.config().LaggingPriorityThreshold
+	if threshold == 0 {
+		return validator.PriorityRoutine
+	}
+	processed, err := v.streamer.GetProcessedMessageCount()
+	if err != nil {
+		log.Warn("error getting processed message count", "err", err)
+		return validator.PriorityRoutine
+	}
+	if processed > v.validated()+arbutil.MessageIndex(threshold) {
+		return validator.PriorityLagging
+	}
+	return validator.PriorityRoutine
+}
+
 func (v *BlockValidator) iterativeValidationProgress(ctx context.Context, ignored struct{}) time.Duration {
 	reorg, err := v.advanceValidations(ctx)
 	if err != nil {
@@ -1196,17 +1231,27 @@ func (v *BlockValidator) Initialize(ctx context.Context) error {
 			v.chosenValidator[root] = v.redisValidator
 			log.Info("validator chosen", "WasmModuleRoot", root, "chosen", "redis")
 		} else {
+			// validations are spread over the spawners supporting the root with the same stylus archs as the first one
+			var supporting []validator.ValidationSpawner
 			for _, spawner := range v.execSpawners {
-				if validator.SpawnerSupportsModule(spawner, root) {
-					v.chosenValidator[root] = spawner
-					log.Info("validator chosen", "WasmModuleRoot", root, "chosen", spawner.Name())
-					break
+				if !validator.SpawnerSupportsModule(spawner, root) {
+					continue
+				}
+				if len(supporting) > 0 && !slices.Equal(spawner.StylusArchs(), supporting[0].StylusArchs()) {
+					continue
 				}
+				supporting = append(supporting, spawner)
 			}
-			if v.chosenValidator[root] == nil {
+			if len(supporting) == 0 {
 				return fmt.Errorf("cannot validate WasmModuleRoot %v", root)
 			}
+			// the pool is used even for a single spawner, to retry validations refused by an overloaded server
+			pool := validatorclient.NewValidationPool(supporting)
+			v.validationPools = append(v.validationPools, pool)
+			v.chosenValidator[root] = pool
+			log.Info("validator chosen", "WasmModuleRoot", root, "chosen", v.chosenValidator[root].Name())
 		}
+		v.chosenValidator[root] = v.withResultCache(v.chosenValidator[root])
 	}
 	return nil
 }
@@ -1362,6 +1407,11 @@ func (v *BlockValidator) LaunchWorkthreadsWhenCaughtUp(ctx context.Context) {
 
 func (v *BlockValidator) Start(ctxIn context.Context) error {
 	v.StopWaiter.Start(ctxIn, v)
+	for _, pool := range v.validationPools {
+		if err := pool.Start(ctxIn); err != nil {
+			return err
+		}
+	}
 	v.LaunchThread(v.LaunchWorkthreadsWhenCaughtUp)
 	v.CallIteratively(v.iterativeValidationPrint)
 	return nil
@@ -1369,6 +1419,9 @@ func (v *BlockValidator) Start(ctxIn context.Context) error {
 
 func (v *BlockValidator) StopAndWait() {
 	v.StopWaiter.StopAndWait()
+	for _, pool := range v.validationPools {
+		pool.Stop()
+	}
 }
 
 // WaitForPos can only be used from One thread
@@ -0,0 +1,260 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+)
+
+var validatorDivergencesCounter = metrics.NewRegisteredCounter("arb/validator/validations/divergences", nil)
+
+type DivergenceConfig struct {
+	Enable       bool          `koanf:"enable"`
+	BundleDir    string        `koanf:"bundle-dir"`
+	AlertWebhook string        `koanf:"alert-webhook" reload:"hot"`
+	AlertExec    string        `koanf:"alert-exec" reload:"hot"`
+	AlertTimeout time.Duration `koanf:"alert-timeout" reload:"hot"`
+}
+
+var DefaultDivergenceConfig = DivergenceConfig{
+	Enable:       true,
+	BundleDir:    "",
+	AlertWebhook: "",
+	AlertExec:    "",
+	AlertTimeout: time.Second * 10,
+}
+
+func DivergenceConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultDivergenceConfig.Enable, "write a forensic bundle when a validation result doesn't match execution")
+	f.String(prefix+".bundle-dir", DefaultDivergenceConfig.BundleDir, "directory to write forensic bundles to (default: divergence-bundles in the node's instance directory)")
+	f.String(prefix+".alert-webhook", DefaultDivergenceConfig.AlertWebhook, "url to post a json alert to when a validation result doesn't match execution")
+	f.String(prefix+".alert-exec", DefaultDivergenceConfig.AlertExec, "executable to run when a validation result doesn't match execution, with the json alert on stdin and the bundle path as argument")
+	f.Duration(prefix+".alert-timeout", DefaultDivergenceConfig.AlertTimeout, "timeout of each divergence alert")
+}
+
+// DivergenceAlert describes a validation result that doesn't match the result of execution.
+type DivergenceAlert struct {
+	Pos         arbutil.MessageIndex    `json:"pos"`
+	ModuleRoot  common.Hash             `json:"moduleRoot"`
+	Start       validator.GoGlobalState `json:"start"`
+	Expected    validator.GoGlobalState `json:"expected"`
+	Validated   validator.GoGlobalState `json:"validated"`
+	NodeVersion string                  `json:"nodeVersion"`
+	Time        time.Time               `json:"time"`
+	// Empty if the bundle couldn't be written
+	BundlePath string `json:"bundlePath,omitempty"`
+}
+
+// DivergenceAlerter is notified of every divergence, after its bundle was written.
+type DivergenceAlerter interface {
+	Alert(ctx context.Context, alert *DivergenceAlert) error
+}
+
+type webhookAlerter struct {
+	url string
+}
+
+func (a *webhookAlerter) Alert(ctx context.Context, alert *DivergenceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+type execAlerter struct {
+	path string
+}
+
+func (a *execAlerter) Alert(ctx context.Context, alert *DivergenceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, a.path, alert.BundlePath)
+	cmd.Stdin = bytes.NewReader(body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}
+
+// divergenceBundle is the bundle.json file of a forensic bundle. The bundle's input.json holds the
+// validation input, including the recorded preimages, and can be replayed with validation-replay.
+type divergenceBundle struct {
+	DivergenceAlert
+	Message       *arbostypes.MessageWithMetadata `json:"message"`
+	MessageResult *execution.MessageResult        `json:"messageResult"`
+	// The headers of the start, expected and validated blocks that are in the preimages
+	Headers []*types.Header `json:"headers"`
+}
+
+// AddDivergenceAlerter adds an alerter notified of divergences, on top of the configured ones. Must be
+// called before the validator is started.
+func (v *BlockValidator) AddDivergenceAlerter(alerter DivergenceAlerter) {
+	v.divergenceAlerters = append(v.divergenceAlerters, alerter)
+}
+
+// reportDivergence writes the validation input and a forensic bundle for a validation result that
+// doesn't match execution and fires the alerts, on a background thread. Failed validations are retried,
+// so each position is only reported once.
+func (v *BlockValidator) reportDivergence(entry *validationEntry, moduleRoot common.Hash, validated validator.GoGlobalState) {
+	config := v.config()
+	if v.lastDivergencePos != nil && *v.lastDivergencePos == entry.Pos {
+		return
+	}
+	pos := entry.Pos
+	v.lastDivergencePos = &pos
+	validatorDivergencesCounter.Inc(1)
+	version, _, _ := confighelpers.GetVersion()
+	alert := &DivergenceAlert{
+		Pos:         entry.Pos,
+		ModuleRoot:  moduleRoot,
+		Start:       entry.Start,
+		Expected:    entry.End,
+		Validated:   validated,
+		NodeVersion: version,
+		Time:        time.Now(),
+	}
+	alerters := slices.Clone(v.divergenceAlerters)
+	if config.Divergence.AlertWebhook != "" {
+		alerters = append(alerters, &webhookAlerter{url: config.Divergence.AlertWebhook})
+	}
+	if config.Divergence.AlertExec != "" {
+		alerters = append(alerters, &execAlerter{path: config.Divergence.AlertExec})
+	}
+	v.LaunchThread(func(ctx context.Context) {
+		if config.FailureIsFatal {
+			// the failure stops the node, which waits for the alerts but cancels the thread's context
+			ctx = v.GetParentContext()
+		}
+		input, err := entry.ToInput(v.divergenceStylusArchs(moduleRoot))
+		if err != nil {
+			log.Error("failed to get validation input of divergence", "pos", entry.Pos, "err", err)
+		} else {
+			inputJson := server_api.ValidationInputToJson(input)
+			if err := v.validationInputsWriter.Write(inputJson); err != nil {
+				log.Warn("failed to write debug results file", "err", err)
+			}
+			if config.Divergence.Enable {
+				bundlePath, err := v.writeDivergenceBundle(entry, inputJson, alert)
+				if err != nil {
+					log.Error("failed to write divergence bundle", "pos", entry.Pos, "err", err)
+				} else {
+					alert.BundlePath = bundlePath
+					log.Warn("wrote divergence bundle", "pos", entry.Pos, "path", bundlePath)
+				}
+			}
+		}
+		sendDivergenceAlerts(ctx, alerters, alert, config.Divergence.AlertTimeout)
+	})
+}
+
+func sendDivergenceAlerts(ctx context.Context, alerters []DivergenceAlerter, alert *DivergenceAlert, timeout time.Duration) {
+	for _, alerter := range alerters {
+		alertCtx, cancel := context.WithTimeout(ctx, timeout)
+		if err := alerter.Alert(alertCtx, alert); err != nil {
+			log.Error("failed to send divergence alert", "pos", alert.Pos, "err", err)
+		}
+		cancel()
+	}
+}
+
+// divergenceStylusArchs returns the archs the user wasms of a divergence are dumped for: the ones
+// validation-replay runs, and the ones of the validator that diverged.
+func (v *BlockValidator) divergenceStylusArchs(moduleRoot common.Hash) []ethdb.WasmTarget {
+	var spawnerArchs []ethdb.WasmTarget
+	if spawner := v.chosenValidator[moduleRoot]; spawner != nil {
+		spawnerArchs = spawner.StylusArchs()
+	}
+	return dumpedStylusArchs(spawnerArchs...)
+}
+
+func (v *BlockValidator) writeDivergenceBundle(entry *validationEntry, inputJson *server_api.InputJSON, alert *DivergenceAlert) (string, error) {
+	bundle := divergenceBundle{DivergenceAlert: *alert}
+	var err error
+	bundle.Message, err = v.streamer.GetMessage(entry.Pos)
+	if err != nil {
+		log.Warn("failed to get message for divergence bundle", "pos", entry.Pos, "err", err)
+	}
+	bundle.MessageResult, err = v.streamer.ResultAtCount(entry.Pos + 1)
+	if err != nil {
+		log.Warn("failed to get message result for divergence bundle", "pos", entry.Pos, "err", err)
+	}
+	for _, blockHash := range []common.Hash{alert.Start.BlockHash, alert.Expected.BlockHash, alert.Validated.BlockHash} {
+		encoded, ok := entry.Preimages[arbutil.Keccak256PreimageType][blockHash]
+		if !ok {
+			continue
+		}
+		var header types.Header
+		if err := rlp.DecodeBytes(encoded, &header); err != nil {
+			log.Warn("failed to decode header for divergence bundle", "hash", blockHash, "err", err)
+			continue
+		}
+		bundle.Headers = append(bundle.Headers, &header)
+	}
+
+	dir := v.config().Divergence.BundleDir
+	if dir == "" {
+		dir = filepath.Join(v.stack.InstanceDir(), "divergence-bundles")
+	}
+	dir = filepath.Join(dir, fmt.Sprintf("divergence_%d_%s", entry.Pos, alert.Time.Format("20060102_150405")))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	inputContents, err := inputJson.Marshal()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "input.json"), inputContents, 0600); err != nil {
+		return "", err
+	}
+	bundleJson, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bundle.json"), bundleJson, 0600); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
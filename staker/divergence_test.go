@@ -0,0 +1,197 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/inputs"
+	"github.com/offchainlabs/nitro/validator/server_api"
+)
+
+type divergenceTestStreamer struct {
+	message *arbostypes.MessageWithMetadata
+	result  *execution.MessageResult
+}
+
+func (s *divergenceTestStreamer) SetBlockValidator(*BlockValidator) {}
+func (s *divergenceTestStreamer) GetProcessedMessageCount() (arbutil.MessageIndex, error) {
+	return 0, nil
+}
+func (s *divergenceTestStreamer) GetMessage(arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	return s.message, nil
+}
+func (s *divergenceTestStreamer) ResultAtCount(arbutil.MessageIndex) (*execution.MessageResult, error) {
+	return s.result, nil
+}
+func (s *divergenceTestStreamer) PauseReorgs()                     {}
+func (s *divergenceTestStreamer) ResumeReorgs()                    {}
+func (s *divergenceTestStreamer) ChainConfig() *params.ChainConfig { return nil }
+
+type recordingAlerter struct {
+	alerts chan *DivergenceAlert
+}
+
+func (a *recordingAlerter) Alert(_ context.Context, alert *DivergenceAlert) error {
+	a.alerts <- alert
+	return nil
+}
+
+func newDivergenceTestValidator(t *testing.T, config *BlockValidatorConfig, streamer *divergenceTestStreamer, inputsDir string) *BlockValidator {
+	t.Helper()
+	inputsWriter, err := inputs.NewWriter(inputs.WithBaseDir(inputsDir), inputs.WithTimestampDirEnabled(false))
+	require.NoError(t, err)
+	v := &BlockValidator{
+		StatelessBlockValidator: &StatelessBlockValidator{streamer: streamer},
+		config:                  func() *BlockValidatorConfig { return config },
+		validationInputsWriter:  inputsWriter,
+	}
+	v.StopWaiter.Start(context.Background(), v)
+	t.Cleanup(v.StopWaiter.StopAndWait)
+	return v
+}
+
+func newDivergenceTestEntry(t *testing.T, pos arbutil.MessageIndex) (*validationEntry, *types.Header) {
+	t.Helper()
+	header := &types.Header{Number: new(big.Int).SetUint64(uint64(pos)), Difficulty: big.NewInt(1)}
+	encoded, err := rlp.EncodeToBytes(header)
+	require.NoError(t, err)
+	return &validationEntry{
+		Stage:       Ready,
+		Pos:         pos,
+		Start:       validator.GoGlobalState{BlockHash: header.Hash(), Batch: 1},
+		End:         validator.GoGlobalState{BlockHash: common.HexToHash("0x01"), Batch: 1, PosInBatch: 1},
+		ChainConfig: chaininfo.ArbitrumDevTestChainConfig(),
+		Preimages: map[arbutil.PreimageType]map[common.Hash][]byte{
+			arbutil.Keccak256PreimageType: {header.Hash(): encoded},
+		},
+	}, header
+}
+
+func TestDivergenceBundle(t *testing.T) {
+	config := TestBlockValidatorConfig
+	config.FailureIsFatal = false
+	config.Divergence.BundleDir = t.TempDir()
+	streamer := &divergenceTestStreamer{
+		message: &arbostypes.MessageWithMetadata{DelayedMessagesRead: 3},
+		result:  &execution.MessageResult{BlockHash: common.HexToHash("0x01")},
+	}
+	inputsDir := t.TempDir()
+	v := newDivergenceTestValidator(t, &config, streamer, inputsDir)
+	alerter := &recordingAlerter{alerts: make(chan *DivergenceAlert, 1)}
+	v.AddDivergenceAlerter(alerter)
+
+	entry, header := newDivergenceTestEntry(t, 7)
+	validated := validator.GoGlobalState{BlockHash: common.HexToHash("0x02"), Batch: 1, PosInBatch: 1}
+	// the bundle is written in the background, and the alert sent once it's done
+	v.reportDivergence(entry, common.HexToHash("0xaa"), validated)
+
+	var alert *DivergenceAlert
+	select {
+	case alert = <-alerter.alerts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("divergence alert not sent")
+	}
+	require.Equal(t, entry.Pos, alert.Pos)
+	require.Equal(t, entry.End, alert.Expected)
+	require.Equal(t, validated, alert.Validated)
+	require.Equal(t, config.Divergence.BundleDir, filepath.Dir(alert.BundlePath))
+
+	bundleJson, err := os.ReadFile(filepath.Join(alert.BundlePath, "bundle.json"))
+	require.NoError(t, err)
+	var bundle divergenceBundle
+	require.NoError(t, json.Unmarshal(bundleJson, &bundle))
+	require.Equal(t, entry.Pos, bundle.Pos)
+	require.Equal(t, common.HexToHash("0xaa"), bundle.ModuleRoot)
+	require.Equal(t, entry.Start, bundle.Start)
+	require.Equal(t, validated, bundle.Validated)
+	require.Equal(t, streamer.message.DelayedMessagesRead, bundle.Message.DelayedMessagesRead)
+	require.Equal(t, *streamer.result, *bundle.MessageResult)
+	// only the start block's header is in the preimages
+	require.Len(t, bundle.Headers, 1)
+	require.Equal(t, header.Hash(), bundle.Headers[0].Hash())
+
+	inputJson, err := os.ReadFile(filepath.Join(alert.BundlePath, "input.json"))
+	require.NoError(t, err)
+	var input server_api.InputJSON
+	require.NoError(t, json.Unmarshal(inputJson, &input))
+	require.Equal(t, uint64(entry.Pos), input.Id)
+	require.Equal(t, entry.Start, input.StartState)
+	// the user wasms are dumped for both the arbitrator and JIT machines to replay
+	require.Contains(t, input.UserWasms, rawdb.TargetWavm)
+	require.Contains(t, input.UserWasms, rawdb.LocalTarget())
+
+	// the validation input is still written to the block inputs directory
+	blockInputsJson, err := os.ReadFile(filepath.Join(inputsDir, "block_inputs_7.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, string(inputJson), string(blockInputsJson))
+
+	// retries of the failed validation aren't reported again
+	v.reportDivergence(entry, common.HexToHash("0xaa"), validated)
+	select {
+	case <-alerter.alerts:
+		t.Fatal("divergence reported twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDivergenceAlerts(t *testing.T) {
+	webhookAlerts := make(chan DivergenceAlert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert DivergenceAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		webhookAlerts <- alert
+	}))
+	defer server.Close()
+
+	config := TestBlockValidatorConfig
+	config.FailureIsFatal = true
+	config.Divergence.BundleDir = t.TempDir()
+	config.Divergence.AlertWebhook = server.URL
+	v := newDivergenceTestValidator(t, &config, &divergenceTestStreamer{}, t.TempDir())
+	alerter := &recordingAlerter{alerts: make(chan *DivergenceAlert, 1)}
+	v.AddDivergenceAlerter(alerter)
+
+	entry, _ := newDivergenceTestEntry(t, 3)
+	v.reportDivergence(entry, common.Hash{}, validator.GoGlobalState{})
+	// with fatal failures, the alerts are still sent while the validator stops
+	v.StopAndWait()
+	select {
+	case alert := <-alerter.alerts:
+		require.Equal(t, entry.Pos, alert.Pos)
+	default:
+		t.Fatal("divergence alert not sent before stopping")
+	}
+	select {
+	case alert := <-webhookAlerts:
+		require.Equal(t, entry.Pos, alert.Pos)
+		require.NotEmpty(t, alert.BundlePath)
+	default:
+		t.Fatal("divergence webhook not called before stopping")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
@@ -24,6 +25,7 @@ import (
 	"github.com/offchainlabs/nitro/validator"
 	validatorclient "github.com/offchainlabs/nitro/validator/client"
 	"github.com/offchainlabs/nitro/validator/client/redis"
+	"github.com/offchainlabs/nitro/validator/client/resultcache"
 	"github.com/offchainlabs/nitro/validator/server_api"
 )
 
@@ -32,6 +34,7 @@ type StatelessBlockValidator struct {
 
 	execSpawners   []validator.ExecutionSpawner
 	redisValidator *redis.ValidationClient
+	resultCache    *resultcache.ResultCache
 
 	recorder execution.ExecutionRecorder
 
@@ -40,6 +43,7 @@ type StatelessBlockValidator struct {
 	streamer     TransactionStreamerInterface
 	db           ethdb.Database
 	dapReaders   []daprovider.Reader
+	dictReader   arbstate.DictionaryReader
 	stack        *node.Node
 }
 
@@ -232,6 +236,7 @@ func NewStatelessBlockValidator(
 	recorder execution.ExecutionRecorder,
 	arbdb ethdb.Database,
 	dapReaders []daprovider.Reader,
+	dictReader arbstate.DictionaryReader,
 	config func() *BlockValidatorConfig,
 	stack *node.Node,
 ) (*StatelessBlockValidator, error) {
@@ -245,6 +250,14 @@ func NewStatelessBlockValidator(
 			return nil, fmt.Errorf("creating new redis validation client: %w", err)
 		}
 	}
+	var resultCache *resultcache.ResultCache
+	if config().ResultCache.Enable {
+		var err error
+		resultCache, err = resultcache.NewResultCache(func() *resultcache.Config { return &config().ResultCache })
+		if err != nil {
+			return nil, err
+		}
+	}
 	configs := config().ValidationServerConfigs
 	for i := range configs {
 		i := i
@@ -260,11 +273,13 @@ func NewStatelessBlockValidator(
 		config:         config(),
 		recorder:       recorder,
 		redisValidator: redisValClient,
+		resultCache:    resultCache,
 		inboxReader:    inboxReader,
 		inboxTracker:   inbox,
 		streamer:       streamer,
 		db:             arbdb,
 		dapReaders:     dapReaders,
+		dictReader:     dictReader,
 		execSpawners:   executionSpawners,
 		stack:          stack,
 	}, nil
@@ -316,11 +331,19 @@ func (v *StatelessBlockValidator) readFullBatch(ctx context.Context, batchNum ui
 	}
 	preimages := make(map[arbutil.PreimageType]map[common.Hash][]byte)
 	if len(postedData) > 40 {
+		payload := postedData[40:]
 		foundDA := false
 		for _, dapReader := range v.dapReaders {
-			if dapReader != nil && dapReader.IsValidHeaderByte(postedData[40]) {
+			if dapReader == nil {
+				continue
+			}
+			isValid, err := dapReader.IsValidHeaderByte(ctx, postedData[40])
+			if err != nil {
+				return false, nil, err
+			}
+			if isValid {
 				preimageRecorder := daprovider.RecordPreimagesTo(preimages)
-				_, err := dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, postedData, preimageRecorder, true)
+				payload, err = dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, postedData, preimageRecorder, true)
 				if err != nil {
 					// Matches the way keyset validation was done inside DAS readers i.e logging the error
 					//  But other daproviders might just want to return the error
@@ -339,6 +362,9 @@ func (v *StatelessBlockValidator) readFullBatch(ctx context.Context, batchNum ui
 				log.Error("No DAS Reader configured, but sequencer message found with DAS header")
 			}
 		}
+		if err := v.recordCompressionDictionary(ctx, payload, preimages); err != nil {
+			return false, nil, err
+		}
 	}
 	fullInfo := FullBatchInfo{
 		Number:     batchNum,
@@ -349,6 +375,29 @@ func (v *StatelessBlockValidator) readFullBatch(ctx context.Context, batchNum ui
 	return true, &fullInfo, nil
 }
 
+// recordCompressionDictionary adds the dictionary a batch payload was compressed with to the preimages,
+// as the replay binary reads it by hash.
+func (v *StatelessBlockValidator) recordCompressionDictionary(ctx context.Context, payload []byte, preimages map[arbutil.PreimageType]map[common.Hash][]byte) error {
+	hash, ok := arbstate.DictionaryHashFromPayload(payload)
+	if !ok {
+		return nil
+	}
+	if v.dictReader == nil {
+		return arbstate.ErrNoDictionaryReader
+	}
+	dictionary, err := v.dictReader.GetDictionary(ctx, hash)
+	if errors.Is(err, arbstate.ErrUnknownDictionary) {
+		// batches referencing a dictionary before batch compression was enabled are empty
+		log.Debug("batch references an unknown compression dictionary", "hash", hash)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	daprovider.RecordPreimagesTo(preimages)(hash, dictionary, arbutil.Keccak256PreimageType)
+	return nil
+}
+
 func copyPreimagesInto(dest, source map[arbutil.PreimageType]map[common.Hash][]byte) {
 	for piType, piMap := range source {
 		if dest[piType] == nil {
@@ -485,6 +534,14 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 	return entry, nil
 }
 
+// withResultCache puts the result cache in front of the spawner, if it's enabled.
+func (v *StatelessBlockValidator) withResultCache(spawner validator.ValidationSpawner) validator.ValidationSpawner {
+	if v.resultCache == nil {
+		return spawner
+	}
+	return v.resultCache.Wrap(spawner)
+}
+
 func (v *StatelessBlockValidator) ValidateResult(
 	ctx context.Context, pos arbutil.MessageIndex, useExec bool, moduleRoot common.Hash,
 ) (bool, *validator.GoGlobalState, error) {
@@ -500,7 +557,8 @@ func (v *StatelessBlockValidator) ValidateResult(
 				if err != nil {
 					return false, nil, err
 				}
-				run = v.redisValidator.Launch(input, moduleRoot)
+				// Unlike forward validation, the caller is waiting on this result
+				run = validator.LaunchWithPriority(v.withResultCache(v.redisValidator), input, moduleRoot, validator.PriorityCritical)
 			}
 		}
 	}
@@ -511,7 +569,7 @@ func (v *StatelessBlockValidator) ValidateResult(
 				if err != nil {
 					return false, nil, err
 				}
-				run = spawner.Launch(input, moduleRoot)
+				run = v.withResultCache(spawner).Launch(input, moduleRoot)
 				break
 			}
 		}
@@ -559,6 +617,9 @@ func (v *StatelessBlockValidator) GetLatestWasmModuleRoot(ctx context.Context) (
 }
 
 func (v *StatelessBlockValidator) Start(ctx_in context.Context) error {
+	if v.resultCache != nil {
+		v.resultCache.Start(ctx_in)
+	}
 	if v.redisValidator != nil {
 		if err := v.redisValidator.Start(ctx_in); err != nil {
 			return fmt.Errorf("starting execution spawner: %w", err)
@@ -579,4 +640,7 @@ func (v *StatelessBlockValidator) Stop() {
 	if v.redisValidator != nil {
 		v.redisValidator.Stop()
 	}
+	if v.resultCache != nil {
+		v.resultCache.StopAndWait()
+	}
 }
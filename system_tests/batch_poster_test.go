@@ -6,9 +6,12 @@ package arbtest
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -26,6 +29,7 @@ import (
 	"github.com/offchainlabs/nitro/arbnode/dataposter/externalsignertest"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/upgrade_executorgen"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/redisutil"
 )
 
@@ -467,3 +471,73 @@ func TestBatchPosterDelayBufferDontForceNonDelayedMessages(t *testing.T) {
 	}
 	CheckBatchCount(t, builder, initialBatchCount+1)
 }
+
+func TestBatchPosterDryRun(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reportFile := filepath.Join(t.TempDir(), "dry-run.jsonl")
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, true)
+	builder.nodeConfig.BatchPoster.DryRun.Enable = true
+	builder.nodeConfig.BatchPoster.DryRun.ReportFile = reportFile
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	initialBatchCount := GetBatchCount(t, builder)
+	var reports []arbnode.DryRunBatchReport
+	for i := 0; i < 3; i++ {
+		tx := builder.L2Info.PrepareTx("Owner", "Owner", builder.L2Info.TransferGas, common.Big1, nil)
+		Require(t, builder.L2.Client.SendTransaction(ctx, tx))
+		_, err := builder.L2.EnsureTxSucceeded(tx)
+		Require(t, err)
+		// the dry-run batch with each transaction is built before the next one is sent
+		for attempt := 0; ; attempt++ {
+			reports = readDryRunReports(t, reportFile)
+			if len(reports) > i {
+				break
+			}
+			if attempt >= 100 {
+				Fatal(t, "dry-run batch", i, "wasn't reported")
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	for i, report := range reports {
+		if i > 0 && report.SequenceNumber != reports[i-1].SequenceNumber+1 {
+			Fatal(t, "dry-run batch", i, "has sequence number", report.SequenceNumber, "after", reports[i-1].SequenceNumber)
+		}
+		if i > 0 && report.FromMessage != reports[i-1].ToMessage {
+			Fatal(t, "dry-run batch", i, "starts at message", report.FromMessage, "but the previous one ended at", reports[i-1].ToMessage)
+		}
+		if report.GasLimit == 0 || report.EstimatedCost.Sign() <= 0 || report.MaxCost.Cmp(report.EstimatedCost) < 0 {
+			Fatal(t, "dry-run batch", i, "has bad costs", "gasLimit", report.GasLimit, "estimatedCost", report.EstimatedCost, "maxCost", report.MaxCost)
+		}
+		// every batch is priced as the next one production would post, not behind the unposted ones
+		if report.GasFeeCap.Cmp(arbmath.BigMulByUint(reports[0].GasFeeCap, 2)) > 0 {
+			Fatal(t, "dry-run batch", i, "fee cap", report.GasFeeCap, "escalated from", reports[0].GasFeeCap)
+		}
+	}
+	// nothing was posted to the parent chain
+	CheckBatchCount(t, builder, initialBatchCount)
+}
+
+func readDryRunReports(t *testing.T, path string) []arbnode.DryRunBatchReport {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	Require(t, err)
+	var reports []arbnode.DryRunBatchReport
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var report arbnode.DryRunBatchReport
+		Require(t, json.Unmarshal([]byte(line), &report))
+		reports = append(reports, report)
+	}
+	return reports
+}
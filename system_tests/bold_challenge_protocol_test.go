@@ -163,6 +163,7 @@ func testChallengeProtocolBOLD(t *testing.T, spawnerOpts ...server_arb.SpawnerOp
 		l2nodeA.Execution,
 		l2nodeA.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -178,6 +179,7 @@ func testChallengeProtocolBOLD(t *testing.T, spawnerOpts ...server_arb.SpawnerOp
 		l2nodeB.Execution,
 		l2nodeB.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStackB,
 	)
@@ -0,0 +1,168 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/execution/execrpc"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+// TestExecutionOverRPC runs consensus and execution in separate stacks, connected over their
+// authenticated RPC like the consensus-only and execution-only modes of cmd/nitro.
+func TestExecutionOverRPC(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, false)
+	builder.CheckConfig(t)
+	l2info := builder.L2Info
+
+	jwtSecret := testhelpers.RandomHash()
+	jwtPath := filepath.Join(t.TempDir(), "jwtsecret")
+	Require(t, os.WriteFile(jwtPath, []byte(hexutil.Encode(jwtSecret[:])), 0600))
+
+	// The consensus stack's auth port has to be known before the execution node connects to it
+	listener, err := testhelpers.FreeTCPPortListener()
+	Require(t, err)
+	consensusAuthPort := testhelpers.AddrTCPPort(listener.Addr(), t)
+	Require(t, listener.Close())
+
+	execConfig := builder.execConfig
+	execConfig.ConsensusClient = execrpc.DefaultClientConfig
+	execConfig.ConsensusClient.URL = fmt.Sprintf("ws://127.0.0.1:%d", consensusAuthPort)
+	execConfig.ConsensusClient.JWTSecret = jwtPath
+	execStackConfig := testhelpers.CreateStackConfigForTest(t.TempDir())
+	execStackConfig.AuthAddr = "127.0.0.1"
+	execStackConfig.JWTSecret = jwtPath
+	execrpc.EnsureExposedViaAuthRPC(execStackConfig, execrpc.ExecutionNamespace)
+	_, execStack, chainDb, _, blockchain := createNonL1BlockChainWithStackConfig(t, l2info, "", builder.chainConfig, nil, execStackConfig, execConfig, 0)
+	execNode, err := gethexec.CreateExecutionNode(ctx, execStack, chainDb, blockchain, nil, func() *gethexec.Config { return execConfig })
+	Require(t, err)
+	Require(t, execNode.Initialize(ctx))
+	Require(t, execStack.Start())
+	defer requireClose(t, execStack)
+	// Connects to consensus in the background
+	Require(t, execNode.Start(ctx))
+	defer execNode.StopAndWait()
+
+	nodeConfig := builder.nodeConfig
+	nodeConfig.ExecutionClient = execrpc.DefaultClientConfig
+	nodeConfig.ExecutionClient.URL = execStack.WSAuthEndpoint()
+	nodeConfig.ExecutionClient.JWTSecret = jwtPath
+	Require(t, nodeConfig.Validate())
+	consensusStackConfig := testhelpers.CreateStackConfigForTest(t.TempDir())
+	consensusStackConfig.AuthAddr = "127.0.0.1"
+	consensusStackConfig.AuthPort = consensusAuthPort
+	consensusStackConfig.JWTSecret = jwtPath
+	execrpc.EnsureExposedViaAuthRPC(consensusStackConfig, execrpc.ConsensusNamespace)
+	consensusStack, err := node.New(consensusStackConfig)
+	Require(t, err)
+	arbDb, err := consensusStack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", false, conf.PersistentConfigDefault.Pebble.ExtraOptions("arbitrumdata"))
+	Require(t, err)
+	execClient, err := execrpc.NewExecutionClient(ctx, func() *rpcclient.ClientConfig { return &nodeConfig.ExecutionClient }, consensusStack)
+	Require(t, err)
+	fatalErrChan := make(chan error, 10)
+	consensusNode, err := arbnode.CreateNode(
+		ctx, consensusStack, execClient, arbDb, NewFetcherFromConfig(nodeConfig), blockchain.Config(),
+		nil, nil, nil, nil, nil, fatalErrChan, big.NewInt(1337), nil)
+	Require(t, err)
+	// The init message is digested by the execution node over RPC
+	Require(t, consensusNode.TxStreamer.AddFakeInitMessage())
+	Require(t, consensusNode.Start(ctx))
+	defer consensusNode.StopAndWait()
+	StartWatchChanErr(t, ctx, fatalErrChan, consensusNode)
+
+	client := ClientForStack(t, execStack)
+	requireResultsMatch := func(scenario string) {
+		t.Helper()
+		count, err := consensusNode.TxStreamer.GetMessageCount()
+		Require(t, err)
+		head, err := execClient.HeadMessageNumber()
+		Require(t, err)
+		if head+1 != count {
+			Fatal(t, "execution head", head, "doesn't match consensus message count", count, scenario)
+		}
+		for pos := arbutil.MessageIndex(1); pos < count; pos++ {
+			consensusResult, err := consensusNode.TxStreamer.ResultAtCount(pos + 1)
+			Require(t, err)
+			execResult, err := execClient.ResultAtPos(pos)
+			Require(t, err)
+			if consensusResult.BlockHash != execResult.BlockHash {
+				Fatal(t, "block hash of message", pos, "differs between consensus", consensusResult.BlockHash, "and execution", execResult.BlockHash, scenario)
+			}
+		}
+	}
+
+	// The sequencer writes its blocks' messages to consensus once it's connected
+	l2info.GenerateAccount("User2")
+	l2info.GenerateAccount("User3")
+	tx := l2info.PrepareTx("Owner", "User2", l2info.TransferGas, big.NewInt(1e12), nil)
+	Require(t, client.SendTransaction(ctx, tx))
+	receipt, err := EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+	seqCount := arbutil.MessageIndex(receipt.BlockNumber.Uint64() + 1)
+	doUntil(t, 10*time.Millisecond, 500, func() bool {
+		count, err := consensusNode.TxStreamer.GetMessageCount()
+		Require(t, err)
+		return count >= seqCount
+	})
+	requireResultsMatch("after sequencing")
+
+	// Messages added to consensus are digested by the execution node
+	prevMessage, err := consensusNode.TxStreamer.GetMessage(seqCount - 1)
+	Require(t, err)
+	delayedIndexHash := common.BigToHash(new(big.Int).SetUint64(prevMessage.DelayedMessagesRead))
+	deposit := &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{
+			Kind:      arbostypes.L1MessageType_EthDeposit,
+			RequestId: &delayedIndexHash,
+			L1BaseFee: common.Big0,
+		},
+		L2msg: append(l2info.GetAddress("User3").Bytes(), arbmath.Uint64ToU256Bytes(params.Ether)...),
+	}
+	Require(t, consensusNode.TxStreamer.AddMessages(seqCount, true, []arbostypes.MessageWithMetadata{{
+		Message:             deposit,
+		DelayedMessagesRead: prevMessage.DelayedMessagesRead + 1,
+	}}, nil))
+	doUntil(t, 10*time.Millisecond, 500, func() bool {
+		balance, err := client.BalanceAt(ctx, l2info.GetAddress("User3"), nil)
+		Require(t, err)
+		return balance.Cmp(big.NewInt(params.Ether)) == 0
+	})
+	requireResultsMatch("after digesting a deposit")
+
+	// Reorgs of consensus are applied to the execution node
+	Require(t, consensusNode.TxStreamer.ReorgTo(seqCount))
+	head, err := execClient.HeadMessageNumber()
+	Require(t, err)
+	if head+1 != seqCount {
+		Fatal(t, "execution head", head, "not reorged to", seqCount-1)
+	}
+	balance, err := client.BalanceAt(ctx, l2info.GetAddress("User3"), nil)
+	Require(t, err)
+	if balance.Sign() != 0 {
+		Fatal(t, "reorged out deposit still credited", balance)
+	}
+	requireResultsMatch("after reorg")
+}
@@ -151,6 +151,7 @@ func TestFastConfirmation(t *testing.T) {
 		execNode,
 		l2node.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -352,6 +353,7 @@ func TestFastConfirmationWithSafe(t *testing.T) {
 		execNodeA,
 		l2nodeA.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -404,6 +406,7 @@ func TestFastConfirmationWithSafe(t *testing.T) {
 		execNodeB,
 		l2nodeB.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
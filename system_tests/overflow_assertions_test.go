@@ -97,6 +97,7 @@ func TestOverflowAssertions(t *testing.T) {
 		l2node.Execution,
 		l2node.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -0,0 +1,261 @@
+// Copyright 2024-2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package timeboost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const AuctionHistoryNamespace = "auctionhistory"
+
+type AuctionHistoryAPIConfig struct {
+	Enable         bool   `koanf:"enable"`
+	MaxQueryRounds uint64 `koanf:"max-query-rounds"`
+}
+
+var DefaultAuctionHistoryAPIConfig = AuctionHistoryAPIConfig{
+	Enable:         false,
+	MaxQueryRounds: 10000,
+}
+
+func AuctionHistoryAPIConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultAuctionHistoryAPIConfig.Enable, "enable the read-only auctionhistory RPC namespace serving past auction results and bids")
+	f.Uint64(prefix+".max-query-rounds", DefaultAuctionHistoryAPIConfig.MaxQueryRounds, "maximum number of rounds that can be queried at once")
+}
+
+func (c *AuctionHistoryAPIConfig) Validate() error {
+	if c.Enable && c.MaxQueryRounds == 0 {
+		return errors.New("invalid max-query-rounds value for auctioneer's history-api config, it should be positive")
+	}
+	return nil
+}
+
+func EnsureAuctionHistoryExposedViaRPC(stackConf *node.Config) {
+	for _, module := range stackConf.HTTPModules {
+		if module == AuctionHistoryNamespace {
+			return
+		}
+	}
+	stackConf.HTTPModules = append(stackConf.HTTPModules, AuctionHistoryNamespace)
+}
+
+// AuctionRoundResult is the outcome of the auction for a round. The winner pays SecondPrice,
+// which is the second highest bid, or the reserve price if there was a single bid.
+type AuctionRoundResult struct {
+	Round                 hexutil.Uint64  `json:"round"`
+	BidCount              hexutil.Uint64  `json:"bidCount"`
+	ReservePrice          *hexutil.Big    `json:"reservePrice"`
+	Winner                *common.Address `json:"winner,omitempty"`
+	ExpressLaneController *common.Address `json:"expressLaneController,omitempty"`
+	WinningBid            *hexutil.Big    `json:"winningBid,omitempty"`
+	SecondBidder          *common.Address `json:"secondBidder,omitempty"`
+	SecondPrice           *hexutil.Big    `json:"secondPrice,omitempty"`
+	ResolutionTxHash      *common.Hash    `json:"resolutionTxHash,omitempty"`
+	ResolvedAt            hexutil.Uint64  `json:"resolvedAt"`
+}
+
+type ReservePricePoint struct {
+	Round        hexutil.Uint64 `json:"round"`
+	ReservePrice *hexutil.Big   `json:"reservePrice"`
+}
+
+type BidderHistory struct {
+	// Bids already archived to S3 are no longer part of the history
+	Bids []*JsonValidatedBid   `json:"bids"`
+	Wins []*AuctionRoundResult `json:"wins"`
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q in database", s)
+	}
+	return value, nil
+}
+
+func auctionRoundResultFromDb(r *SqliteDatabaseAuctionResult) (*AuctionRoundResult, error) {
+	reservePrice, err := parseBigInt(r.ReservePrice)
+	if err != nil {
+		return nil, err
+	}
+	result := &AuctionRoundResult{
+		Round:        hexutil.Uint64(r.Round),
+		BidCount:     hexutil.Uint64(r.BidCount),
+		ReservePrice: (*hexutil.Big)(reservePrice),
+		// #nosec G115
+		ResolvedAt: hexutil.Uint64(r.ResolvedAt),
+	}
+	if r.FirstBidder != "" {
+		winner := common.HexToAddress(r.FirstBidder)
+		controller := common.HexToAddress(r.FirstExpressLaneController)
+		winningBid, err := parseBigInt(r.FirstAmount)
+		if err != nil {
+			return nil, err
+		}
+		result.Winner = &winner
+		result.ExpressLaneController = &controller
+		result.WinningBid = (*hexutil.Big)(winningBid)
+		result.SecondPrice = result.ReservePrice
+	}
+	if r.SecondBidder != "" {
+		secondBidder := common.HexToAddress(r.SecondBidder)
+		secondPrice, err := parseBigInt(r.SecondAmount)
+		if err != nil {
+			return nil, err
+		}
+		result.SecondBidder = &secondBidder
+		result.SecondPrice = (*hexutil.Big)(secondPrice)
+	}
+	if r.ResolutionTxHash != "" {
+		txHash := common.HexToHash(r.ResolutionTxHash)
+		result.ResolutionTxHash = &txHash
+	}
+	return result, nil
+}
+
+// AuctionHistoryAPI serves the auction results and bids persisted by the auctioneer.
+type AuctionHistoryAPI struct {
+	database *SqliteDatabase
+	config   func() *AuctionHistoryAPIConfig
+}
+
+func NewAuctionHistoryAPI(database *SqliteDatabase, config func() *AuctionHistoryAPIConfig) *AuctionHistoryAPI {
+	return &AuctionHistoryAPI{
+		database: database,
+		config:   config,
+	}
+}
+
+func (a *AuctionHistoryAPI) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: AuctionHistoryNamespace,
+		Version:   "1.0",
+		Service:   a,
+		Public:    true,
+	}}
+}
+
+// checkRange validates a round range and returns the number of rounds in it.
+func (a *AuctionHistoryAPI) checkRange(fromRound, toRound hexutil.Uint64) (int, error) {
+	if fromRound > toRound {
+		return 0, fmt.Errorf("invalid inputs, fromRound: %d is greater than toRound: %d", fromRound, toRound)
+	}
+	// sqlite doesn't support uint64 values with the high bit set
+	if toRound > math.MaxInt64 {
+		return 0, fmt.Errorf("toRound %d is too large", toRound)
+	}
+	count := uint64(toRound-fromRound) + 1
+	if limit := a.config().MaxQueryRounds; count > limit {
+		return 0, fmt.Errorf("number of rounds requested exceeded. Range requested- %d, Limit- %d", count, limit)
+	}
+	// #nosec G115
+	return int(count), nil
+}
+
+func (a *AuctionHistoryAPI) getRounds(fromRound, toRound hexutil.Uint64) ([]*AuctionRoundResult, error) {
+	limit, err := a.checkRange(fromRound, toRound)
+	if err != nil {
+		return nil, err
+	}
+	dbResults, err := a.database.GetAuctionResults(uint64(fromRound), uint64(toRound), limit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*AuctionRoundResult, 0, len(dbResults))
+	for _, dbResult := range dbResults {
+		result, err := auctionRoundResultFromDb(dbResult)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetRound returns the result of the auction for the round, or nil if the auctioneer has no result for it.
+func (a *AuctionHistoryAPI) GetRound(ctx context.Context, round hexutil.Uint64) (*AuctionRoundResult, error) {
+	results, err := a.getRounds(round, round)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// GetRounds returns the results of the auctions for rounds in [fromRound, toRound]. Rounds the auctioneer
+// didn't resolve are omitted.
+func (a *AuctionHistoryAPI) GetRounds(ctx context.Context, fromRound, toRound hexutil.Uint64) ([]*AuctionRoundResult, error) {
+	return a.getRounds(fromRound, toRound)
+}
+
+// GetReservePrices returns the reserve price of every resolved round in [fromRound, toRound].
+func (a *AuctionHistoryAPI) GetReservePrices(ctx context.Context, fromRound, toRound hexutil.Uint64) ([]ReservePricePoint, error) {
+	results, err := a.getRounds(fromRound, toRound)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ReservePricePoint, 0, len(results))
+	for _, result := range results {
+		points = append(points, ReservePricePoint{Round: result.Round, ReservePrice: result.ReservePrice})
+	}
+	return points, nil
+}
+
+// GetBidderHistory returns the validated bids and the won auctions of the bidder for rounds in [fromRound, toRound].
+func (a *AuctionHistoryAPI) GetBidderHistory(ctx context.Context, bidder common.Address, fromRound, toRound hexutil.Uint64) (*BidderHistory, error) {
+	limit, err := a.checkRange(fromRound, toRound)
+	if err != nil {
+		return nil, err
+	}
+	dbBids, err := a.database.GetBidderBids(bidder, uint64(fromRound), uint64(toRound))
+	if err != nil {
+		return nil, err
+	}
+	dbWins, err := a.database.GetBidderWins(bidder, uint64(fromRound), uint64(toRound), limit)
+	if err != nil {
+		return nil, err
+	}
+	history := &BidderHistory{
+		Bids: make([]*JsonValidatedBid, 0, len(dbBids)),
+		Wins: make([]*AuctionRoundResult, 0, len(dbWins)),
+	}
+	for _, dbBid := range dbBids {
+		amount, err := parseBigInt(dbBid.Amount)
+		if err != nil {
+			return nil, err
+		}
+		chainId, err := parseBigInt(dbBid.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		bid := &ValidatedBid{
+			ExpressLaneController:  common.HexToAddress(dbBid.ExpressLaneController),
+			Amount:                 amount,
+			Signature:              []byte(dbBid.Signature),
+			ChainId:                chainId,
+			AuctionContractAddress: common.HexToAddress(dbBid.AuctionContractAddress),
+			Round:                  dbBid.Round,
+			Bidder:                 common.HexToAddress(dbBid.Bidder),
+		}
+		history.Bids = append(history.Bids, bid.ToJson())
+	}
+	for _, dbWin := range dbWins {
+		win, err := auctionRoundResultFromDb(dbWin)
+		if err != nil {
+			return nil, err
+		}
+		history.Wins = append(history.Wins, win)
+	}
+	return history, nil
+}
@@ -0,0 +1,89 @@
+package timeboost
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestAuctionHistoryAPI(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db, err := NewDatabase(t.TempDir())
+	require.NoError(t, err)
+	bidder := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	otherBidder := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	controller := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	txHash := common.HexToHash("0x1234")
+
+	results := []*SqliteDatabaseAuctionResult{
+		{Round: 1, ChainId: "1", AuctionContractAddress: "0x", BidCount: 0, ReservePrice: "10"},
+		{Round: 2, ChainId: "1", AuctionContractAddress: "0x", BidCount: 1, ReservePrice: "10", FirstBidder: bidder.Hex(), FirstExpressLaneController: controller.Hex(), FirstAmount: "50", ResolutionTxHash: txHash.Hex()},
+		{Round: 3, ChainId: "1", AuctionContractAddress: "0x", BidCount: 2, ReservePrice: "20", FirstBidder: otherBidder.Hex(), FirstExpressLaneController: controller.Hex(), FirstAmount: "80", SecondBidder: bidder.Hex(), SecondAmount: "60"},
+	}
+	for _, result := range results {
+		require.NoError(t, db.InsertAuctionResult(result))
+	}
+	for _, round := range []uint64{2, 3, 3} {
+		require.NoError(t, db.InsertBid(&ValidatedBid{
+			ChainId:                big.NewInt(1),
+			ExpressLaneController:  controller,
+			AuctionContractAddress: common.Address{},
+			Bidder:                 bidder,
+			Round:                  round,
+			Amount:                 big.NewInt(50),
+			Signature:              []byte("signature"),
+		}))
+	}
+
+	config := DefaultAuctionHistoryAPIConfig
+	config.MaxQueryRounds = 10
+	api := NewAuctionHistoryAPI(db, func() *AuctionHistoryAPIConfig { return &config })
+
+	round, err := api.GetRound(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, bidder, *round.Winner)
+	require.Equal(t, int64(10), round.SecondPrice.ToInt().Int64(), "single bid auctions are priced at the reserve price")
+	require.Equal(t, txHash, *round.ResolutionTxHash)
+
+	round, err = api.GetRound(ctx, 5)
+	require.NoError(t, err)
+	require.Nil(t, round)
+
+	rounds, err := api.GetRounds(ctx, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, rounds, 3)
+	require.Nil(t, rounds[0].Winner)
+	require.Equal(t, int64(60), rounds[2].SecondPrice.ToInt().Int64())
+
+	prices, err := api.GetReservePrices(ctx, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, []ReservePricePoint{
+		{Round: 2, ReservePrice: (*hexutil.Big)(big.NewInt(10))},
+		{Round: 3, ReservePrice: (*hexutil.Big)(big.NewInt(20))},
+	}, prices)
+
+	history, err := api.GetBidderHistory(ctx, bidder, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, history.Bids, 3)
+	require.Len(t, history.Wins, 1)
+	require.Equal(t, hexutil.Uint64(2), history.Wins[0].Round)
+
+	// the range limits rounds, not bids
+	config.MaxQueryRounds = 2
+	history, err = api.GetBidderHistory(ctx, bidder, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, history.Bids, 3)
+	require.Len(t, history.Wins, 1)
+
+	_, err = api.GetRounds(ctx, 3, 1)
+	require.Error(t, err)
+	_, err = api.GetRounds(ctx, 1, 20)
+	require.Error(t, err)
+}
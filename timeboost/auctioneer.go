@@ -67,6 +67,7 @@ type AuctioneerServerConfig struct {
 	DbDirectory               string                   `koanf:"db-directory"`
 	AuctionResolutionWaitTime time.Duration            `koanf:"auction-resolution-wait-time"`
 	S3Storage                 S3StorageServiceConfig   `koanf:"s3-storage"`
+	HistoryAPI                AuctionHistoryAPIConfig  `koanf:"history-api"`
 }
 
 var DefaultAuctioneerServerConfig = AuctioneerServerConfig{
@@ -76,6 +77,7 @@ var DefaultAuctioneerServerConfig = AuctioneerServerConfig{
 	StreamTimeout:             10 * time.Minute,
 	AuctionResolutionWaitTime: 2 * time.Second,
 	S3Storage:                 DefaultS3StorageServiceConfig,
+	HistoryAPI:                DefaultAuctionHistoryAPIConfig,
 }
 
 var TestAuctioneerServerConfig = AuctioneerServerConfig{
@@ -84,12 +86,13 @@ var TestAuctioneerServerConfig = AuctioneerServerConfig{
 	ConsumerConfig:            pubsub.TestConsumerConfig,
 	StreamTimeout:             time.Minute,
 	AuctionResolutionWaitTime: 2 * time.Second,
+	HistoryAPI:                DefaultAuctionHistoryAPIConfig,
 }
 
 func AuctioneerServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultAuctioneerServerConfig.Enable, "enable auctioneer server")
 	f.String(prefix+".redis-url", DefaultAuctioneerServerConfig.RedisURL, "url of redis server to receive bids from bid validators")
-	pubsub.ConsumerConfigAddOptions(prefix+".consumer-config", f)
+	pubsub.ConsumerConfigAddOptions(prefix+".consumer-config", f, &DefaultAuctioneerServerConfig.ConsumerConfig)
 	f.Duration(prefix+".stream-timeout", DefaultAuctioneerServerConfig.StreamTimeout, "Timeout on polling for existence of redis streams")
 	genericconf.WalletConfigAddOptions(prefix+".wallet", f, "wallet for auctioneer server")
 	f.String(prefix+".sequencer-endpoint", DefaultAuctioneerServerConfig.SequencerEndpoint, "sequencer RPC endpoint")
@@ -100,6 +103,7 @@ func AuctioneerServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".db-directory", DefaultAuctioneerServerConfig.DbDirectory, "path to database directory for persisting validated bids in a sqlite file")
 	f.Duration(prefix+".auction-resolution-wait-time", DefaultAuctioneerServerConfig.AuctionResolutionWaitTime, "wait time after auction closing before resolving the auction")
 	S3StorageServiceConfigAddOptions(prefix+".s3-storage", f)
+	AuctionHistoryAPIConfigAddOptions(prefix+".history-api", f)
 }
 
 // AuctioneerServer is a struct that represents an autonomous auctioneer.
@@ -119,6 +123,7 @@ type AuctioneerServer struct {
 	auctionResolutionWaitTime time.Duration
 	database                  *SqliteDatabase
 	s3StorageService          *S3StorageService
+	historyAPI                *AuctionHistoryAPI
 }
 
 // NewAuctioneerServer creates a new autonomous auctioneer struct.
@@ -194,12 +199,17 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 	if err = roundTimingInfo.ValidateResolutionWaitTime(cfg.AuctionResolutionWaitTime); err != nil {
 		return nil, err
 	}
+	var historyAPI *AuctionHistoryAPI
+	if cfg.HistoryAPI.Enable {
+		historyAPI = NewAuctionHistoryAPI(database, func() *AuctionHistoryAPIConfig { return &configFetcher().HistoryAPI })
+	}
 	return &AuctioneerServer{
 		txOpts:                    txOpts,
 		endpointManager:           endpointManager,
 		chainId:                   chainId,
 		database:                  database,
 		s3StorageService:          s3StorageService,
+		historyAPI:                historyAPI,
 		consumer:                  c,
 		auctionContract:           auctionContract,
 		auctionContractAddr:       auctionContractAddr,
@@ -210,6 +220,11 @@ func NewAuctioneerServer(ctx context.Context, configFetcher AuctioneerServerConf
 	}, nil
 }
 
+// HistoryAPI returns the auction history API, or nil if it's disabled.
+func (a *AuctioneerServer) HistoryAPI() *AuctionHistoryAPI {
+	return a.historyAPI
+}
+
 func (a *AuctioneerServer) Start(ctx_in context.Context) {
 	a.StopWaiter.Start(ctx_in, a)
 	// Start S3 storage service to persist validated bids to s3
@@ -260,7 +275,7 @@ func (a *AuctioneerServer) Start(ctx_in context.Context) {
 			a.bidsReceiver <- req.Value
 
 			// We received the message, then we ack with a nil error.
-			if err := a.consumer.SetResult(ctx, req.ID, nil); err != nil {
+			if err := a.consumer.SetResult(ctx, req, nil); err != nil {
 				log.Error("Error setting result for request", "id", req.ID, "result", nil, "error", err)
 				return 0
 			}
@@ -345,6 +360,28 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 		}
 	}
 
+	roundResult := &SqliteDatabaseAuctionResult{
+		Round:                  upcomingRound,
+		ChainId:                a.chainId.String(),
+		AuctionContractAddress: a.auctionContractAddr.Hex(),
+		// #nosec G115
+		BidCount: uint64(a.bidCache.size()),
+	}
+	if reservePrice, err := a.auctionContract.ReservePrice(&bind.CallOpts{Context: ctx}); err != nil {
+		log.Warn("Could not fetch reserve price for auction history", "round", upcomingRound, "err", err)
+		roundResult.ReservePrice = "0"
+	} else {
+		roundResult.ReservePrice = reservePrice.String()
+	}
+	if first != nil {
+		roundResult.FirstBidder = first.Bidder.Hex()
+		roundResult.FirstExpressLaneController = first.ExpressLaneController.Hex()
+		roundResult.FirstAmount = first.Amount.String()
+	}
+	if second != nil {
+		roundResult.SecondBidder = second.Bidder.Hex()
+		roundResult.SecondAmount = second.Amount.String()
+	}
 	switch {
 	case first != nil && second != nil: // Both bids are present
 		tx, err = a.auctionContract.ResolveMultiBidAuction(
@@ -378,6 +415,7 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 
 	case second == nil: // No bids received
 		log.Info("No bids received for auction resolution", "round", upcomingRound)
+		a.persistAuctionResult(roundResult)
 		return nil
 	}
 	if err != nil {
@@ -414,6 +452,9 @@ func (a *AuctioneerServer) resolveAuction(ctx context.Context) error {
 		return err
 	}
 
+	// Only resolved rounds are recorded, as a failed resolution has no winner
+	roundResult.ResolutionTxHash = tx.Hash().Hex()
+	a.persistAuctionResult(roundResult)
 	log.Info("Auction resolved successfully", "txHash", tx.Hash().Hex())
 	return nil
 }
@@ -447,6 +488,13 @@ func (a *AuctioneerServer) persistValidatedBid(bid *JsonValidatedBid) {
 	}
 }
 
+func (a *AuctioneerServer) persistAuctionResult(result *SqliteDatabaseAuctionResult) {
+	result.ResolvedAt = time.Now().Unix()
+	if err := a.database.InsertAuctionResult(result); err != nil {
+		log.Error("Could not persist auction result to database", "err", err, "round", result.Round)
+	}
+}
+
 func copyTxOpts(opts *bind.TransactOpts) *bind.TransactOpts {
 	if opts == nil {
 		return nil
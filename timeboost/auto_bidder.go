@@ -0,0 +1,284 @@
+// Copyright 2024-2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package timeboost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type AutoBidderConfig struct {
+	Enable                bool                  `koanf:"enable"`
+	Strategy              BiddingStrategyConfig `koanf:"strategy"`
+	DailyBudgetGwei       uint64                `koanf:"daily-budget-gwei"`
+	TopUpGwei             uint64                `koanf:"top-up-gwei"`
+	WithdrawOnStop        bool                  `koanf:"withdraw-on-stop"`
+	ExpressLaneController string                `koanf:"express-lane-controller"`
+	DbDirectory           string                `koanf:"db-directory"`
+}
+
+var DefaultAutoBidderConfig = AutoBidderConfig{
+	Enable:   false,
+	Strategy: DefaultBiddingStrategyConfig,
+}
+
+func AutoBidderConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultAutoBidderConfig.Enable, "keep running and bid in every auction according to the bidding strategy")
+	BiddingStrategyConfigAddOptions(prefix+".strategy", f)
+	f.Uint64(prefix+".daily-budget-gwei", DefaultAutoBidderConfig.DailyBudgetGwei, "maximum amount in gwei to commit to bids per UTC day, bids are charged when submitted and refunded down to the price paid once resolved (0 = unlimited)")
+	f.Uint64(prefix+".top-up-gwei", DefaultAutoBidderConfig.TopUpGwei, "amount in gwei to deposit into the auction contract when the deposit doesn't cover the next bid (0 = never deposit)")
+	f.Bool(prefix+".withdraw-on-stop", DefaultAutoBidderConfig.WithdrawOnStop, "initiate a withdrawal of the whole deposit when stopping, it's finalized on the next start")
+	f.String(prefix+".express-lane-controller", DefaultAutoBidderConfig.ExpressLaneController, "express lane controller to bid for (defaults to the bidder's address)")
+	f.String(prefix+".db-directory", DefaultAutoBidderConfig.DbDirectory, "path to database directory for persisting the daily budget in a sqlite file (required with daily-budget-gwei)")
+}
+
+func (c *AutoBidderConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.ExpressLaneController != "" && !common.IsHexAddress(c.ExpressLaneController) {
+		return fmt.Errorf("invalid express lane controller address %q", c.ExpressLaneController)
+	}
+	if c.DailyBudgetGwei > 0 && c.DbDirectory == "" {
+		return errors.New("auto-bid.db-directory must be set to enforce a daily budget across restarts")
+	}
+	return c.Strategy.Validate()
+}
+
+// AutoBidder bids in every auction once the reserve price for it is settled, managing the bidder's
+// deposit and keeping the amount paid for won auctions within a daily budget.
+type AutoBidder struct {
+	stopwaiter.StopWaiter
+	config     *AutoBidderConfig
+	bidder     *BidderClient
+	strategy   BiddingStrategy
+	budget     *dailyBudget
+	controller common.Address
+
+	// only accessed by the bidding thread
+	clearingPrices   []*big.Int
+	lastScannedBlock uint64
+	// bids charged to the budget whose auction wasn't resolved yet, by round
+	pendingBids map[uint64]pendingBid
+}
+
+type pendingBid struct {
+	amount *big.Int
+	day    string
+}
+
+func NewAutoBidder(bidder *BidderClient, config *AutoBidderConfig) (*AutoBidder, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	strategy, err := NewBiddingStrategy(&config.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	var budgetLimit *big.Int
+	var store budgetStore
+	if config.DailyBudgetGwei > 0 {
+		budgetLimit = gweiToWei(config.DailyBudgetGwei)
+		database, err := NewBidderDatabase(config.DbDirectory)
+		if err != nil {
+			return nil, err
+		}
+		store = database
+	}
+	controller := bidder.txOpts.From
+	if config.ExpressLaneController != "" {
+		controller = common.HexToAddress(config.ExpressLaneController)
+	}
+	return &AutoBidder{
+		config:      config,
+		bidder:      bidder,
+		strategy:    strategy,
+		budget:      newDailyBudget(budgetLimit, store),
+		controller:  controller,
+		pendingBids: make(map[uint64]pendingBid),
+	}, nil
+}
+
+func (b *AutoBidder) Start(ctxIn context.Context) error {
+	b.StopWaiter.Start(ctxIn, b)
+	ctx := b.GetContext()
+	withdrawable, err := b.bidder.auctionContract.WithdrawableBalance(&bind.CallOpts{Context: ctx}, b.bidder.txOpts.From)
+	if err != nil {
+		return err
+	}
+	if withdrawable.Sign() > 0 {
+		log.Info("Finalizing pending withdrawal from the auction contract", "amount", withdrawable)
+		if err := b.bidder.FinalizeWithdrawal(ctx); err != nil {
+			return fmt.Errorf("finalizing withdrawal: %w", err)
+		}
+	}
+	b.lastScannedBlock, err = b.bidder.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	return b.LaunchThreadSafe(func(ctx context.Context) {
+		ticker := newRoundTicker(b.bidder.roundTimingInfo)
+		go ticker.tickAtReserveSubmissionDeadline()
+		defer close(ticker.done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.c:
+				if err := b.bidRound(ctx); err != nil {
+					log.Error("Error bidding in auction", "round", b.bidder.roundTimingInfo.RoundNumber()+1, "err", err)
+				}
+			}
+		}
+	})
+}
+
+func (b *AutoBidder) StopAndWait() {
+	b.StopWaiter.StopAndWait()
+	if b.config.WithdrawOnStop {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := b.bidder.InitiateWithdrawal(ctx); err != nil {
+			log.Error("Error initiating withdrawal from the auction contract", "err", err)
+		}
+	}
+}
+
+// scanResolutions records the clearing prices of the auctions resolved since the last scan, and refunds
+// the daily budget for the bids this bidder lost, or the part of the bid above the price it paid, and
+// for the bids of auctions that were never resolved. Bids submitted before a restart stay fully charged.
+func (b *AutoBidder) scanResolutions(ctx context.Context) error {
+	head, err := b.bidder.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head <= b.lastScannedBlock {
+		return nil
+	}
+	it, err := b.bidder.auctionContract.FilterAuctionResolved(&bind.FilterOpts{
+		Context: ctx,
+		Start:   b.lastScannedBlock + 1,
+		End:     &head,
+	}, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		b.clearingPrices = append(b.clearingPrices, it.Event.Price)
+		refund := new(big.Int)
+		pending, isPending := b.pendingBids[it.Event.Round]
+		if isPending {
+			refund = pending.amount
+			delete(b.pendingBids, it.Event.Round)
+		}
+		if it.Event.FirstPriceBidder == b.bidder.txOpts.From {
+			log.Info("Won express lane auction", "round", it.Event.Round, "price", it.Event.Price)
+			refund = arbmath.BigSub(refund, it.Event.Price)
+		}
+		if isPending && refund.Sign() > 0 {
+			if err := b.budget.refund(refund, pending.day); err != nil {
+				log.Warn("Error refunding daily budget", "round", it.Event.Round, "amount", refund, "err", err)
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	// auctions aren't resolved once their round started, leaving a round of margin in case the
+	// scanned head lags behind, and unresolved auctions charge no one
+	currentRound := b.bidder.roundTimingInfo.RoundNumber()
+	for round, pending := range b.pendingBids {
+		if round+1 >= currentRound {
+			continue
+		}
+		log.Warn("Auction was never resolved, refunding bid", "round", round, "amount", pending.amount)
+		if err := b.budget.refund(pending.amount, pending.day); err != nil {
+			log.Warn("Error refunding daily budget", "round", round, "amount", pending.amount, "err", err)
+		}
+		delete(b.pendingBids, round)
+	}
+	if window := b.config.Strategy.ClearingPriceWindow; len(b.clearingPrices) > window {
+		b.clearingPrices = b.clearingPrices[len(b.clearingPrices)-window:]
+	}
+	b.lastScannedBlock = head
+	return nil
+}
+
+// ensureDeposit makes sure the bidder's deposit covers the amount, topping it up if configured to.
+func (b *AutoBidder) ensureDeposit(ctx context.Context, amount *big.Int) error {
+	balance, err := b.bidder.auctionContract.BalanceOf(&bind.CallOpts{Context: ctx}, b.bidder.txOpts.From)
+	if err != nil {
+		return err
+	}
+	if !arbmath.BigLessThan(balance, amount) {
+		return nil
+	}
+	if b.config.TopUpGwei == 0 {
+		return fmt.Errorf("deposit of %v is below the bid of %v and top-up is disabled", balance, amount)
+	}
+	topUp := arbmath.BigMax(gweiToWei(b.config.TopUpGwei), arbmath.BigSub(amount, balance))
+	log.Info("Topping up deposit in the auction contract", "balance", balance, "bid", amount, "deposit", topUp)
+	return b.bidder.Deposit(ctx, topUp)
+}
+
+func (b *AutoBidder) bidRound(ctx context.Context) error {
+	if err := b.scanResolutions(ctx); err != nil {
+		log.Warn("Error scanning auction resolutions", "err", err)
+	}
+	round := b.bidder.roundTimingInfo.RoundNumber() + 1
+	reservePrice, err := b.bidder.auctionContract.ReservePrice(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return err
+	}
+	amount := b.strategy.BidAmount(&BiddingState{
+		Round:          round,
+		ReservePrice:   reservePrice,
+		ClearingPrices: b.clearingPrices,
+	})
+	if amount == nil {
+		log.Info("Bidding strategy skipped round", "round", round, "reservePrice", reservePrice)
+		return nil
+	}
+	remaining, err := b.budget.remaining(time.Now())
+	if err != nil {
+		return err
+	}
+	if remaining != nil && arbmath.BigGreaterThan(amount, remaining) {
+		if arbmath.BigLessThan(remaining, reservePrice) {
+			log.Info("Daily budget exhausted, skipping round", "round", round, "remaining", remaining, "reservePrice", reservePrice)
+			return nil
+		}
+		amount = remaining
+	}
+	if err := b.ensureDeposit(ctx, amount); err != nil {
+		return err
+	}
+	// the bid is charged before it's submitted, so that a crash can't leave it unaccounted for
+	day, err := b.budget.spend(amount, time.Now())
+	if err != nil {
+		return err
+	}
+	if _, err := b.bidder.Bid(ctx, amount, b.controller); err != nil {
+		if refundErr := b.budget.refund(amount, day); refundErr != nil {
+			log.Warn("Error refunding daily budget", "round", round, "amount", amount, "err", refundErr)
+		}
+		return err
+	}
+	b.pendingBids[round] = pendingBid{amount: amount, day: day}
+	log.Info("Submitted bid", "round", round, "amount", amount, "reservePrice", reservePrice)
+	return nil
+}
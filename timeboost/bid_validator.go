@@ -51,7 +51,7 @@ var TestBidValidatorConfig = BidValidatorConfig{
 func BidValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultBidValidatorConfig.Enable, "enable bid validator")
 	f.String(prefix+".redis-url", DefaultBidValidatorConfig.RedisURL, "url of redis server")
-	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f)
+	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f, &DefaultBidValidatorConfig.ProducerConfig)
 	f.String(prefix+".sequencer-endpoint", DefaultAuctioneerServerConfig.SequencerEndpoint, "sequencer RPC endpoint")
 	f.String(prefix+".auction-contract-address", DefaultAuctioneerServerConfig.AuctionContractAddress, "express lane auction contract address")
 }
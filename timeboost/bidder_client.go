@@ -33,16 +33,19 @@ type BidderClientConfig struct {
 	AuctionContractAddress string                   `koanf:"auction-contract-address"`
 	DepositGwei            int                      `koanf:"deposit-gwei"`
 	BidGwei                int                      `koanf:"bid-gwei"`
+	AutoBid                AutoBidderConfig         `koanf:"auto-bid"`
 }
 
 var DefaultBidderClientConfig = BidderClientConfig{
 	ArbitrumNodeEndpoint: "http://localhost:8547",
 	BidValidatorEndpoint: "http://localhost:9372",
+	AutoBid:              DefaultAutoBidderConfig,
 }
 
 var TestBidderClientConfig = BidderClientConfig{
 	ArbitrumNodeEndpoint: "http://localhost:8547",
 	BidValidatorEndpoint: "http://localhost:9372",
+	AutoBid:              DefaultAutoBidderConfig,
 }
 
 func BidderClientConfigAddOptions(f *pflag.FlagSet) {
@@ -52,6 +55,7 @@ func BidderClientConfigAddOptions(f *pflag.FlagSet) {
 	f.String("auction-contract-address", DefaultBidderClientConfig.AuctionContractAddress, "express lane auction contract address")
 	f.Int("deposit-gwei", DefaultBidderClientConfig.DepositGwei, "deposit amount in gwei to take from bidder's account and send to auction contract")
 	f.Int("bid-gwei", DefaultBidderClientConfig.BidGwei, "bid amount in gwei, bidder must have already deposited enough into the auction contract")
+	AutoBidderConfigAddOptions("auto-bid", f)
 }
 
 type BidderClient struct {
@@ -182,6 +186,39 @@ func (bd *BidderClient) Deposit(ctx context.Context, amount *big.Int) error {
 	return nil
 }
 
+// InitiateWithdrawal starts withdrawing the whole deposit of the account configured by the BidderClient wallet.
+// The withdrawal can be finalized with FinalizeWithdrawal once the contract's withdrawal delay has passed.
+func (bd *BidderClient) InitiateWithdrawal(ctx context.Context) error {
+	tx, err := bd.auctionContract.InitiateWithdrawal(bd.txOpts)
+	if err != nil {
+		return err
+	}
+	receipt, err := bind.WaitMined(ctx, bd.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("initiating withdrawal failed")
+	}
+	return nil
+}
+
+// FinalizeWithdrawal transfers a previously initiated withdrawal back to the account.
+func (bd *BidderClient) FinalizeWithdrawal(ctx context.Context) error {
+	tx, err := bd.auctionContract.FinalizeWithdrawal(bd.txOpts)
+	if err != nil {
+		return err
+	}
+	receipt, err := bind.WaitMined(ctx, bd.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("finalizing withdrawal failed")
+	}
+	return nil
+}
+
 func (bd *BidderClient) Bid(
 	ctx context.Context, amount *big.Int, expressLaneController common.Address,
 ) (*Bid, error) {
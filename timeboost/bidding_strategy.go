@@ -0,0 +1,239 @@
+// Copyright 2024-2025, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package timeboost
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+const (
+	BiddingStrategyFixed            = "fixed"
+	BiddingStrategyReservePlusDelta = "reserve-plus-delta"
+	BiddingStrategyOutbidCap        = "outbid-cap"
+)
+
+var gwei = big.NewInt(1_000_000_000)
+
+func gweiToWei(amount uint64) *big.Int {
+	return arbmath.BigMulByUint(gwei, amount)
+}
+
+type BiddingStrategyConfig struct {
+	Name                string `koanf:"name"`
+	BidGwei             uint64 `koanf:"bid-gwei"`
+	DeltaGwei           uint64 `koanf:"delta-gwei"`
+	MaxBidGwei          uint64 `koanf:"max-bid-gwei"`
+	ClearingPriceWindow int    `koanf:"clearing-price-window"`
+}
+
+var DefaultBiddingStrategyConfig = BiddingStrategyConfig{
+	Name:                BiddingStrategyFixed,
+	ClearingPriceWindow: 10,
+}
+
+func BiddingStrategyConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.String(prefix+".name", DefaultBiddingStrategyConfig.Name, "bidding strategy, one of \""+BiddingStrategyFixed+"\", \""+BiddingStrategyReservePlusDelta+"\" or \""+BiddingStrategyOutbidCap+"\"")
+	f.Uint64(prefix+".bid-gwei", DefaultBiddingStrategyConfig.BidGwei, "bid amount in gwei for the fixed strategy")
+	f.Uint64(prefix+".delta-gwei", DefaultBiddingStrategyConfig.DeltaGwei, "amount in gwei to bid above the reserve price, or above the highest recent clearing price for the outbid-cap strategy")
+	f.Uint64(prefix+".max-bid-gwei", DefaultBiddingStrategyConfig.MaxBidGwei, "maximum bid in gwei for the reserve-plus-delta and outbid-cap strategies (0 = unlimited for reserve-plus-delta)")
+	f.Int(prefix+".clearing-price-window", DefaultBiddingStrategyConfig.ClearingPriceWindow, "number of recent auction clearing prices the outbid-cap strategy considers")
+}
+
+func (c *BiddingStrategyConfig) Validate() error {
+	switch c.Name {
+	case BiddingStrategyFixed:
+		if c.BidGwei == 0 {
+			return fmt.Errorf("bid-gwei must be set for the %s bidding strategy", c.Name)
+		}
+	case BiddingStrategyReservePlusDelta:
+	case BiddingStrategyOutbidCap:
+		if c.MaxBidGwei == 0 {
+			return fmt.Errorf("max-bid-gwei must be set for the %s bidding strategy", c.Name)
+		}
+		if c.ClearingPriceWindow <= 0 {
+			return fmt.Errorf("clearing-price-window must be positive for the %s bidding strategy", c.Name)
+		}
+	default:
+		return fmt.Errorf("unknown bidding strategy %q", c.Name)
+	}
+	return nil
+}
+
+// BiddingState is what a strategy knows about the upcoming auction.
+type BiddingState struct {
+	Round        uint64
+	ReservePrice *big.Int
+	// Prices paid by the winners of recent auctions, oldest first
+	ClearingPrices []*big.Int
+}
+
+// BiddingStrategy decides how much to bid in each auction.
+type BiddingStrategy interface {
+	// BidAmount returns the amount to bid for the upcoming round, or nil to skip it.
+	BidAmount(state *BiddingState) *big.Int
+}
+
+func NewBiddingStrategy(config *BiddingStrategyConfig) (BiddingStrategy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var maxBid *big.Int
+	if config.MaxBidGwei > 0 {
+		maxBid = gweiToWei(config.MaxBidGwei)
+	}
+	switch config.Name {
+	case BiddingStrategyFixed:
+		return &FixedBidStrategy{Amount: gweiToWei(config.BidGwei)}, nil
+	case BiddingStrategyReservePlusDelta:
+		return &ReservePlusDeltaStrategy{Delta: gweiToWei(config.DeltaGwei), MaxBid: maxBid}, nil
+	default:
+		return &OutbidCapStrategy{Increment: gweiToWei(config.DeltaGwei), Cap: maxBid}, nil
+	}
+}
+
+// FixedBidStrategy always bids the same amount, skipping rounds where it's below the reserve price.
+type FixedBidStrategy struct {
+	Amount *big.Int
+}
+
+func (s *FixedBidStrategy) BidAmount(state *BiddingState) *big.Int {
+	if arbmath.BigLessThan(s.Amount, state.ReservePrice) {
+		return nil
+	}
+	return s.Amount
+}
+
+// ReservePlusDeltaStrategy bids a fixed amount above the reserve price, up to an optional maximum.
+type ReservePlusDeltaStrategy struct {
+	Delta  *big.Int
+	MaxBid *big.Int
+}
+
+func (s *ReservePlusDeltaStrategy) BidAmount(state *BiddingState) *big.Int {
+	amount := arbmath.BigAdd(state.ReservePrice, s.Delta)
+	if s.MaxBid != nil && arbmath.BigGreaterThan(amount, s.MaxBid) {
+		return nil
+	}
+	return amount
+}
+
+// OutbidCapStrategy bids an increment above the highest recent clearing price, capped at Cap.
+// Without any observed clearing prices it bids an increment above the reserve price.
+type OutbidCapStrategy struct {
+	Increment *big.Int
+	Cap       *big.Int
+}
+
+func (s *OutbidCapStrategy) BidAmount(state *BiddingState) *big.Int {
+	highest := state.ReservePrice
+	for _, price := range state.ClearingPrices {
+		highest = arbmath.BigMax(highest, price)
+	}
+	amount := arbmath.BigMin(arbmath.BigAdd(highest, s.Increment), s.Cap)
+	if arbmath.BigLessThan(amount, state.ReservePrice) {
+		return nil
+	}
+	return amount
+}
+
+// budgetStore persists the amount committed to bids per UTC day, so that restarts don't reset the budget.
+type budgetStore interface {
+	DailySpending(day string) (*big.Int, error)
+	SetDailySpending(day string, spent *big.Int) error
+}
+
+// dailyBudget tracks the amount committed to bids during the current UTC day. Bids are charged when
+// they're submitted, and refunded once the auction is resolved.
+type dailyBudget struct {
+	limit *big.Int
+	store budgetStore
+	spent *big.Int
+	day   string
+}
+
+func newDailyBudget(limit *big.Int, store budgetStore) *dailyBudget {
+	return &dailyBudget{limit: limit, store: store, spent: new(big.Int)}
+}
+
+func budgetDay(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+func (b *dailyBudget) spentOn(day string) (*big.Int, error) {
+	if day == b.day {
+		return b.spent, nil
+	}
+	if b.store == nil {
+		return new(big.Int), nil
+	}
+	spent, err := b.store.DailySpending(day)
+	if err != nil || spent == nil {
+		return new(big.Int), err
+	}
+	return spent, nil
+}
+
+func (b *dailyBudget) rollover(now time.Time) error {
+	day := budgetDay(now)
+	if day == b.day {
+		return nil
+	}
+	spent, err := b.spentOn(day)
+	if err != nil {
+		return err
+	}
+	b.day = day
+	b.spent = spent
+	return nil
+}
+
+// remaining returns how much can still be spent today, or nil if the budget is unlimited.
+func (b *dailyBudget) remaining(now time.Time) (*big.Int, error) {
+	if b.limit == nil {
+		return nil, nil
+	}
+	if err := b.rollover(now); err != nil {
+		return nil, err
+	}
+	if !arbmath.BigLessThan(b.spent, b.limit) {
+		return new(big.Int), nil
+	}
+	return arbmath.BigSub(b.limit, b.spent), nil
+}
+
+// spend charges the amount to the current day, and returns the day so that it can later be refunded.
+func (b *dailyBudget) spend(amount *big.Int, now time.Time) (string, error) {
+	if err := b.rollover(now); err != nil {
+		return "", err
+	}
+	return b.day, b.charge(b.day, amount)
+}
+
+// refund gives back an amount charged on the given day.
+func (b *dailyBudget) refund(amount *big.Int, day string) error {
+	return b.charge(day, new(big.Int).Neg(amount))
+}
+
+func (b *dailyBudget) charge(day string, amount *big.Int) error {
+	spent, err := b.spentOn(day)
+	if err != nil {
+		return err
+	}
+	spent = arbmath.BigMax(arbmath.BigAdd(spent, amount), new(big.Int))
+	if b.store != nil {
+		if err := b.store.SetDailySpending(day, spent); err != nil {
+			return err
+		}
+	}
+	if day == b.day {
+		b.spent = spent
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package timeboost
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBiddingStrategies(t *testing.T) {
+	t.Parallel()
+	reserve := gweiToWei(10)
+
+	fixed, err := NewBiddingStrategy(&BiddingStrategyConfig{Name: BiddingStrategyFixed, BidGwei: 15})
+	require.NoError(t, err)
+	require.Equal(t, gweiToWei(15), fixed.BidAmount(&BiddingState{ReservePrice: reserve}))
+	require.Nil(t, fixed.BidAmount(&BiddingState{ReservePrice: gweiToWei(20)}), "fixed bid below reserve price")
+
+	reservePlusDelta, err := NewBiddingStrategy(&BiddingStrategyConfig{Name: BiddingStrategyReservePlusDelta, DeltaGwei: 2, MaxBidGwei: 15})
+	require.NoError(t, err)
+	require.Equal(t, gweiToWei(12), reservePlusDelta.BidAmount(&BiddingState{ReservePrice: reserve}))
+	require.Nil(t, reservePlusDelta.BidAmount(&BiddingState{ReservePrice: gweiToWei(14)}), "bid above max")
+
+	outbid, err := NewBiddingStrategy(&BiddingStrategyConfig{Name: BiddingStrategyOutbidCap, DeltaGwei: 1, MaxBidGwei: 30, ClearingPriceWindow: 5})
+	require.NoError(t, err)
+	require.Equal(t, gweiToWei(11), outbid.BidAmount(&BiddingState{ReservePrice: reserve}))
+	require.Equal(t, gweiToWei(26), outbid.BidAmount(&BiddingState{
+		ReservePrice:   reserve,
+		ClearingPrices: []*big.Int{gweiToWei(25), gweiToWei(12)},
+	}))
+	require.Equal(t, gweiToWei(30), outbid.BidAmount(&BiddingState{
+		ReservePrice:   reserve,
+		ClearingPrices: []*big.Int{gweiToWei(40)},
+	}), "bid should be capped")
+	require.Nil(t, outbid.BidAmount(&BiddingState{ReservePrice: gweiToWei(31)}), "cap below reserve price")
+
+	_, err = NewBiddingStrategy(&BiddingStrategyConfig{Name: BiddingStrategyOutbidCap, ClearingPriceWindow: 5})
+	require.Error(t, err)
+	_, err = NewBiddingStrategy(&BiddingStrategyConfig{Name: "random"})
+	require.Error(t, err)
+}
+
+type memoryBudgetStore map[string]*big.Int
+
+func (s memoryBudgetStore) DailySpending(day string) (*big.Int, error) {
+	return s[day], nil
+}
+
+func (s memoryBudgetStore) SetDailySpending(day string, spent *big.Int) error {
+	s[day] = spent
+	return nil
+}
+
+func TestDailyBudget(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	remaining, err := newDailyBudget(nil, nil).remaining(now)
+	require.NoError(t, err)
+	require.Nil(t, remaining, "unlimited budget")
+
+	store := make(memoryBudgetStore)
+	budget := newDailyBudget(big.NewInt(100), store)
+	remaining, err = budget.remaining(now)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), remaining)
+	day, err := budget.spend(big.NewInt(70), now)
+	require.NoError(t, err)
+	remaining, err = budget.remaining(now)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), remaining)
+	_, err = budget.spend(big.NewInt(50), now)
+	require.NoError(t, err)
+	remaining, err = budget.remaining(now)
+	require.NoError(t, err)
+	require.Equal(t, 0, remaining.Sign())
+
+	restarted := newDailyBudget(big.NewInt(100), store)
+	remaining, err = restarted.remaining(now)
+	require.NoError(t, err)
+	require.Equal(t, 0, remaining.Sign(), "budget survives restarts")
+	remaining, err = restarted.remaining(now.Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), remaining, "budget resets on the next UTC day")
+
+	require.NoError(t, restarted.refund(big.NewInt(70), day))
+	require.Equal(t, big.NewInt(50), store[day], "refunds go to the day the bid was charged to")
+}
@@ -3,6 +3,7 @@ package timeboost
 import (
 	"fmt"
 	"io/fs"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,9 +11,12 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 const sqliteFileName = "validated_bids.db?_journal_mode=WAL"
+const bidderSqliteFileName = "bidder.db?_journal_mode=WAL"
 
 type SqliteDatabase struct {
 	sqlDB               *sqlx.DB
@@ -21,13 +25,39 @@ type SqliteDatabase struct {
 }
 
 func NewDatabase(path string) (*SqliteDatabase, error) {
+	db, err := openDatabase(path, sqliteFileName, schemaList)
+	if err != nil {
+		return nil, err
+	}
+	return &SqliteDatabase{
+		sqlDB:               db,
+		currentTableVersion: -1,
+	}, nil
+}
+
+// BidderDatabase persists the state of the auto bidder, in its own file with its own schema versions,
+// as the bidder and the auctioneer run separately.
+type BidderDatabase struct {
+	sqlDB *sqlx.DB
+	lock  sync.Mutex
+}
+
+func NewBidderDatabase(path string) (*BidderDatabase, error) {
+	db, err := openDatabase(path, bidderSqliteFileName, bidderSchemaList)
+	if err != nil {
+		return nil, err
+	}
+	return &BidderDatabase{sqlDB: db}, nil
+}
+
+func openDatabase(path string, fileName string, schemaList []string) (*sqlx.DB, error) {
 	//#nosec G304
 	if _, err := os.Stat(path); err != nil {
 		if err = os.MkdirAll(path, fs.ModeDir); err != nil {
 			return nil, err
 		}
 	}
-	filePath := filepath.Join(path, sqliteFileName)
+	filePath := filepath.Join(path, fileName)
 	db, err := sqlx.Open("sqlite3", filePath)
 	if err != nil {
 		return nil, err
@@ -36,10 +66,7 @@ func NewDatabase(path string) (*SqliteDatabase, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &SqliteDatabase{
-		sqlDB:               db,
-		currentTableVersion: -1,
-	}, nil
+	return db, nil
 }
 
 func dbInit(db *sqlx.DB, schemaList []string) error {
@@ -166,3 +193,81 @@ func (d *SqliteDatabase) DeleteBids(round uint64) error {
 	_, err := d.sqlDB.Exec(query, round)
 	return err
 }
+
+func (d *SqliteDatabase) InsertAuctionResult(r *SqliteDatabaseAuctionResult) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	query := `INSERT OR REPLACE INTO AuctionResults (
+        Round, ChainId, AuctionContractAddress, BidCount, ReservePrice, FirstBidder, FirstExpressLaneController,
+        FirstAmount, SecondBidder, SecondAmount, ResolutionTxHash, ResolvedAt
+    ) VALUES (
+        :Round, :ChainId, :AuctionContractAddress, :BidCount, :ReservePrice, :FirstBidder, :FirstExpressLaneController,
+        :FirstAmount, :SecondBidder, :SecondAmount, :ResolutionTxHash, :ResolvedAt
+    )`
+	_, err := d.sqlDB.NamedExec(query, r)
+	return err
+}
+
+// GetAuctionResults returns the results of the auctions for rounds in [fromRound, toRound], in ascending order.
+func (d *SqliteDatabase) GetAuctionResults(fromRound, toRound uint64, limit int) ([]*SqliteDatabaseAuctionResult, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var results []*SqliteDatabaseAuctionResult
+	query := `SELECT * FROM AuctionResults WHERE Round >= ? AND Round <= ? ORDER BY Round ASC LIMIT ?`
+	if err := d.sqlDB.Select(&results, query, fromRound, toRound, limit); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetBidderWins returns the results of the auctions the bidder won for rounds in [fromRound, toRound], in ascending order.
+func (d *SqliteDatabase) GetBidderWins(bidder common.Address, fromRound, toRound uint64, limit int) ([]*SqliteDatabaseAuctionResult, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var results []*SqliteDatabaseAuctionResult
+	query := `SELECT * FROM AuctionResults WHERE FirstBidder = ? AND Round >= ? AND Round <= ? ORDER BY Round ASC LIMIT ?`
+	if err := d.sqlDB.Select(&results, query, bidder.Hex(), fromRound, toRound, limit); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetBidderBids returns the bids of the bidder for rounds in [fromRound, toRound], in ascending order.
+// Bids that were already archived to S3 are no longer in the database. A bidder can bid several times per
+// round, so the number of rounds is what callers have to limit.
+func (d *SqliteDatabase) GetBidderBids(bidder common.Address, fromRound, toRound uint64) ([]*SqliteDatabaseBid, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var bids []*SqliteDatabaseBid
+	query := `SELECT * FROM Bids WHERE Bidder = ? AND Round >= ? AND Round <= ? ORDER BY Round ASC, Id ASC`
+	if err := d.sqlDB.Select(&bids, query, bidder.Hex(), fromRound, toRound); err != nil {
+		return nil, err
+	}
+	return bids, nil
+}
+
+// DailySpending returns the amount the auto bidder committed to bids on the given UTC day, or nil if
+// nothing was recorded for it.
+func (d *BidderDatabase) DailySpending(day string) (*big.Int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var spent []string
+	if err := d.sqlDB.Select(&spent, "SELECT Spent FROM BidderSpending WHERE Day = ?", day); err != nil {
+		return nil, err
+	}
+	if len(spent) == 0 {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(spent[0], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid spending %q recorded for %s", spent[0], day)
+	}
+	return amount, nil
+}
+
+func (d *BidderDatabase) SetDailySpending(day string, spent *big.Int) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, err := d.sqlDB.Exec("INSERT OR REPLACE INTO BidderSpending (Day, Spent) VALUES (?, ?)", day, spent.String())
+	return err
+}
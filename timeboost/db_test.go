@@ -131,3 +131,31 @@ func TestDeleteBidsLowerThanRound(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestBidderDatabase(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// the auctioneer's database doesn't share the bidder's tables
+	auctioneerDb, err := NewDatabase(dir)
+	require.NoError(t, err)
+	_, err = auctioneerDb.sqlDB.Exec("SELECT * FROM BidderSpending")
+	require.Error(t, err)
+
+	db, err := NewBidderDatabase(dir)
+	require.NoError(t, err)
+	spent, err := db.DailySpending("2024-01-01")
+	require.NoError(t, err)
+	require.Nil(t, spent)
+	require.NoError(t, db.SetDailySpending("2024-01-01", big.NewInt(100)))
+	require.NoError(t, db.SetDailySpending("2024-01-01", big.NewInt(150)))
+	spent, err = db.DailySpending("2024-01-01")
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(150), spent)
+
+	// reopening keeps the spending and doesn't rerun the schema
+	db, err = NewBidderDatabase(dir)
+	require.NoError(t, err)
+	spent, err = db.DailySpending("2024-01-01")
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(150), spent)
+}
@@ -21,5 +21,32 @@ CREATE TABLE IF NOT EXISTS Bids (
 );
 CREATE INDEX idx_bids_round ON Bids(Round);
 `
-	schemaList = []string{version1}
+	version2 = `
+CREATE TABLE IF NOT EXISTS AuctionResults (
+    Round INTEGER NOT NULL PRIMARY KEY,
+    ChainId TEXT NOT NULL,
+    AuctionContractAddress TEXT NOT NULL,
+    BidCount INTEGER NOT NULL,
+    ReservePrice TEXT NOT NULL,
+    FirstBidder TEXT NOT NULL DEFAULT '',
+    FirstExpressLaneController TEXT NOT NULL DEFAULT '',
+    FirstAmount TEXT NOT NULL DEFAULT '',
+    SecondBidder TEXT NOT NULL DEFAULT '',
+    SecondAmount TEXT NOT NULL DEFAULT '',
+    ResolutionTxHash TEXT NOT NULL DEFAULT '',
+    ResolvedAt INTEGER NOT NULL
+);
+CREATE INDEX idx_auction_results_first_bidder ON AuctionResults(FirstBidder, Round);
+CREATE INDEX idx_bids_bidder_round ON Bids(Bidder, Round);
+`
+	schemaList = []string{version1, version2}
+
+	// the auto bidder's database
+	bidderVersion1 = `
+CREATE TABLE IF NOT EXISTS BidderSpending (
+    Day TEXT NOT NULL PRIMARY KEY,
+    Spent TEXT NOT NULL
+);
+`
+	bidderSchemaList = []string{bidderVersion1}
 )
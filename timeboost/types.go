@@ -282,3 +282,18 @@ type SqliteDatabaseBid struct {
 	Amount                 string `db:"Amount"`
 	Signature              string `db:"Signature"`
 }
+
+type SqliteDatabaseAuctionResult struct {
+	Round                      uint64 `db:"Round"`
+	ChainId                    string `db:"ChainId"`
+	AuctionContractAddress     string `db:"AuctionContractAddress"`
+	BidCount                   uint64 `db:"BidCount"`
+	ReservePrice               string `db:"ReservePrice"`
+	FirstBidder                string `db:"FirstBidder"`
+	FirstExpressLaneController string `db:"FirstExpressLaneController"`
+	FirstAmount                string `db:"FirstAmount"`
+	SecondBidder               string `db:"SecondBidder"`
+	SecondAmount               string `db:"SecondAmount"`
+	ResolutionTxHash           string `db:"ResolutionTxHash"`
+	ResolvedAt                 int64  `db:"ResolvedAt"`
+}
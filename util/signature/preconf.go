@@ -0,0 +1,75 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package signature
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var preconfDomain = crypto.Keccak256Hash([]byte("Arbitrum Nitro sequencer preconfirmation"))
+
+// Preconf is a sequencer's signed promise that a transaction was sequenced into a block
+// at a given message index. It can be checked later against the feed and the posted batch.
+type Preconf struct {
+	ChainId      hexutil.Uint64 `json:"chainId"`
+	TxHash       common.Hash    `json:"txHash"`
+	MessageIndex hexutil.Uint64 `json:"messageIndex"`
+	BlockHash    common.Hash    `json:"blockHash"`
+	// The timestamp of the block the transaction was sequenced in
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// SigningHash is the hash the sequencer signs, which commits to every field but the signature.
+func (p *Preconf) SigningHash() common.Hash {
+	return crypto.Keccak256Hash(
+		preconfDomain.Bytes(),
+		binary.BigEndian.AppendUint64(nil, uint64(p.ChainId)),
+		p.TxHash.Bytes(),
+		binary.BigEndian.AppendUint64(nil, uint64(p.MessageIndex)),
+		p.BlockHash.Bytes(),
+		binary.BigEndian.AppendUint64(nil, uint64(p.Timestamp)),
+	)
+}
+
+// Sign sets the preconf's signature using the given signer.
+func (p *Preconf) Sign(signer DataSignerFunc) error {
+	sig, err := signer(p.SigningHash().Bytes())
+	if err != nil {
+		return fmt.Errorf("error signing preconf: %w", err)
+	}
+	p.Signature = sig
+	return nil
+}
+
+// Signer recovers the address that signed the preconf.
+func (p *Preconf) Signer() (common.Address, error) {
+	if len(p.Signature) == 0 {
+		return common.Address{}, ErrMissingSignature
+	}
+	pubKey, err := crypto.SigToPub(p.SigningHash().Bytes(), p.Signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrSignatureNotVerified, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// VerifyPreconf checks the preconf was signed by an address the verifier accepts.
+// Unlike feed messages, a preconf without a signature is never accepted.
+func (v *Verifier) VerifyPreconf(ctx context.Context, preconf *Preconf) error {
+	if preconf == nil {
+		return errors.New("missing preconf")
+	}
+	if len(preconf.Signature) == 0 {
+		return ErrMissingSignature
+	}
+	return v.VerifyHash(ctx, preconf.Signature, preconf.SigningHash())
+}
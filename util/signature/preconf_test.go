@@ -0,0 +1,70 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package signature
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/contracts"
+)
+
+func TestPreconf(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	signingAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	preconf := &Preconf{
+		ChainId:      412346,
+		TxHash:       common.HexToHash("0x01"),
+		MessageIndex: 42,
+		BlockHash:    common.HexToHash("0x02"),
+		Timestamp:    1700000000,
+	}
+	Require(t, preconf.Sign(DataSignerFromPrivateKey(privateKey)))
+	signer, err := preconf.Signer()
+	Require(t, err)
+	if signer != signingAddr {
+		t.Fatal("recovered signer", signer, "expected", signingAddr)
+	}
+
+	config := TestingFeedVerifierConfig
+	config.AcceptSequencer = true
+	verifier, err := NewVerifier(&config, contracts.NewMockAddressVerifier(signingAddr))
+	Require(t, err)
+	Require(t, verifier.VerifyPreconf(ctx, preconf))
+
+	tampered := *preconf
+	tampered.MessageIndex++
+	if err := verifier.VerifyPreconf(ctx, &tampered); !errors.Is(err, ErrSignatureNotVerified) {
+		t.Error("unexpected error verifying tampered preconf", err)
+	}
+
+	// Preconfs must be signed even if feed signatures are optional
+	config.Dangerous.AcceptMissing = true
+	verifier, err = NewVerifier(&config, nil)
+	Require(t, err)
+	unsigned := *preconf
+	unsigned.Signature = nil
+	if err := verifier.VerifyPreconf(ctx, &unsigned); !errors.Is(err, ErrMissingSignature) {
+		t.Error("unexpected error verifying unsigned preconf", err)
+	}
+
+	// Nodes checking preconfs only accept the configured signers
+	verifier, err = NewVerifier(&VerifierConfig{AllowedAddresses: []string{common.HexToAddress("0x03").Hex()}}, nil)
+	Require(t, err)
+	if err := verifier.VerifyPreconf(ctx, preconf); !errors.Is(err, ErrSignerNotApproved) {
+		t.Error("unexpected error verifying preconf of another signer", err)
+	}
+	verifier, err = NewVerifier(&VerifierConfig{AllowedAddresses: []string{signingAddr.Hex()}}, nil)
+	Require(t, err)
+	Require(t, verifier.VerifyPreconf(ctx, preconf))
+}
@@ -0,0 +1,140 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+var overloadedRetryDelay = time.Second
+
+// ValidationPool spreads validations over spawners supporting the same stylus archs, launching each
+// on the spawner with the most room. Saturated validation servers report no room, so work is routed
+// around them, and validations they refuse are relaunched on the spawner with the most room then. The
+// pool doesn't own its spawners: starting and stopping it only starts and stops its relaunching threads.
+type ValidationPool struct {
+	stopwaiter.StopWaiter
+	spawners []validator.ValidationSpawner
+}
+
+func NewValidationPool(spawners []validator.ValidationSpawner) *ValidationPool {
+	return &ValidationPool{spawners: spawners}
+}
+
+func (p *ValidationPool) pick(moduleRoot common.Hash) validator.ValidationSpawner {
+	var chosen validator.ValidationSpawner
+	chosenRoom := -1
+	for _, spawner := range p.spawners {
+		if !validator.SpawnerSupportsModule(spawner, moduleRoot) {
+			continue
+		}
+		if room := spawner.Room(); room > chosenRoom {
+			chosen = spawner
+			chosenRoom = room
+		}
+	}
+	if chosen == nil {
+		return p.spawners[0]
+	}
+	return chosen
+}
+
+func (p *ValidationPool) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return p.launch(moduleRoot, func(spawner validator.ValidationSpawner) validator.ValidationRun {
+		return spawner.Launch(entry, moduleRoot)
+	})
+}
+
+func (p *ValidationPool) LaunchWithPriority(entry *validator.ValidationInput, moduleRoot common.Hash, priority validator.Priority) validator.ValidationRun {
+	return p.launch(moduleRoot, func(spawner validator.ValidationSpawner) validator.ValidationRun {
+		return validator.LaunchWithPriority(spawner, entry, moduleRoot, priority)
+	})
+}
+
+// launch relaunches the validation while validation servers refuse it for being overloaded, waiting
+// before each retry if every spawner is saturated. Other errors are returned as is.
+func (p *ValidationPool) launch(moduleRoot common.Hash, launch func(validator.ValidationSpawner) validator.ValidationRun) validator.ValidationRun {
+	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](p, func(ctx context.Context) (validator.GoGlobalState, error) {
+		for {
+			spawner := p.pick(moduleRoot)
+			run := launch(spawner)
+			res, err := run.Await(ctx)
+			if err == nil {
+				return res, nil
+			}
+			if !server_api.IsOverloaded(err) || ctx.Err() != nil {
+				run.Cancel()
+				return res, err
+			}
+			log.Debug("validation server overloaded, relaunching validation", "name", spawner.Name(), "err", err)
+			if p.pick(moduleRoot).Room() > 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return validator.GoGlobalState{}, ctx.Err()
+			case <-time.After(overloadedRetryDelay):
+			}
+		}
+	})
+	return server_common.NewValRun(promise, moduleRoot)
+}
+
+func (p *ValidationPool) WasmModuleRoots() ([]common.Hash, error) {
+	var roots []common.Hash
+	seen := make(map[common.Hash]bool)
+	for _, spawner := range p.spawners {
+		spawnerRoots, err := spawner.WasmModuleRoots()
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range spawnerRoots {
+			if !seen[root] {
+				seen[root] = true
+				roots = append(roots, root)
+			}
+		}
+	}
+	return roots, nil
+}
+
+func (p *ValidationPool) Start(ctx context.Context) error {
+	p.StopWaiter.Start(ctx, p)
+	return nil
+}
+
+func (p *ValidationPool) Stop() {
+	p.StopWaiter.StopAndWait()
+}
+
+func (p *ValidationPool) Name() string {
+	names := make([]string, 0, len(p.spawners))
+	for _, spawner := range p.spawners {
+		names = append(names, spawner.Name())
+	}
+	return "pool(" + strings.Join(names, ",") + ")"
+}
+
+func (p *ValidationPool) StylusArchs() []ethdb.WasmTarget {
+	return p.spawners[0].StylusArchs()
+}
+
+func (p *ValidationPool) Room() int {
+	room := 0
+	for _, spawner := range p.spawners {
+		room += spawner.Room()
+	}
+	return room
+}
@@ -0,0 +1,104 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+// overloadedSpawner refuses its first validations like an overloaded validation server, reporting
+// no room while it's saturated.
+type overloadedSpawner struct {
+	name      string
+	room      int
+	refusals  int32
+	err       error
+	launches  atomic.Int32
+	saturated atomic.Bool
+}
+
+func (s *overloadedSpawner) Launch(_ *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	var res validator.GoGlobalState
+	var err error
+	if s.launches.Add(1) <= s.refusals {
+		s.saturated.Store(true)
+		err = &server_api.OverloadedError{Reason: "test"}
+	} else if s.err != nil {
+		err = s.err
+	} else {
+		res = validator.GoGlobalState{Batch: 1}
+	}
+	return server_common.NewValRun(containers.NewReadyPromise(res, err), moduleRoot)
+}
+
+func (s *overloadedSpawner) WasmModuleRoots() ([]common.Hash, error) { return []common.Hash{{}}, nil }
+func (s *overloadedSpawner) Start(context.Context) error             { return nil }
+func (s *overloadedSpawner) Stop()                                   {}
+func (s *overloadedSpawner) Name() string                            { return s.name }
+func (s *overloadedSpawner) StylusArchs() []ethdb.WasmTarget         { return nil }
+
+func (s *overloadedSpawner) Room() int {
+	if s.saturated.Load() {
+		return 0
+	}
+	return s.room
+}
+
+func TestValidationPoolOverloaded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	oldDelay := overloadedRetryDelay
+	overloadedRetryDelay = 10 * time.Millisecond
+	defer func() { overloadedRetryDelay = oldDelay }()
+
+	// a refused validation is relaunched on the spawner with the most room left
+	saturated := &overloadedSpawner{name: "saturated", room: 4, refusals: 1}
+	available := &overloadedSpawner{name: "available", room: 2}
+	pool := NewValidationPool([]validator.ValidationSpawner{saturated, available})
+	require.NoError(t, pool.Start(ctx))
+	defer pool.Stop()
+	res, err := pool.Launch(&validator.ValidationInput{}, common.Hash{}).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Batch)
+	require.Equal(t, int32(1), saturated.launches.Load())
+	require.Equal(t, int32(1), available.launches.Load())
+
+	// a single saturated spawner is retried until it accepts the validation
+	single := &overloadedSpawner{name: "single", room: 2, refusals: 2}
+	pool = NewValidationPool([]validator.ValidationSpawner{single})
+	require.NoError(t, pool.Start(ctx))
+	defer pool.Stop()
+	res, err = pool.LaunchWithPriority(&validator.ValidationInput{}, common.Hash{}, validator.PriorityCritical).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.Batch)
+	require.Equal(t, int32(3), single.launches.Load())
+
+	// other errors aren't retried
+	failing := &overloadedSpawner{name: "failing", room: 2, err: errors.New("validation failed")}
+	pool = NewValidationPool([]validator.ValidationSpawner{failing})
+	require.NoError(t, pool.Start(ctx))
+	_, err = pool.Launch(&validator.ValidationInput{}, common.Hash{}).Await(ctx)
+	require.ErrorIs(t, err, failing.err)
+	require.Equal(t, int32(1), failing.launches.Load())
+
+	// a stopped pool doesn't launch validations anymore
+	pool.Stop()
+	_, err = pool.Launch(&validator.ValidationInput{}, common.Hash{}).Await(ctx)
+	require.Error(t, err)
+	require.Equal(t, int32(1), failing.launches.Load())
+}
@@ -50,10 +50,18 @@ var DefaultValidationClientConfig = ValidationClientConfig{
 	Room:           2,
 	RedisURL:       "",
 	StylusArchs:    []string{string(rawdb.TargetWavm)},
-	ProducerConfig: pubsub.DefaultProducerConfig,
+	ProducerConfig: defaultProducerConfig(),
 	CreateStreams:  true,
 }
 
+// defaultProducerConfig limits the validations each validator keeps in the streams, so validators sharing
+// validation servers each get a share of them even while one of them is catching up.
+func defaultProducerConfig() pubsub.ProducerConfig {
+	config := pubsub.DefaultProducerConfig
+	config.MaxInFlight = 64
+	return config
+}
+
 var TestValidationClientConfig = ValidationClientConfig{
 	Name:           "test redis validation client",
 	Room:           2,
@@ -70,7 +78,7 @@ func ValidationClientConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".redis-url", DefaultValidationClientConfig.RedisURL, "redis url")
 	f.String(prefix+".stream-prefix", DefaultValidationClientConfig.StreamPrefix, "prefix for stream name")
 	f.StringSlice(prefix+".stylus-archs", DefaultValidationClientConfig.StylusArchs, "archs required for stylus workers")
-	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f)
+	pubsub.ProducerAddConfigAddOptions(prefix+".producer-config", f, &DefaultValidationClientConfig.ProducerConfig)
 	f.Bool(prefix+".create-streams", DefaultValidationClientConfig.CreateStreams, "create redis streams if it does not exist")
 }
 
@@ -105,8 +113,10 @@ func NewValidationClient(cfg *ValidationClientConfig) (*ValidationClient, error)
 func (c *ValidationClient) Initialize(ctx context.Context, moduleRoots []common.Hash) error {
 	for _, mr := range moduleRoots {
 		if c.config.CreateStreams {
-			if err := pubsub.CreateStream(ctx, server_api.RedisStreamForRoot(c.config.StreamPrefix, mr), c.redisClient); err != nil {
-				return fmt.Errorf("creating redis stream: %w", err)
+			for _, stream := range pubsub.PriorityStreams(server_api.RedisStreamForRoot(c.config.StreamPrefix, mr), c.config.ProducerConfig.PriorityLevels) {
+				if err := pubsub.CreateStream(ctx, stream, c.redisClient); err != nil {
+					return fmt.Errorf("creating redis stream: %w", err)
+				}
 			}
 		}
 		if _, exists := c.producers[mr]; exists {
@@ -131,6 +141,10 @@ func (c *ValidationClient) WasmModuleRoots() ([]common.Hash, error) {
 }
 
 func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return c.LaunchWithPriority(entry, moduleRoot, validator.PriorityRoutine)
+}
+
+func (c *ValidationClient) LaunchWithPriority(entry *validator.ValidationInput, moduleRoot common.Hash, priority validator.Priority) validator.ValidationRun {
 	c.room.Add(-1)
 	defer c.room.Add(1)
 	producer, found := c.producers[moduleRoot]
@@ -138,7 +152,7 @@ func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot c
 		errPromise := containers.NewReadyPromise(validator.GoGlobalState{}, fmt.Errorf("no validation is configured for wasm root %v", moduleRoot))
 		return server_common.NewValRun(errPromise, moduleRoot)
 	}
-	promise, err := producer.Produce(c.GetContext(), entry)
+	promise, err := producer.ProduceWithPriority(c.GetContext(), entry, uint8(priority))
 	if err != nil {
 		errPromise := containers.NewReadyPromise(validator.GoGlobalState{}, fmt.Errorf("error producing input: %w", err))
 		return server_common.NewValRun(errPromise, moduleRoot)
@@ -0,0 +1,72 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package resultcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type Config struct {
+	Enable         bool          `koanf:"enable"`
+	RedisURL       string        `koanf:"redis-url"`
+	Directory      string        `koanf:"directory"`
+	Expiry         time.Duration `koanf:"expiry"`
+	SigningKey     string        `koanf:"signing-key"`
+	AllowedSigners []string      `koanf:"allowed-signers"`
+	RecheckRate    float64       `koanf:"recheck-rate" reload:"hot"`
+}
+
+var DefaultConfig = Config{
+	Enable:         false,
+	RedisURL:       "",
+	Directory:      "",
+	Expiry:         time.Hour * 24 * 7,
+	SigningKey:     "",
+	AllowedSigners: []string{},
+	RecheckRate:    0,
+}
+
+func ConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultConfig.Enable, "reuse validation results of inputs already validated by this or other trusted validators")
+	f.String(prefix+".redis-url", DefaultConfig.RedisURL, "url of a redis shared by the validators to store results in")
+	f.String(prefix+".directory", DefaultConfig.Directory, "directory of a local pebble database to store results in, if no redis url is set")
+	f.Duration(prefix+".expiry", DefaultConfig.Expiry, "how long results are kept in redis (0 = forever)")
+	f.String(prefix+".signing-key", DefaultConfig.SigningKey, "hex private key to sign the results stored by this validator with (empty = read only)")
+	f.StringSlice(prefix+".allowed-signers", DefaultConfig.AllowedSigners, "addresses of the other validators whose stored results are trusted")
+	f.Float64(prefix+".recheck-rate", DefaultConfig.RecheckRate, "fraction of cached results that are validated again and compared, to detect a poisoned cache")
+}
+
+func (c *Config) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if (c.RedisURL == "") == (c.Directory == "") {
+		return errors.New("exactly one of redis-url and directory must be set")
+	}
+	if c.SigningKey != "" {
+		if _, err := crypto.HexToECDSA(c.SigningKey); err != nil {
+			return fmt.Errorf("invalid signing-key: %w", err)
+		}
+	} else if len(c.AllowedSigners) == 0 {
+		return errors.New("either signing-key or allowed-signers must be set")
+	}
+	for _, signer := range c.AllowedSigners {
+		if !common.IsHexAddress(signer) {
+			return fmt.Errorf("invalid allowed-signers address: %s", signer)
+		}
+	}
+	if c.RecheckRate < 0 || c.RecheckRate > 1 {
+		return fmt.Errorf("recheck-rate must be between 0 and 1, got %v", c.RecheckRate)
+	}
+	return nil
+}
+
+type ConfigFetcher func() *Config
@@ -0,0 +1,285 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package resultcache shares validation results between validators, so an input validated by one of
+// them doesn't have to be validated again by the others. Results are keyed by module root and a hash of
+// the input, and signed by the validator that produced them.
+package resultcache
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+var (
+	hitCounter      = metrics.NewRegisteredCounter("arb/validator/resultcache/hit", nil)
+	missCounter     = metrics.NewRegisteredCounter("arb/validator/resultcache/miss", nil)
+	rejectedCounter = metrics.NewRegisteredCounter("arb/validator/resultcache/rejected", nil)
+	mismatchCounter = metrics.NewRegisteredCounter("arb/validator/resultcache/mismatch", nil)
+)
+
+var (
+	ErrResultMismatch    = errors.New("cached validation result doesn't match validation")
+	ErrSignerQuarantined = errors.New("validation result signer is quarantined")
+)
+
+var (
+	resultKeyPrefix  = []byte("validation-result.")
+	signedDataPrefix = []byte("Validation result:")
+)
+
+// InputHash hashes everything in the input that the end state depends on. The Id is only used to track
+// the input. The compiled user wasms are included as the validator runs them as given, so inputs for
+// other stylus architectures hash differently.
+func InputHash(input *validator.ValidationInput) common.Hash {
+	hasher := crypto.NewKeccakState()
+	writeUint64 := func(x uint64) {
+		_, _ = hasher.Write(binary.BigEndian.AppendUint64(nil, x))
+	}
+	writeBytes := func(data []byte) {
+		// #nosec G115
+		writeUint64(uint64(len(data)))
+		_, _ = hasher.Write(data)
+	}
+	writeBool := func(b bool) {
+		if b {
+			writeUint64(1)
+		} else {
+			writeUint64(0)
+		}
+	}
+	_, _ = hasher.Write(input.StartState.Hash().Bytes())
+	writeBool(input.HasDelayedMsg)
+	writeUint64(input.DelayedMsgNr)
+	writeBytes(input.DelayedMsg)
+	writeBool(input.DebugChain)
+	// #nosec G115
+	writeUint64(uint64(len(input.BatchInfo)))
+	for _, batch := range input.BatchInfo {
+		writeUint64(batch.Number)
+		writeBytes(batch.Data)
+	}
+	preimageTypes := make([]arbutil.PreimageType, 0, len(input.Preimages))
+	for ty := range input.Preimages {
+		preimageTypes = append(preimageTypes, ty)
+	}
+	sort.Slice(preimageTypes, func(i, j int) bool { return preimageTypes[i] < preimageTypes[j] })
+	for _, ty := range preimageTypes {
+		preimages := input.Preimages[ty]
+		keys := make([]common.Hash, 0, len(preimages))
+		for key := range preimages {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Cmp(keys[j]) < 0 })
+		writeUint64(uint64(ty))
+		// #nosec G115
+		writeUint64(uint64(len(keys)))
+		for _, key := range keys {
+			_, _ = hasher.Write(key.Bytes())
+			writeBytes(preimages[key])
+		}
+	}
+	targets := make([]ethdb.WasmTarget, 0, len(input.UserWasms))
+	for target := range input.UserWasms {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	// #nosec G115
+	writeUint64(uint64(len(targets)))
+	for _, target := range targets {
+		wasms := input.UserWasms[target]
+		moduleHashes := make([]common.Hash, 0, len(wasms))
+		for moduleHash := range wasms {
+			moduleHashes = append(moduleHashes, moduleHash)
+		}
+		sort.Slice(moduleHashes, func(i, j int) bool { return moduleHashes[i].Cmp(moduleHashes[j]) < 0 })
+		writeBytes([]byte(target))
+		// #nosec G115
+		writeUint64(uint64(len(moduleHashes)))
+		for _, moduleHash := range moduleHashes {
+			_, _ = hasher.Write(moduleHash.Bytes())
+			writeBytes(wasms[moduleHash])
+		}
+	}
+	var hash common.Hash
+	_, _ = hasher.Read(hash[:])
+	return hash
+}
+
+// cachedResult is an end state as stored in the cache, along with the signature of the validator that
+// produced it over the module root, input hash and end state.
+type cachedResult struct {
+	End       validator.GoGlobalState
+	Signature []byte
+}
+
+func resultKey(moduleRoot common.Hash, inputHash common.Hash) []byte {
+	key := make([]byte, 0, len(resultKeyPrefix)+2*common.HashLength)
+	key = append(key, resultKeyPrefix...)
+	key = append(key, moduleRoot.Bytes()...)
+	return append(key, inputHash.Bytes()...)
+}
+
+func signedHash(moduleRoot common.Hash, inputHash common.Hash, end validator.GoGlobalState) common.Hash {
+	return crypto.Keccak256Hash(signedDataPrefix, moduleRoot.Bytes(), inputHash.Bytes(), end.Hash().Bytes())
+}
+
+type ResultCache struct {
+	stopwaiter.StopWaiter
+	config  ConfigFetcher
+	store   resultStore
+	signer  signature.DataSignerFunc
+	address common.Address
+	allowed map[common.Address]struct{}
+
+	// signers whose results didn't match a recheck, which are no longer trusted until restart
+	quarantineMutex sync.Mutex
+	quarantined     map[common.Address]struct{}
+}
+
+func NewResultCache(config ConfigFetcher) (*ResultCache, error) {
+	var store resultStore
+	var err error
+	if config().RedisURL != "" {
+		store, err = newRedisStore(config().RedisURL, config().Expiry)
+	} else {
+		store, err = newLocalStore(config().Directory)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening validation result cache: %w", err)
+	}
+	var privateKey *ecdsa.PrivateKey
+	if config().SigningKey != "" {
+		privateKey, err = crypto.HexToECDSA(config().SigningKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newResultCache(config, store, privateKey), nil
+}
+
+func newResultCache(config ConfigFetcher, store resultStore, privateKey *ecdsa.PrivateKey) *ResultCache {
+	c := &ResultCache{
+		config:      config,
+		store:       store,
+		allowed:     make(map[common.Address]struct{}),
+		quarantined: make(map[common.Address]struct{}),
+	}
+	for _, signer := range config().AllowedSigners {
+		c.allowed[common.HexToAddress(signer)] = struct{}{}
+	}
+	if privateKey != nil {
+		c.signer = signature.DataSignerFromPrivateKey(privateKey)
+		c.address = crypto.PubkeyToAddress(privateKey.PublicKey)
+		c.allowed[c.address] = struct{}{}
+	}
+	return c
+}
+
+func (c *ResultCache) Start(ctx context.Context) {
+	c.StopWaiter.Start(ctx, c)
+}
+
+func (c *ResultCache) StopAndWait() {
+	c.StopWaiter.StopAndWait()
+	if err := c.store.close(); err != nil {
+		log.Error("error closing validation result cache", "err", err)
+	}
+}
+
+// Get returns the cached end state for the input and who signed it, if a trusted validator has stored one.
+func (c *ResultCache) Get(ctx context.Context, moduleRoot common.Hash, inputHash common.Hash) (validator.GoGlobalState, common.Address, bool) {
+	data, found, err := c.store.get(ctx, resultKey(moduleRoot, inputHash))
+	if err != nil {
+		log.Warn("error reading validation result cache", "moduleRoot", moduleRoot, "inputHash", inputHash, "err", err)
+		missCounter.Inc(1)
+		return validator.GoGlobalState{}, common.Address{}, false
+	}
+	if !found {
+		missCounter.Inc(1)
+		return validator.GoGlobalState{}, common.Address{}, false
+	}
+	var result cachedResult
+	if err := rlp.DecodeBytes(data, &result); err != nil {
+		log.Warn("error decoding cached validation result", "moduleRoot", moduleRoot, "inputHash", inputHash, "err", err)
+		rejectedCounter.Inc(1)
+		return validator.GoGlobalState{}, common.Address{}, false
+	}
+	signer, err := c.verify(moduleRoot, inputHash, &result)
+	if err != nil {
+		log.Warn("rejected cached validation result", "moduleRoot", moduleRoot, "inputHash", inputHash, "signer", signer, "err", err)
+		rejectedCounter.Inc(1)
+		return validator.GoGlobalState{}, common.Address{}, false
+	}
+	hitCounter.Inc(1)
+	return result.End, signer, true
+}
+
+func (c *ResultCache) verify(moduleRoot common.Hash, inputHash common.Hash, result *cachedResult) (common.Address, error) {
+	if len(result.Signature) == 0 {
+		return common.Address{}, signature.ErrMissingSignature
+	}
+	pubKey, err := crypto.SigToPub(signedHash(moduleRoot, inputHash, result.End).Bytes(), result.Signature)
+	if err != nil {
+		return common.Address{}, signature.ErrSignatureNotVerified
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if _, ok := c.allowed[signer]; !ok {
+		return signer, signature.ErrSignerNotApproved
+	}
+	c.quarantineMutex.Lock()
+	defer c.quarantineMutex.Unlock()
+	if _, ok := c.quarantined[signer]; ok {
+		return signer, ErrSignerQuarantined
+	}
+	return signer, nil
+}
+
+// quarantine stops trusting the results of the signer, and evicts the entry that didn't match.
+func (c *ResultCache) quarantine(ctx context.Context, signer common.Address, moduleRoot common.Hash, inputHash common.Hash) {
+	c.quarantineMutex.Lock()
+	c.quarantined[signer] = struct{}{}
+	c.quarantineMutex.Unlock()
+	log.Error("quarantined validation result cache signer", "signer", signer)
+	if err := c.store.delete(ctx, resultKey(moduleRoot, inputHash)); err != nil {
+		log.Warn("error evicting mismatched validation result", "moduleRoot", moduleRoot, "inputHash", inputHash, "err", err)
+	}
+}
+
+// Put signs and stores the end state for the input. It's a no-op if the cache has no signing key.
+func (c *ResultCache) Put(ctx context.Context, moduleRoot common.Hash, inputHash common.Hash, end validator.GoGlobalState) error {
+	if c.signer == nil {
+		return nil
+	}
+	sig, err := c.signer(signedHash(moduleRoot, inputHash, end).Bytes())
+	if err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(&cachedResult{End: end, Signature: sig})
+	if err != nil {
+		return err
+	}
+	return c.store.put(ctx, resultKey(moduleRoot, inputHash), data)
+}
+
+// Address is the address results stored by this cache are signed with, if it has a signing key.
+func (c *ResultCache) Address() common.Address {
+	return c.address
+}
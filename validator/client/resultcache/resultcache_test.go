@@ -0,0 +1,148 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package resultcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+type mockSpawner struct {
+	end      validator.GoGlobalState
+	launched int
+}
+
+func (s *mockSpawner) Launch(_ *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	s.launched++
+	return server_common.NewValRun(containers.NewReadyPromise(s.end, nil), moduleRoot)
+}
+
+func (s *mockSpawner) WasmModuleRoots() ([]common.Hash, error) { return nil, nil }
+func (s *mockSpawner) Start(context.Context) error             { return nil }
+func (s *mockSpawner) Stop()                                   {}
+func (s *mockSpawner) Name() string                            { return "mock" }
+func (s *mockSpawner) StylusArchs() []ethdb.WasmTarget         { return nil }
+func (s *mockSpawner) Room() int                               { return 4 }
+
+func testInput(id uint64) *validator.ValidationInput {
+	return &validator.ValidationInput{
+		Id: id,
+		Preimages: map[arbutil.PreimageType]map[common.Hash][]byte{
+			arbutil.Keccak256PreimageType: {
+				crypto.Keccak256Hash([]byte{1}): {1},
+				crypto.Keccak256Hash([]byte{2}): {2},
+			},
+		},
+		BatchInfo:  []validator.BatchInfo{{Number: 3, Data: []byte{3}}},
+		StartState: validator.GoGlobalState{Batch: 3},
+	}
+}
+
+func testCache(t *testing.T, store resultStore, config *Config) *ResultCache {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	cache := newResultCache(func() *Config { return config }, store, key)
+	cache.Start(context.Background())
+	t.Cleanup(cache.StopOnly)
+	return cache
+}
+
+func TestInputHash(t *testing.T) {
+	hash := InputHash(testInput(1))
+	require.Equal(t, hash, InputHash(testInput(2)))
+
+	input := testInput(1)
+	input.Preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash([]byte{4})] = []byte{4}
+	require.NotEqual(t, hash, InputHash(input))
+
+	input = testInput(1)
+	input.BatchInfo[0].Data = []byte{4}
+	require.NotEqual(t, hash, InputHash(input))
+
+	input = testInput(1)
+	input.UserWasms = map[ethdb.WasmTarget]map[common.Hash][]byte{
+		rawdb.TargetWavm: {common.HexToHash("0x05"): {5}},
+	}
+	require.NotEqual(t, hash, InputHash(input))
+}
+
+func TestCachingSpawner(t *testing.T) {
+	ctx := context.Background()
+	moduleRoot := common.HexToHash("0x01")
+	end := validator.GoGlobalState{BlockHash: common.HexToHash("0x02"), Batch: 4}
+	store := &localStore{db: rawdb.NewMemoryDatabase()}
+	config := DefaultConfig
+	cache := testCache(t, store, &config)
+	inner := &mockSpawner{end: end}
+	spawner := cache.Wrap(inner)
+
+	res, err := spawner.Launch(testInput(1), moduleRoot).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, end, res)
+	require.Equal(t, 1, inner.launched)
+
+	// The same input at another position is served from the cache
+	res, err = spawner.Launch(testInput(2), moduleRoot).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, end, res)
+	require.Equal(t, 1, inner.launched)
+
+	// Under another module root it isn't
+	_, err = spawner.Launch(testInput(1), common.HexToHash("0x03")).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, inner.launched)
+
+	// Results signed by validators that aren't allowed are ignored
+	other := testCache(t, store, &config)
+	otherInner := &mockSpawner{end: end}
+	_, err = other.Wrap(otherInner).Launch(testInput(1), moduleRoot).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, otherInner.launched)
+
+	allowingConfig := DefaultConfig
+	allowingConfig.AllowedSigners = []string{other.Address().Hex()}
+	allowing := testCache(t, store, &allowingConfig)
+	_, signer, found := allowing.Get(ctx, moduleRoot, InputHash(testInput(1)))
+	require.True(t, found)
+	require.Equal(t, other.Address(), signer)
+
+	// Rechecking a poisoned result fails the validation, evicts the result and quarantines its signer
+	poisoned := end
+	poisoned.SendRoot = common.HexToHash("0x04")
+	require.NoError(t, other.Put(ctx, moduleRoot, InputHash(testInput(1)), poisoned))
+	allowingConfig.RecheckRate = 1
+	allowingInner := &mockSpawner{end: end}
+	allowingSpawner := allowing.Wrap(allowingInner)
+	_, err = allowingSpawner.Launch(testInput(1), moduleRoot).Await(ctx)
+	require.ErrorIs(t, err, ErrResultMismatch)
+	require.Equal(t, 1, allowingInner.launched)
+	_, _, found = allowing.Get(ctx, moduleRoot, InputHash(testInput(1)))
+	require.False(t, found)
+	require.NoError(t, other.Put(ctx, moduleRoot, InputHash(testInput(1)), end))
+	_, _, found = allowing.Get(ctx, moduleRoot, InputHash(testInput(1)))
+	require.False(t, found, "results of a quarantined signer are rejected")
+
+	// The retried validation stores its own result
+	res, err = allowingSpawner.Launch(testInput(1), moduleRoot).Await(ctx)
+	require.NoError(t, err)
+	require.Equal(t, end, res)
+	require.Equal(t, 2, allowingInner.launched)
+	cached, signer, found := allowing.Get(ctx, moduleRoot, InputHash(testInput(1)))
+	require.True(t, found)
+	require.Equal(t, end, cached)
+	require.Equal(t, allowing.Address(), signer)
+}
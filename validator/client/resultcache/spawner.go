@@ -0,0 +1,71 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package resultcache
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+// cachingSpawner returns cached results instead of launching validations for inputs that were already
+// validated, and caches the results of the validations it does launch. Everything but launching is
+// passed through to the wrapped spawner, including Start and Stop.
+type cachingSpawner struct {
+	validator.ValidationSpawner
+	cache *ResultCache
+}
+
+// Wrap returns a spawner using the cache in front of spawner. The cache must be started for launches
+// through it to succeed.
+func (c *ResultCache) Wrap(spawner validator.ValidationSpawner) validator.ValidationSpawner {
+	return &cachingSpawner{
+		ValidationSpawner: spawner,
+		cache:             c,
+	}
+}
+
+func (s *cachingSpawner) Launch(input *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return s.LaunchWithPriority(input, moduleRoot, validator.PriorityRoutine)
+}
+
+func (s *cachingSpawner) LaunchWithPriority(input *validator.ValidationInput, moduleRoot common.Hash, priority validator.Priority) validator.ValidationRun {
+	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](s.cache, func(ctx context.Context) (validator.GoGlobalState, error) {
+		inputHash := InputHash(input)
+		cached, signer, found := s.cache.Get(ctx, moduleRoot, inputHash)
+		// #nosec G404
+		if found && rand.Float64() >= s.cache.config().RecheckRate {
+			return cached, nil
+		}
+		run := validator.LaunchWithPriority(s.ValidationSpawner, input, moduleRoot, priority)
+		defer run.Cancel()
+		end, err := run.Await(ctx)
+		if err != nil {
+			return end, err
+		}
+		if found {
+			if end == cached {
+				return end, nil
+			}
+			// Neither result can be trusted until validated again, so the validation fails and is retried
+			// without the mismatched entry or the signer's other entries.
+			mismatchCounter.Inc(1)
+			log.Error("cached validation result doesn't match validation", "id", input.Id, "moduleRoot", moduleRoot, "inputHash", inputHash, "signer", signer, "cached", cached, "validated", end)
+			s.cache.quarantine(ctx, signer, moduleRoot, inputHash)
+			return end, fmt.Errorf("%w: signer %v cached %v, validated %v", ErrResultMismatch, signer, cached, end)
+		}
+		if err := s.cache.Put(ctx, moduleRoot, inputHash, end); err != nil {
+			log.Warn("error storing validation result", "id", input.Id, "moduleRoot", moduleRoot, "err", err)
+		}
+		return end, nil
+	})
+	return server_common.NewValRun(promise, moduleRoot)
+}
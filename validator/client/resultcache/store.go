@@ -0,0 +1,106 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package resultcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/util/redisutil"
+)
+
+// resultStore is where the cache keeps its signed entries. A missing entry isn't an error.
+type resultStore interface {
+	get(ctx context.Context, key []byte) ([]byte, bool, error)
+	put(ctx context.Context, key []byte, value []byte) error
+	delete(ctx context.Context, key []byte) error
+	close() error
+}
+
+type redisStore struct {
+	client redis.UniversalClient
+	expiry time.Duration
+}
+
+func newRedisStore(redisUrl string, expiry time.Duration) (*redisStore, error) {
+	client, err := redisutil.RedisClientFromURL(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("redis url is empty")
+	}
+	return &redisStore{client: client, expiry: expiry}, nil
+}
+
+func (s *redisStore) get(ctx context.Context, key []byte) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, string(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *redisStore) put(ctx context.Context, key []byte, value []byte) error {
+	return s.client.Set(ctx, string(key), value, s.expiry).Err()
+}
+
+func (s *redisStore) delete(ctx context.Context, key []byte) error {
+	return s.client.Del(ctx, string(key)).Err()
+}
+
+func (s *redisStore) close() error {
+	return s.client.Close()
+}
+
+type localStore struct {
+	db ethdb.Database
+}
+
+func newLocalStore(directory string) (*localStore, error) {
+	db, err := rawdb.Open(rawdb.OpenOptions{
+		Type:      "pebble",
+		Directory: directory,
+		Namespace: "validationresultcache/",
+		Cache:     16,
+		Handles:   16,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &localStore{db: db}, nil
+}
+
+func (s *localStore) get(_ context.Context, key []byte) ([]byte, bool, error) {
+	has, err := s.db.Has(key)
+	if err != nil || !has {
+		return nil, false, err
+	}
+	value, err := s.db.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *localStore) put(_ context.Context, key []byte, value []byte) error {
+	return s.db.Put(key, value)
+}
+
+func (s *localStore) delete(_ context.Context, key []byte) error {
+	return s.db.Delete(key)
+}
+
+func (s *localStore) close() error {
+	return s.db.Close()
+}
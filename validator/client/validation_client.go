@@ -26,12 +26,15 @@ import (
 	"github.com/offchainlabs/nitro/validator/server_common"
 )
 
+const capacityPollInterval = time.Second * 5
+
 type ValidationClient struct {
 	stopwaiter.StopWaiter
 	client          *rpcclient.RpcClient
 	name            string
 	stylusArchs     []ethdb.WasmTarget
 	room            atomic.Int32
+	saturated       atomic.Bool
 	wasmModuleRoots []common.Hash
 }
 
@@ -46,10 +49,15 @@ func NewValidationClient(config rpcclient.ClientConfigFetcher, stack *node.Node)
 func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
 	c.room.Add(-1)
 	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](c, func(ctx context.Context) (validator.GoGlobalState, error) {
+		defer c.room.Add(1)
 		input := server_api.ValidationInputToJson(entry)
 		var res validator.GoGlobalState
 		err := c.client.CallContext(ctx, &res, server_api.Namespace+"_validate", input, moduleRoot)
-		c.room.Add(1)
+		if server_api.IsOverloaded(err) {
+			// the server refused the validation without running it, it's retried by the ValidationPool
+			c.saturated.Store(true)
+			log.Debug("validation server overloaded", "name", c.name, "err", err)
+		}
 		return res, err
 	})
 	return server_common.NewValRun(promise, moduleRoot)
@@ -68,9 +76,7 @@ func (c *ValidationClient) Start(ctx context.Context) error {
 	}
 	var stylusArchs []ethdb.WasmTarget
 	if err := c.client.CallContext(ctx, &stylusArchs, server_api.Namespace+"_stylusArchs"); err != nil {
-		var rpcError rpc.Error
-		ok := errors.As(err, &rpcError)
-		if !ok || rpcError.ErrorCode() != -32601 {
+		if !isMethodNotFound(err) {
 			return fmt.Errorf("could not read stylus arch from server: %w", err)
 		}
 		stylusArchs = []ethdb.WasmTarget{ethdb.WasmTarget("pre-stylus")} // invalid, will fail if trying to validate block with stylus
@@ -107,6 +113,36 @@ func (c *ValidationClient) Start(ctx context.Context) error {
 	c.name = name
 	c.stylusArchs = stylusArchs
 	c.StopWaiter.Start(ctx, c)
+	if err := c.updateCapacity(ctx); err != nil {
+		if !isMethodNotFound(err) {
+			return fmt.Errorf("could not read capacity from server: %w", err)
+		}
+		log.Info("validation server doesn't report capacity", "name", name)
+	} else {
+		c.CallIteratively(func(ctx context.Context) time.Duration {
+			if err := c.updateCapacity(ctx); err != nil && ctx.Err() == nil {
+				log.Warn("could not read capacity from validation server", "name", c.name, "err", err)
+			}
+			return capacityPollInterval
+		})
+	}
+	return nil
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcError rpc.Error
+	return errors.As(err, &rpcError) && rpcError.ErrorCode() == -32601
+}
+
+func (c *ValidationClient) updateCapacity(ctx context.Context) error {
+	var capacity server_api.CapacityJson
+	if err := c.client.CallContext(ctx, &capacity, server_api.Namespace+"_capacity"); err != nil {
+		return err
+	}
+	if capacity.Saturated != c.saturated.Load() {
+		log.Info("validation server saturation changed", "name", c.name, "saturated", capacity.Saturated, "inFlight", capacity.InFlight, "queued", capacity.Queued, "memoryPressure", capacity.MemoryPressure)
+	}
+	c.saturated.Store(capacity.Saturated)
 	return nil
 }
 
@@ -135,7 +171,11 @@ func (c *ValidationClient) Name() string {
 	return c.name
 }
 
+// Room returns 0 while the server is saturated, so work is routed to other servers.
 func (c *ValidationClient) Room() int {
+	if c.saturated.Load() {
+		return 0
+	}
 	room32 := c.room.Load()
 	if room32 < 0 {
 		return 0
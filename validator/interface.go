@@ -19,6 +19,24 @@ type ValidationSpawner interface {
 	Room() int
 }
 
+// Priority orders validations sent to a shared pool of workers.
+type Priority uint8
+
+const (
+	// Forward validation of new blocks
+	PriorityRoutine Priority = iota
+	// Validation of blocks a staker is waiting for
+	PriorityLagging
+	// Validation a caller is waiting on, like the validateMessageNumber debug API. Challenges don't go
+	// through the validation streams, as they run the machines step by step on the execution spawners.
+	PriorityCritical
+)
+
+// PrioritizedSpawner is a spawner that can run some validations ahead of others.
+type PrioritizedSpawner interface {
+	LaunchWithPriority(entry *ValidationInput, moduleRoot common.Hash, priority Priority) ValidationRun
+}
+
 type ValidationRun interface {
 	containers.PromiseInterface[GoGlobalState]
 	WasmModuleRoot() common.Hash
@@ -0,0 +1,52 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package server_api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// OverloadedErrorCode is the json-rpc error code of validations refused by an overloaded server.
+// It's the "limit exceeded" code of EIP-1474.
+const OverloadedErrorCode = -32005
+
+// CapacityJson is the load of a validation server, for clients to route around saturated servers and
+// for autoscaling.
+type CapacityJson struct {
+	// The number of validations the server runs in parallel
+	Room int
+	// The number of validations launched and not finished, including queued ones
+	InFlight int
+	// The number of validations waiting for room
+	Queued int
+	// Whether free memory is below the server's limit
+	MemoryPressure bool
+	// Whether the server is refusing new validations
+	Saturated bool
+	// Estimated time for the validations in flight to finish
+	DrainTimeMillis int64
+}
+
+// OverloadedError is returned for validations refused by admission control. Clients should retry them
+// later or on another server.
+type OverloadedError struct {
+	Reason string
+}
+
+func (e *OverloadedError) Error() string {
+	return fmt.Sprintf("validation server overloaded: %s", e.Reason)
+}
+
+func (e *OverloadedError) ErrorCode() int {
+	return OverloadedErrorCode
+}
+
+// IsOverloaded returns whether the error is an OverloadedError, either directly or as received over rpc.
+func IsOverloaded(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == OverloadedErrorCode
+}
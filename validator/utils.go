@@ -18,3 +18,11 @@ func SpawnerSupportsModule(spawner ValidationSpawner, requested common.Hash) boo
 	}
 	return false
 }
+
+// LaunchWithPriority launches the validation with the priority if the spawner supports priorities.
+func LaunchWithPriority(spawner ValidationSpawner, entry *ValidationInput, moduleRoot common.Hash, priority Priority) ValidationRun {
+	if prioritized, ok := spawner.(PrioritizedSpawner); ok {
+		return prioritized.LaunchWithPriority(entry, moduleRoot, priority)
+	}
+	return spawner.Launch(entry, moduleRoot)
+}
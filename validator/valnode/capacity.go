@@ -0,0 +1,263 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package valnode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbnode/resourcemanager"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+var (
+	roomGauge           = metrics.NewRegisteredGauge("arb/validator/server/room", nil)
+	inFlightGauge       = metrics.NewRegisteredGauge("arb/validator/server/inflight", nil)
+	queuedGauge         = metrics.NewRegisteredGauge("arb/validator/server/queued", nil)
+	memoryPressureGauge = metrics.NewRegisteredGauge("arb/validator/server/memorypressure", nil)
+	drainTimeGauge      = metrics.NewRegisteredGauge("arb/validator/server/draintime", nil)
+	rejectedCounter     = metrics.NewRegisteredCounter("arb/validator/server/rejected", nil)
+)
+
+type CapacityConfig struct {
+	AdmissionControl bool          `koanf:"admission-control" reload:"hot"`
+	MaxQueued        int           `koanf:"max-queued" reload:"hot"`
+	MemoryFreeLimit  string        `koanf:"memory-free-limit"`
+	MetricsInterval  time.Duration `koanf:"metrics-interval"`
+}
+
+type CapacityConfigFetcher func() *CapacityConfig
+
+var DefaultCapacityConfig = CapacityConfig{
+	AdmissionControl: false,
+	MaxQueued:        16,
+	MemoryFreeLimit:  "default",
+	MetricsInterval:  time.Second * 5,
+}
+
+func CapacityConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".admission-control", DefaultCapacityConfig.AdmissionControl, "refuse validations over rpc with a retryable error when over max-queued or memory-free-limit")
+	f.Int(prefix+".max-queued", DefaultCapacityConfig.MaxQueued, "maximum number of validations waiting for room before refusing new ones")
+	f.String(prefix+".memory-free-limit", DefaultCapacityConfig.MemoryFreeLimit, "minimum free-memory limit under which the server reports memory pressure and refuses validations. Enabled by default as 1GB, to disable provide empty string")
+	f.Duration(prefix+".metrics-interval", DefaultCapacityConfig.MetricsInterval, "interval of updating capacity metrics")
+}
+
+func (c *CapacityConfig) memoryFreeLimit() (int, error) {
+	if c.MemoryFreeLimit == "default" {
+		return 1073741824, nil // 1GB
+	}
+	limit, err := resourcemanager.ParseMemLimit(c.MemoryFreeLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse validation config capacity.memory-free-limit string: %w", err)
+	}
+	return limit, nil
+}
+
+// CapacityTracker wraps a spawner to track the validations it runs, estimate when they'll be done,
+// and refuse new validations when the server is over budget. Starting and stopping it doesn't start or
+// stop the wrapped spawner.
+type CapacityTracker struct {
+	stopwaiter.StopWaiter
+	validator.ValidationSpawner
+	config        CapacityConfigFetcher
+	memoryChecker resourcemanager.LimitChecker
+
+	// the wrapped spawner comes first, then the ones passed to Track
+	spawnersMutex sync.Mutex
+	spawners      []*trackedSpawner
+	// exponential moving average of the validation durations, in nanoseconds
+	avgDuration atomic.Int64
+}
+
+func NewCapacityTracker(config CapacityConfigFetcher, spawner validator.ValidationSpawner) (*CapacityTracker, error) {
+	tracker := &CapacityTracker{
+		ValidationSpawner: spawner,
+		config:            config,
+	}
+	tracker.Track(spawner)
+	memoryFreeLimit := config().MemoryFreeLimit
+	if memoryFreeLimit != "" {
+		limit, err := config().memoryFreeLimit()
+		if err != nil {
+			return nil, err
+		}
+		checker, err := resourcemanager.NewCgroupsMemoryLimitCheckerIfSupported(limit)
+		if err != nil {
+			if memoryFreeLimit != "default" {
+				return nil, fmt.Errorf("failed to create memory limit checker, Cgroups V1 or V2 is unsupported")
+			}
+			log.Warn("Cgroups V1 or V2 is unsupported, memory-free-limit feature of validation capacity is disabled")
+		} else {
+			tracker.memoryChecker = checker
+		}
+	}
+	return tracker, nil
+}
+
+func (t *CapacityTracker) Start(ctx context.Context) error {
+	t.StopWaiter.Start(ctx, t)
+	t.CallIteratively(func(context.Context) time.Duration {
+		t.Capacity()
+		return t.config().MetricsInterval
+	})
+	return nil
+}
+
+func (t *CapacityTracker) Stop() {
+	t.StopWaiter.StopAndWait()
+}
+
+func (t *CapacityTracker) Launch(input *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return t.trackedSpawners()[0].Launch(input, moduleRoot)
+}
+
+// Track returns a spawner launching validations on the given spawner, tracked along with the ones
+// launched on the tracker. Validations only wait for room on the spawner they're launched on.
+func (t *CapacityTracker) Track(spawner validator.ValidationSpawner) validator.ValidationSpawner {
+	tracked := &trackedSpawner{ValidationSpawner: spawner, tracker: t}
+	t.spawnersMutex.Lock()
+	defer t.spawnersMutex.Unlock()
+	t.spawners = append(t.spawners, tracked)
+	return tracked
+}
+
+func (t *CapacityTracker) trackedSpawners() []*trackedSpawner {
+	t.spawnersMutex.Lock()
+	defer t.spawnersMutex.Unlock()
+	return t.spawners
+}
+
+type trackedSpawner struct {
+	validator.ValidationSpawner
+	tracker  *CapacityTracker
+	inFlight atomic.Int64
+}
+
+func (s *trackedSpawner) Launch(input *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return s.tracker.launch(s, input, moduleRoot)
+}
+
+// load returns the room of the spawner and the validations it runs. Spawners reporting no room still
+// run one validation at a time.
+func (s *trackedSpawner) load() (int, int) {
+	return max(s.Room(), 1), int(s.inFlight.Load())
+}
+
+func (t *CapacityTracker) launch(spawner *trackedSpawner, input *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	spawner.inFlight.Add(1)
+	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](t, func(ctx context.Context) (validator.GoGlobalState, error) {
+		defer spawner.inFlight.Add(-1)
+		start := time.Now()
+		run := spawner.ValidationSpawner.Launch(input, moduleRoot)
+		defer run.Cancel()
+		res, err := run.Await(ctx)
+		if err == nil {
+			t.recordDuration(time.Since(start))
+		}
+		return res, err
+	})
+	return server_common.NewValRun(promise, moduleRoot)
+}
+
+func (t *CapacityTracker) recordDuration(duration time.Duration) {
+	for {
+		old := t.avgDuration.Load()
+		updated := int64(duration)
+		if old != 0 {
+			updated = old - old/8 + updated/8
+		}
+		if t.avgDuration.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+func (t *CapacityTracker) memoryPressure() bool {
+	if t.memoryChecker == nil {
+		return false
+	}
+	exceeded, err := t.memoryChecker.IsLimitExceeded()
+	if err != nil {
+		log.Error("error checking if free-memory limit exceeded using MemoryFreeLimitChecker", "err", err)
+		return false
+	}
+	return exceeded
+}
+
+// load sums the room and validations of the tracked spawners, and the validations waiting for room on
+// them. It also returns the most rounds of validations a spawner has to run to be done.
+func (t *CapacityTracker) load() (room int, inFlight int, queued int, rounds int) {
+	for _, spawner := range t.trackedSpawners() {
+		spawnerRoom, spawnerInFlight := spawner.load()
+		room += spawnerRoom
+		inFlight += spawnerInFlight
+		queued += max(spawnerInFlight-spawnerRoom, 0)
+		rounds = max(rounds, (spawnerInFlight+spawnerRoom-1)/spawnerRoom)
+	}
+	return room, inFlight, queued, rounds
+}
+
+// overBudget returns whether a validation launched on the tracker would wait behind max-queued others.
+// Validations waiting for other tracked spawners don't hold it back.
+func (t *CapacityTracker) overBudget(config *CapacityConfig) bool {
+	room, inFlight := t.trackedSpawners()[0].load()
+	return inFlight-room >= config.MaxQueued
+}
+
+// Capacity returns the current load of the server and updates the capacity metrics.
+func (t *CapacityTracker) Capacity() server_api.CapacityJson {
+	config := t.config()
+	room, inFlight, queued, rounds := t.load()
+	memoryPressure := t.memoryPressure()
+	// every round of room validations takes about the average validation duration
+	drainTime := time.Duration(t.avgDuration.Load()) * time.Duration(rounds)
+	capacity := server_api.CapacityJson{
+		Room:            room,
+		InFlight:        inFlight,
+		Queued:          queued,
+		MemoryPressure:  memoryPressure,
+		Saturated:       config.AdmissionControl && (memoryPressure || t.overBudget(config)),
+		DrainTimeMillis: drainTime.Milliseconds(),
+	}
+	roomGauge.Update(int64(room))
+	inFlightGauge.Update(int64(inFlight))
+	queuedGauge.Update(int64(queued))
+	if memoryPressure {
+		memoryPressureGauge.Update(1)
+	} else {
+		memoryPressureGauge.Update(0)
+	}
+	drainTimeGauge.Update(capacity.DrainTimeMillis)
+	return capacity
+}
+
+// Admit returns an OverloadedError if admission control is enabled and the server is over budget.
+func (t *CapacityTracker) Admit() error {
+	config := t.config()
+	if !config.AdmissionControl {
+		return nil
+	}
+	if t.memoryPressure() {
+		rejectedCounter.Inc(1)
+		return &server_api.OverloadedError{Reason: "free memory below limit"}
+	}
+	if t.overBudget(config) {
+		rejectedCounter.Inc(1)
+		_, _, queued, _ := t.load()
+		return &server_api.OverloadedError{Reason: fmt.Sprintf("%d validations queued", queued)}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE
+
+package valnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+// blockingSpawner runs validations until they're released.
+type blockingSpawner struct {
+	room    int
+	release chan struct{}
+}
+
+func (s *blockingSpawner) Launch(_ *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	promise := containers.NewPromise[validator.GoGlobalState](nil)
+	go func() {
+		<-s.release
+		promise.Produce(validator.GoGlobalState{})
+	}()
+	return server_common.NewValRun(&promise, moduleRoot)
+}
+
+func (s *blockingSpawner) WasmModuleRoots() ([]common.Hash, error) { return nil, nil }
+func (s *blockingSpawner) Start(context.Context) error             { return nil }
+func (s *blockingSpawner) Stop()                                   {}
+func (s *blockingSpawner) Name() string                            { return "blocking" }
+func (s *blockingSpawner) StylusArchs() []ethdb.WasmTarget         { return nil }
+func (s *blockingSpawner) Room() int                               { return s.room }
+
+func TestCapacityTracker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := DefaultCapacityConfig
+	config.AdmissionControl = true
+	config.MaxQueued = 1
+	config.MemoryFreeLimit = ""
+	release := make(chan struct{})
+	spawner := &blockingSpawner{room: 2, release: release}
+	tracker, err := NewCapacityTracker(func() *CapacityConfig { return &config }, spawner)
+	require.NoError(t, err)
+	require.NoError(t, tracker.Start(ctx))
+	defer tracker.Stop()
+
+	// validations launched on tracked spawners, like the redis consumer's, count in the load but only
+	// wait for room on their own spawner
+	tracked := tracker.Track(&blockingSpawner{room: 1, release: release})
+	var runs []validator.ValidationRun
+	for i := 0; i < 3; i++ {
+		runs = append(runs, tracked.Launch(&validator.ValidationInput{}, common.Hash{}))
+	}
+	capacity := tracker.Capacity()
+	require.Equal(t, 3, capacity.Room)
+	require.Equal(t, 3, capacity.InFlight)
+	require.Equal(t, 2, capacity.Queued)
+	require.False(t, capacity.Saturated)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tracker.Admit())
+		runs = append(runs, tracker.Launch(&validator.ValidationInput{}, common.Hash{}))
+	}
+	capacity = tracker.Capacity()
+	require.Equal(t, 6, capacity.InFlight)
+	require.Equal(t, 3, capacity.Queued)
+	require.True(t, capacity.Saturated)
+	err = tracker.Admit()
+	require.Error(t, err)
+	require.True(t, server_api.IsOverloaded(err))
+
+	config.AdmissionControl = false
+	require.NoError(t, tracker.Admit())
+	require.False(t, tracker.Capacity().Saturated)
+	config.AdmissionControl = true
+
+	close(release)
+	for _, run := range runs {
+		_, err := run.Await(ctx)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 0, tracker.Capacity().InFlight)
+	require.NoError(t, tracker.Admit())
+}
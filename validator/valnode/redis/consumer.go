@@ -166,7 +166,7 @@ func (s *ValidationServer) Start(ctx_in context.Context) {
 					work.req.Ack()
 				} else {
 					log.Debug("done work", "thread", i, "workid", work.req.ID)
-					err := s.consumers[work.moduleRoot].SetResult(ctx, work.req.ID, res)
+					err := s.consumers[work.moduleRoot].SetResult(ctx, work.req, res)
 					// Even in error we close ackNotifier as there's no retry mechanism here and closing it will alow other consumers to autoclaim
 					work.req.Ack()
 					if err != nil {
@@ -199,7 +199,7 @@ type ValidationServerConfig struct {
 var DefaultValidationServerConfig = ValidationServerConfig{
 	RedisURL:       "",
 	StreamPrefix:   "",
-	ConsumerConfig: pubsub.DefaultConsumerConfig,
+	ConsumerConfig: defaultConsumerConfig(),
 	ModuleRoots:    []string{},
 	StreamTimeout:  10 * time.Minute,
 	Workers:        0,
@@ -216,8 +216,16 @@ var TestValidationServerConfig = ValidationServerConfig{
 	BufferReads:    true,
 }
 
+// defaultConsumerConfig dead-letters validations that keep crashing or stalling validation servers,
+// instead of letting them be retried forever.
+func defaultConsumerConfig() pubsub.ConsumerConfig {
+	config := pubsub.DefaultConsumerConfig
+	config.MaxDeliveries = 5
+	return config
+}
+
 func ValidationServerConfigAddOptions(prefix string, f *pflag.FlagSet) {
-	pubsub.ConsumerConfigAddOptions(prefix+".consumer-config", f)
+	pubsub.ConsumerConfigAddOptions(prefix+".consumer-config", f, &DefaultValidationServerConfig.ConsumerConfig)
 	f.StringSlice(prefix+".module-roots", nil, "Supported module root hashes")
 	f.String(prefix+".redis-url", DefaultValidationServerConfig.RedisURL, "url of redis server")
 	f.String(prefix+".stream-prefix", DefaultValidationServerConfig.StreamPrefix, "prefix for stream name")
@@ -21,7 +21,8 @@ import (
 )
 
 type ValidationServerAPI struct {
-	spawner validator.ValidationSpawner
+	spawner  validator.ValidationSpawner
+	capacity *CapacityTracker
 }
 
 func (a *ValidationServerAPI) Name() string {
@@ -33,6 +34,11 @@ func (a *ValidationServerAPI) Room() int {
 }
 
 func (a *ValidationServerAPI) Validate(ctx context.Context, entry *server_api.InputJSON, moduleRoot common.Hash) (validator.GoGlobalState, error) {
+	if a.capacity != nil {
+		if err := a.capacity.Admit(); err != nil {
+			return validator.GoGlobalState{}, err
+		}
+	}
 	valInput, err := server_api.ValidationInputFromJson(entry)
 	if err != nil {
 		return validator.GoGlobalState{}, err
@@ -49,8 +55,19 @@ func (a *ValidationServerAPI) StylusArchs() ([]ethdb.WasmTarget, error) {
 	return a.spawner.StylusArchs(), nil
 }
 
+// Capacity returns the load of the server. Servers without capacity tracking only report their room.
+func (a *ValidationServerAPI) Capacity() server_api.CapacityJson {
+	if a.capacity == nil {
+		return server_api.CapacityJson{Room: a.spawner.Room()}
+	}
+	return a.capacity.Capacity()
+}
+
+// NewValidationServerAPI creates the api of the spawner. If the spawner is a CapacityTracker, the api
+// reports its capacity and admits validations through it.
 func NewValidationServerAPI(spawner validator.ValidationSpawner) *ValidationServerAPI {
-	return &ValidationServerAPI{spawner}
+	capacity, _ := spawner.(*CapacityTracker)
+	return &ValidationServerAPI{spawner, capacity}
 }
 
 type execRunEntry struct {
@@ -42,6 +42,7 @@ type Config struct {
 	Arbitrator server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
 	Jit        server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
 	Wasm       WasmConfig                         `koanf:"wasm"`
+	Capacity   CapacityConfig                     `koanf:"capacity" reload:"hot"`
 }
 
 type ValidationConfigFetcher func() *Config
@@ -53,6 +54,7 @@ var DefaultValidationConfig = Config{
 	ApiPublic:  false,
 	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
 	Wasm:       DefaultWasmConfig,
+	Capacity:   DefaultCapacityConfig,
 }
 
 var TestValidationConfig = Config{
@@ -62,6 +64,7 @@ var TestValidationConfig = Config{
 	ApiPublic:  true,
 	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
 	Wasm:       DefaultWasmConfig,
+	Capacity:   DefaultCapacityConfig,
 }
 
 func ValidationConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -71,12 +74,14 @@ func ValidationConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	server_arb.ArbitratorSpawnerConfigAddOptions(prefix+".arbitrator", f)
 	server_jit.JitSpawnerConfigAddOptions(prefix+".jit", f)
 	WasmConfigAddOptions(prefix+".wasm", f)
+	CapacityConfigAddOptions(prefix+".capacity", f)
 }
 
 type ValidationNode struct {
 	config     ValidationConfigFetcher
 	arbSpawner *server_arb.ArbitratorSpawner
 	jitSpawner *server_jit.JitSpawner
+	capacity   *CapacityTracker
 
 	redisConsumer *redis.ValidationServer
 }
@@ -107,7 +112,7 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 	if err != nil {
 		return nil, err
 	}
-	var serverAPI *ExecServerAPI
+	var valSpawner validator.ValidationSpawner = arbSpawner
 	var jitSpawner *server_jit.JitSpawner
 	if config.UseJit {
 		jitConfigFetcher := func() *server_jit.JitSpawnerConfig { return &configFetcher().Jit }
@@ -116,14 +121,18 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 		if err != nil {
 			return nil, err
 		}
-		serverAPI = NewExecutionServerAPI(jitSpawner, arbSpawner, arbConfigFetcher)
-	} else {
-		serverAPI = NewExecutionServerAPI(arbSpawner, arbSpawner, arbConfigFetcher)
+		valSpawner = jitSpawner
 	}
+	capacityConfigFetcher := func() *CapacityConfig { return &configFetcher().Capacity }
+	capacity, err := NewCapacityTracker(capacityConfigFetcher, valSpawner)
+	if err != nil {
+		return nil, err
+	}
+	serverAPI := NewExecutionServerAPI(capacity, arbSpawner, arbConfigFetcher)
 	var redisConsumer *redis.ValidationServer
 	redisValidationConfig := arbConfigFetcher().RedisValidationServerConfig
 	if redisValidationConfig.Enabled() {
-		redisConsumer, err = redis.NewValidationServer(&redisValidationConfig, arbSpawner)
+		redisConsumer, err = redis.NewValidationServer(&redisValidationConfig, capacity.Track(arbSpawner))
 		if err != nil {
 			log.Error("Creating new redis validation server", "error", err)
 		}
@@ -137,7 +146,7 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 	}}
 	stack.RegisterAPIs(valAPIs)
 
-	return &ValidationNode{configFetcher, arbSpawner, jitSpawner, redisConsumer}, nil
+	return &ValidationNode{configFetcher, arbSpawner, jitSpawner, capacity, redisConsumer}, nil
 }
 
 func (v *ValidationNode) Start(ctx context.Context) error {
@@ -149,6 +158,9 @@ func (v *ValidationNode) Start(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := v.capacity.Start(ctx); err != nil {
+		return err
+	}
 	if v.redisConsumer != nil {
 		v.redisConsumer.Start(ctx)
 	}
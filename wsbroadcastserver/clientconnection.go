@@ -62,6 +62,10 @@ type ClientConnection struct {
 	flateReader *wsflate.Reader
 
 	delay time.Duration
+
+	// credentials is nil for anonymous clients
+	credentials *FeedCredentials
+	filter      FeedFilter
 }
 
 func NewClientConnection(
@@ -74,6 +78,8 @@ func NewClientConnection(
 	maxSendQueue int,
 	delay time.Duration,
 	bklg backlog.Backlog,
+	credentials *FeedCredentials,
+	filter FeedFilter,
 ) *ClientConnection {
 	clientConnection := &ClientConnection{
 		conn:            conn,
@@ -90,6 +96,8 @@ func NewClientConnection(
 		backlog:         bklg,
 		registered:      make(chan bool, 1),
 		backlogSent:     false,
+		credentials:     credentials,
+		filter:          filter,
 	}
 	clientConnection.lastHeardUnix.Store(time.Now().Unix())
 	return clientConnection
@@ -167,6 +175,10 @@ func (cc *ClientConnection) writeBacklog(ctx context.Context, segment backlog.Ba
 }
 
 func (cc *ClientConnection) writeBroadcastMessage(bm *m.BroadcastMessage) error {
+	bm = cc.filter.Apply(bm)
+	if bm == nil {
+		return nil
+	}
 	notCompressed, compressed, err := serializeMessage(bm, !cc.compression, cc.compression)
 	if err != nil {
 		return err
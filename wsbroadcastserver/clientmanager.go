@@ -53,9 +53,11 @@ type ClientManager struct {
 	backlog       backlog.Backlog
 
 	connectionLimiter *ConnectionLimiter
+	// authenticator is nil if feed auth is disabled
+	authenticator *FeedAuthenticator
 }
 
-func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetcher, bklg backlog.Backlog) *ClientManager {
+func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetcher, bklg backlog.Backlog, authenticator *FeedAuthenticator) *ClientManager {
 	config := configFetcher()
 	return &ClientManager{
 		poller:            poller,
@@ -66,6 +68,7 @@ func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetc
 		config:            configFetcher,
 		backlog:           bklg,
 		connectionLimiter: NewConnectionLimiter(func() *ConnectionLimiterConfig { return &configFetcher().ConnectionLimits }),
+		authenticator:     authenticator,
 	}
 }
 
@@ -78,7 +81,11 @@ func (cm *ClientManager) registerClient(ctx context.Context, clientConnection *C
 
 	// TODO:(clamb) the clientsTotalFailedRegisterCounter was deleted after backlog logic moved to ClientConnection. Should this metric be reintroduced or will it be ok to just delete completely given the behaviour has changed, ask Lee
 
-	if cm.config().ConnectionLimits.Enable && !cm.connectionLimiter.Register(clientConnection.clientIp) {
+	if clientConnection.credentials != nil {
+		if !cm.authenticator.Register(clientConnection.credentials) {
+			return fmt.Errorf("Connection quota exceeded %s", clientConnection.credentials.Name)
+		}
+	} else if cm.config().ConnectionLimits.Enable && !cm.connectionLimiter.Register(clientConnection.clientIp) {
 		return fmt.Errorf("Connection limited %s", clientConnection.clientIp)
 	}
 
@@ -129,7 +136,9 @@ func (cm *ClientManager) removeClient(clientConnection *ClientConnection) {
 	}
 
 	cm.removeClientImpl(clientConnection)
-	if cm.config().ConnectionLimits.Enable {
+	if clientConnection.credentials != nil {
+		cm.authenticator.Release(clientConnection.credentials)
+	} else if cm.config().ConnectionLimits.Enable {
 		cm.connectionLimiter.Release(clientConnection.clientIp)
 	}
 
@@ -165,13 +174,37 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 		return nil, err
 	}
 
+	// Clients whose filters keep the same part of bm are sent the same data, so it's only serialized once
+	filtered := make(map[filteredPart]*serializedMessage)
 	sendQueueTooLargeCount := 0
 	clientDeleteList := make([]*ClientConnection, 0, len(cm.clientPtrMap))
 	for client := range cm.clientPtrMap {
+		clientNotCompressed, clientCompressed := &notCompressed, &compressed
+		part := filteredPart{kinds: client.filter.allowedKinds(), start: client.filter.trimStart(bm)}
+		if !client.filter.IsEmpty() && part != (filteredPart{kinds: feedKindsAll}) {
+			serialized, ok := filtered[part]
+			if !ok {
+				serialized = &serializedMessage{}
+				if filteredBm := client.filter.Apply(bm); filteredBm != nil {
+					serialized.notCompressed, serialized.compressed, err = serializeMessage(filteredBm, !config.RequireCompression, config.EnableCompression)
+					if err != nil {
+						return nil, err
+					}
+				} else {
+					serialized.empty = true
+				}
+				filtered[part] = serialized
+			}
+			if serialized.empty {
+				continue
+			}
+			clientNotCompressed, clientCompressed = &serialized.notCompressed, &serialized.compressed
+		}
+
 		var data []byte
 		if client.Compression() {
 			if config.EnableCompression {
-				data = compressed.Bytes()
+				data = clientCompressed.Bytes()
 			} else {
 				log.Warn("disconnecting because client has enabled compression, but compression support is disabled", "client", client.Name)
 				clientDeleteList = append(clientDeleteList, client)
@@ -179,7 +212,7 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 			}
 		} else {
 			if !config.RequireCompression {
-				data = notCompressed.Bytes()
+				data = clientNotCompressed.Bytes()
 			} else {
 				log.Warn("disconnecting because client has disabled compression, but compression support is required", "client", client.Name)
 				clientDeleteList = append(clientDeleteList, client)
@@ -221,6 +254,19 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 	return clientDeleteList, nil
 }
 
+// filteredPart identifies the part of a broadcast message a feed filter keeps.
+type filteredPart struct {
+	kinds feedKinds
+	start int
+}
+
+type serializedMessage struct {
+	notCompressed bytes.Buffer
+	compressed    bytes.Buffer
+	// empty is true if the filter left nothing to send
+	empty bool
+}
+
 func serializeMessage(bm *m.BroadcastMessage, enableNonCompressedOutput, enableCompressedOutput bool) (bytes.Buffer, bytes.Buffer, error) {
 	flateWriter, err := flate.NewWriterDict(nil, DeflateCompressionLevel, GetStaticCompressorDictionary())
 	if err != nil {
@@ -0,0 +1,221 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package wsbroadcastserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	clientsUnauthorizedCounter = metrics.NewRegisteredCounter("arb/feed/clients/unauthorized", nil)
+	clientsQuotaLimitedCounter = metrics.NewRegisteredCounter("arb/feed/clients/quota_limited", nil)
+
+	errMissingFeedCredentials = errors.New("missing feed credentials")
+	errInvalidFeedCredentials = errors.New("invalid feed credentials")
+)
+
+type FeedAuthConfig struct {
+	Enable                      bool   `koanf:"enable"`
+	AllowAnonymous              bool   `koanf:"allow-anonymous" reload:"hot"`
+	KeysFile                    string `koanf:"keys-file"`
+	JWTSecretFile               string `koanf:"jwt-secret-file"`
+	DefaultMaxConnections       int    `koanf:"default-max-connections" reload:"hot"`
+	DefaultConnectionsPerMinute int    `koanf:"default-connections-per-minute" reload:"hot"`
+}
+
+var DefaultFeedAuthConfig = FeedAuthConfig{
+	Enable:                      false,
+	AllowAnonymous:              true,
+	KeysFile:                    "",
+	JWTSecretFile:               "",
+	DefaultMaxConnections:       10,
+	DefaultConnectionsPerMinute: 60,
+}
+
+func FeedAuthConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultFeedAuthConfig.Enable, "authenticate clients with an API key or JWT sent as a bearer token in the Authorization header")
+	f.Bool(prefix+".allow-anonymous", DefaultFeedAuthConfig.AllowAnonymous, "allow clients without credentials, which are only subject to the per IP connection limits")
+	f.String(prefix+".keys-file", DefaultFeedAuthConfig.KeysFile, "JSON file holding the list of API keys, with their name and optional max-connections and connections-per-minute quotas")
+	f.String(prefix+".jwt-secret-file", DefaultFeedAuthConfig.JWTSecretFile, "file holding the hex encoded secret of HS256 JWTs, whose sub claim names the client and optional maxConnections and connectionsPerMinute claims set its quotas")
+	f.Int(prefix+".default-max-connections", DefaultFeedAuthConfig.DefaultMaxConnections, "maximum number of concurrent connections per authenticated client that doesn't set its own quota (0 = unlimited)")
+	f.Int(prefix+".default-connections-per-minute", DefaultFeedAuthConfig.DefaultConnectionsPerMinute, "maximum number of new connections per minute per authenticated client that doesn't set its own quota (0 = unlimited)")
+}
+
+func (c *FeedAuthConfig) Validate() error {
+	if c.Enable && c.KeysFile == "" && c.JWTSecretFile == "" {
+		return errors.New("feed auth is enabled but neither keys-file nor jwt-secret-file is set")
+	}
+	if c.DefaultMaxConnections < 0 || c.DefaultConnectionsPerMinute < 0 {
+		return errors.New("feed auth default quotas can't be negative")
+	}
+	return nil
+}
+
+type FeedAuthConfigFetcher func() *FeedAuthConfig
+
+// FeedCredentials identifies an authenticated client and its quotas, where zero quotas use the configured defaults.
+type FeedCredentials struct {
+	Name                 string `json:"name"`
+	Key                  string `json:"key"`
+	MaxConnections       int    `json:"max-connections"`
+	ConnectionsPerMinute int    `json:"connections-per-minute"`
+}
+
+type feedClaims struct {
+	jwt.RegisteredClaims
+	MaxConnections       int `json:"maxConnections"`
+	ConnectionsPerMinute int `json:"connectionsPerMinute"`
+}
+
+// FeedAuthenticator checks client credentials and enforces per client connection quotas.
+type FeedAuthenticator struct {
+	config    FeedAuthConfigFetcher
+	keys      map[common.Hash]*FeedCredentials
+	jwtSecret []byte
+
+	mutex          sync.Mutex
+	connections    map[string]int
+	recentConnects map[string][]time.Time
+}
+
+func NewFeedAuthenticator(configFetcher FeedAuthConfigFetcher) (*FeedAuthenticator, error) {
+	config := configFetcher()
+	a := &FeedAuthenticator{
+		config:         configFetcher,
+		keys:           make(map[common.Hash]*FeedCredentials),
+		connections:    make(map[string]int),
+		recentConnects: make(map[string][]time.Time),
+	}
+	if config.KeysFile != "" {
+		data, err := os.ReadFile(config.KeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading feed keys file: %w", err)
+		}
+		var keys []*FeedCredentials
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("error parsing feed keys file: %w", err)
+		}
+		for _, key := range keys {
+			if key.Name == "" || key.Key == "" {
+				return nil, errors.New("every feed key must have a name and a key")
+			}
+			// Keys are looked up by hash so that the lookup time doesn't depend on the secret
+			a.keys[crypto.Keccak256Hash([]byte(key.Key))] = key
+		}
+	}
+	if config.JWTSecretFile != "" {
+		data, err := os.ReadFile(config.JWTSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading feed JWT secret: %w", err)
+		}
+		a.jwtSecret, err = hexutil.Decode(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding feed JWT secret: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// Authenticate returns the credentials of the bearer token in the Authorization header, which is either
+// an API key or a JWT.
+func (a *FeedAuthenticator) Authenticate(authorization string) (*FeedCredentials, error) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return nil, errMissingFeedCredentials
+	}
+	if key, ok := a.keys[crypto.Keccak256Hash([]byte(token))]; ok {
+		return key, nil
+	}
+	if a.jwtSecret == nil {
+		return nil, errInvalidFeedCredentials
+	}
+	var claims feedClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return a.jwtSecret, nil
+	})
+	if err != nil || claims.Subject == "" {
+		return nil, errInvalidFeedCredentials
+	}
+	return &FeedCredentials{
+		Name:                 claims.Subject,
+		MaxConnections:       claims.MaxConnections,
+		ConnectionsPerMinute: claims.ConnectionsPerMinute,
+	}, nil
+}
+
+func (a *FeedAuthenticator) quotas(c *FeedCredentials) (int, int) {
+	config := a.config()
+	maxConnections, connectionsPerMinute := c.MaxConnections, c.ConnectionsPerMinute
+	if maxConnections == 0 {
+		maxConnections = config.DefaultMaxConnections
+	}
+	if connectionsPerMinute == 0 {
+		connectionsPerMinute = config.DefaultConnectionsPerMinute
+	}
+	return maxConnections, connectionsPerMinute
+}
+
+// Admit checks whether the client can open a new connection, counting the attempt towards its connection rate.
+func (a *FeedAuthenticator) Admit(c *FeedCredentials) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	maxConnections, connectionsPerMinute := a.quotas(c)
+	if maxConnections > 0 && a.connections[c.Name] >= maxConnections {
+		clientsQuotaLimitedCounter.Inc(1)
+		return false
+	}
+	if connectionsPerMinute > 0 {
+		cutoff := time.Now().Add(-time.Minute)
+		recent := a.recentConnects[c.Name]
+		for len(recent) > 0 && recent[0].Before(cutoff) {
+			recent = recent[1:]
+		}
+		if len(recent) >= connectionsPerMinute {
+			a.recentConnects[c.Name] = recent
+			clientsQuotaLimitedCounter.Inc(1)
+			return false
+		}
+		a.recentConnects[c.Name] = append(recent, time.Now())
+	}
+	return true
+}
+
+// Register counts a new connection of the client, returning false if it's over its connection quota.
+func (a *FeedAuthenticator) Register(c *FeedCredentials) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	maxConnections, _ := a.quotas(c)
+	if maxConnections > 0 && a.connections[c.Name] >= maxConnections {
+		clientsQuotaLimitedCounter.Inc(1)
+		return false
+	}
+	a.connections[c.Name]++
+	return true
+}
+
+func (a *FeedAuthenticator) Release(c *FeedCredentials) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.connections[c.Name]--
+	if a.connections[c.Name] <= 0 {
+		delete(a.connections, c.Name)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package wsbroadcastserver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestFeedAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	keysFile := filepath.Join(dir, "keys.json")
+	keys, err := json.Marshal([]*FeedCredentials{
+		{Name: "partner", Key: "partner-key", MaxConnections: 2},
+		{Name: "limited", Key: "limited-key", ConnectionsPerMinute: 1},
+	})
+	Require(t, err)
+	Require(t, os.WriteFile(keysFile, keys, 0o600))
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	secretFile := filepath.Join(dir, "jwt.hex")
+	Require(t, os.WriteFile(secretFile, []byte(hexutil.Encode(secret)), 0o600))
+
+	config := DefaultFeedAuthConfig
+	config.Enable = true
+	config.KeysFile = keysFile
+	config.JWTSecretFile = secretFile
+	a, err := NewFeedAuthenticator(func() *FeedAuthConfig { return &config })
+	Require(t, err)
+
+	_, err = a.Authenticate("")
+	Expect(t, err == errMissingFeedCredentials)
+	_, err = a.Authenticate("Bearer wrong-key")
+	Expect(t, err == errInvalidFeedCredentials)
+
+	partner, err := a.Authenticate("Bearer partner-key")
+	Require(t, err)
+	Expect(t, partner.Name == "partner")
+	Expect(t, a.Admit(partner))
+	Expect(t, a.Register(partner))
+	Expect(t, a.Register(partner))
+	Expect(t, !a.Admit(partner))
+	Expect(t, !a.Register(partner))
+	a.Release(partner)
+	Expect(t, a.Admit(partner))
+
+	limited, err := a.Authenticate("Bearer limited-key")
+	Require(t, err)
+	Expect(t, a.Admit(limited))
+	Expect(t, !a.Admit(limited), "connection rate should be limited")
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, feedClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jwt-client",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		MaxConnections: 1,
+	}).SignedString(secret)
+	Require(t, err)
+	jwtClient, err := a.Authenticate("Bearer " + token)
+	Require(t, err)
+	Expect(t, jwtClient.Name == "jwt-client" && jwtClient.MaxConnections == 1)
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, feedClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jwt-client",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	}).SignedString(secret)
+	Require(t, err)
+	_, err = a.Authenticate("Bearer " + expired)
+	Expect(t, err == errInvalidFeedCredentials, "expired token should be rejected")
+}
@@ -0,0 +1,116 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package wsbroadcastserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+const (
+	FeedFilterBlockMetadata = "block-metadata"
+	FeedFilterConfirmations = "confirmations"
+)
+
+// feedKinds is the set of message kinds a client receives.
+type feedKinds uint8
+
+const (
+	feedKindMessages feedKinds = 1 << iota
+	// feedKindBlockMetadata sends the messages stripped down to their sequence number and block metadata
+	feedKindBlockMetadata
+	feedKindConfirmations
+
+	feedKindsAll = feedKindMessages | feedKindConfirmations
+)
+
+// FeedFilter is a server side filter on the messages sent to a client, negotiated in the websocket handshake.
+// The zero value doesn't filter anything.
+type FeedFilter struct {
+	kinds             feedKinds
+	minSequenceNumber arbutil.MessageIndex
+}
+
+// ParseFeedFilter parses the comma separated list of kinds from the filter handshake header,
+// an empty list meaning all kinds.
+func ParseFeedFilter(kinds string, minSequenceNumber arbutil.MessageIndex) (FeedFilter, error) {
+	filter := FeedFilter{minSequenceNumber: minSequenceNumber}
+	for _, kind := range strings.Split(kinds, ",") {
+		switch strings.TrimSpace(kind) {
+		case "":
+		case FeedFilterBlockMetadata:
+			filter.kinds |= feedKindBlockMetadata
+		case FeedFilterConfirmations:
+			filter.kinds |= feedKindConfirmations
+		default:
+			return FeedFilter{}, fmt.Errorf("unknown feed filter %q", kind)
+		}
+	}
+	return filter, nil
+}
+
+func (f FeedFilter) IsEmpty() bool {
+	return f == FeedFilter{}
+}
+
+// allowedKinds returns the kinds of messages to send.
+func (f FeedFilter) allowedKinds() feedKinds {
+	if f.kinds == 0 {
+		return feedKindsAll
+	}
+	return f.kinds
+}
+
+// trimStart returns the index of the first message of bm at or after the minimum sequence number.
+func (f FeedFilter) trimStart(bm *m.BroadcastMessage) int {
+	start := 0
+	for start < len(bm.Messages) && bm.Messages[start].SequenceNumber < f.minSequenceNumber {
+		start++
+	}
+	return start
+}
+
+// Apply returns the part of bm the client should receive, or nil if there is nothing to send.
+func (f FeedFilter) Apply(bm *m.BroadcastMessage) *m.BroadcastMessage {
+	if f.IsEmpty() {
+		return bm
+	}
+	if start := f.trimStart(bm); start > 0 {
+		bm = &m.BroadcastMessage{
+			Version:                        bm.Version,
+			Messages:                       bm.Messages[start:],
+			ConfirmedSequenceNumberMessage: bm.ConfirmedSequenceNumberMessage,
+		}
+	}
+	return filterKinds(bm, f.allowedKinds())
+}
+
+func filterKinds(bm *m.BroadcastMessage, kinds feedKinds) *m.BroadcastMessage {
+	filtered := &m.BroadcastMessage{Version: bm.Version}
+	if kinds&feedKindMessages != 0 {
+		filtered.Messages = bm.Messages
+	} else if kinds&feedKindBlockMetadata != 0 {
+		for _, msg := range bm.Messages {
+			if msg.BlockMetadata == nil {
+				continue
+			}
+			filtered.Messages = append(filtered.Messages, &m.BroadcastFeedMessage{
+				SequenceNumber: msg.SequenceNumber,
+				Message:        arbostypes.MessageWithMetadata{},
+				BlockMetadata:  msg.BlockMetadata,
+			})
+		}
+	}
+	if kinds&feedKindConfirmations != 0 {
+		filtered.ConfirmedSequenceNumberMessage = bm.ConfirmedSequenceNumberMessage
+	}
+	if len(filtered.Messages) == 0 && filtered.ConfirmedSequenceNumberMessage == nil {
+		return nil
+	}
+	return filtered
+}
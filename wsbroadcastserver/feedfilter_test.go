@@ -0,0 +1,71 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package wsbroadcastserver
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+func testFeedMessage(seqNums ...arbutil.MessageIndex) *m.BroadcastMessage {
+	bm := m.CreateDummyBroadcastMessage(seqNums)
+	bm.Version = m.V1
+	for _, msg := range bm.Messages {
+		msg.BlockMetadata = []byte{0, 2}
+	}
+	bm.ConfirmedSequenceNumberMessage = &m.ConfirmedSequenceNumberMessage{SequenceNumber: 1}
+	return bm
+}
+
+func TestParseFeedFilter(t *testing.T) {
+	filter, err := ParseFeedFilter("", 0)
+	Require(t, err)
+	Expect(t, filter.IsEmpty())
+
+	filter, err = ParseFeedFilter("block-metadata, confirmations", 5)
+	Require(t, err)
+	Expect(t, filter.kinds == feedKindBlockMetadata|feedKindConfirmations)
+	Expect(t, filter.minSequenceNumber == 5)
+
+	_, err = ParseFeedFilter("transactions", 0)
+	Expect(t, err != nil, "expected error for unknown filter")
+}
+
+func TestFeedFilterApply(t *testing.T) {
+	bm := testFeedMessage(10)
+
+	Expect(t, FeedFilter{}.Apply(bm) == bm)
+
+	filter, err := ParseFeedFilter(FeedFilterBlockMetadata, 0)
+	Require(t, err)
+	filtered := filter.Apply(bm)
+	Expect(t, filtered != nil && len(filtered.Messages) == 1)
+	Expect(t, filtered.ConfirmedSequenceNumberMessage == nil)
+	Expect(t, filtered.Messages[0].SequenceNumber == 10)
+	Expect(t, filtered.Messages[0].Message.Message == nil, "block metadata filter should strip the message")
+	Expect(t, len(filtered.Messages[0].BlockMetadata) == 2)
+
+	filter, err = ParseFeedFilter(FeedFilterConfirmations, 0)
+	Require(t, err)
+	filtered = filter.Apply(bm)
+	Expect(t, filtered != nil && len(filtered.Messages) == 0 && filtered.ConfirmedSequenceNumberMessage != nil)
+	Expect(t, filter.Apply(m.CreateDummyBroadcastMessage([]arbutil.MessageIndex{11})) == nil, "expected nothing to send")
+
+	filter, err = ParseFeedFilter("", 12)
+	Require(t, err)
+	filtered = filter.Apply(bm)
+	Expect(t, filtered != nil && len(filtered.Messages) == 0 && filtered.ConfirmedSequenceNumberMessage != nil)
+	filtered = filter.Apply(testFeedMessage(10, 11, 12, 13))
+	Expect(t, filtered != nil && len(filtered.Messages) == 2 && filtered.Messages[0].SequenceNumber == 12)
+
+	// Messages before the minimum are trimmed rather than dropping the whole message
+	filter, err = ParseFeedFilter(FeedFilterBlockMetadata, 12)
+	Require(t, err)
+	Expect(t, filter.trimStart(testFeedMessage(10, 11, 12, 13)) == 2)
+	filtered = filter.Apply(testFeedMessage(11, 12))
+	Expect(t, filtered != nil && len(filtered.Messages) == 1 && filtered.Messages[0].SequenceNumber == 12)
+	Expect(t, len(filtered.Messages[0].BlockMetadata) == 2)
+}
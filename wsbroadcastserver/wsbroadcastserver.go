@@ -36,6 +36,9 @@ var (
 	HTTPHeaderFeedClientVersion       = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Client-Version")
 	HTTPHeaderRequestedSequenceNumber = textproto.CanonicalMIMEHeaderKey("Arbitrum-Requested-Sequence-Number")
 	HTTPHeaderChainId                 = textproto.CanonicalMIMEHeaderKey("Arbitrum-Chain-Id")
+	HTTPHeaderAuthorization           = textproto.CanonicalMIMEHeaderKey("Authorization")
+	HTTPHeaderFeedFilter              = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Filter")
+	HTTPHeaderFeedMinSequenceNumber   = textproto.CanonicalMIMEHeaderKey("Arbitrum-Feed-Min-Sequence-Number")
 	upgradeToWSTimer                  = metrics.NewRegisteredTimer("arb/feed/clients/upgrade/duration", nil)
 	startWithHeaderTimer              = metrics.NewRegisteredTimer("arb/feed/clients/start/duration", nil)
 )
@@ -70,13 +73,18 @@ type BroadcasterConfig struct {
 	ConnectionLimits   ConnectionLimiterConfig `koanf:"connection-limits" reload:"hot"`
 	ClientDelay        time.Duration           `koanf:"client-delay" reload:"hot"`
 	Backlog            backlog.Config          `koanf:"backlog" reload:"hot"`
+	Auth               FeedAuthConfig          `koanf:"auth" reload:"hot"`
+	EnableFilters      bool                    `koanf:"enable-filters" reload:"hot"` // reloaded value will affect only future upgrades to websocket
 }
 
 func (bc *BroadcasterConfig) Validate() error {
 	if !bc.EnableCompression && bc.RequireCompression {
 		return errors.New("require-compression cannot be true while enable-compression is false")
 	}
-	return nil
+	if err := bc.Auth.Validate(); err != nil {
+		return err
+	}
+	return bc.Backlog.Validate()
 }
 
 type BroadcasterConfigFetcher func() *BroadcasterConfig
@@ -105,6 +113,8 @@ func BroadcasterConfigAddOptions(prefix string, f *flag.FlagSet) {
 	ConnectionLimiterConfigAddOptions(prefix+".connection-limits", f)
 	f.Duration(prefix+".client-delay", DefaultBroadcasterConfig.ClientDelay, "delay the first messages sent to each client by this amount")
 	backlog.AddOptions(prefix+".backlog", f)
+	FeedAuthConfigAddOptions(prefix+".auth", f)
+	f.Bool(prefix+".enable-filters", DefaultBroadcasterConfig.EnableFilters, "allow clients to only subscribe to block metadata, confirmations or messages after a sequence number with the "+HTTPHeaderFeedFilter+" and "+HTTPHeaderFeedMinSequenceNumber+" handshake headers")
 }
 
 var DefaultBroadcasterConfig = BroadcasterConfig{
@@ -131,6 +141,8 @@ var DefaultBroadcasterConfig = BroadcasterConfig{
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
 	Backlog:            backlog.DefaultConfig,
+	Auth:               DefaultFeedAuthConfig,
+	EnableFilters:      false,
 }
 
 var DefaultTestBroadcasterConfig = BroadcasterConfig{
@@ -157,6 +169,8 @@ var DefaultTestBroadcasterConfig = BroadcasterConfig{
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
 	Backlog:            backlog.DefaultTestConfig,
+	Auth:               DefaultFeedAuthConfig,
+	EnableFilters:      true,
 }
 
 type WSBroadcastServer struct {
@@ -199,7 +213,14 @@ func (s *WSBroadcastServer) Initialize() error {
 
 	// Make pool of X size, Y sized work queue and one pre-spawned
 	// goroutine.
-	s.clientManager = NewClientManager(s.poller, s.config, s.backlog)
+	var authenticator *FeedAuthenticator
+	if s.config().Auth.Enable {
+		authenticator, err = NewFeedAuthenticator(func() *FeedAuthConfig { return &s.config().Auth })
+		if err != nil {
+			return err
+		}
+	}
+	s.clientManager = NewClientManager(s.poller, s.config, s.backlog, authenticator)
 
 	return nil
 }
@@ -259,6 +280,10 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		var feedClientVersionSeen bool
 		var connectingIP net.IP
 		var requestedSeqNum arbutil.MessageIndex
+		var authorization, filterKinds string
+		var minSeqNum arbutil.MessageIndex
+		var credentials *FeedCredentials
+		var filter FeedFilter
 		upgrader := ws.Upgrader{
 			OnRequest: func(uri []byte) error {
 				if strings.Contains(string(uri), LivenessProbeURI) {
@@ -294,6 +319,19 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 						)
 					}
 					requestedSeqNum = arbutil.MessageIndex(num)
+				} else if headerName == HTTPHeaderAuthorization {
+					authorization = string(value)
+				} else if headerName == HTTPHeaderFeedFilter {
+					filterKinds = string(value)
+				} else if headerName == HTTPHeaderFeedMinSequenceNumber {
+					num, err := strconv.ParseUint(string(value), 0, 64)
+					if err != nil {
+						return ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason(fmt.Sprintf("Malformed HTTP header %s", HTTPHeaderFeedMinSequenceNumber)),
+						)
+					}
+					minSeqNum = arbutil.MessageIndex(num)
 				} else if headerName == HTTPHeaderCloudflareConnectingIP {
 					connectingIP = net.ParseIP(string(value))
 					log.Trace("Client IP parsed from header", "ip", connectingIP, "header", headerName, "value", string(value))
@@ -317,7 +355,44 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 					}
 				}
 
-				if config.ConnectionLimits.Enable && !s.clientManager.connectionLimiter.IsAllowed(connectingIP) {
+				if filterKinds != "" || minSeqNum != 0 {
+					if !config.EnableFilters {
+						return nil, ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason("Feed filters are not enabled."),
+						)
+					}
+					var err error
+					filter, err = ParseFeedFilter(filterKinds, minSeqNum)
+					if err != nil {
+						return nil, ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusBadRequest),
+							ws.RejectionReason(fmt.Sprintf("Malformed HTTP header %s: %v", HTTPHeaderFeedFilter, err)),
+						)
+					}
+				}
+
+				// Authenticated clients are subject to their own quotas instead of the per IP limits
+				if authenticator := s.clientManager.authenticator; authenticator != nil {
+					var err error
+					credentials, err = authenticator.Authenticate(authorization)
+					if errors.Is(err, errMissingFeedCredentials) && config.Auth.AllowAnonymous {
+						credentials = nil
+					} else if err != nil {
+						clientsUnauthorizedCounter.Inc(1)
+						return nil, ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusUnauthorized),
+							ws.RejectionReason(fmt.Sprintf("Unauthorized: %v.", err)),
+						)
+					} else if !authenticator.Admit(credentials) {
+						return nil, ws.RejectConnectionError(
+							ws.RejectionStatus(http.StatusTooManyRequests),
+							ws.RejectionReason("Feed connection quota exceeded."),
+						)
+					}
+				}
+
+				if credentials == nil && config.ConnectionLimits.Enable && !s.clientManager.connectionLimiter.IsAllowed(connectingIP) {
 					return nil, ws.RejectConnectionError(
 						ws.RejectionStatus(http.StatusTooManyRequests),
 						ws.RejectionReason("Too many open feed connections."),
@@ -379,7 +454,7 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		// Register incoming client in clientManager.
 		safeConn := writeDeadliner{conn, config.WriteTimeout}
 
-		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog)
+		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog, credentials, filter)
 		client.Start(ctx)
 
 		// Subscribe to events about conn.